@@ -4,12 +4,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"net"
 	"os"
 	"strconv"
 	"strings"
 
+	"gocheck/internal/checker"
 	"gocheck/internal/db"
 	"gocheck/internal/models"
+	"gocheck/internal/statuscode"
 )
 
 type UptimeKumaMonitor struct {
@@ -26,50 +29,26 @@ type UptimeKumaMonitor struct {
 	JSONPath                string   `json:"jsonPath"`
 	ExpectedValue           string   `json:"expectedValue"`
 	DNSResolveType          string   `json:"dns_resolve_type"`
+	DNSResolveServer        string   `json:"dns_resolve_server"`
 	Method                  string   `json:"method"`
+	Port                    int      `json:"port"`
+	AuthMethod              string   `json:"authMethod"`
+	BasicAuthUser           string   `json:"basic_auth_user"`
+	BasicAuthPass           string   `json:"basic_auth_pass"`
+	IgnoreTLS               bool     `json:"ignoreTls"`
+	MaxRedirects            *int     `json:"maxredirects"`
 }
 
+// parseStatusCodes expands Uptime Kuma's accepted status codes, such as "200-299",
+// skipping invalid entries. A monitor without any valid ones accepts any 2xx or 3xx code.
 func parseStatusCodes(codes []string) []int {
-	if len(codes) == 0 {
-		return []int{200}
-	}
-
-	var result []int
-	seen := make(map[int]bool)
-
+	var valid []string
 	for _, code := range codes {
-		code = strings.TrimSpace(code)
-		if code == "" {
-			continue
-		}
-
-		if strings.Contains(code, "-") {
-			parts := strings.Split(code, "-")
-			if len(parts) == 2 {
-				start, err1 := strconv.Atoi(strings.TrimSpace(parts[0]))
-				end, err2 := strconv.Atoi(strings.TrimSpace(parts[1]))
-				if err1 == nil && err2 == nil && start <= end {
-					for i := start; i <= end && i <= 599; i++ {
-						if !seen[i] {
-							result = append(result, i)
-							seen[i] = true
-						}
-					}
-				}
-			}
-		} else {
-			if num, err := strconv.Atoi(code); err == nil && num >= 100 && num <= 599 {
-				if !seen[num] {
-					result = append(result, num)
-					seen[num] = true
-				}
-			}
+		if _, err := statuscode.Parse([]string{code}); err == nil {
+			valid = append(valid, code)
 		}
 	}
-
-	if len(result) == 0 {
-		return []int{200}
-	}
+	result, _ := statuscode.Parse(valid)
 	return result
 }
 
@@ -81,10 +60,14 @@ func mapUptimeKumaType(kumaType string) models.CheckType {
 		return models.CheckTypePing
 	case "postgres":
 		return models.CheckTypePostgres
+	case "redis":
+		return models.CheckTypeRedis
 	case "json-query":
 		return models.CheckTypeJSONHTTP
 	case "dns":
 		return models.CheckTypeDNS
+	case "port":
+		return models.CheckTypeTCP
 	default:
 		return models.CheckTypeHTTP
 	}
@@ -92,13 +75,13 @@ func mapUptimeKumaType(kumaType string) models.CheckType {
 
 func main() {
 	if len(os.Args) < 2 {
-		log.Fatal("Usage: go run cmd/import/main.go <import.json> [database_path]")
+		log.Fatal("Usage: go run cmd/import/main.go <import.json> [database_url]")
 	}
 
 	jsonPath := os.Args[1]
-	dbPath := "gocheck.db"
+	databaseURL := os.Getenv("DATABASE_URL")
 	if len(os.Args) > 2 {
-		dbPath = os.Args[2]
+		databaseURL = os.Args[2]
 	}
 
 	data, err := os.ReadFile(jsonPath)
@@ -111,7 +94,7 @@ func main() {
 		log.Fatalf("Failed to parse JSON: %v", err)
 	}
 
-	database, err := db.NewDatabase(dbPath)
+	database, err := db.NewDatabaseWithURL(databaseURL)
 	if err != nil {
 		log.Fatalf("Failed to open database: %v", err)
 	}
@@ -140,6 +123,7 @@ func main() {
 			TimeoutSeconds:  monitor.Timeout,
 			Enabled:         monitor.Active,
 			Method:          monitor.Method,
+			FollowRedirects: true,
 		}
 
 		if check.Method == "" {
@@ -152,6 +136,21 @@ func main() {
 		if check.TimeoutSeconds <= 0 {
 			check.TimeoutSeconds = 10
 		}
+		if check.TimeoutSeconds > check.IntervalSeconds {
+			check.TimeoutSeconds = check.IntervalSeconds
+		}
+
+		// Only basic auth maps over; NTLM, mTLS and OAuth2 monitors import without it
+		isHTTP := checkType == models.CheckTypeHTTP || checkType == models.CheckTypeJSONHTTP
+		if isHTTP && monitor.AuthMethod == "basic" && monitor.BasicAuthUser != "" {
+			check.AuthType = models.AuthTypeBasic
+			check.AuthUsername = monitor.BasicAuthUser
+			check.AuthPassword = monitor.BasicAuthPass
+		}
+		if isHTTP {
+			check.InsecureSkipVerify = monitor.IgnoreTLS
+			check.FollowRedirects = monitor.MaxRedirects == nil || *monitor.MaxRedirects > 0
+		}
 
 		switch checkType {
 		case models.CheckTypeHTTP:
@@ -188,6 +187,14 @@ func main() {
 				continue
 			}
 
+		case models.CheckTypeRedis:
+			check.RedisConnString = monitor.DatabaseConnectionString
+			if check.RedisConnString == "" {
+				fmt.Printf("Skipping %s: no connection string\n", monitor.Name)
+				skipped++
+				continue
+			}
+
 		case models.CheckTypeDNS:
 			check.DNSHostname = monitor.Hostname
 			check.DNSRecordType = monitor.DNSResolveType
@@ -199,6 +206,28 @@ func main() {
 			if check.DNSRecordType == "" {
 				check.DNSRecordType = "A"
 			}
+			// Uptime Kuma may list several servers; the check asks the first one
+			if server, _, _ := strings.Cut(monitor.DNSResolveServer, ","); strings.TrimSpace(server) != "" {
+				check.DNSResolver = strings.TrimSpace(server)
+				if monitor.Port > 0 {
+					check.DNSResolver = net.JoinHostPort(check.DNSResolver, strconv.Itoa(monitor.Port))
+				}
+			}
+
+		case models.CheckTypeTCP:
+			check.Host = monitor.Hostname
+			check.Port = monitor.Port
+			if check.Host == "" || check.Port <= 0 {
+				fmt.Printf("Skipping %s: no hostname or port\n", monitor.Name)
+				skipped++
+				continue
+			}
+		}
+
+		if err := checker.ValidateTemplate(check.URL); err != nil {
+			fmt.Printf("Skipping %s: %v\n", monitor.Name, err)
+			skipped++
+			continue
 		}
 
 		if err := database.CreateCheck(&check); err != nil {
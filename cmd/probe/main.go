@@ -2,32 +2,58 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"log"
 	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"os/exec"
-	"runtime"
+	"regexp"
+	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
+	"gocheck/internal/compare"
+	"gocheck/internal/dbpool"
+	"gocheck/internal/dnsrecord"
+	"gocheck/internal/grpchealth"
+	"gocheck/internal/grpctls"
+	"gocheck/internal/jsonpath"
+	"gocheck/internal/mongocheck"
+	"gocheck/internal/ntp"
+	"gocheck/internal/pinger"
+	"gocheck/internal/redis"
+	"gocheck/internal/smtpcheck"
+	"gocheck/internal/sqlcheck"
+	"gocheck/internal/statuscode"
 	"gocheck/proto/pb"
 
+	_ "github.com/go-sql-driver/mysql"
 	_ "github.com/lib/pq"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
 func main() {
 	region := flag.String("region", os.Getenv("REGION"), "Region code (e.g., us-east-1)")
 	token := flag.String("token", os.Getenv("PROBE_TOKEN"), "Probe authentication token")
 	serverAddr := flag.String("server", os.Getenv("SENTINEL_ADDR"), "Sentinel server address (e.g., localhost:50051)")
+	caFile := flag.String("tls-ca", os.Getenv("SENTINEL_TLS_CA_FILE"), "CA certificate to verify the server with (default: system roots)")
+	certFile := flag.String("tls-cert", os.Getenv("PROBE_TLS_CERT_FILE"), "Client certificate for servers that require mTLS")
+	keyFile := flag.String("tls-key", os.Getenv("PROBE_TLS_KEY_FILE"), "Client certificate key for servers that require mTLS")
+	serverName := flag.String("tls-server-name", os.Getenv("SENTINEL_TLS_SERVER_NAME"), "Name expected in the server certificate (default: the server host)")
+	insecureConn := flag.Bool("insecure", os.Getenv("SENTINEL_INSECURE") == "true", "Connect without TLS, sending the token in plaintext (local development only)")
 	flag.Parse()
 
 	if *region == "" {
@@ -40,16 +66,27 @@ func main() {
 		*serverAddr = "localhost:50051"
 	}
 
+	creds := insecure.NewCredentials()
+	if *insecureConn {
+		log.Printf("WARNING: connecting without TLS; the probe token is sent in plaintext")
+	} else {
+		var err error
+		creds, err = grpctls.ClientCredentials(*caFile, *certFile, *keyFile, *serverName)
+		if err != nil {
+			log.Fatalf("Invalid TLS configuration: %v", err)
+		}
+	}
+
 	for {
-		if err := connectAndListen(*region, *token, *serverAddr); err != nil {
+		if err := connectAndListen(*region, *token, *serverAddr, creds); err != nil {
 			log.Printf("Connection error: %v, reconnecting in 2 seconds...", err)
 			time.Sleep(2 * time.Second)
 		}
 	}
 }
 
-func connectAndListen(region, token, serverAddr string) error {
-	conn, err := grpc.Dial(serverAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+func connectAndListen(region, token, serverAddr string, creds credentials.TransportCredentials) error {
+	conn, err := grpc.Dial(serverAddr, grpc.WithTransportCredentials(creds))
 	if err != nil {
 		return fmt.Errorf("failed to connect: %w", err)
 	}
@@ -122,6 +159,7 @@ func performCheck(stream pb.Sentinel_EstablishConnectionClient, cmd *pb.ServerCo
 	var success bool
 	var statusCode int32
 	var errorMessage string
+	var rtt time.Duration // set by checks that measure the round trip themselves
 
 	checkType := cmd.GetCheckType()
 	if checkType == "" && cmd.GetUrl() != "" {
@@ -134,11 +172,27 @@ func performCheck(stream pb.Sentinel_EstablishConnectionClient, cmd *pb.ServerCo
 	case "http", "json_http":
 		success, statusCode, errorMessage = performHTTPCheck(cmd, timeoutSeconds)
 	case "ping":
-		success, statusCode, errorMessage = performPingCheck(cmd, timeoutSeconds)
+		success, statusCode, errorMessage, rtt = performPingCheck(cmd, timeoutSeconds)
 	case "postgres":
 		success, statusCode, errorMessage = performPostgresCheck(cmd, timeoutSeconds)
+	case "mysql":
+		success, statusCode, errorMessage = performMySQLCheck(cmd, timeoutSeconds)
+	case "mongo":
+		success, statusCode, errorMessage = performMongoCheck(cmd, timeoutSeconds)
+	case "redis":
+		success, statusCode, errorMessage = performRedisCheck(cmd, timeoutSeconds)
 	case "dns":
 		success, statusCode, errorMessage = performDNSCheck(cmd, timeoutSeconds)
+	case "tcp":
+		success, statusCode, errorMessage = performTCPCheck(cmd, timeoutSeconds)
+	case "ntp":
+		success, statusCode, errorMessage = performNTPCheck(cmd, timeoutSeconds)
+	case "ssl_cert":
+		success, statusCode, errorMessage = performSSLCertCheck(cmd, timeoutSeconds)
+	case "grpc":
+		success, statusCode, errorMessage, rtt = performGRPCCheck(cmd, timeoutSeconds)
+	case "smtp":
+		success, statusCode, errorMessage = performSMTPCheck(cmd, timeoutSeconds)
 	default:
 		success = false
 		statusCode = 0
@@ -146,6 +200,15 @@ func performCheck(stream pb.Sentinel_EstablishConnectionClient, cmd *pb.ServerCo
 	}
 
 	latency := int32(time.Since(start).Milliseconds())
+	if rtt > 0 {
+		latency = int32(rtt.Milliseconds())
+	}
+
+	// Degraded responses fail HTTP checks, matching the server-side engine
+	if maxMs := cmd.GetMaxResponseTimeMs(); success && maxMs > 0 && latency > maxMs && (checkType == "http" || checkType == "json_http") {
+		success = false
+		errorMessage = fmt.Sprintf("response time %dms exceeds threshold %dms", latency, maxMs)
+	}
 
 	if success {
 		log.Printf("[CHECK] Check completed successfully for check_id=%d, type=%s, latency=%dms", cmd.GetCheckId(), checkType, latency)
@@ -160,6 +223,7 @@ func performCheck(stream pb.Sentinel_EstablishConnectionClient, cmd *pb.ServerCo
 		Success:     success,
 		StatusCode:  statusCode,
 		ErrorMessage: errorMessage,
+		RequestId:    cmd.GetRequestId(),
 	}
 
 	err := stream.Send(&pb.ProbeMessage{
@@ -180,13 +244,39 @@ func performHTTPCheck(cmd *pb.ServerCommand, timeoutSeconds int) (bool, int32, s
 	client := &http.Client{
 		Timeout: time.Duration(timeoutSeconds) * time.Second,
 	}
+	if cmd.GetInsecureSkipVerify() || cmd.GetProxyUrl() != "" {
+		// Cloned from DefaultTransport, so HTTP/2 is still attempted
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.DisableKeepAlives = true
+		if cmd.GetInsecureSkipVerify() {
+			transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+		}
+		if cmd.GetProxyUrl() != "" {
+			proxyURL, err := url.Parse(cmd.GetProxyUrl())
+			if err != nil {
+				return false, 0, fmt.Sprintf("invalid proxy URL: %v", err)
+			}
+			transport.Proxy = http.ProxyURL(proxyURL)
+		}
+		client.Transport = transport
+	}
+	if cmd.GetNoFollowRedirects() {
+		client.CheckRedirect = func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	}
 
 	method := cmd.GetMethod()
 	if method == "" {
 		method = "GET"
 	}
 
-	req, err := http.NewRequest(method, cmd.GetUrl(), nil)
+	var body io.Reader
+	if cmd.GetRequestBody() != "" {
+		body = strings.NewReader(cmd.GetRequestBody())
+	}
+
+	req, err := http.NewRequest(method, cmd.GetUrl(), body)
 	if err != nil {
 		return false, 0, fmt.Sprintf("invalid request: %v", err)
 	}
@@ -194,6 +284,26 @@ func performHTTPCheck(cmd *pb.ServerCommand, timeoutSeconds int) (bool, int32, s
 	if cmd.GetCheckType() == "json_http" {
 		req.Header.Set("Accept", "application/json")
 	}
+	if cmd.GetContentType() != "" {
+		req.Header.Set("Content-Type", cmd.GetContentType())
+	}
+	if cmd.GetBypassCache() {
+		req.Header.Set("Cache-Control", "no-cache")
+		req.Header.Set("Pragma", "no-cache")
+	}
+	switch cmd.GetAuthType() {
+	case "basic":
+		req.SetBasicAuth(cmd.GetAuthUsername(), cmd.GetAuthPassword())
+	case "bearer":
+		req.Header.Set("Authorization", "Bearer "+cmd.GetAuthToken())
+	}
+	for key, value := range cmd.GetHeaders() {
+		if http.CanonicalHeaderKey(key) == "Host" {
+			req.Host = value
+			continue
+		}
+		req.Header.Set(key, value)
+	}
 
 	resp, err := client.Do(req)
 	if err != nil {
@@ -202,7 +312,15 @@ func performHTTPCheck(cmd *pb.ServerCommand, timeoutSeconds int) (bool, int32, s
 	defer resp.Body.Close()
 
 	statusCode := int32(resp.StatusCode)
-	success := resp.StatusCode >= 200 && resp.StatusCode < 400
+	// HTTP checks expect the codes they list, like on the server; JSON HTTP checks
+	// any 2xx or 3xx
+	var expectedCodes []int
+	if cmd.GetCheckType() == "http" {
+		for _, code := range cmd.GetExpectedStatusCodes() {
+			expectedCodes = append(expectedCodes, int(code))
+		}
+	}
+	success := statuscode.Match(resp.StatusCode, expectedCodes)
 
 	if cmd.GetCheckType() == "json_http" && success && cmd.GetJsonPath() != "" {
 		body, err := io.ReadAll(resp.Body)
@@ -215,60 +333,256 @@ func performHTTPCheck(cmd *pb.ServerCommand, timeoutSeconds int) (bool, int32, s
 			return false, statusCode, fmt.Sprintf("invalid JSON: %v", err)
 		}
 
-		value, err := extractJSONValue(jsonData, cmd.GetJsonPath())
+		value, err := jsonpath.Get(jsonData, cmd.GetJsonPath())
 		if err != nil {
 			return false, statusCode, fmt.Sprintf("JSON path error: %v", err)
 		}
 
 		if cmd.GetExpectedJsonValue() != "" {
-			valueStr := fmt.Sprintf("%v", value)
-			if valueStr != cmd.GetExpectedJsonValue() {
-				return false, statusCode, fmt.Sprintf("expected '%s', got '%s'", cmd.GetExpectedJsonValue(), valueStr)
+			valueStr := jsonpath.Format(value)
+			if !compare.Match(valueStr, cmd.GetAssertionOperator(), cmd.GetExpectedJsonValue()) {
+				return false, statusCode, compare.Mismatch(valueStr, cmd.GetAssertionOperator(), cmd.GetExpectedJsonValue())
 			}
 		}
 	}
 
 	if !success {
-		return false, statusCode, fmt.Sprintf("unexpected status code: %d", resp.StatusCode)
+		return false, statusCode, fmt.Sprintf("unexpected status code: %d (expected: %s)", resp.StatusCode, statuscode.Format(expectedCodes))
+	}
+
+	if expected := cmd.GetExpectedHttpVersion(); expected != "" && cmd.GetCheckType() == "http" {
+		got := fmt.Sprintf("%d.%d", resp.ProtoMajor, resp.ProtoMinor)
+		if resp.ProtoMajor >= 2 {
+			got = fmt.Sprintf("%d", resp.ProtoMajor)
+		}
+		if got != expected {
+			return false, statusCode, fmt.Sprintf("served over HTTP/%s, expected HTTP/%s", got, expected)
+		}
+	}
+
+	if name := cmd.GetExpectedHeaderName(); name != "" {
+		if msg := matchHeader(resp.Header, name, cmd.GetExpectedHeaderValue(), cmd.GetExpectedHeaderMode()); msg != "" {
+			return false, statusCode, msg
+		}
+	}
+
+	minBytes, maxBytes := cmd.GetMinResponseBytes(), cmd.GetMaxResponseBytes()
+	checkSize := minBytes > 0 || maxBytes > 0
+	if cmd.GetCheckType() == "http" && (cmd.GetResponseKeyword() != "" || cmd.GetForbiddenBodyKeyword() != "" || checkSize) {
+		body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+		if err != nil {
+			return false, statusCode, fmt.Sprintf("failed to read body: %v", err)
+		}
+		if checkSize {
+			rest, err := io.Copy(io.Discard, io.LimitReader(resp.Body, maxBodySizeBytes+1-int64(len(body))))
+			if err != nil {
+				return false, statusCode, fmt.Sprintf("failed to read body: %v", err)
+			}
+			if msg := matchBodySize(int64(len(body))+rest, int64(minBytes), int64(maxBytes)); msg != "" {
+				return false, statusCode, msg
+			}
+		}
+		if forbidden := cmd.GetForbiddenBodyKeyword(); forbidden != "" && strings.Contains(string(body), forbidden) {
+			return false, statusCode, fmt.Sprintf("forbidden keyword '%s' found in response body", forbidden)
+		}
+		if cmd.GetResponseKeyword() != "" {
+			if msg := matchKeyword(string(body), cmd.GetResponseKeyword(), cmd.GetResponseKeywordMode()); msg != "" {
+				return false, statusCode, msg
+			}
+		}
 	}
 
 	return true, statusCode, ""
 }
 
-func performPingCheck(cmd *pb.ServerCommand, timeoutSeconds int) (bool, int32, string) {
+// matchKeyword returns an error message when the body fails the keyword assertion
+func matchKeyword(body, keyword, mode string) string {
+	switch mode {
+	case "", "contains":
+		if !strings.Contains(body, keyword) {
+			return fmt.Sprintf("keyword '%s' not found in response body", keyword)
+		}
+	case "not_contains":
+		if strings.Contains(body, keyword) {
+			return fmt.Sprintf("keyword '%s' found in response body", keyword)
+		}
+	case "regex":
+		re, err := regexp.Compile(keyword)
+		if err != nil {
+			return fmt.Sprintf("invalid regex: %v", err)
+		}
+		if !re.MatchString(body) {
+			return fmt.Sprintf("regex '%s' did not match response body", keyword)
+		}
+	default:
+		return fmt.Sprintf("unsupported keyword mode: %s", mode)
+	}
+	return ""
+}
+
+// maxBodySizeBytes bounds how much of a body is counted for the size assertion, as on
+// the server
+const maxBodySizeBytes = 64 << 20
+
+// matchBodySize returns an error message when the body size is outside the bounds;
+// zero disables a bound
+func matchBodySize(size, min, max int64) string {
+	if min > 0 && size < min {
+		return fmt.Sprintf("response body is %d bytes, expected at least %d", size, min)
+	}
+	if max > 0 && size > max {
+		if size > maxBodySizeBytes {
+			return fmt.Sprintf("response body is over %d bytes, expected at most %d", maxBodySizeBytes, max)
+		}
+		return fmt.Sprintf("response body is %d bytes, expected at most %d", size, max)
+	}
+	return ""
+}
+
+// matchHeader returns an error message when the response fails the header assertion
+func matchHeader(header http.Header, name, value, mode string) string {
+	values := header.Values(name)
+	if len(values) == 0 {
+		return fmt.Sprintf("response header '%s' not found", name)
+	}
+	if value == "" {
+		return ""
+	}
+
+	got := strings.Join(values, ", ")
+	switch mode {
+	case "exact":
+		if got != value {
+			return fmt.Sprintf("response header '%s' is '%s', expected '%s'", name, got, value)
+		}
+	default:
+		if !strings.Contains(got, value) {
+			return fmt.Sprintf("response header '%s' is '%s', expected it to contain '%s'", name, got, value)
+		}
+	}
+	return ""
+}
+
+// performPingCheck sends the check's echo requests and returns their average round trip,
+// falling back to the ping binary without ICMP sockets. Packet loss above the check's
+// threshold fails it.
+func performPingCheck(cmd *pb.ServerCommand, timeoutSeconds int) (bool, int32, string, time.Duration) {
 	host := cmd.GetHost()
 	if host == "" {
-		return false, 0, "no host specified"
+		return false, 0, "no host specified", 0
 	}
 
 	timeout := time.Duration(timeoutSeconds) * time.Second
-	var cmdExec *exec.Cmd
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
 
-	if runtime.GOOS == "windows" {
-		cmdExec = exec.Command("ping", "-n", "1", "-w", fmt.Sprintf("%d", timeoutSeconds*1000), host)
-	} else {
-		cmdExec = exec.Command("ping", "-c", "1", "-W", fmt.Sprintf("%d", timeoutSeconds), host)
+	stats, err := pinger.Run(ctx, host, nil, int(cmd.GetPingCount()))
+	if errors.Is(err, pinger.ErrUnavailable) {
+		return performExecPing(cmd, timeoutSeconds)
+	}
+	if cmd.GetExpectUnreachable() {
+		switch {
+		case err == nil:
+			return false, 0, "host responded to ping but was expected to be unreachable", 0
+		case errors.Is(err, pinger.ErrNoReply):
+			return true, 0, "", 0
+		}
+		return false, 0, fmt.Sprintf("ping failed: %v", err), 0
+	}
+	if err != nil {
+		return false, 0, fmt.Sprintf("ping failed: %v", err), 0
+	}
+	if msg := packetLossError(cmd, stats); msg != "" {
+		return false, 0, msg, stats.AvgRTT
+	}
+	return true, 200, "", stats.AvgRTT
+}
+
+// packetLossError describes loss above the check's threshold, or returns ""
+func packetLossError(cmd *pb.ServerCommand, stats pinger.Stats) string {
+	if maxLoss := cmd.GetMaxPacketLoss(); maxLoss > 0 && stats.Loss() > float64(maxLoss) {
+		return fmt.Sprintf("packet loss exceeds threshold %d%% (%s)", maxLoss, stats)
 	}
+	return ""
+}
+
+// performExecPing runs the system ping binary, returning the average round trip it
+// reports when there is one
+func performExecPing(cmd *pb.ServerCommand, timeoutSeconds int) (bool, int32, string, time.Duration) {
+	host := cmd.GetHost()
+	timeout := time.Duration(timeoutSeconds) * time.Second
+	args := append(pinger.ExecArgs(int(cmd.GetPingCount()), timeoutSeconds), host)
 
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
-	cmdExec = exec.CommandContext(ctx, cmdExec.Path, cmdExec.Args[1:]...)
+	cmdExec := exec.CommandContext(ctx, "ping", args...)
 
 	output, err := cmdExec.CombinedOutput()
+	if cmd.GetExpectUnreachable() {
+		if unreachable, reason := classifyPingResult(ctx, output, err); !unreachable {
+			return false, 0, reason, 0
+		}
+		return true, 0, "", 0
+	}
 	if err != nil {
-		return false, 0, fmt.Sprintf("ping failed: %v", err)
+		return false, 0, fmt.Sprintf("ping failed: %v", err), 0
+	}
+
+	if stats, ok := pinger.ParseOutput(output); ok && stats.Received > 0 {
+		if msg := packetLossError(cmd, stats); msg != "" {
+			return false, 0, msg, stats.AvgRTT
+		}
+		return true, 200, "", stats.AvgRTT
 	}
 
 	outputStr := string(output)
 	if strings.Contains(outputStr, "time=") || strings.Contains(outputStr, "Time=") {
-		return true, 200, ""
+		return true, 200, "", 0
 	} else if strings.Contains(outputStr, "bytes from") || strings.Contains(outputStr, "Reply from") {
-		return true, 200, ""
+		return true, 200, "", 0
 	}
 
-	return false, 0, "no response from host"
+	return false, 0, "no response from host", 0
+}
+
+// classifyPingResult reports whether a ping run shows the host does not answer; ping
+// exits with 1 when no reply arrived, anything else is an error
+func classifyPingResult(ctx context.Context, output []byte, err error) (bool, string) {
+	if err == nil {
+		return false, "host responded to ping but was expected to be unreachable"
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		return true, "no response before timeout"
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return true, "no response"
+	}
+	return false, fmt.Sprintf("ping failed: %v: %s", err, strings.TrimSpace(string(output)))
 }
 
+// classifyDialError reports whether a failed dial proves the port is closed. Timeouts
+// are not proof since a firewall dropping packets looks the same.
+func classifyDialError(err error) (bool, string) {
+	switch {
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return true, "connection refused"
+	case errors.Is(err, syscall.EHOSTUNREACH), errors.Is(err, syscall.ENETUNREACH):
+		return true, "host unreachable"
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return false, "no response before timeout (filtered or dropped); cannot confirm the port is closed"
+	}
+	return false, fmt.Sprintf("connection failed: %v", err)
+}
+
+// postgresPools keeps the connection of each PostgreSQL check open between runs. The
+// probe isn't told when a check is deleted, so unused pools are left to expire.
+var postgresPools = dbpool.New(10 * time.Minute)
+
 func performPostgresCheck(cmd *pb.ServerCommand, timeoutSeconds int) (bool, int32, string) {
 	if cmd.GetPostgresConnString() == "" {
 		return false, 0, "no connection string specified"
@@ -277,14 +591,10 @@ func performPostgresCheck(cmd *pb.ServerCommand, timeoutSeconds int) (bool, int3
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeoutSeconds)*time.Second)
 	defer cancel()
 
-	db, err := sql.Open("postgres", cmd.GetPostgresConnString())
+	db, err := postgresPools.Get(cmd.GetCheckId(), "postgres", cmd.GetPostgresConnString())
 	if err != nil {
 		return false, 0, fmt.Sprintf("connection error: %v", err)
 	}
-	defer db.Close()
-
-	db.SetConnMaxLifetime(time.Duration(timeoutSeconds) * time.Second)
-	db.SetMaxOpenConns(1)
 
 	if cmd.GetPostgresQuery() == "" {
 		err = db.PingContext(ctx)
@@ -294,16 +604,109 @@ func performPostgresCheck(cmd *pb.ServerCommand, timeoutSeconds int) (bool, int3
 		return true, 200, ""
 	}
 
-	var result string
-	err = db.QueryRowContext(ctx, cmd.GetPostgresQuery()).Scan(&result)
+	return performQuery(ctx, db, cmd)
+}
+
+// performQuery runs the query of a PostgreSQL or MySQL check and asserts on its rows,
+// like the server does
+func performQuery(ctx context.Context, db *sql.DB, cmd *pb.ServerCommand) (bool, int32, string) {
+	result, err := sqlcheck.Query(ctx, db, cmd.GetPostgresQuery())
 	if err != nil {
 		return false, 0, fmt.Sprintf("query failed: %v", err)
 	}
 
-	if cmd.GetExpectedQueryValue() != "" {
-		if result != cmd.GetExpectedQueryValue() {
-			return false, 200, fmt.Sprintf("expected '%s', got '%s'", cmd.GetExpectedQueryValue(), result)
+	assertion := sqlcheck.Assertion{
+		Column:   cmd.GetQueryColumn(),
+		Operator: cmd.GetAssertionOperator(),
+		Expected: cmd.GetExpectedQueryValue(),
+	}
+	if cmd.GetAssertRowCount() {
+		rowCount := int(cmd.GetExpectedRowCount())
+		assertion.RowCount = &rowCount
+	}
+	if err := result.Check(assertion); err != nil {
+		return false, 200, err.Error()
+	}
+
+	return true, 200, ""
+}
+
+// performMySQLCheck mirrors performPostgresCheck; the query fields are shared
+func performMySQLCheck(cmd *pb.ServerCommand, timeoutSeconds int) (bool, int32, string) {
+	if cmd.GetMysqlConnString() == "" {
+		return false, 0, "no connection string specified"
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeoutSeconds)*time.Second)
+	defer cancel()
+
+	db, err := sql.Open("mysql", cmd.GetMysqlConnString())
+	if err != nil {
+		return false, 0, fmt.Sprintf("connection error: %v", err)
+	}
+	defer db.Close()
+
+	db.SetConnMaxLifetime(time.Duration(timeoutSeconds) * time.Second)
+	db.SetMaxOpenConns(1)
+
+	if cmd.GetPostgresQuery() == "" {
+		if err := db.PingContext(ctx); err != nil {
+			return false, 0, fmt.Sprintf("ping failed: %v", err)
 		}
+		return true, 200, ""
+	}
+
+	return performQuery(ctx, db, cmd)
+}
+
+// performMongoCheck mirrors the server's MongoDB check; the query fields are shared
+func performMongoCheck(cmd *pb.ServerCommand, timeoutSeconds int) (bool, int32, string) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeoutSeconds)*time.Second)
+	defer cancel()
+
+	result, err := mongocheck.Check(ctx, cmd.GetMongoConnString(), cmd.GetPostgresQuery())
+	if err != nil {
+		return false, 0, err.Error()
+	}
+
+	if cmd.GetPostgresQuery() != "" && cmd.GetExpectedQueryValue() != "" &&
+		!compare.Match(result, cmd.GetAssertionOperator(), cmd.GetExpectedQueryValue()) {
+		return false, 200, compare.Mismatch(result, cmd.GetAssertionOperator(), cmd.GetExpectedQueryValue())
+	}
+
+	return true, 200, ""
+}
+
+func performRedisCheck(cmd *pb.ServerCommand, timeoutSeconds int) (bool, int32, string) {
+	opts, err := redis.ParseURL(cmd.GetRedisConnString())
+	if err != nil {
+		return false, 0, err.Error()
+	}
+
+	conn, err := redis.Dial(opts, time.Duration(timeoutSeconds)*time.Second)
+	if err != nil {
+		return false, 0, err.Error()
+	}
+	defer conn.Close()
+
+	if _, err := conn.Ping(); err != nil {
+		return false, 0, err.Error()
+	}
+
+	if cmd.GetRedisKey() == "" {
+		return true, 200, ""
+	}
+
+	value, ok, err := conn.Get(cmd.GetRedisKey())
+	if err != nil {
+		return false, 0, err.Error()
+	}
+	if !ok {
+		return false, 200, fmt.Sprintf("key '%s' not found", cmd.GetRedisKey())
+	}
+
+	if cmd.GetExpectedRedisValue() != "" && value != cmd.GetExpectedRedisValue() {
+		return false, 200, fmt.Sprintf("expected '%s', got '%s'", cmd.GetExpectedRedisValue(), value)
 	}
 
 	return true, 200, ""
@@ -319,53 +722,11 @@ func performDNSCheck(cmd *pb.ServerCommand, timeoutSeconds int) (bool, int32, st
 		recordType = "A"
 	}
 
-	resolver := &net.Resolver{
-		PreferGo: true,
-	}
-
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeoutSeconds)*time.Second)
 	defer cancel()
 
-	var records []string
-	var err error
-
-	switch strings.ToUpper(recordType) {
-	case "A":
-		var ips []net.IP
-		ips, err = resolver.LookupIP(ctx, "ip4", cmd.GetDnsHostname())
-		for _, ip := range ips {
-			records = append(records, ip.String())
-		}
-	case "AAAA":
-		var ips []net.IP
-		ips, err = resolver.LookupIP(ctx, "ip6", cmd.GetDnsHostname())
-		for _, ip := range ips {
-			records = append(records, ip.String())
-		}
-	case "CNAME":
-		var cname string
-		cname, err = resolver.LookupCNAME(ctx, cmd.GetDnsHostname())
-		if err == nil {
-			records = append(records, cname)
-		}
-	case "MX":
-		var mxs []*net.MX
-		mxs, err = resolver.LookupMX(ctx, cmd.GetDnsHostname())
-		if err == nil {
-			for _, mx := range mxs {
-				records = append(records, fmt.Sprintf("%s (priority: %d)", mx.Host, mx.Pref))
-			}
-		}
-	case "TXT":
-		var txts []string
-		txts, err = resolver.LookupTXT(ctx, cmd.GetDnsHostname())
-		if err == nil {
-			records = txts
-		}
-	default:
-		err = fmt.Errorf("unsupported record type: %s", recordType)
-	}
-
+	resolver := &dnsrecord.Resolver{Server: cmd.GetDnsResolver()}
+	records, err := resolver.Lookup(ctx, cmd.GetDnsHostname(), recordType)
 	if err != nil {
 		return false, 0, fmt.Sprintf("DNS lookup failed: %v", err)
 	}
@@ -390,36 +751,179 @@ func performDNSCheck(cmd *pb.ServerCommand, timeoutSeconds int) (bool, int32, st
 	return true, 200, ""
 }
 
-func extractJSONValue(data interface{}, path string) (interface{}, error) {
-	parts := strings.Split(path, ".")
-	current := data
+func performTCPCheck(cmd *pb.ServerCommand, timeoutSeconds int) (bool, int32, string) {
+	host := cmd.GetHost()
+	if host == "" {
+		return false, 0, "no host specified"
+	}
 
-	for _, part := range parts {
-		if part == "" {
-			continue
-		}
+	port := int(cmd.GetPort())
+	if port <= 0 || port > 65535 {
+		return false, 0, "invalid port"
+	}
 
-		switch v := current.(type) {
-		case map[string]interface{}:
-			var ok bool
-			current, ok = v[part]
-			if !ok {
-				return nil, fmt.Errorf("key '%s' not found", part)
-			}
-		case []interface{}:
-			idx := 0
-			if _, err := fmt.Sscanf(part, "%d", &idx); err != nil {
-				return nil, fmt.Errorf("invalid array index: %s", part)
-			}
-			if idx < 0 || idx >= len(v) {
-				return nil, fmt.Errorf("array index %d out of bounds", idx)
-			}
-			current = v[idx]
-		default:
-			return nil, fmt.Errorf("cannot access '%s' on non-object/non-array", part)
+	timeout := time.Duration(timeoutSeconds) * time.Second
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(host, strconv.Itoa(port)), timeout)
+	if cmd.GetExpectUnreachable() {
+		if err == nil {
+			conn.Close()
+			return false, 0, fmt.Sprintf("port %d is open but expected to be closed", port)
+		}
+		if closed, reason := classifyDialError(err); !closed {
+			return false, 0, reason
 		}
+		return true, 0, ""
+	}
+	if err != nil {
+		return false, 0, fmt.Sprintf("connection failed: %v", err)
+	}
+	defer conn.Close()
+
+	if cmd.GetExpectedBanner() == "" {
+		return true, 200, ""
+	}
+
+	conn.SetReadDeadline(time.Now().Add(timeout))
+	buf := make([]byte, 1024)
+	n, err := conn.Read(buf)
+	if n == 0 && err != nil {
+		return false, 0, fmt.Sprintf("failed to read banner: %v", err)
+	}
+
+	banner := string(buf[:n])
+	if idx := strings.IndexAny(banner, "\r\n"); idx >= 0 {
+		banner = banner[:idx]
+	}
+	if !strings.Contains(banner, cmd.GetExpectedBanner()) {
+		return false, 200, fmt.Sprintf("expected banner '%s', got '%s'", cmd.GetExpectedBanner(), banner)
+	}
+
+	return true, 200, ""
+}
+
+func performNTPCheck(cmd *pb.ServerCommand, timeoutSeconds int) (bool, int32, string) {
+	host := cmd.GetHost()
+	if host == "" {
+		return false, 0, "no host specified"
+	}
+
+	port := int(cmd.GetPort())
+	if port <= 0 {
+		port = ntp.DefaultPort
+	}
+
+	resp, err := ntp.Query(net.JoinHostPort(host, strconv.Itoa(port)), time.Duration(timeoutSeconds)*time.Second)
+	if err != nil {
+		return false, 0, fmt.Sprintf("NTP query failed: %v", err)
+	}
+
+	maxOffset := int(cmd.GetNtpMaxOffsetMs())
+	if maxOffset <= 0 {
+		maxOffset = 1000
 	}
 
-	return current, nil
+	offset := resp.Offset
+	if offset < 0 {
+		offset = -offset
+	}
+	if offset > time.Duration(maxOffset)*time.Millisecond {
+		return false, 200, fmt.Sprintf("clock offset %s exceeds %dms", resp.Offset, maxOffset)
+	}
+
+	return true, 200, ""
 }
 
+func performSSLCertCheck(cmd *pb.ServerCommand, timeoutSeconds int) (bool, int32, string) {
+	host := cmd.GetHost()
+	if host == "" {
+		return false, 0, "no host specified"
+	}
+
+	port := int(cmd.GetPort())
+	if port <= 0 {
+		port = 443
+	}
+
+	dialer := &net.Dialer{Timeout: time.Duration(timeoutSeconds) * time.Second}
+	conn, err := tls.DialWithDialer(dialer, "tcp", net.JoinHostPort(host, strconv.Itoa(port)), &tls.Config{
+		ServerName:         host,
+		InsecureSkipVerify: cmd.GetInsecureSkipVerify(),
+	})
+	if err != nil {
+		return false, 0, fmt.Sprintf("TLS handshake failed: %v", err)
+	}
+	defer conn.Close()
+
+	certs := conn.ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return false, 0, "no peer certificates presented"
+	}
+
+	threshold := int(cmd.GetCertExpiryThresholdDays())
+	if threshold <= 0 {
+		threshold = 14
+	}
+
+	notAfter := certs[0].NotAfter
+	daysLeft := int(time.Until(notAfter).Hours() / 24)
+	if daysLeft < threshold {
+		return false, 200, fmt.Sprintf("certificate expires on %s (%d days left, threshold %d)", notAfter.UTC().Format(time.RFC3339), daysLeft, threshold)
+	}
+
+	return true, 200, ""
+}
+
+// performGRPCCheck reports the round trip of the health RPC alone, like the server
+func performGRPCCheck(cmd *pb.ServerCommand, timeoutSeconds int) (bool, int32, string, time.Duration) {
+	host := cmd.GetHost()
+	if host == "" {
+		return false, 0, "no host specified", 0
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeoutSeconds)*time.Second)
+	defer cancel()
+
+	var tlsConfig *tls.Config
+	if cmd.GetGrpcTls() {
+		tlsConfig = &tls.Config{ServerName: host, InsecureSkipVerify: cmd.GetInsecureSkipVerify()}
+	}
+
+	addr := net.JoinHostPort(host, strconv.Itoa(int(cmd.GetPort())))
+	status, rtt, err := grpchealth.Check(ctx, addr, cmd.GetGrpcService(), tlsConfig)
+	if err != nil {
+		return false, 0, err.Error(), 0
+	}
+	if status != healthpb.HealthCheckResponse_SERVING {
+		return false, 200, fmt.Sprintf("status %s, expected SERVING", status), rtt
+	}
+
+	return true, 200, "", rtt
+}
+
+func performSMTPCheck(cmd *pb.ServerCommand, timeoutSeconds int) (bool, int32, string) {
+	host := cmd.GetHost()
+	if host == "" {
+		return false, 0, "no host specified"
+	}
+
+	port := int(cmd.GetPort())
+	if port <= 0 {
+		port = smtpcheck.DefaultPort
+	}
+
+	var tlsConfig *tls.Config
+	if cmd.GetSmtpStarttls() {
+		tlsConfig = &tls.Config{ServerName: host, InsecureSkipVerify: cmd.GetInsecureSkipVerify()}
+	}
+
+	result, err := smtpcheck.Check(net.JoinHostPort(host, strconv.Itoa(port)), time.Duration(timeoutSeconds)*time.Second, tlsConfig)
+	if err != nil {
+		return false, 0, err.Error()
+	}
+
+	if cmd.GetExpectedBanner() != "" && !strings.Contains(result.Greeting, cmd.GetExpectedBanner()) {
+		return false, 200, fmt.Sprintf("expected banner '%s', got '%s'", cmd.GetExpectedBanner(), result.Greeting)
+	}
+
+	return true, 200, ""
+}
@@ -0,0 +1,410 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"gocheck/internal/models"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Actions of a declarative apply
+const (
+	applyCreate    = "create"
+	applyUpdate    = "update"
+	applyUnchanged = "unchanged"
+	applyDelete    = "delete"
+)
+
+// checkDefinition is a check of an apply. Besides the create request fields it can
+// name its group and tags, as a config export does; names win over IDs.
+type checkDefinition struct {
+	models.CreateCheckRequest
+	Group string   `json:"group,omitempty"`
+	Tags  []string `json:"tags,omitempty"`
+}
+
+// applyDocument is a parsed apply file. Groups and tags list the details to create
+// missing ones with.
+type applyDocument struct {
+	Groups []models.ExportedGroup
+	Tags   []models.ExportedTag
+	Checks []checkDefinition
+}
+
+// roundTrip decodes a parsed YAML value into v through JSON, so it decodes exactly
+// like an API request
+func roundTrip(value interface{}, v interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// parseCheckDefinitions reads a YAML (or JSON) list of check definitions, or an object
+// with a checks list and optionally groups and tags lists, like a config export.
+// Definitions are enabled unless they say otherwise.
+func parseCheckDefinitions(data []byte) (applyDocument, error) {
+	var doc applyDocument
+	var root interface{}
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return doc, fmt.Errorf("invalid YAML or JSON: %w", err)
+	}
+	if m, ok := root.(map[string]interface{}); ok {
+		root = m["checks"]
+		if err := roundTrip(m["groups"], &doc.Groups); err != nil {
+			return doc, fmt.Errorf("groups: %w", err)
+		}
+		if err := roundTrip(m["tags"], &doc.Tags); err != nil {
+			return doc, fmt.Errorf("tags: %w", err)
+		}
+	}
+	items, ok := root.([]interface{})
+	if !ok {
+		return doc, errors.New("expected a list of checks, or an object with a checks list")
+	}
+	if len(items) > maxImportRows {
+		return doc, fmt.Errorf("at most %d checks can be applied at once", maxImportRows)
+	}
+
+	doc.Checks = make([]checkDefinition, len(items))
+	for i, item := range items {
+		fields, ok := item.(map[string]interface{})
+		if !ok {
+			return doc, fmt.Errorf("checks[%d]: expected an object", i)
+		}
+		if _, ok := fields["enabled"]; !ok {
+			fields["enabled"] = true
+		}
+		if err := roundTrip(fields, &doc.Checks[i]); err != nil {
+			return doc, fmt.Errorf("checks[%d]: %w", i, err)
+		}
+	}
+	return doc, nil
+}
+
+// sameCheckConfig reports whether two checks have the same configuration, write-only
+// secrets included, ignoring identity, tags and snapshots
+func sameCheckConfig(a, b models.Check) bool {
+	config := func(c models.Check) []byte {
+		c.ID, c.CreatedAt, c.Tags = 0, time.Time{}, nil
+		c.SnapshotURL, c.SnapshotTakenAt, c.SnapshotError = "", nil, ""
+		data, _ := json.Marshal(struct {
+			models.Check
+			Secrets []string
+		}{c, []string{c.AuthPassword, c.AuthToken, c.TriggerSecret}})
+		return data
+	}
+	return string(config(a)) == string(config(b))
+}
+
+func tagIDs(tags []models.Tag) []int64 {
+	ids := make([]int64, len(tags))
+	for i, tag := range tags {
+		ids[i] = tag.ID
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// plannedCheck is a definition matched against the stored checks
+type plannedCheck struct {
+	check  models.Check
+	tagIDs []int64 // nil leaves the tags alone
+	action string
+
+	// group and tags are the names to resolve once missing ones are created
+	group string
+	tags  []string
+}
+
+// resolve sets the group and tag IDs from the names, reporting false when one of
+// them does not exist yet
+func (p *plannedCheck) resolve(groups, tags map[string]int64) bool {
+	resolved := true
+	if p.group != "" {
+		id, ok := groups[p.group]
+		p.check.GroupID = &id
+		resolved = ok
+	}
+	if p.tags != nil {
+		p.tagIDs = make([]int64, 0, len(p.tags))
+		for _, name := range p.tags {
+			id, ok := tags[name]
+			p.tagIDs = append(p.tagIDs, id)
+			resolved = resolved && ok
+		}
+		slices.Sort(p.tagIDs)
+	}
+	return resolved
+}
+
+// applyRefs maps group and tag names to IDs, and lists the referenced ones that do
+// not exist yet
+type applyRefs struct {
+	groups, tags               map[string]int64
+	missingGroups, missingTags []string
+}
+
+// loadApplyRefs maps the stored groups and tags by name. Groups names are not unique,
+// so a shared name is an error only when a definition uses it.
+func (h *Handlers) loadApplyRefs(doc applyDocument) (*applyRefs, []error, error) {
+	groups, err := h.db.GetAllGroups()
+	if err != nil {
+		return nil, nil, err
+	}
+	tags, err := h.db.GetAllTags()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	refs := &applyRefs{groups: map[string]int64{}, tags: map[string]int64{}}
+	groupCount := map[string]int{}
+	for _, group := range groups {
+		refs.groups[group.Name] = group.ID
+		groupCount[group.Name]++
+	}
+	for _, tag := range tags {
+		refs.tags[tag.Name] = tag.ID
+	}
+
+	var errs []error
+	for i, def := range doc.Checks {
+		if def.Group != "" {
+			if groupCount[def.Group] > 1 {
+				errs = append(errs, fmt.Errorf("checks[%d]: %d groups are named %q", i, groupCount[def.Group], def.Group))
+			} else if _, ok := refs.groups[def.Group]; !ok && !slices.Contains(refs.missingGroups, def.Group) {
+				refs.missingGroups = append(refs.missingGroups, def.Group)
+			}
+		}
+		for _, name := range def.Tags {
+			if _, ok := refs.tags[name]; !ok && !slices.Contains(refs.missingTags, name) {
+				refs.missingTags = append(refs.missingTags, name)
+			}
+		}
+	}
+	return refs, errs, nil
+}
+
+// createMissingRefs creates the referenced groups and tags that do not exist, with
+// the details the document gives for them
+func (h *Handlers) createMissingRefs(r *http.Request, doc applyDocument, refs *applyRefs) error {
+	for _, name := range refs.missingGroups {
+		group := models.Group{Name: name}
+		for _, g := range doc.Groups {
+			if g.Name == name {
+				group.SortOrder = g.SortOrder
+			}
+		}
+		if err := h.db.CreateGroup(&group); err != nil {
+			return fmt.Errorf("creating group %q: %w", name, err)
+		}
+		refs.groups[name] = group.ID
+		h.audit(r, models.AuditCreate, models.AuditTargetGroup, group.ID, map[string]string{"name": group.Name, "source": "apply"})
+	}
+	for _, name := range refs.missingTags {
+		tag := models.Tag{Name: name, Color: defaultTagColor}
+		for _, t := range doc.Tags {
+			if t.Name == name && isHexColor(t.Color) {
+				tag.Color = t.Color
+			}
+		}
+		if err := h.db.CreateTag(&tag); err != nil {
+			return fmt.Errorf("creating tag %q: %w", name, err)
+		}
+		refs.tags[name] = tag.ID
+		h.audit(r, models.AuditCreate, models.AuditTargetTag, tag.ID, map[string]string{"name": tag.Name, "color": tag.Color, "source": "apply"})
+	}
+	return nil
+}
+
+// ApplyChecks reconciles the checks with a declarative file, for managing checks from
+// version control, or a config export. Each definition is matched to the stored check
+// with the same name, which is created or updated to match; write-only secrets a
+// definition leaves out are kept, and groups and tags it names are created if missing. With ?prune=true checks missing from the file are deleted, and ?dry_run=true
+// reports the changes without making them. Nothing is changed unless every definition
+// is valid.
+func (h *Handlers) ApplyChecks(w http.ResponseWriter, r *http.Request) {
+	prune, _ := strconv.ParseBool(r.URL.Query().Get("prune"))
+	dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dry_run"))
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxImportBytes))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	doc, err := parseCheckDefinitions(data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	existing, err := h.db.GetAllChecks()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	refs, errs, err := h.loadApplyRefs(doc)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	byName := make(map[string][]models.Check, len(existing))
+	for _, check := range existing {
+		byName[check.Name] = append(byName[check.Name], check)
+	}
+
+	plan := make([]plannedCheck, 0, len(doc.Checks))
+	defined := make(map[string]bool, len(doc.Checks))
+	for i, def := range doc.Checks {
+		if defined[def.Name] {
+			errs = append(errs, fmt.Errorf("checks[%d]: %q is defined more than once", i, def.Name))
+			continue
+		}
+		defined[def.Name] = true
+
+		matches := byName[def.Name]
+		if len(matches) > 1 {
+			errs = append(errs, fmt.Errorf("checks[%d]: %d checks are named %q, rename all but one first", i, len(matches), def.Name))
+			continue
+		}
+		var current *models.Check
+		if len(matches) == 1 {
+			current = &matches[0]
+			if def.AuthPassword == "" {
+				def.AuthPassword = current.AuthPassword
+			}
+			if def.AuthToken == "" {
+				def.AuthToken = current.AuthToken
+			}
+			if def.TriggerSecret == "" {
+				def.TriggerSecret = current.TriggerSecret
+			}
+		}
+
+		check, err := h.newCheck(&def.CreateCheckRequest)
+		if err == nil && !check.Type.Valid() {
+			err = fmt.Errorf("unknown check type %q", check.Type)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("checks[%d] (%s): %w", i, def.Name, err))
+			continue
+		}
+		p := plannedCheck{check: check, action: applyCreate, group: def.Group, tags: def.Tags}
+		if def.TagIDs != nil {
+			p.tagIDs = slices.Sorted(slices.Values(def.TagIDs))
+		}
+		// A group or tag that does not exist yet is a change by itself
+		resolved := p.resolve(refs.groups, refs.tags)
+		if current != nil {
+			p.check.ID, p.check.CreatedAt = current.ID, current.CreatedAt
+			p.action = applyUpdate
+			if resolved && sameCheckConfig(p.check, *current) && (p.tagIDs == nil || slices.Equal(p.tagIDs, tagIDs(current.Tags))) {
+				p.action = applyUnchanged
+			}
+		}
+		plan = append(plan, p)
+	}
+	if len(errs) > 0 {
+		http.Error(w, errors.Join(errs...).Error(), http.StatusBadRequest)
+		return
+	}
+
+	resp := models.CheckApplyResponse{
+		DryRun:        dryRun,
+		Results:       []models.CheckApplyResult{},
+		CreatedGroups: refs.missingGroups,
+		CreatedTags:   refs.missingTags,
+	}
+	if !dryRun {
+		if err := h.createMissingRefs(r, doc, refs); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	for _, p := range plan {
+		result := models.CheckApplyResult{Name: p.check.Name, Action: p.action, ID: p.check.ID}
+		if !dryRun {
+			p.resolve(refs.groups, refs.tags)
+			if err := h.applyCheck(r, &p); err != nil {
+				result.Error = err.Error()
+			}
+			result.ID = p.check.ID
+		}
+		resp.Results = append(resp.Results, result)
+	}
+	if prune {
+		for _, check := range existing {
+			if defined[check.Name] {
+				continue
+			}
+			result := models.CheckApplyResult{Name: check.Name, Action: applyDelete, ID: check.ID}
+			if !dryRun {
+				if err := h.db.DeleteCheck(check.ID); err != nil {
+					result.Error = err.Error()
+				} else {
+					h.engine.RemoveCheck(check.ID)
+					h.audit(r, models.AuditDelete, models.AuditTargetCheck, check.ID, map[string]string{"name": check.Name, "source": "apply"})
+				}
+			}
+			resp.Results = append(resp.Results, result)
+		}
+	}
+
+	for _, result := range resp.Results {
+		if result.Error != "" {
+			continue
+		}
+		switch result.Action {
+		case applyCreate:
+			resp.Created++
+		case applyUpdate:
+			resp.Updated++
+		case applyUnchanged:
+			resp.Unchanged++
+		case applyDelete:
+			resp.Deleted++
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// applyCheck saves one planned check and updates the engine to match
+func (h *Handlers) applyCheck(r *http.Request, p *plannedCheck) error {
+	switch p.action {
+	case applyCreate:
+		if err := h.db.CreateCheck(&p.check); err != nil {
+			return err
+		}
+	case applyUpdate:
+		if err := h.db.UpdateCheck(&p.check); err != nil {
+			return err
+		}
+	default:
+		return nil
+	}
+
+	if p.tagIDs != nil {
+		if err := h.db.SetCheckTags(p.check.ID, p.tagIDs); err != nil {
+			return fmt.Errorf("saved, but setting tags failed: %w", err)
+		}
+	}
+	h.engine.AddCheck(p.check)
+
+	action := models.AuditUpdate
+	if p.action == applyCreate {
+		action = models.AuditCreate
+	}
+	h.audit(r, action, models.AuditTargetCheck, p.check.ID, map[string]string{"name": p.check.Name, "source": "apply"})
+	return nil
+}
@@ -0,0 +1,170 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"gocheck/internal/checker"
+	"gocheck/internal/db"
+	"gocheck/internal/models"
+)
+
+// applyDB stores checks, groups and tags in memory
+type applyDB struct {
+	auditDB
+	checks []models.Check
+	groups []models.Group
+	tags   []models.Tag
+	nextID int64
+}
+
+func (d *applyDB) GetAllGroups() ([]models.Group, error) { return d.groups, nil }
+func (d *applyDB) GetAllTags() ([]models.Tag, error)     { return d.tags, nil }
+
+func (d *applyDB) CreateGroup(g *models.Group) error {
+	d.nextID++
+	g.ID = d.nextID
+	d.groups = append(d.groups, *g)
+	return nil
+}
+
+func (d *applyDB) CreateTag(t *models.Tag) error {
+	d.nextID++
+	t.ID = d.nextID
+	d.tags = append(d.tags, *t)
+	return nil
+}
+
+func (d *applyDB) SetCheckTags(checkID int64, tagIDs []int64) error {
+	var tags []models.Tag
+	for _, tag := range d.tags {
+		if slices.Contains(tagIDs, tag.ID) {
+			tags = append(tags, tag)
+		}
+	}
+	for i := range d.checks {
+		if d.checks[i].ID == checkID {
+			d.checks[i].Tags = tags
+		}
+	}
+	return nil
+}
+
+func (d *applyDB) GetAllChecks() ([]models.Check, error) {
+	return append([]models.Check(nil), d.checks...), nil
+}
+
+func (d *applyDB) CreateCheck(c *models.Check) error {
+	d.nextID++
+	c.ID = d.nextID
+	d.checks = append(d.checks, *c)
+	return nil
+}
+
+func (d *applyDB) GetCheck(id int64) (*models.Check, error) {
+	for _, c := range d.checks {
+		if c.ID == id {
+			return &c, nil
+		}
+	}
+	return nil, nil
+}
+
+func (d *applyDB) UpdateCheck(c *models.Check) error {
+	for i := range d.checks {
+		if d.checks[i].ID == c.ID {
+			d.checks[i] = *c
+		}
+	}
+	return nil
+}
+
+func (d *applyDB) DeleteCheck(id int64) error {
+	for i := range d.checks {
+		if d.checks[i].ID == id {
+			d.checks = append(d.checks[:i], d.checks[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (d *applyDB) find(name string) *models.Check {
+	for i := range d.checks {
+		if d.checks[i].Name == name {
+			return &d.checks[i]
+		}
+	}
+	return nil
+}
+
+func TestApplyChecks(t *testing.T) {
+	fake := &applyDB{}
+	database := &db.Database{DB: fake}
+	h := &Handlers{db: database, engine: checker.NewEngine(database, nil)}
+
+	apply := func(query, body string) (int, string) {
+		req := httptest.NewRequest(http.MethodPost, "/api/checks/apply"+query, strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		h.ApplyChecks(rec, req)
+		return rec.Code, rec.Body.String()
+	}
+
+	initial := `
+- name: api
+  url: https://api.example.com/health
+  interval_seconds: 60
+  enabled: false
+  auth_type: bearer
+  auth_token: secret-token
+- name: legacy
+  url: https://legacy.example.com
+  enabled: false
+`
+	if code, body := apply("", initial); code != http.StatusOK || !strings.Contains(body, `"created":2`) {
+		t.Fatalf("initial apply = %d %s", code, body)
+	}
+
+	// Applying the same file again changes nothing
+	if code, body := apply("", initial); code != http.StatusOK || !strings.Contains(body, `"unchanged":2`) {
+		t.Fatalf("repeated apply = %d %s", code, body)
+	}
+
+	// The token is left out of the file but kept, the interval changes, and legacy is
+	// only deleted for real without dry_run
+	changed := `{"checks": [{"name": "api", "url": "https://api.example.com/health", "interval_seconds": 120, "enabled": false, "auth_type": "bearer"}]}`
+	if code, body := apply("?prune=true&dry_run=true", changed); code != http.StatusOK || !strings.Contains(body, `"updated":1`) || !strings.Contains(body, `"deleted":1`) {
+		t.Fatalf("dry run = %d %s", code, body)
+	}
+	if fake.find("legacy") == nil || fake.find("api").IntervalSeconds != 60 {
+		t.Fatal("dry run changed the checks")
+	}
+	if code, body := apply("?prune=true", changed); code != http.StatusOK {
+		t.Fatalf("prune = %d %s", code, body)
+	}
+	if fake.find("legacy") != nil {
+		t.Error("legacy was not pruned")
+	}
+	api := fake.find("api")
+	if api.IntervalSeconds != 120 || api.AuthToken != "secret-token" {
+		t.Errorf("api = interval %d, token %q", api.IntervalSeconds, api.AuthToken)
+	}
+
+	// One invalid definition rejects the whole file
+	invalid := `
+- name: new
+  url: https://new.example.com
+  enabled: false
+- name: broken
+  type: carrier-pigeon
+`
+	if code, _ := apply("", invalid); code != http.StatusBadRequest {
+		t.Errorf("invalid apply = %d, want 400", code)
+	}
+	if fake.find("new") != nil {
+		t.Error("a check was created from a rejected file")
+	}
+}
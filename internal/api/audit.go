@@ -0,0 +1,172 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gocheck/internal/checker"
+	"gocheck/internal/db"
+	"gocheck/internal/models"
+	"gocheck/internal/snapshot"
+)
+
+const (
+	// redactedValue replaces secret setting values in the audit log
+	redactedValue = "[redacted]"
+
+	// defaultAuditLimit is the page size when no limit is given
+	defaultAuditLimit = 100
+)
+
+// secretSettingMarkers are substrings of the settings keys that hold credentials.
+// Webhook URLs embed their token, and Apprise service URLs their passwords.
+var secretSettingMarkers = []string{"key", "token", "password", "secret", "webhook_url", "apprise_urls"}
+
+func isSecretSetting(key string) bool {
+	for _, marker := range secretSettingMarkers {
+		if strings.Contains(key, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetSessionLookup sets how handlers identify the user behind a request for the
+// audit log
+func (h *Handlers) SetSessionLookup(sessions interface {
+	GetSession(r *http.Request) (*models.Session, bool)
+}) {
+	h.sessions = sessions
+}
+
+// audit records a configuration change made by the request's user. targetID 0 means
+// the change has no single target. Failures are logged rather than failing a change
+// that has already been made.
+func (h *Handlers) audit(r *http.Request, action, targetType string, targetID int64, details map[string]string) {
+	entry := models.AuditEntry{Action: action, TargetType: targetType, Details: details}
+	if targetID != 0 {
+		entry.TargetID = &targetID
+	}
+	if h.sessions != nil {
+		if session, _ := h.sessions.GetSession(r); session != nil {
+			entry.UserID = &session.UserID
+			entry.Username = session.Username
+		}
+	}
+
+	if err := h.db.CreateAuditEntry(&entry); err != nil {
+		log.Printf("Failed to write audit entry (%s %s %d): %v", action, targetType, targetID, err)
+	}
+}
+
+// auditedSettings reads the values of every setting the settings API writes
+func auditedSettings(database *db.Database) map[string]string {
+	values := notifierSettings(database)
+	for _, key := range []string{
+		"tailscale_api_key",
+		"tailscale_tailnet",
+		"browserless_url",
+		"browserless_token",
+		checker.NotifyMisconfiguredSetting,
+		db.HistoryRetentionSetting,
+		snapshot.IntervalSetting,
+		snapshot.WidthSetting,
+		snapshot.HeightSetting,
+		snapshot.FullPageSetting,
+	} {
+		values[key], _ = database.GetSetting(key)
+	}
+	return values
+}
+
+// settingChanges lists the settings whose value differs, with their new values and
+// secrets redacted
+func settingChanges(before, after map[string]string) map[string]string {
+	changes := map[string]string{}
+	for key, value := range after {
+		if before[key] == value {
+			continue
+		}
+		if isSecretSetting(key) && value != "" {
+			value = redactedValue
+		}
+		changes[key] = value
+	}
+	for key := range before {
+		if _, ok := after[key]; !ok {
+			changes[key] = ""
+		}
+	}
+	return changes
+}
+
+// parseAuditFilter reads the limit, offset, range, since and until params. since and
+// until are RFC 3339 timestamps; since overrides range.
+func parseAuditFilter(r *http.Request) (models.AuditFilter, error) {
+	var filter models.AuditFilter
+	q := r.URL.Query()
+
+	for param, target := range map[string]*int{
+		"limit":  &filter.Limit,
+		"offset": &filter.Offset,
+	} {
+		if v := q.Get(param); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				return filter, fmt.Errorf("invalid %s", param)
+			}
+			*target = n
+		}
+	}
+
+	since, err := parseRangeParam(r)
+	if err != nil {
+		return filter, err
+	}
+	filter.Since = since
+	for param, target := range map[string]**time.Time{
+		"since": &filter.Since,
+		"until": &filter.Until,
+	} {
+		if v := q.Get(param); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return filter, fmt.Errorf("invalid %s, expected an RFC 3339 timestamp", param)
+			}
+			*target = &t
+		}
+	}
+	return filter, nil
+}
+
+// GetAuditLog returns a page of configuration changes, newest first
+func (h *Handlers) GetAuditLog(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseAuditFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultAuditLimit
+	}
+	filter.Limit = h.capRows(filter.Limit)
+
+	entries, total, err := h.db.GetAuditEntries(filter)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.AuditLogResponse{
+		Entries: entries,
+		Total:   total,
+		Limit:   filter.Limit,
+		Offset:  filter.Offset,
+	})
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gocheck/internal/checker"
+	"gocheck/internal/db"
+	"gocheck/internal/models"
+)
+
+// auditDB records audit entries in memory; fakes of handlers that change
+// configuration embed it in place of db.DB
+type auditDB struct {
+	db.DB
+	entries []models.AuditEntry
+}
+
+func (d *auditDB) CreateAuditEntry(entry *models.AuditEntry) error {
+	entry.ID = int64(len(d.entries) + 1)
+	d.entries = append(d.entries, *entry)
+	return nil
+}
+
+type staticSession struct{ session *models.Session }
+
+func (s staticSession) GetSession(r *http.Request) (*models.Session, bool) {
+	return s.session, false
+}
+
+func TestSettingChanges(t *testing.T) {
+	before := map[string]string{
+		"discord_webhook_url": "https://discord.example/old",
+		"tailscale_tailnet":   "example.com",
+		"smtp_password":       "hunter2",
+		"smtp_host":           "mail.example.com",
+	}
+	after := map[string]string{
+		"discord_webhook_url": "https://discord.example/new",
+		"tailscale_tailnet":   "example.org",
+		"smtp_password":       "",
+		"smtp_host":           "mail.example.com",
+	}
+
+	changes := settingChanges(before, after)
+	want := map[string]string{
+		"discord_webhook_url": redactedValue,
+		"tailscale_tailnet":   "example.org",
+		"smtp_password":       "",
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("changes = %v, want %v", changes, want)
+	}
+	for key, value := range want {
+		if changes[key] != value {
+			t.Errorf("changes[%s] = %q, want %q", key, changes[key], value)
+		}
+	}
+}
+
+func TestAuditRecordsUser(t *testing.T) {
+	fake := &bulkDB{ids: map[int64]bool{1: true}}
+	database := &db.Database{DB: fake}
+	h := &Handlers{db: database, engine: checker.NewEngine(database, nil)}
+	h.SetSessionLookup(staticSession{&models.Session{UserID: 7, Username: "alice"}})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/checks/bulk", strings.NewReader(`{"ids": [1, 2], "action": "delete"}`))
+	h.BulkUpdateChecks(httptest.NewRecorder(), req)
+
+	if len(fake.entries) != 1 {
+		t.Fatalf("entries = %+v, want one for the deleted check", fake.entries)
+	}
+	e := fake.entries[0]
+	if e.UserID == nil || *e.UserID != 7 || e.Username != "alice" {
+		t.Errorf("user = %v %q, want 7 alice", e.UserID, e.Username)
+	}
+	if e.Action != models.AuditDelete || e.TargetType != models.AuditTargetCheck || e.TargetID == nil || *e.TargetID != 1 {
+		t.Errorf("entry = %+v", e)
+	}
+}
+
+func TestNotifierAuditDetailsRedactsConfig(t *testing.T) {
+	details := notifierAuditDetails(&models.NotifierConfig{
+		Type:    "gotify",
+		Name:    "Gotify",
+		Enabled: true,
+		Config:  map[string]string{"server_url": "https://gotify.example", "token": "secret"},
+	})
+
+	if details["config.token"] != redactedValue {
+		t.Errorf("token = %q, want it redacted", details["config.token"])
+	}
+	if details["config.server_url"] != "https://gotify.example" || details["type"] != "gotify" {
+		t.Errorf("details = %v", details)
+	}
+}
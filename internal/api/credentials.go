@@ -0,0 +1,90 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"gocheck/internal/models"
+)
+
+// redactedPassword replaces passwords in connection strings, the same placeholder
+// url.URL.Redacted uses, so a redacted DSN still reads as one
+const redactedPassword = "xxxxx"
+
+// keyValuePassword matches the password options of a libpq key=value connection string
+var keyValuePassword = regexp.MustCompile(`(?i)\b((?:ssl)?password\s*=\s*)('(?:[^'\\]|\\.)*'|\S*)`)
+
+// showsCredentials reports whether the request may read the credentials stored on
+// checks. Only viewers are denied: without users, OptionalAuth lets everyone in as an
+// admin.
+func (h *Handlers) showsCredentials(r *http.Request) bool {
+	if h.sessions == nil {
+		return true
+	}
+	session, _ := h.sessions.GetSession(r)
+	return session == nil || session.Role == models.RoleAdmin
+}
+
+// redactChecks hides the credentials of checks sent to a viewer
+func redactChecks(checks []models.CheckWithStatus) {
+	for i := range checks {
+		redactCheckCredentials(&checks[i].Check)
+	}
+}
+
+// redactGroupedChecks is redactChecks for every group
+func redactGroupedChecks(groups []models.GroupWithChecks) {
+	for i := range groups {
+		redactChecks(groups[i].Checks)
+	}
+}
+
+// redactCheckCredentials masks the passwords in a check's connection strings and
+// proxy URL, leaving hosts and options readable. Result webhook URLs often carry their
+// token in the path, so they are hidden entirely.
+func redactCheckCredentials(check *models.Check) {
+	check.PostgresConnString = redactDSN(check.PostgresConnString)
+	check.MySQLConnString = redactDSN(check.MySQLConnString)
+	check.MongoConnString = redactDSN(check.MongoConnString)
+	check.RedisConnString = redactDSN(check.RedisConnString)
+	check.ProxyURL = redactDSN(check.ProxyURL)
+	if check.ResultWebhookURL != "" {
+		check.ResultWebhookURL = redactedValue
+	}
+}
+
+// redactDSN masks the password in a URL, a libpq key=value string or a MySQL
+// user:password@tcp(host)/db DSN. A URL that doesn't parse is hidden entirely.
+func redactDSN(dsn string) string {
+	if dsn == "" {
+		return dsn
+	}
+
+	if strings.Contains(dsn, "://") {
+		u, err := url.Parse(dsn)
+		if err != nil {
+			return redactedValue
+		}
+		query := u.Query()
+		for key := range query {
+			if strings.Contains(strings.ToLower(key), "password") {
+				query.Set(key, redactedPassword)
+				u.RawQuery = query.Encode()
+			}
+		}
+		return u.Redacted()
+	}
+
+	if keyValuePassword.MatchString(dsn) {
+		return keyValuePassword.ReplaceAllString(dsn, "${1}"+redactedPassword)
+	}
+
+	if at := strings.LastIndex(dsn, "@"); at >= 0 {
+		if user, _, ok := strings.Cut(dsn[:at], ":"); ok {
+			return user + ":" + redactedPassword + dsn[at:]
+		}
+	}
+	return dsn
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	"gocheck/internal/models"
+)
+
+// DashboardResponse bundles everything the frontend needs on initial load
+type DashboardResponse struct {
+	Stats         *models.Stats            `json:"stats"`
+	GroupedChecks []models.GroupWithChecks `json:"grouped_checks"`
+	Groups        []models.Group           `json:"groups"`
+	Tags          []models.Tag             `json:"tags"`
+	Monitoring    models.MonitoringStatus  `json:"monitoring"`
+}
+
+// GetDashboard returns stats, grouped checks, groups and tags in a single round trip.
+// The individual endpoints remain available for incremental updates.
+func (h *Handlers) GetDashboard(w http.ResponseWriter, r *http.Request) {
+	since, err := parseRangeParam(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	tags, err := h.parseTagFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.groupedTimeout)
+	defer cancel()
+
+	var resp DashboardResponse
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var firstErr error
+
+	setErr := func(err error) {
+		mu.Lock()
+		if firstErr == nil {
+			firstErr = err
+		}
+		mu.Unlock()
+	}
+
+	wg.Add(4)
+	go func() {
+		defer wg.Done()
+		stats, err := h.db.GetStats(since, tags)
+		if err != nil {
+			setErr(err)
+			return
+		}
+		stats.RunningChecks, stats.WaitingChecks, stats.MaxConcurrentChecks = h.engine.RunningChecks()
+		resp.Stats = stats
+	}()
+	go func() {
+		defer wg.Done()
+		grouped, err := h.buildGroupedChecks(ctx, since, tags)
+		if err != nil {
+			setErr(err)
+			return
+		}
+		resp.GroupedChecks = grouped
+	}()
+	go func() {
+		defer wg.Done()
+		groups, err := h.db.GetAllGroups()
+		if err != nil {
+			setErr(err)
+			return
+		}
+		resp.Groups = groups
+	}()
+	go func() {
+		defer wg.Done()
+		tags, err := h.db.GetAllTags()
+		if err != nil {
+			setErr(err)
+			return
+		}
+		resp.Tags = tags
+	}()
+	wg.Wait()
+
+	if firstErr != nil {
+		writeGroupedChecksError(w, firstErr)
+		return
+	}
+
+	if !h.showsCredentials(r) {
+		redactGroupedChecks(resp.GroupedChecks)
+	}
+	if resp.Groups == nil {
+		resp.Groups = []models.Group{}
+	}
+	if resp.Tags == nil {
+		resp.Tags = []models.Tag{}
+	}
+	resp.Monitoring = h.engine.MonitoringStatus()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// ETag wraps a read handler so clients polling it can revalidate instead of downloading
+// the same payload again. The response is buffered and tagged with a hash of its body;
+// a request whose If-None-Match carries that tag gets 304 Not Modified without a body.
+// Only complete 200 responses are tagged, so it must not wrap streaming endpoints.
+//
+// The tag is weak because the body may be compressed on the way out. Unless the handler
+// sets its own Cache-Control, responses are private since they depend on the caller's
+// session.
+func ETag(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		buf := getBuffer()
+		defer putBuffer(buf)
+		rec := &etagRecorder{ResponseWriter: w, body: buf, status: http.StatusOK}
+		next(rec, r)
+
+		if rec.status != http.StatusOK {
+			w.WriteHeader(rec.status)
+			w.Write(buf.Bytes())
+			return
+		}
+
+		sum := sha256.Sum256(buf.Bytes())
+		tag := `W/"` + hex.EncodeToString(sum[:16]) + `"`
+		w.Header().Set("ETag", tag)
+		if w.Header().Get("Cache-Control") == "" {
+			w.Header().Set("Cache-Control", "private, no-cache")
+		}
+		if etagMatches(r.Header.Get("If-None-Match"), tag) {
+			w.Header().Del("Content-Type")
+			w.Header().Del("Content-Length")
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Write(buf.Bytes())
+	}
+}
+
+// etagMatches reports whether an If-None-Match header matches tag. The comparison is
+// weak, as RFC 9110 requires for If-None-Match.
+func etagMatches(header, tag string) bool {
+	if header == "" {
+		return false
+	}
+	opaque := strings.TrimPrefix(tag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == opaque {
+			return true
+		}
+	}
+	return false
+}
+
+// etagRecorder holds back the status and body of a response until it is complete
+type etagRecorder struct {
+	http.ResponseWriter
+	body        *bytes.Buffer
+	status      int
+	wroteHeader bool
+}
+
+func (r *etagRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+}
+
+func (r *etagRecorder) Write(p []byte) (int, error) {
+	r.wroteHeader = true
+	return r.body.Write(p)
+}
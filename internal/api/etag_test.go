@@ -0,0 +1,55 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestETag(t *testing.T) {
+	body := `{"up":3}`
+	status := http.StatusOK
+	handler := ETag(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	})
+	get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/checks/grouped", nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		return rec
+	}
+
+	first := get("")
+	tag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || first.Body.String() != body || tag == "" {
+		t.Fatalf("first request = %d %q, ETag %q", first.Code, first.Body.String(), tag)
+	}
+	if cc := first.Header().Get("Cache-Control"); cc != "private, no-cache" {
+		t.Errorf("Cache-Control = %q", cc)
+	}
+
+	for _, header := range []string{tag, `"other", ` + tag, "*"} {
+		rec := get(header)
+		if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+			t.Errorf("If-None-Match %s: got %d with %d bytes, want an empty 304", header, rec.Code, rec.Body.Len())
+		}
+		if rec.Header().Get("ETag") != tag {
+			t.Errorf("If-None-Match %s: 304 without the ETag", header)
+		}
+	}
+
+	body = `{"up":2}`
+	if rec := get(tag); rec.Code != http.StatusOK || rec.Body.String() != body || rec.Header().Get("ETag") == tag {
+		t.Errorf("changed body: got %d %q, ETag %q", rec.Code, rec.Body.String(), rec.Header().Get("ETag"))
+	}
+
+	status = http.StatusInternalServerError
+	if rec := get(""); rec.Code != status || rec.Body.String() != body || rec.Header().Get("ETag") != "" {
+		t.Errorf("error response: got %d %q, ETag %q", rec.Code, rec.Body.String(), rec.Header().Get("ETag"))
+	}
+}
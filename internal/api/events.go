@@ -0,0 +1,93 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"gocheck/internal/models"
+)
+
+// parseEventFilter reads the optional check_id, group_id, tag_id and limit params
+func parseEventFilter(r *http.Request) (models.EventFilter, error) {
+	var filter models.EventFilter
+	q := r.URL.Query()
+
+	for param, target := range map[string]**int64{
+		"check_id": &filter.CheckID,
+		"group_id": &filter.GroupID,
+		"tag_id":   &filter.TagID,
+	} {
+		if v := q.Get(param); v != "" {
+			id, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return filter, fmt.Errorf("invalid %s", param)
+			}
+			*target = &id
+		}
+	}
+
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			return filter, fmt.Errorf("invalid limit")
+		}
+		filter.Limit = limit
+	}
+
+	return filter, nil
+}
+
+// GetEvents returns the chronological feed of up/down transitions across checks
+func (h *Handlers) GetEvents(w http.ResponseWriter, r *http.Request) {
+	since, err := parseRangeParam(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	filter, err := parseEventFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	filter.Limit = h.capRows(filter.Limit)
+
+	events, err := h.db.GetStatusEvents(since, filter)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(events)
+}
+
+// GetIncidents returns downtime incidents overlapping the range, newest first, with the
+// same check, group and tag filters as events
+func (h *Handlers) GetIncidents(w http.ResponseWriter, r *http.Request) {
+	since, err := parseRangeParam(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	filter, err := parseEventFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	filter.Limit = h.capRows(filter.Limit)
+
+	incidents, err := h.db.GetIncidents(since, filter)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(incidents)
+}
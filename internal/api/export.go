@@ -0,0 +1,156 @@
+package api
+
+import (
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"gocheck/internal/models"
+
+	"github.com/gorilla/mux"
+)
+
+// historyCSVHeader lists the columns of the history CSV export
+var historyCSVHeader = []string{"checked_at", "success", "status_code", "response_time_ms", "error_message", "region"}
+
+// GetCheckHistoryCSV streams the raw history of a check as a CSV download. Rows are
+// written as they are read, so large ranges never sit in memory.
+func (h *Handlers) GetCheckHistoryCSV(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+	since, err := parseRangeParam(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	loc, precision, err := parseTimeDisplayParams(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	check, err := h.db.GetCheck(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if check == nil {
+		http.Error(w, "check not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="check-%d-history.csv"`, id))
+
+	cw := csv.NewWriter(w)
+	cw.Write(historyCSVHeader)
+
+	err = h.db.StreamCheckHistory(id, since, func(row *models.CheckHistory) error {
+		t := row.CheckedAt
+		if precision > 0 {
+			t = t.Truncate(precision)
+		}
+		return cw.Write([]string{
+			t.In(loc).Format(time.RFC3339Nano),
+			strconv.FormatBool(row.Success),
+			strconv.Itoa(row.StatusCode),
+			strconv.Itoa(row.ResponseTimeMs),
+			row.ErrorMessage,
+			row.Region,
+		})
+	})
+	cw.Flush()
+
+	// The status line is already sent, so a failure can only cut the download short
+	if err == nil {
+		err = cw.Error()
+	}
+	if err != nil {
+		log.Printf("History CSV export for check %d failed: %v", id, err)
+	}
+}
+
+// exportCheck converts a check for a config export, naming its group and tags
+func exportCheck(check models.Check, groupNames map[int64]string, secrets bool) models.ExportedCheck {
+	exported := models.ExportedCheck{Tags: []string{}}
+	if check.GroupID != nil {
+		exported.Group = groupNames[*check.GroupID]
+	}
+	for _, tag := range check.Tags {
+		exported.Tags = append(exported.Tags, tag.Name)
+	}
+	slices.Sort(exported.Tags)
+	if secrets {
+		exported.AuthPassword = check.AuthPassword
+		exported.AuthToken = check.AuthToken
+		exported.TriggerSecret = check.TriggerSecret
+	}
+
+	check.Tags = nil
+	check.SnapshotURL, check.SnapshotError = "", ""
+	exported.Check = check
+	return exported
+}
+
+// ExportConfig returns every check, group and tag as one JSON document, for backing up
+// the configuration apart from the history or moving it to another instance with
+// POST /api/checks/apply. Write-only secrets are left out unless ?secrets=true.
+func (h *Handlers) ExportConfig(w http.ResponseWriter, r *http.Request) {
+	secrets, _ := strconv.ParseBool(r.URL.Query().Get("secrets"))
+
+	checks, err := h.db.GetAllChecks()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	groups, err := h.db.GetAllGroups()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	tags, err := h.db.GetAllTags()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	export := models.ConfigExport{
+		Version:    models.ConfigExportVersion,
+		ExportedAt: time.Now().UTC(),
+		Secrets:    secrets,
+		Groups:     make([]models.ExportedGroup, 0, len(groups)),
+		Tags:       make([]models.ExportedTag, 0, len(tags)),
+		Checks:     make([]models.ExportedCheck, 0, len(checks)),
+	}
+	groupNames := make(map[int64]string, len(groups))
+	for _, group := range groups {
+		groupNames[group.ID] = group.Name
+		export.Groups = append(export.Groups, models.ExportedGroup{Name: group.Name, SortOrder: group.SortOrder})
+	}
+	for _, tag := range tags {
+		export.Tags = append(export.Tags, models.ExportedTag{Name: tag.Name, Color: tag.Color})
+	}
+	for _, check := range checks {
+		export.Checks = append(export.Checks, exportCheck(check, groupNames, secrets))
+	}
+	// Sorted by name so exports of the same config diff cleanly
+	slices.SortFunc(export.Checks, func(a, b models.ExportedCheck) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", `attachment; filename="check-config.json"`)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(export)
+}
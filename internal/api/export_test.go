@@ -0,0 +1,93 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"gocheck/internal/checker"
+	"gocheck/internal/db"
+	"gocheck/internal/models"
+)
+
+func TestExportConfigRoundTrip(t *testing.T) {
+	newHandlers := func(fake *applyDB) *Handlers {
+		database := &db.Database{DB: fake}
+		return &Handlers{db: database, engine: checker.NewEngine(database, nil)}
+	}
+	export := func(h *Handlers, query string) string {
+		rec := httptest.NewRecorder()
+		h.ExportConfig(rec, httptest.NewRequest(http.MethodGet, "/api/export"+query, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("export = %d %s", rec.Code, rec.Body)
+		}
+		return rec.Body.String()
+	}
+
+	// The check is disabled, so the engine never runs it
+	groupID := int64(7)
+	source := &applyDB{
+		groups: []models.Group{{ID: groupID, Name: "Production", SortOrder: 2}},
+		tags:   []models.Tag{{ID: 3, Name: "api", Color: "#ff0000"}},
+		checks: []models.Check{{
+			ID:                11,
+			Name:              "api",
+			Type:              models.CheckTypeHTTP,
+			URL:               "https://api.example.com/health",
+			IntervalSeconds:   60,
+			TimeoutSeconds:    10,
+			RetryDelaySeconds: 5,
+			GroupID:           &groupID,
+			Tags:              []models.Tag{{ID: 3, Name: "api", Color: "#ff0000"}},
+			Method:            "GET",
+			FollowRedirects:   true,
+			AuthType:          "bearer",
+			AuthToken:         "secret-token",
+			Headers:           map[string]string{"Accept": "application/json"},
+			SnapshotURL:       "/snapshots/11.png",
+
+			ExpectedStatusCodes: []int{200, 204},
+		}},
+	}
+
+	redacted := export(newHandlers(source), "")
+	if strings.Contains(redacted, "secret-token") || strings.Contains(redacted, `"id"`) || strings.Contains(redacted, "snapshot") {
+		t.Errorf("export leaks a secret or instance-specific field: %s", redacted)
+	}
+	full := export(newHandlers(source), "?secrets=true")
+	var doc models.ConfigExport
+	if err := json.Unmarshal([]byte(full), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Checks) != 1 || doc.Checks[0].Group != "Production" || !slices.Equal(doc.Checks[0].Tags, []string{"api"}) {
+		t.Fatalf("exported checks = %+v", doc.Checks)
+	}
+
+	// Applying the export to an empty instance recreates the group, tag and check with
+	// new IDs, and the same config
+	target := &applyDB{nextID: 100}
+	h := newHandlers(target)
+	rec := httptest.NewRecorder()
+	h.ApplyChecks(rec, httptest.NewRequest(http.MethodPost, "/api/checks/apply", strings.NewReader(full)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("apply = %d %s", rec.Code, rec.Body)
+	}
+	if len(target.groups) != 1 || target.groups[0].SortOrder != 2 || len(target.tags) != 1 || target.tags[0].Color != "#ff0000" {
+		t.Fatalf("groups %+v, tags %+v", target.groups, target.tags)
+	}
+	check := target.find("api")
+	if check == nil || check.GroupID == nil || *check.GroupID != target.groups[0].ID || check.AuthToken != "secret-token" {
+		t.Fatalf("applied check = %+v", check)
+	}
+	want := source.checks[0]
+	want.GroupID = check.GroupID
+	if !sameCheckConfig(*check, want) {
+		t.Errorf("applied check differs:\n got %+v\nwant %+v", *check, want)
+	}
+	if got := tagIDs(check.Tags); !slices.Equal(got, []int64{target.tags[0].ID}) {
+		t.Errorf("applied tags = %v", got)
+	}
+}
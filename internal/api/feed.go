@@ -0,0 +1,203 @@
+package api
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"time"
+
+	"gocheck/internal/models"
+)
+
+const feedLimit = 50
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomEntry struct {
+	ID        string   `xml:"id"`
+	Title     string   `xml:"title"`
+	Updated   string   `xml:"updated"`
+	Published string   `xml:"published"`
+	Summary   string   `xml:"summary"`
+	Link      atomLink `xml:"link"`
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type rssGUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	Description string  `xml:"description"`
+	GUID        rssGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+// feedIncident is a single down/recovered event as rendered in the public feeds
+type feedIncident struct {
+	guid    string
+	title   string
+	summary string
+	link    string
+	started time.Time
+	updated time.Time
+}
+
+func requestBaseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+	return scheme + "://" + r.Host
+}
+
+// loadFeedIncidents returns the most recent transitions of public checks, newest first
+func (h *Handlers) loadFeedIncidents(r *http.Request) ([]feedIncident, error) {
+	since, err := parseRangeParam(r)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := h.db.GetStatusEvents(since, models.EventFilter{PublicOnly: true, Limit: feedLimit})
+	if err != nil {
+		return nil, err
+	}
+
+	base := requestBaseURL(r)
+	incidents := make([]feedIncident, 0, len(events))
+	for i := len(events) - 1; i >= 0; i-- {
+		e := events[i]
+		inc := feedIncident{
+			guid:    fmt.Sprintf("urn:gocheck:incident:%d:%s:%d", e.CheckID, e.Region, e.StartedAt.Unix()),
+			link:    fmt.Sprintf("%s/#check-%d", base, e.CheckID),
+			started: e.StartedAt,
+			updated: e.StartedAt,
+		}
+		if e.IsUp {
+			inc.title = fmt.Sprintf("%s recovered (%s)", e.CheckName, e.Region)
+			inc.summary = fmt.Sprintf("%s is back up in region %s", e.CheckName, e.Region)
+		} else {
+			inc.title = fmt.Sprintf("%s is down (%s)", e.CheckName, e.Region)
+			inc.summary = fmt.Sprintf("%s went down in region %s", e.CheckName, e.Region)
+			if e.ErrorMessage != "" {
+				inc.summary += ": " + e.ErrorMessage
+			}
+			if e.EndedAt != nil {
+				inc.updated = *e.EndedAt
+				inc.summary += fmt.Sprintf(" (resolved after %s)", time.Duration(*e.DurationSeconds)*time.Second)
+			}
+		}
+		incidents = append(incidents, inc)
+	}
+
+	return incidents, nil
+}
+
+func feedUpdated(incidents []feedIncident) time.Time {
+	updated := time.Unix(0, 0).UTC()
+	for _, inc := range incidents {
+		if inc.updated.After(updated) {
+			updated = inc.updated
+		}
+	}
+	return updated
+}
+
+// GetAtomFeed renders recent incidents of public checks as an Atom 1.0 feed
+func (h *Handlers) GetAtomFeed(w http.ResponseWriter, r *http.Request) {
+	incidents, err := h.loadFeedIncidents(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	base := requestBaseURL(r)
+	feed := atomFeed{
+		ID:      base + "/api/feed.atom",
+		Title:   "GoCheck incidents",
+		Updated: feedUpdated(incidents).Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: base + "/api/feed.atom", Rel: "self", Type: "application/atom+xml"},
+			{Href: base + "/", Rel: "alternate", Type: "text/html"},
+		},
+	}
+	for _, inc := range incidents {
+		feed.Entries = append(feed.Entries, atomEntry{
+			ID:        inc.guid,
+			Title:     inc.title,
+			Updated:   inc.updated.Format(time.RFC3339),
+			Published: inc.started.Format(time.RFC3339),
+			Summary:   inc.summary,
+			Link:      atomLink{Href: inc.link, Rel: "alternate"},
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+	w.Write([]byte(xml.Header))
+	xml.NewEncoder(w).Encode(feed)
+}
+
+// GetRSSFeed renders recent incidents of public checks as an RSS 2.0 feed
+func (h *Handlers) GetRSSFeed(w http.ResponseWriter, r *http.Request) {
+	incidents, err := h.loadFeedIncidents(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	base := requestBaseURL(r)
+	feed := rssFeed{
+		Version: "2.0",
+		Channel: rssChannel{
+			Title:         "GoCheck incidents",
+			Link:          base + "/",
+			Description:   "Down and recovery events for public checks",
+			LastBuildDate: feedUpdated(incidents).Format(time.RFC1123Z),
+		},
+	}
+	for _, inc := range incidents {
+		feed.Channel.Items = append(feed.Channel.Items, rssItem{
+			Title:       inc.title,
+			Link:        inc.link,
+			Description: inc.summary,
+			GUID:        rssGUID{Value: inc.guid},
+			PubDate:     inc.updated.Format(time.RFC1123Z),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/rss+xml; charset=utf-8")
+	w.Write([]byte(xml.Header))
+	xml.NewEncoder(w).Encode(feed)
+}
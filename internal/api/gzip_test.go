@@ -0,0 +1,62 @@
+package api
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGzip(t *testing.T) {
+	payload := strings.Repeat(`{"name":"api","status":"up"},`, 100)
+	handler := Gzip(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/snapshot.png" {
+			w.Header().Set("Content-Type", "image/png")
+		} else if r.URL.Path != "/sniffed" {
+			w.Header().Set("Content-Type", "application/json")
+		}
+		w.Header().Set("Content-Length", "1")
+		io.WriteString(w, payload)
+	}))
+	get := func(path, acceptEncoding string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if acceptEncoding != "" {
+			req.Header.Set("Accept-Encoding", acceptEncoding)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for _, path := range []string{"/api/checks/grouped", "/sniffed"} {
+		rec := get(path, "deflate, gzip;q=0.8")
+		if rec.Header().Get("Content-Encoding") != "gzip" || rec.Header().Get("Content-Length") != "" {
+			t.Fatalf("%s: Content-Encoding %q, Content-Length %q", path, rec.Header().Get("Content-Encoding"), rec.Header().Get("Content-Length"))
+		}
+		zr, err := gzip.NewReader(rec.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := io.ReadAll(zr)
+		if err != nil || string(body) != payload {
+			t.Errorf("%s: decompressed body %d bytes, err %v", path, len(body), err)
+		}
+		if rec.Header().Get("Vary") != "Accept-Encoding" {
+			t.Errorf("%s: Vary = %q", path, rec.Header().Get("Vary"))
+		}
+	}
+
+	for _, tt := range []struct{ path, acceptEncoding string }{
+		{"/api/checks/grouped", ""},
+		{"/api/checks/grouped", "gzip;q=0"},
+		{"/snapshot.png", "gzip"},
+		{"/api/stream/updates", "gzip"},
+	} {
+		rec := get(tt.path, tt.acceptEncoding)
+		if rec.Header().Get("Content-Encoding") != "" || rec.Body.String() != payload {
+			t.Errorf("%s with %q: Content-Encoding %q, want an uncompressed body", tt.path, tt.acceptEncoding, rec.Header().Get("Content-Encoding"))
+		}
+	}
+}
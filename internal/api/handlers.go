@@ -3,41 +3,69 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"math"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"sort"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"gocheck/internal/checker"
+	"gocheck/internal/compare"
 	"gocheck/internal/db"
+	"gocheck/internal/dnsrecord"
+	"gocheck/internal/geoip"
+	"gocheck/internal/jsonpath"
 	"gocheck/internal/models"
+	"gocheck/internal/mongocheck"
 	"gocheck/internal/notifier"
+	"gocheck/internal/redis"
 	"gocheck/internal/snapshot"
 
 	"github.com/gorilla/mux"
 	tailscale "tailscale.com/client/tailscale/v2"
 )
 
+// regionResultGrace is added to the check timeout when waiting for probe results,
+// covering the round trip to the probe
+const regionResultGrace = 5 * time.Second
+
+// DefaultMaxHistoryRows caps how many history rows a single request may return
+const DefaultMaxHistoryRows = 5000
+
+// DefaultGroupedChecksTimeout bounds how long loading the statuses of all checks for the
+// grouped checks and dashboard endpoints may take
+const DefaultGroupedChecksTimeout = 10 * time.Second
+
 type Handlers struct {
 	db              *db.Database
 	engine          *checker.Engine
 	notifiers       []notifier.Notifier
 	snapshotService *snapshot.Service
 	dataDir         string
+	maxHistoryRows  int
+	historyRollups  bool
+	groupedTimeout  time.Duration
+	geoip           *geoip.Reader
 	sentinelServer  interface {
-		BroadcastCheckFull(check models.Check)
-		BroadcastCheckToRegion(check models.Check, region string)
+		BroadcastCheckFull(check models.Check) map[string]error
+		BroadcastCheckToRegion(check models.Check, region string) map[string]error
+	}
+	sessions interface {
+		GetSession(r *http.Request) (*models.Session, bool)
 	}
 }
 
 func NewHandlers(database *db.Database, engine *checker.Engine, notifiers []notifier.Notifier, snapshotService *snapshot.Service, dataDir string, sentinelServer interface {
-	BroadcastCheckFull(check models.Check)
-	BroadcastCheckToRegion(check models.Check, region string)
+	BroadcastCheckFull(check models.Check) map[string]error
+	BroadcastCheckToRegion(check models.Check, region string) map[string]error
 }) *Handlers {
 	return &Handlers{
 		db:              database,
@@ -45,10 +73,44 @@ func NewHandlers(database *db.Database, engine *checker.Engine, notifiers []noti
 		notifiers:       notifiers,
 		snapshotService: snapshotService,
 		dataDir:         dataDir,
+		maxHistoryRows:  DefaultMaxHistoryRows,
+		groupedTimeout:  DefaultGroupedChecksTimeout,
 		sentinelServer:  sentinelServer,
 	}
 }
 
+// SetMaxHistoryRows sets the server-side cap on rows returned by history and event
+// endpoints; values <= 0 keep the default
+func (h *Handlers) SetMaxHistoryRows(n int) {
+	if n <= 0 {
+		n = DefaultMaxHistoryRows
+	}
+	h.maxHistoryRows = n
+}
+
+// SetGroupedChecksTimeout sets the deadline for loading the statuses of all checks in
+// the grouped checks and dashboard endpoints; values <= 0 keep the default
+func (h *Handlers) SetGroupedChecksTimeout(d time.Duration) {
+	if d <= 0 {
+		d = DefaultGroupedChecksTimeout
+	}
+	h.groupedTimeout = d
+}
+
+// SetHistoryRollups makes history requests longer than a day read the rollup tables
+// kept by the rollup job instead of aggregating raw rows
+func (h *Handlers) SetHistoryRollups(enabled bool) {
+	h.historyRollups = enabled
+}
+
+// capRows bounds a client supplied row limit to the configured maximum
+func (h *Handlers) capRows(limit int) int {
+	if h.maxHistoryRows > 0 && limit > h.maxHistoryRows {
+		return h.maxHistoryRows
+	}
+	return limit
+}
+
 func parseRangeParam(r *http.Request) (*time.Time, error) {
 	rangeStr := r.URL.Query().Get("range")
 	if rangeStr == "" {
@@ -63,8 +125,10 @@ func parseRangeParam(r *http.Request) (*time.Time, error) {
 		dur = 30 * time.Minute
 	case "60m":
 		dur = 60 * time.Minute
-	case "1d":
+	case "1d", "24h":
 		dur = 24 * time.Hour
+	case "7d":
+		dur = 7 * 24 * time.Hour
 	case "30d":
 		dur = 30 * 24 * time.Hour
 	default:
@@ -75,12 +139,337 @@ func parseRangeParam(r *http.Request) (*time.Time, error) {
 	return &t, nil
 }
 
+// parseTimeDisplayParams reads the optional tz (IANA name) and precision (s, ms) params
+// used to present history timestamps. Timestamps are always stored and bucketed in UTC.
+func parseTimeDisplayParams(r *http.Request) (*time.Location, time.Duration, error) {
+	loc := time.UTC
+	if tz := r.URL.Query().Get("tz"); tz != "" {
+		l, err := time.LoadLocation(tz)
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid tz")
+		}
+		loc = l
+	}
+
+	var precision time.Duration
+	switch r.URL.Query().Get("precision") {
+	case "":
+	case "s":
+		precision = time.Second
+	case "ms":
+		precision = time.Millisecond
+	default:
+		return nil, 0, fmt.Errorf("invalid precision")
+	}
+
+	return loc, precision, nil
+}
+
+func formatHistoryTimes(history []models.CheckHistory, loc *time.Location, precision time.Duration) {
+	for i := range history {
+		t := history[i].CheckedAt
+		if precision > 0 {
+			t = t.Truncate(precision)
+		}
+		history[i].CheckedAt = t.In(loc)
+	}
+}
+
+// maxConfirmationThreshold bounds how long a status change may go unnotified
+const maxConfirmationThreshold = 100
+
+// maxPingCount bounds the echo requests a ping check sends per run
+const maxPingCount = 20
+
+// validateCheck rejects check fields that would only fail once the check runs
+func validateCheck(check *models.Check) error {
+	if err := checker.ValidateTemplate(check.URL); err != nil {
+		return fmt.Errorf("url: %w", err)
+	}
+	if err := checker.ValidateTemplate(check.RequestBody); err != nil {
+		return fmt.Errorf("request_body: %w", err)
+	}
+	if err := checker.ValidateKeyword(check.ResponseKeyword, check.ResponseKeywordMode); err != nil {
+		return fmt.Errorf("response_keyword: %w", err)
+	}
+	if check.MinResponseBytes != 0 || check.MaxResponseBytes != 0 {
+		if check.Type != models.CheckTypeHTTP {
+			return fmt.Errorf("min_response_bytes and max_response_bytes are only supported for http checks")
+		}
+		if err := checker.ValidateBodySize(check.MinResponseBytes, check.MaxResponseBytes); err != nil {
+			return err
+		}
+	}
+	if check.ExpectedHTTPVersion != "" {
+		if check.Type != models.CheckTypeHTTP {
+			return fmt.Errorf("expected_http_version is only supported for http checks")
+		}
+		if err := checker.ValidateHTTPVersion(check.ExpectedHTTPVersion); err != nil {
+			return fmt.Errorf("expected_http_version: %w", err)
+		}
+	}
+	if check.ProxyURL != "" {
+		if check.Type != models.CheckTypeHTTP && check.Type != models.CheckTypeJSONHTTP {
+			return fmt.Errorf("proxy_url is only supported for http and json_http checks")
+		}
+		if _, err := checker.ParseProxyURL(check.ProxyURL); err != nil {
+			return fmt.Errorf("proxy_url: %w", err)
+		}
+	}
+	if check.ExpectedHeaderName != "" || check.ExpectedHeaderValue != "" {
+		if check.Type != models.CheckTypeHTTP && check.Type != models.CheckTypeJSONHTTP {
+			return fmt.Errorf("expected_header_name is only supported for http and json_http checks")
+		}
+		if err := checker.ValidateHeaderAssertion(check.ExpectedHeaderName, check.ExpectedHeaderValue, check.ExpectedHeaderMode); err != nil {
+			return err
+		}
+	}
+	for key, value := range check.Headers {
+		if err := checker.ValidateTemplate(value); err != nil {
+			return fmt.Errorf("headers[%s]: %w", key, err)
+		}
+	}
+	if check.ConfirmationThreshold < 0 || check.ConfirmationThreshold > maxConfirmationThreshold {
+		return fmt.Errorf("confirmation_threshold must be between 1 and %d", maxConfirmationThreshold)
+	}
+	if check.RenotifyIntervalMinutes < 0 {
+		return fmt.Errorf("renotify_interval_minutes cannot be negative")
+	}
+	if check.MaxResponseTimeMs < 0 {
+		return fmt.Errorf("max_response_time_ms cannot be negative")
+	}
+	if check.RetentionDays < 0 {
+		return fmt.Errorf("retention_days cannot be negative")
+	}
+	if check.TriggerSecret != "" && len(check.TriggerSecret) < minTriggerSecretLen {
+		return fmt.Errorf("trigger_secret must be at least %d characters", minTriggerSecretLen)
+	}
+	if check.ExpectUnreachable && check.Type != models.CheckTypePing && check.Type != models.CheckTypeTCP {
+		return fmt.Errorf("expect_unreachable is only supported for ping and tcp checks")
+	}
+	if err := validateAuth(check); err != nil {
+		return err
+	}
+	if check.PingCount != 0 || check.MaxPacketLoss != 0 {
+		if check.Type != models.CheckTypePing {
+			return fmt.Errorf("ping_count and max_packet_loss are only supported for ping checks")
+		}
+		if check.PingCount < 0 || check.PingCount > maxPingCount {
+			return fmt.Errorf("ping_count must be between 1 and %d", maxPingCount)
+		}
+		if check.MaxPacketLoss < 0 || check.MaxPacketLoss > 100 {
+			return fmt.Errorf("max_packet_loss must be a percentage between 0 and 100")
+		}
+	}
+	if check.SourceIP != "" {
+		switch check.Type {
+		case models.CheckTypeHTTP, models.CheckTypeJSONHTTP, models.CheckTypeTCP, models.CheckTypePing:
+		default:
+			return fmt.Errorf("source_ip is only supported for http, json_http, tcp and ping checks")
+		}
+		if err := checker.ValidateSourceIP(check.SourceIP); err != nil {
+			return fmt.Errorf("source_ip: %w", err)
+		}
+	}
+	if check.Type == models.CheckTypeRedis {
+		if _, err := redis.ParseURL(check.RedisConnString); err != nil {
+			return fmt.Errorf("redis_conn_string: %w", err)
+		}
+	}
+	if check.Type == models.CheckTypeMongo {
+		if err := mongocheck.ValidateConnString(check.MongoConnString, check.PostgresQuery != ""); err != nil {
+			return fmt.Errorf("mongo_conn_string: %w", err)
+		}
+		if check.PostgresQuery != "" {
+			if _, err := mongocheck.ParseQuery(check.PostgresQuery); err != nil {
+				return fmt.Errorf("postgres_query: %w", err)
+			}
+		}
+	}
+	if check.ExpectedRowCount != nil || check.QueryColumn != "" {
+		if check.Type != models.CheckTypePostgres && check.Type != models.CheckTypeMySQL {
+			return fmt.Errorf("expected_row_count and query_column are only supported for postgres and mysql checks")
+		}
+		if check.PostgresQuery == "" {
+			return fmt.Errorf("expected_row_count and query_column need a postgres_query")
+		}
+	}
+	if check.DNSResolver != "" {
+		if check.Type != models.CheckTypeDNS {
+			return fmt.Errorf("dns_resolver is only supported for dns checks")
+		}
+		addr, err := dnsrecord.ServerAddr(check.DNSResolver)
+		if err != nil {
+			return fmt.Errorf("dns_resolver: %w", err)
+		}
+		check.DNSResolver = addr
+	}
+	if check.AssertionOperator != "" {
+		switch check.Type {
+		case models.CheckTypeJSONHTTP, models.CheckTypePostgres, models.CheckTypeMySQL, models.CheckTypeMongo:
+		default:
+			return fmt.Errorf("assertion_operator is only supported for json_http, postgres, mysql and mongo checks")
+		}
+		if !compare.Valid(check.AssertionOperator) {
+			return fmt.Errorf("assertion_operator must be one of eq, ne, gt, lt, gte, lte or contains")
+		}
+	}
+	if check.JSONPath != "" {
+		if _, err := jsonpath.Compile(check.JSONPath); err != nil {
+			return fmt.Errorf("json_path: %w", err)
+		}
+	}
+	if check.CronExpression != "" {
+		if _, err := checker.ParseCron(check.CronExpression); err != nil {
+			return fmt.Errorf("cron_expression: %w", err)
+		}
+	}
+	if check.ResultWebhookURL != "" {
+		u, err := url.Parse(check.ResultWebhookURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("result_webhook_url must be an http or https URL")
+		}
+	}
+	return nil
+}
+
+// validateAuth checks the HTTP authentication settings and drops the credentials the
+// chosen scheme doesn't use, so switching schemes leaves no stale secrets behind
+func validateAuth(check *models.Check) error {
+	switch check.AuthType {
+	case "", models.AuthTypeNone:
+		check.AuthType = ""
+		check.AuthUsername, check.AuthPassword, check.AuthToken = "", "", ""
+		return nil
+	case models.AuthTypeBasic, models.AuthTypeBearer:
+	default:
+		return fmt.Errorf("auth_type must be none, basic or bearer")
+	}
+	if check.Type != models.CheckTypeHTTP && check.Type != models.CheckTypeJSONHTTP {
+		return fmt.Errorf("auth_type is only supported for http and json_http checks")
+	}
+
+	if check.AuthType == models.AuthTypeBasic {
+		if check.AuthUsername == "" {
+			return fmt.Errorf("auth_username is required for basic auth")
+		}
+		check.AuthToken = ""
+		return nil
+	}
+	if check.AuthToken == "" {
+		return fmt.Errorf("auth_token is required for bearer auth")
+	}
+	check.AuthUsername, check.AuthPassword = "", ""
+	return nil
+}
+
+// checkFilterParams are the query params that switch GetChecks to the paginated response
+var checkFilterParams = []string{"limit", "offset", "type", "enabled", "group_id", "search", "tags"}
+
+// parseTagFilter reads the tags param, a comma-separated list of tag ids or names, and
+// match, which is any (the default) or all
+func (h *Handlers) parseTagFilter(r *http.Request) (models.TagFilter, error) {
+	var filter models.TagFilter
+	q := r.URL.Query()
+	switch q.Get("match") {
+	case "", "any":
+	case "all":
+		filter.MatchAll = true
+	default:
+		return filter, fmt.Errorf("invalid match, expected any or all")
+	}
+
+	var byName map[string]int64
+	for _, tag := range strings.Split(q.Get("tags"), ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+		if id, err := strconv.ParseInt(tag, 10, 64); err == nil {
+			filter.IDs = append(filter.IDs, id)
+			continue
+		}
+		if byName == nil {
+			tags, err := h.db.GetAllTags()
+			if err != nil {
+				return filter, err
+			}
+			byName = make(map[string]int64, len(tags))
+			for _, t := range tags {
+				byName[t.Name] = t.ID
+			}
+		}
+		id, ok := byName[tag]
+		if !ok {
+			return filter, fmt.Errorf("unknown tag: %s", tag)
+		}
+		filter.IDs = append(filter.IDs, id)
+	}
+	return filter, nil
+}
+
+// parseCheckFilter reads the checks list filters. ok is false when none are given, in
+// which case the full list is returned as before.
+func parseCheckFilter(r *http.Request) (filter models.CheckFilter, ok bool, err error) {
+	q := r.URL.Query()
+	for _, param := range checkFilterParams {
+		if q.Has(param) {
+			ok = true
+		}
+	}
+	if !ok {
+		return filter, false, nil
+	}
+
+	for param, target := range map[string]*int{
+		"limit":  &filter.Limit,
+		"offset": &filter.Offset,
+	} {
+		if v := q.Get(param); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				return filter, true, fmt.Errorf("invalid %s", param)
+			}
+			*target = n
+		}
+	}
+	if v := q.Get("enabled"); v != "" {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			return filter, true, fmt.Errorf("invalid enabled")
+		}
+		filter.Enabled = &enabled
+	}
+	if v := q.Get("group_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return filter, true, fmt.Errorf("invalid group_id")
+		}
+		filter.GroupID = &id
+	}
+	filter.Type = models.CheckType(q.Get("type"))
+	filter.Search = strings.TrimSpace(q.Get("search"))
+
+	return filter, true, nil
+}
+
+// GetChecks lists checks with their status and recent history. With any of the filter
+// params it returns a CheckListResponse page instead of the full array.
 func (h *Handlers) GetChecks(w http.ResponseWriter, r *http.Request) {
 	since, err := parseRangeParam(r)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	filter, filtered, err := parseCheckFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if filter.Tags, err = h.parseTagFilter(r); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	// Determine aggregation strategy based on time range
 	var historyLimit int
@@ -112,12 +501,20 @@ func (h *Handlers) GetChecks(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	checks, err := h.db.GetAllChecks()
+	var checks []models.Check
+	var total int
+	if filtered {
+		checks, total, err = h.db.GetChecksFiltered(filter)
+	} else {
+		checks, err = h.db.GetAllChecks()
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	regionsByCheck := h.lastStatusByRegion(checks)
+
 	checksWithStatus := make([]models.CheckWithStatus, 0, len(checks))
 	for _, check := range checks {
 		var history []models.CheckHistory
@@ -134,6 +531,9 @@ func (h *Handlers) GetChecks(w http.ResponseWriter, r *http.Request) {
 			Check:      check,
 			LastStatus: lastStatus,
 			History:    history,
+			Regions:    regionStatusMap(regionsByCheck[check.ID]),
+
+			Misconfigured: h.engine.Misconfiguration(check.ID),
 		}
 
 		if lastStatus != nil {
@@ -143,49 +543,51 @@ func (h *Handlers) GetChecks(w http.ResponseWriter, r *http.Request) {
 
 		checksWithStatus = append(checksWithStatus, cws)
 	}
+	if !h.showsCredentials(r) {
+		redactChecks(checksWithStatus)
+	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(checksWithStatus)
-}
-
-func (h *Handlers) CreateCheck(w http.ResponseWriter, r *http.Request) {
-	var req models.CreateCheckRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	if filtered {
+		json.NewEncoder(w).Encode(models.CheckListResponse{
+			Checks: checksWithStatus,
+			Total:  total,
+			Limit:  filter.Limit,
+			Offset: filter.Offset,
+		})
 		return
 	}
+	json.NewEncoder(w).Encode(checksWithStatus)
+}
 
+// newCheck builds a check from a create request, applying defaults and the same
+// validation for every way checks are created
+func (h *Handlers) newCheck(req *models.CreateCheckRequest) (models.Check, error) {
 	if req.Name == "" {
-		http.Error(w, "name is required", http.StatusBadRequest)
-		return
+		return models.Check{}, fmt.Errorf("name is required")
 	}
 
 	if req.Type == "" {
 		req.Type = models.CheckTypeHTTP
 	}
 
+	limits := h.engine.Limits()
 	intervalSeconds := req.IntervalSeconds.Value
 	if intervalSeconds <= 0 {
-		intervalSeconds = 60
+		intervalSeconds = limits.DefaultIntervalSeconds
 	}
 	timeoutSeconds := req.TimeoutSeconds.Value
 	if timeoutSeconds <= 0 {
-		timeoutSeconds = 10
-	}
-	retries := req.Retries.Value
-	if retries < 0 {
-		retries = 0
-	}
-	if retries > 10 {
-		retries = 10
-	}
-	retryDelaySeconds := req.RetryDelaySeconds.Value
-	if retryDelaySeconds <= 0 {
-		retryDelaySeconds = 5
+		timeoutSeconds = limits.DefaultTimeoutSeconds
+		if timeoutSeconds >= intervalSeconds {
+			timeoutSeconds = max(intervalSeconds-1, 1)
+		}
 	}
-	if retryDelaySeconds > 60 {
-		retryDelaySeconds = 60
+	if err := limits.ValidateTiming(intervalSeconds, timeoutSeconds); err != nil {
+		return models.Check{}, err
 	}
+	retries := limits.ClampRetries(req.Retries.Value)
+	retryDelaySeconds := limits.ClampRetryDelay(req.RetryDelaySeconds.Value)
 
 	check := models.Check{
 		Name:                     req.Name,
@@ -204,27 +606,94 @@ func (h *Handlers) CreateCheck(w http.ResponseWriter, r *http.Request) {
 		PostgresConnString:       req.PostgresConnString,
 		PostgresQuery:            req.PostgresQuery,
 		ExpectedQueryValue:       req.ExpectedQueryValue,
+		AssertionOperator:        req.AssertionOperator,
+		MySQLConnString:          req.MySQLConnString,
+		MongoConnString:          req.MongoConnString,
+		RedisConnString:          req.RedisConnString,
+		RedisKey:                 req.RedisKey,
+		ExpectedRedisValue:       req.ExpectedRedisValue,
 		Host:                     req.Host,
 		DNSHostname:              req.DNSHostname,
 		DNSRecordType:            req.DNSRecordType,
 		ExpectedDNSValue:         req.ExpectedDNSValue,
+		DNSResolver:              req.DNSResolver,
 		TailscaleDeviceID:        req.TailscaleDeviceID,
 		TailscaleServiceHost:     req.TailscaleServiceHost,
 		TailscaleServicePort:     req.TailscaleServicePort.Value,
 		TailscaleServiceProtocol: req.TailscaleServiceProtocol,
 		TailscaleServicePath:     req.TailscaleServicePath,
+		Public:                   req.Public,
+		Port:                     req.Port.Value,
+		ExpectedBanner:           req.ExpectedBanner,
+		GRPCService:              req.GRPCService,
+		GRPCTLS:                  req.GRPCTLS,
+		SMTPStartTLS:             req.SMTPStartTLS,
+		Headers:                  req.Headers,
+		ResultWebhookURL:         req.ResultWebhookURL,
+		RequestBody:              req.RequestBody,
+		ContentType:              req.ContentType,
+		AuthType:                 req.AuthType,
+		AuthUsername:             req.AuthUsername,
+		AuthPassword:             req.AuthPassword,
+		AuthToken:                req.AuthToken,
+		ResponseKeyword:          req.ResponseKeyword,
+		ResponseKeywordMode:      req.ResponseKeywordMode,
+		ExpectedHeaderName:       req.ExpectedHeaderName,
+		ExpectedHeaderValue:      req.ExpectedHeaderValue,
+		ExpectedHeaderMode:       req.ExpectedHeaderMode,
+		ForbiddenBodyKeyword:     req.ForbiddenBodyKeyword,
+		MinResponseBytes:         req.MinResponseBytes.Value,
+		MaxResponseBytes:         req.MaxResponseBytes.Value,
+		ExpectedHTTPVersion:      req.ExpectedHTTPVersion,
+		ProxyURL:                 req.ProxyURL,
+		TriggerSecret:            req.TriggerSecret,
+		BackoffOnFailure:         req.BackoffOnFailure,
+		QueryColumn:              req.QueryColumn,
+		NTPMaxOffsetMs:           req.NTPMaxOffsetMs.Value,
+		CertExpiryThresholdDays:  req.CertExpiryThresholdDays.Value,
+		InsecureSkipVerify:       req.InsecureSkipVerify,
+		CronExpression:           req.CronExpression,
+		ExpectUnreachable:        req.ExpectUnreachable,
+		PingCount:                req.PingCount.Value,
+		MaxPacketLoss:            req.MaxPacketLoss.Value,
+		BypassCache:              req.BypassCache,
+		SourceIP:                 req.SourceIP,
+		ConfirmationThreshold:    req.ConfirmationThreshold.Value,
+		RenotifyIntervalMinutes:  req.RenotifyIntervalMinutes.Value,
+		MaxResponseTimeMs:        req.MaxResponseTimeMs.Value,
+		RetentionDays:            req.RetentionDays.Value,
+	}
+
+	if req.ExpectedRowCount != nil && *req.ExpectedRowCount >= 0 {
+		check.ExpectedRowCount = req.ExpectedRowCount
 	}
-
 	if check.Method == "" {
 		check.Method = "GET"
 	}
-	if len(check.ExpectedStatusCodes) == 0 {
-		check.ExpectedStatusCodes = []int{200}
-	}
+	check.FollowRedirects = req.FollowRedirects == nil || *req.FollowRedirects
 	if check.DNSRecordType == "" && check.Type == models.CheckTypeDNS {
 		check.DNSRecordType = "A"
 	}
 
+	if err := validateCheck(&check); err != nil {
+		return models.Check{}, err
+	}
+	return check, nil
+}
+
+func (h *Handlers) CreateCheck(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateCheckRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	check, err := h.newCheck(&req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	if err := h.db.CreateCheck(&check); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -236,6 +705,92 @@ func (h *Handlers) CreateCheck(w http.ResponseWriter, r *http.Request) {
 	}
 
 	h.engine.AddCheck(check)
+	h.audit(r, models.AuditCreate, models.AuditTargetCheck, check.ID, map[string]string{"name": check.Name})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(check)
+}
+
+// ValidateCheck validates a create request and runs the check once against its
+// target without saving it, so a connection string or assertion can be tried first
+func (h *Handlers) ValidateCheck(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateCheckRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	check, err := h.newCheck(&req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	history, err := h.engine.DryRunCheck(r.Context(), check)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(history)
+}
+
+// CloneCheck creates a copy of a check named "<name> (copy)" with the same settings,
+// tags and assigned regions. History and snapshots belong to the original and are not copied.
+func (h *Handlers) CloneCheck(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	source, err := h.db.GetCheck(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if source == nil {
+		http.Error(w, "check not found", http.StatusNotFound)
+		return
+	}
+
+	check := *source
+	check.ID = 0
+	check.Name = source.Name + " (copy)"
+	check.SnapshotURL = ""
+	check.SnapshotTakenAt = nil
+	check.SnapshotError = ""
+
+	if err := h.db.CreateCheck(&check); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if len(source.Tags) > 0 {
+		tagIDs := make([]int64, 0, len(source.Tags))
+		for _, tag := range source.Tags {
+			tagIDs = append(tagIDs, tag.ID)
+		}
+		h.db.SetCheckTags(check.ID, tagIDs)
+		check.Tags, _ = h.db.GetCheckTags(check.ID)
+	}
+
+	if regions := h.engine.CheckRegions(source.ID); len(regions) > 0 {
+		if err := h.db.SetCheckRegions(check.ID, regions); err != nil {
+			log.Printf("Failed to copy regions of check %d: %v", source.ID, err)
+		} else {
+			h.engine.SetCheckRegions(check.ID, regions)
+		}
+	}
+
+	h.engine.AddCheck(check)
+	h.audit(r, models.AuditCreate, models.AuditTargetCheck, check.ID, map[string]string{
+		"name":        check.Name,
+		"cloned_from": strconv.FormatInt(source.ID, 10),
+	})
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
@@ -281,32 +836,19 @@ func (h *Handlers) UpdateCheck(w http.ResponseWriter, r *http.Request) {
 	if req.TimeoutSeconds.Set {
 		check.TimeoutSeconds = req.TimeoutSeconds.Value
 	}
+	limits := h.engine.Limits()
 	if req.Retries.Set {
-		value := req.Retries.Value
-		if value < 0 {
-			value = 0
-		}
-		if value > 10 {
-			value = 10
-		}
-		check.Retries = value
+		check.Retries = limits.ClampRetries(req.Retries.Value)
 	}
 	if req.RetryDelaySeconds.Set {
-		value := req.RetryDelaySeconds.Value
-		if value <= 0 {
-			value = 5
-		}
-		if value > 60 {
-			value = 60
-		}
-		check.RetryDelaySeconds = value
+		check.RetryDelaySeconds = limits.ClampRetryDelay(req.RetryDelaySeconds.Value)
 	}
 	if req.Enabled != nil {
 		check.Enabled = *req.Enabled
 	}
 	check.GroupID = req.GroupID.Value
 	if req.ExpectedStatusCodes != nil {
-		check.ExpectedStatusCodes = *req.ExpectedStatusCodes
+		check.ExpectedStatusCodes = []int(*req.ExpectedStatusCodes)
 	}
 	if req.Method != nil {
 		check.Method = *req.Method
@@ -326,6 +868,24 @@ func (h *Handlers) UpdateCheck(w http.ResponseWriter, r *http.Request) {
 	if req.ExpectedQueryValue != nil {
 		check.ExpectedQueryValue = *req.ExpectedQueryValue
 	}
+	if req.AssertionOperator != nil {
+		check.AssertionOperator = *req.AssertionOperator
+	}
+	if req.MySQLConnString != nil {
+		check.MySQLConnString = *req.MySQLConnString
+	}
+	if req.MongoConnString != nil {
+		check.MongoConnString = *req.MongoConnString
+	}
+	if req.RedisConnString != nil {
+		check.RedisConnString = *req.RedisConnString
+	}
+	if req.RedisKey != nil {
+		check.RedisKey = *req.RedisKey
+	}
+	if req.ExpectedRedisValue != nil {
+		check.ExpectedRedisValue = *req.ExpectedRedisValue
+	}
 	if req.Host != nil {
 		check.Host = *req.Host
 	}
@@ -338,6 +898,9 @@ func (h *Handlers) UpdateCheck(w http.ResponseWriter, r *http.Request) {
 	if req.ExpectedDNSValue != nil {
 		check.ExpectedDNSValue = *req.ExpectedDNSValue
 	}
+	if req.DNSResolver != nil {
+		check.DNSResolver = *req.DNSResolver
+	}
 	if req.TailscaleDeviceID != nil {
 		check.TailscaleDeviceID = *req.TailscaleDeviceID
 	}
@@ -353,41 +916,280 @@ func (h *Handlers) UpdateCheck(w http.ResponseWriter, r *http.Request) {
 	if req.TailscaleServicePath != nil {
 		check.TailscaleServicePath = *req.TailscaleServicePath
 	}
-
-	if err := h.db.UpdateCheck(check); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+	if req.Public != nil {
+		check.Public = *req.Public
 	}
-
-	if req.TagIDs != nil {
-		h.db.SetCheckTags(check.ID, *req.TagIDs)
-		check.Tags, _ = h.db.GetCheckTags(check.ID)
+	if req.Port.Set {
+		check.Port = req.Port.Value
 	}
-
-	h.engine.AddCheck(*check)
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(check)
-}
-
-func (h *Handlers) DeleteCheck(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	id, err := strconv.ParseInt(vars["id"], 10, 64)
-	if err != nil {
-		http.Error(w, "invalid id", http.StatusBadRequest)
-		return
+	if req.ExpectedBanner != nil {
+		check.ExpectedBanner = *req.ExpectedBanner
 	}
-
-	if err := h.db.DeleteCheck(id); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if req.GRPCService != nil {
+		check.GRPCService = *req.GRPCService
+	}
+	if req.GRPCTLS != nil {
+		check.GRPCTLS = *req.GRPCTLS
+	}
+	if req.SMTPStartTLS != nil {
+		check.SMTPStartTLS = *req.SMTPStartTLS
+	}
+	if req.Headers != nil {
+		check.Headers = *req.Headers
+	}
+	if req.ResultWebhookURL != nil {
+		check.ResultWebhookURL = *req.ResultWebhookURL
+	}
+	if req.RequestBody != nil {
+		check.RequestBody = *req.RequestBody
+	}
+	if req.ContentType != nil {
+		check.ContentType = *req.ContentType
+	}
+	if req.AuthType != nil {
+		check.AuthType = *req.AuthType
+	}
+	if req.AuthUsername != nil {
+		check.AuthUsername = *req.AuthUsername
+	}
+	if req.AuthPassword != nil {
+		check.AuthPassword = *req.AuthPassword
+	}
+	if req.AuthToken != nil {
+		check.AuthToken = *req.AuthToken
+	}
+	if req.FollowRedirects != nil {
+		check.FollowRedirects = *req.FollowRedirects
+	}
+	if req.ResponseKeyword != nil {
+		check.ResponseKeyword = *req.ResponseKeyword
+	}
+	if req.ResponseKeywordMode != nil {
+		check.ResponseKeywordMode = *req.ResponseKeywordMode
+	}
+	if req.ExpectedHeaderName != nil {
+		check.ExpectedHeaderName = *req.ExpectedHeaderName
+	}
+	if req.ExpectedHeaderValue != nil {
+		check.ExpectedHeaderValue = *req.ExpectedHeaderValue
+	}
+	if req.ExpectedHeaderMode != nil {
+		check.ExpectedHeaderMode = *req.ExpectedHeaderMode
+	}
+	if req.ForbiddenBodyKeyword != nil {
+		check.ForbiddenBodyKeyword = *req.ForbiddenBodyKeyword
+	}
+	if req.MinResponseBytes.Set {
+		check.MinResponseBytes = req.MinResponseBytes.Value
+	}
+	if req.MaxResponseBytes.Set {
+		check.MaxResponseBytes = req.MaxResponseBytes.Value
+	}
+	if req.ExpectedHTTPVersion != nil {
+		check.ExpectedHTTPVersion = *req.ExpectedHTTPVersion
+	}
+	if req.ProxyURL != nil {
+		check.ProxyURL = *req.ProxyURL
+	}
+	if req.TriggerSecret != nil {
+		check.TriggerSecret = *req.TriggerSecret
+	}
+	if req.BackoffOnFailure != nil {
+		check.BackoffOnFailure = *req.BackoffOnFailure
+	}
+	if req.ExpectedRowCount != nil {
+		check.ExpectedRowCount = req.ExpectedRowCount
+		if *req.ExpectedRowCount < 0 {
+			check.ExpectedRowCount = nil
+		}
+	}
+	if req.QueryColumn != nil {
+		check.QueryColumn = *req.QueryColumn
+	}
+	if req.CronExpression != nil {
+		check.CronExpression = *req.CronExpression
+	}
+	if req.ExpectUnreachable != nil {
+		check.ExpectUnreachable = *req.ExpectUnreachable
+	}
+	if req.PingCount.Set {
+		check.PingCount = req.PingCount.Value
+	}
+	if req.MaxPacketLoss.Set {
+		check.MaxPacketLoss = req.MaxPacketLoss.Value
+	}
+	if req.BypassCache != nil {
+		check.BypassCache = *req.BypassCache
+	}
+	if req.SourceIP != nil {
+		check.SourceIP = *req.SourceIP
+	}
+	if req.ConfirmationThreshold.Set {
+		check.ConfirmationThreshold = req.ConfirmationThreshold.Value
+	}
+	if req.RenotifyIntervalMinutes.Set {
+		check.RenotifyIntervalMinutes = req.RenotifyIntervalMinutes.Value
+	}
+	if req.MaxResponseTimeMs.Set {
+		check.MaxResponseTimeMs = req.MaxResponseTimeMs.Value
+	}
+	if req.RetentionDays.Set {
+		check.RetentionDays = req.RetentionDays.Value
+	}
+	if req.NTPMaxOffsetMs.Set {
+		check.NTPMaxOffsetMs = req.NTPMaxOffsetMs.Value
+	}
+	if req.CertExpiryThresholdDays.Set {
+		check.CertExpiryThresholdDays = req.CertExpiryThresholdDays.Value
+	}
+	if req.InsecureSkipVerify != nil {
+		check.InsecureSkipVerify = *req.InsecureSkipVerify
+	}
+
+	// Checks saved before timing was validated may have a timeout as long as their
+	// interval, so only edits to the timing itself are held to it
+	if req.IntervalSeconds.Set || req.TimeoutSeconds.Set {
+		if err := limits.ValidateTiming(check.IntervalSeconds, check.TimeoutSeconds); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if err := validateCheck(check); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.db.UpdateCheck(check); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if req.TagIDs != nil {
+		h.db.SetCheckTags(check.ID, *req.TagIDs)
+		check.Tags, _ = h.db.GetCheckTags(check.ID)
+	}
+
+	h.engine.AddCheck(*check)
+	h.audit(r, models.AuditUpdate, models.AuditTargetCheck, check.ID, map[string]string{"name": check.Name})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(check)
+}
+
+func (h *Handlers) DeleteCheck(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.db.DeleteCheck(id); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	h.engine.RemoveCheck(id)
+	h.audit(r, models.AuditDelete, models.AuditTargetCheck, id, nil)
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// maxBulkChecks caps how many checks a single bulk action may touch
+const maxBulkChecks = 1000
+
+// BulkUpdateChecks enables, disables or deletes many checks in one database statement
+// and updates the engine to match. IDs that don't match a check are reported as failed.
+func (h *Handlers) BulkUpdateChecks(w http.ResponseWriter, r *http.Request) {
+	var req models.BulkCheckRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(req.IDs) == 0 {
+		http.Error(w, "ids is required", http.StatusBadRequest)
+		return
+	}
+	if len(req.IDs) > maxBulkChecks {
+		http.Error(w, fmt.Sprintf("at most %d ids are allowed", maxBulkChecks), http.StatusBadRequest)
+		return
+	}
+
+	seen := make(map[int64]bool, len(req.IDs))
+	ids := make([]int64, 0, len(req.IDs))
+	for _, id := range req.IDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	var done []int64
+	var err error
+	switch req.Action {
+	case models.BulkActionEnable:
+		done, err = h.db.SetChecksEnabled(ids, true)
+	case models.BulkActionDisable:
+		done, err = h.db.SetChecksEnabled(ids, false)
+	case models.BulkActionDelete:
+		done, err = h.db.DeleteChecks(ids)
+	default:
+		http.Error(w, "action must be enable, disable or delete", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	succeeded := make(map[int64]bool, len(done))
+	for _, id := range done {
+		succeeded[id] = true
+	}
+
+	if req.Action == models.BulkActionEnable {
+		// Load the enabled checks in one query to start them
+		checks, err := h.db.GetAllChecks()
+		if err != nil {
+			log.Printf("Failed to load checks after bulk enable: %v", err)
+		}
+		for _, check := range checks {
+			if succeeded[check.ID] {
+				h.engine.AddCheck(check)
+			}
+		}
+	} else {
+		for _, id := range done {
+			h.engine.RemoveCheck(id)
+		}
+	}
+
+	action, details := models.AuditUpdate, map[string]string{"enabled": strconv.FormatBool(req.Action == models.BulkActionEnable)}
+	if req.Action == models.BulkActionDelete {
+		action, details = models.AuditDelete, nil
+	}
+	for _, id := range done {
+		h.audit(r, action, models.AuditTargetCheck, id, details)
+	}
+
+	resp := models.BulkCheckResponse{
+		Action:    req.Action,
+		Succeeded: make([]int64, 0, len(done)),
+		Failed:    []models.BulkCheckFailure{},
+	}
+	for _, id := range ids {
+		if succeeded[id] {
+			resp.Succeeded = append(resp.Succeeded, id)
+		} else {
+			resp.Failed = append(resp.Failed, models.BulkCheckFailure{ID: id, Error: "check not found"})
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
 func (h *Handlers) GetCheckHistory(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id, err := strconv.ParseInt(vars["id"], 10, 64)
@@ -400,16 +1202,27 @@ func (h *Handlers) GetCheckHistory(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	loc, precision, err := parseTimeDisplayParams(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	limit := 100
 	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
 		if parsedLimit, err := strconv.Atoi(limitStr); err == nil && parsedLimit > 0 {
-			limit = parsedLimit
+			limit = h.capRows(parsedLimit)
 		}
 	}
 
 	var history []models.CheckHistory
 
+	// Ranges longer than a day read the rollup tables when the rollup job is enabled
+	aggregate := h.db.GetCheckHistoryAggregated
+	if h.historyRollups {
+		aggregate = h.db.GetCheckHistoryRollup
+	}
+
 	// Determine aggregation strategy based on time range
 	if since != nil {
 		duration := time.Since(*since)
@@ -422,10 +1235,10 @@ func (h *Handlers) GetCheckHistory(w http.ResponseWriter, r *http.Request) {
 			history, err = h.db.GetCheckHistoryAggregated(id, since, 5, 288)
 		} else if duration <= 7*24*time.Hour {
 			// For ranges <= 7 days, aggregate by 1-hour buckets
-			history, err = h.db.GetCheckHistoryAggregated(id, since, 60, 168)
+			history, err = aggregate(id, since, 60, 168)
 		} else {
 			// For ranges > 7 days, aggregate by 6-hour buckets
-			history, err = h.db.GetCheckHistoryAggregated(id, since, 360, 120)
+			history, err = aggregate(id, since, 360, 120)
 		}
 	} else {
 		history, err = h.db.GetCheckHistory(id, since, limit)
@@ -436,6 +1249,8 @@ func (h *Handlers) GetCheckHistory(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	formatHistoryTimes(history, loc, precision)
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(history)
 }
@@ -466,7 +1281,9 @@ func (h *Handlers) GetCheckStats(w http.ResponseWriter, r *http.Request) {
 			SuccessCount: 0,
 			SuccessRate:  0,
 			AvgLatency:   0,
+			P50Latency:   0,
 			P90Latency:   0,
+			P95Latency:   0,
 			P99Latency:   0,
 			DownCount:    0,
 			Regions:      []models.RegionStats{},
@@ -479,7 +1296,6 @@ func (h *Handlers) GetCheckStats(w http.ResponseWriter, r *http.Request) {
 	totalChecks := len(history)
 	successCount := 0
 	totalLatency := int64(0)
-	latencies := make([]int, 0, totalChecks)
 	regionMap := make(map[string]*models.RegionStats)
 
 	for _, h := range history {
@@ -487,7 +1303,6 @@ func (h *Handlers) GetCheckStats(w http.ResponseWriter, r *http.Request) {
 			successCount++
 		}
 		totalLatency += int64(h.ResponseTimeMs)
-		latencies = append(latencies, h.ResponseTimeMs)
 
 		region := h.Region
 		if region == "" {
@@ -512,20 +1327,12 @@ func (h *Handlers) GetCheckStats(w http.ResponseWriter, r *http.Request) {
 	avgLatency := int(totalLatency / int64(totalChecks))
 	downCount := totalChecks - successCount
 
-	sort.Ints(latencies)
-	p90Index := int(float64(len(latencies)) * 0.9)
-	p99Index := int(float64(len(latencies)) * 0.99)
-	if p90Index >= len(latencies) {
-		p90Index = len(latencies) - 1
-	}
-	if p99Index >= len(latencies) {
-		p99Index = len(latencies) - 1
-	}
-	p90Latency := 0
-	p99Latency := 0
-	if len(latencies) > 0 {
-		p90Latency = latencies[p90Index]
-		p99Latency = latencies[p99Index]
+	// Percentiles come from the database so they cover the whole range, not just the
+	// rows read above
+	percentiles, err := h.db.GetLatencyPercentiles(id, since)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
 
 	lastStatusByRegion, err := h.db.GetLastStatusByRegion(id)
@@ -558,8 +1365,10 @@ func (h *Handlers) GetCheckStats(w http.ResponseWriter, r *http.Request) {
 		SuccessCount: successCount,
 		SuccessRate:  successRate,
 		AvgLatency:   avgLatency,
-		P90Latency:   p90Latency,
-		P99Latency:   p99Latency,
+		P50Latency:   int(math.Round(percentiles.P50)),
+		P90Latency:   int(math.Round(percentiles.P90)),
+		P95Latency:   int(math.Round(percentiles.P95)),
+		P99Latency:   int(math.Round(percentiles.P99)),
 		DownCount:    downCount,
 		Regions:      regions,
 	}
@@ -575,24 +1384,54 @@ func (h *Handlers) GetStats(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	stats, err := h.db.GetStats(since)
+	tags, err := h.parseTagFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	stats, err := h.db.GetStats(since, tags)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	stats.RunningChecks, stats.WaitingChecks, stats.MaxConcurrentChecks = h.engine.RunningChecks()
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(stats)
 }
 
 func (h *Handlers) GetSettings(w http.ResponseWriter, r *http.Request) {
-	webhookURL, _ := h.db.GetSetting("discord_webhook_url")
-	gotifyServerURL, _ := h.db.GetSetting("gotify_server_url")
-	gotifyToken, _ := h.db.GetSetting("gotify_token")
+	// Notifier settings are those of the first notifier of each type
+	notifierValues := notifierSettings(h.db)
+	webhookURL := notifierValues["discord_webhook_url"]
+	gotifyServerURL := notifierValues["gotify_server_url"]
+	gotifyToken := notifierValues["gotify_token"]
 	tailscaleAPIKey, _ := h.db.GetSetting("tailscale_api_key")
 	tailscaleTailnet, _ := h.db.GetSetting("tailscale_tailnet")
 	browserlessURL, _ := h.db.GetSetting("browserless_url")
 	browserlessToken, _ := h.db.GetSetting("browserless_token")
+	slackWebhookURL := notifierValues["slack_webhook_url"]
+	smtpHost := notifierValues["smtp_host"]
+	smtpPort := notifierValues["smtp_port"]
+	smtpUsername := notifierValues["smtp_username"]
+	smtpPassword := notifierValues["smtp_password"]
+	smtpFrom := notifierValues["smtp_from"]
+	smtpTo := notifierValues["smtp_to"]
+	genericWebhookURL := notifierValues["generic_webhook_url"]
+	genericWebhookMethod := notifierValues["generic_webhook_method"]
+	genericWebhookTemplate := notifierValues["generic_webhook_template"]
+	opsgenieAPIKey := notifierValues["opsgenie_api_key"]
+	opsgenieRegion := notifierValues["opsgenie_region"]
+	opsgeniePriority := notifierValues["opsgenie_priority"]
+	pagerDutyRoutingKey := notifierValues["pagerduty_routing_key"]
+	pagerDutySeverity := notifierValues["pagerduty_severity"]
+	pushoverUserKey := notifierValues["pushover_user_key"]
+	pushoverAPIToken := notifierValues["pushover_api_token"]
+	pushoverPriority := notifierValues["pushover_priority"]
+	pushoverSound := notifierValues["pushover_sound"]
+	appriseURL := notifierValues["apprise_url"]
+	appriseURLs := notifierValues["apprise_urls"]
 
 	settings := models.Settings{
 		DiscordWebhookURL: webhookURL,
@@ -602,7 +1441,38 @@ func (h *Handlers) GetSettings(w http.ResponseWriter, r *http.Request) {
 		TailscaleTailnet:  tailscaleTailnet,
 		BrowserlessURL:    browserlessURL,
 		BrowserlessToken:  browserlessToken,
+		SlackWebhookURL:   slackWebhookURL,
+		SMTPHost:          smtpHost,
+		SMTPUsername:      smtpUsername,
+		SMTPPassword:      smtpPassword,
+		SMTPFrom:          smtpFrom,
+		SMTPTo:            smtpTo,
+
+		GenericWebhookURL:      genericWebhookURL,
+		GenericWebhookMethod:   genericWebhookMethod,
+		GenericWebhookTemplate: genericWebhookTemplate,
+
+		OpsgenieAPIKey:   opsgenieAPIKey,
+		OpsgenieRegion:   opsgenieRegion,
+		OpsgeniePriority: opsgeniePriority,
+
+		PagerDutyRoutingKey: pagerDutyRoutingKey,
+		PagerDutySeverity:   pagerDutySeverity,
+
+		PushoverUserKey:  pushoverUserKey,
+		PushoverAPIToken: pushoverAPIToken,
+		PushoverPriority: pushoverPriority,
+		PushoverSound:    pushoverSound,
+
+		AppriseURL:  appriseURL,
+		AppriseURLs: appriseURLs,
 	}
+	settings.SMTPPort, _ = strconv.Atoi(smtpPort)
+	retentionDays := h.db.HistoryRetentionDays()
+	settings.HistoryRetentionDays = &retentionDays
+	fillSnapshotOptions(h.db, &settings)
+
+	fillNotifierToggles(h.db, &settings)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(settings)
@@ -615,68 +1485,425 @@ func (h *Handlers) UpdateSettings(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.db.SetSetting("discord_webhook_url", settings.DiscordWebhookURL); err != nil {
+	if err := validateGenericWebhook(&settings); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := validateOpsgenie(&settings); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := validatePagerDuty(&settings); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := validatePushover(&settings); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := validateApprise(&settings); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if settings.HistoryRetentionDays != nil && *settings.HistoryRetentionDays < 0 {
+		http.Error(w, "history_retention_days cannot be negative", http.StatusBadRequest)
+		return
+	}
+	if err := validateSnapshotOptions(&settings); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	before := auditedSettings(h.db)
+	if err := h.db.SetSetting("tailscale_api_key", settings.TailscaleAPIKey); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	if err := h.db.SetSetting("gotify_server_url", settings.GotifyServerURL); err != nil {
+	if err := h.db.SetSetting("tailscale_tailnet", settings.TailscaleTailnet); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	if err := h.db.SetSetting("gotify_token", settings.GotifyToken); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if err := h.db.SetSetting("browserless_url", settings.BrowserlessURL); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := h.db.SetSetting("browserless_token", settings.BrowserlessToken); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	smtpPort := ""
+	if settings.SMTPPort > 0 {
+		smtpPort = strconv.Itoa(settings.SMTPPort)
+	}
+	notifierValues := map[string]string{
+		"discord_webhook_url": settings.DiscordWebhookURL,
+		"gotify_server_url":   settings.GotifyServerURL,
+		"gotify_token":        settings.GotifyToken,
+		"slack_webhook_url":   settings.SlackWebhookURL,
+
+		"smtp_host":     settings.SMTPHost,
+		"smtp_port":     smtpPort,
+		"smtp_username": settings.SMTPUsername,
+		"smtp_password": settings.SMTPPassword,
+		"smtp_from":     settings.SMTPFrom,
+		"smtp_to":       settings.SMTPTo,
+
+		"generic_webhook_url":      settings.GenericWebhookURL,
+		"generic_webhook_method":   settings.GenericWebhookMethod,
+		"generic_webhook_template": settings.GenericWebhookTemplate,
+
+		"opsgenie_api_key":  settings.OpsgenieAPIKey,
+		"opsgenie_region":   settings.OpsgenieRegion,
+		"opsgenie_priority": settings.OpsgeniePriority,
+
+		"pagerduty_routing_key": settings.PagerDutyRoutingKey,
+		"pagerduty_severity":    settings.PagerDutySeverity,
+
+		"pushover_user_key":  settings.PushoverUserKey,
+		"pushover_api_token": settings.PushoverAPIToken,
+		"pushover_priority":  settings.PushoverPriority,
+		"pushover_sound":     settings.PushoverSound,
+
+		"apprise_url":  settings.AppriseURL,
+		"apprise_urls": settings.AppriseURLs,
+	}
+	notifierToggles := map[string]*bool{
+		"discord_enabled": settings.DiscordEnabled,
+		"gotify_enabled":  settings.GotifyEnabled,
+		"slack_enabled":   settings.SlackEnabled,
+		"email_enabled":   settings.EmailEnabled,
+
+		"generic_webhook_enabled": settings.WebhookEnabled,
+		"opsgenie_enabled":        settings.OpsgenieEnabled,
+		"pagerduty_enabled":       settings.PagerDutyEnabled,
+		"pushover_enabled":        settings.PushoverEnabled,
+		"apprise_enabled":         settings.AppriseEnabled,
+	}
+	if err := saveNotifierSettings(h.db, notifierValues, notifierToggles); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if settings.NotifyMisconfigured != nil {
+		if err := h.db.SetSetting(checker.NotifyMisconfiguredSetting, strconv.FormatBool(*settings.NotifyMisconfigured)); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if settings.HistoryRetentionDays != nil {
+		if err := h.db.SetSetting(db.HistoryRetentionSetting, strconv.Itoa(*settings.HistoryRetentionDays)); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	retentionDays := h.db.HistoryRetentionDays()
+	settings.HistoryRetentionDays = &retentionDays
+
+	previousInterval := snapshot.LoadOptions(h.db).IntervalHours
+	if err := saveSnapshotOptions(h.db, &settings); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	fillSnapshotOptions(h.db, &settings)
+	if h.snapshotService != nil && *settings.SnapshotIntervalHours != previousInterval {
+		h.snapshotService.ReloadOptions()
+	}
+
+	h.reloadNotifiers()
+
+	if changes := settingChanges(before, auditedSettings(h.db)); len(changes) > 0 {
+		h.audit(r, models.AuditUpdate, models.AuditTargetSetting, 0, changes)
+	}
+
+	fillNotifierToggles(h.db, &settings)
+
+	if h.snapshotService != nil && settings.BrowserlessURL != "" && settings.BrowserlessToken != "" {
+		h.snapshotService.TriggerRefresh()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(settings)
+}
+
+// validateGenericWebhook normalizes the webhook method and rejects templates that
+// would not render to valid JSON
+func validateGenericWebhook(settings *models.Settings) error {
+	if settings.GenericWebhookURL != "" {
+		u, err := url.Parse(settings.GenericWebhookURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("generic_webhook_url must be an http or https URL")
+		}
+	}
+
+	settings.GenericWebhookMethod = strings.ToUpper(strings.TrimSpace(settings.GenericWebhookMethod))
+	switch settings.GenericWebhookMethod {
+	case "":
+		settings.GenericWebhookMethod = http.MethodPost
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+	default:
+		return fmt.Errorf("generic_webhook_method must be POST, PUT or PATCH")
+	}
+
+	if err := notifier.ValidateWebhookTemplate(settings.GenericWebhookTemplate); err != nil {
+		return fmt.Errorf("generic_webhook_template: %w", err)
+	}
+	return nil
+}
+
+// validateOpsgenie normalizes the Opsgenie region and priority
+func validateOpsgenie(settings *models.Settings) error {
+	settings.OpsgenieRegion = strings.ToLower(strings.TrimSpace(settings.OpsgenieRegion))
+	switch settings.OpsgenieRegion {
+	case "":
+		settings.OpsgenieRegion = "us"
+	case "us", "eu":
+	default:
+		return fmt.Errorf("opsgenie_region must be us or eu")
+	}
+
+	settings.OpsgeniePriority = strings.ToUpper(strings.TrimSpace(settings.OpsgeniePriority))
+	if settings.OpsgeniePriority == "" {
+		settings.OpsgeniePriority = notifier.DefaultOpsgeniePriority
+	}
+	if !notifier.ValidOpsgeniePriority(settings.OpsgeniePriority) {
+		return fmt.Errorf("opsgenie_priority must be one of P1-P5")
+	}
+	return nil
+}
+
+// validatePagerDuty normalizes the PagerDuty severity
+func validatePagerDuty(settings *models.Settings) error {
+	settings.PagerDutySeverity = strings.ToLower(strings.TrimSpace(settings.PagerDutySeverity))
+	if settings.PagerDutySeverity == "" {
+		settings.PagerDutySeverity = notifier.DefaultPagerDutySeverity
+	}
+	if !notifier.ValidPagerDutySeverity(settings.PagerDutySeverity) {
+		return fmt.Errorf("pagerduty_severity must be critical, error, warning or info")
+	}
+	return nil
+}
+
+// validatePushover normalizes the Pushover priority and sound
+func validatePushover(settings *models.Settings) error {
+	settings.PushoverPriority = strings.ToLower(strings.TrimSpace(settings.PushoverPriority))
+	if settings.PushoverPriority == "" {
+		settings.PushoverPriority = notifier.DefaultPushoverPriority
+	}
+	if !notifier.ValidPushoverPriority(settings.PushoverPriority) {
+		return fmt.Errorf("pushover_priority must be normal, high or emergency")
+	}
+	settings.PushoverSound = strings.TrimSpace(settings.PushoverSound)
+	return nil
+}
+
+// validateApprise checks the Apprise notify endpoint
+func validateApprise(settings *models.Settings) error {
+	settings.AppriseURL = strings.TrimSpace(settings.AppriseURL)
+	if settings.AppriseURL == "" {
+		return nil
+	}
+	u, err := url.Parse(settings.AppriseURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("apprise_url must be an http or https URL")
+	}
+	if !strings.HasPrefix(u.Path, "/notify") {
+		return fmt.Errorf("apprise_url must be the server's /notify endpoint, e.g. http://apprise:8000/notify")
+	}
+	return nil
+}
+
+func (h *Handlers) TestWebhook(w http.ResponseWriter, r *http.Request) {
+	var discordNotifier *notifier.DiscordNotifier
+	for _, n := range h.notifiers {
+		if dn, ok := n.(*notifier.DiscordNotifier); ok {
+			discordNotifier = dn
+			break
+		}
+	}
+
+	if discordNotifier == nil {
+		http.Error(w, "discord notifier not configured", http.StatusBadRequest)
+		return
+	}
+
+	if err := discordNotifier.TestWebhook(); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok", "message": "Test notification sent successfully"})
+}
+
+func (h *Handlers) TestGotify(w http.ResponseWriter, r *http.Request) {
+	var gotifyNotifier *notifier.GotifyNotifier
+	for _, n := range h.notifiers {
+		if gn, ok := n.(*notifier.GotifyNotifier); ok {
+			gotifyNotifier = gn
+			break
+		}
+	}
+
+	if gotifyNotifier == nil {
+		http.Error(w, "gotify notifier not configured", http.StatusBadRequest)
+		return
+	}
+
+	if err := gotifyNotifier.TestWebhook(); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok", "message": "Test notification sent successfully"})
+}
+
+func (h *Handlers) TestSlack(w http.ResponseWriter, r *http.Request) {
+	var slackNotifier *notifier.SlackNotifier
+	for _, n := range h.notifiers {
+		if sn, ok := n.(*notifier.SlackNotifier); ok {
+			slackNotifier = sn
+			break
+		}
+	}
+
+	if slackNotifier == nil {
+		http.Error(w, "slack notifier not configured", http.StatusBadRequest)
+		return
+	}
+
+	if err := slackNotifier.TestWebhook(); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok", "message": "Test notification sent successfully"})
+}
+
+func (h *Handlers) TestEmail(w http.ResponseWriter, r *http.Request) {
+	var emailNotifier *notifier.EmailNotifier
+	for _, n := range h.notifiers {
+		if en, ok := n.(*notifier.EmailNotifier); ok {
+			emailNotifier = en
+			break
+		}
+	}
+
+	if emailNotifier == nil {
+		http.Error(w, "email notifier not configured", http.StatusBadRequest)
+		return
+	}
+
+	if err := emailNotifier.TestWebhook(); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok", "message": "Test email sent successfully"})
+}
+
+func (h *Handlers) TestGenericWebhook(w http.ResponseWriter, r *http.Request) {
+	var webhookNotifier *notifier.WebhookNotifier
+	for _, n := range h.notifiers {
+		if wn, ok := n.(*notifier.WebhookNotifier); ok {
+			webhookNotifier = wn
+			break
+		}
+	}
+
+	if webhookNotifier == nil {
+		http.Error(w, "generic webhook not configured", http.StatusBadRequest)
 		return
 	}
-	if err := h.db.SetSetting("tailscale_api_key", settings.TailscaleAPIKey); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+	if err := webhookNotifier.TestWebhook(); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
 		return
 	}
-	if err := h.db.SetSetting("tailscale_tailnet", settings.TailscaleTailnet); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok", "message": "Test notification sent successfully"})
+}
+
+func (h *Handlers) TestOpsgenie(w http.ResponseWriter, r *http.Request) {
+	var opsgenieNotifier *notifier.OpsgenieNotifier
+	for _, n := range h.notifiers {
+		if on, ok := n.(*notifier.OpsgenieNotifier); ok {
+			opsgenieNotifier = on
+			break
+		}
 	}
-	if err := h.db.SetSetting("browserless_url", settings.BrowserlessURL); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+	if opsgenieNotifier == nil {
+		http.Error(w, "opsgenie notifier not configured", http.StatusBadRequest)
 		return
 	}
-	if err := h.db.SetSetting("browserless_token", settings.BrowserlessToken); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+	if err := opsgenieNotifier.TestWebhook(); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
 		return
 	}
 
-	var notifiers []notifier.Notifier
-	if settings.DiscordWebhookURL != "" {
-		notifiers = append(notifiers, notifier.NewDiscordNotifier(settings.DiscordWebhookURL))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok", "message": "Test alert created and closed successfully"})
+}
+
+func (h *Handlers) TestPagerDuty(w http.ResponseWriter, r *http.Request) {
+	var pagerDutyNotifier *notifier.PagerDutyNotifier
+	for _, n := range h.notifiers {
+		if pn, ok := n.(*notifier.PagerDutyNotifier); ok {
+			pagerDutyNotifier = pn
+			break
+		}
 	}
-	if settings.GotifyServerURL != "" && settings.GotifyToken != "" {
-		notifiers = append(notifiers, notifier.NewGotifyNotifier(settings.GotifyServerURL, settings.GotifyToken))
+
+	if pagerDutyNotifier == nil {
+		http.Error(w, "pagerduty notifier not configured", http.StatusBadRequest)
+		return
 	}
-	h.notifiers = notifiers
-	h.engine.UpdateNotifiers(notifiers)
 
-	if h.snapshotService != nil && settings.BrowserlessURL != "" && settings.BrowserlessToken != "" {
-		h.snapshotService.TriggerRefresh()
+	if err := pagerDutyNotifier.TestWebhook(); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(settings)
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok", "message": "Test incident triggered and resolved successfully"})
 }
 
-func (h *Handlers) TestWebhook(w http.ResponseWriter, r *http.Request) {
-	var discordNotifier *notifier.DiscordNotifier
+func (h *Handlers) TestPushover(w http.ResponseWriter, r *http.Request) {
+	var pushoverNotifier *notifier.PushoverNotifier
 	for _, n := range h.notifiers {
-		if dn, ok := n.(*notifier.DiscordNotifier); ok {
-			discordNotifier = dn
+		if pn, ok := n.(*notifier.PushoverNotifier); ok {
+			pushoverNotifier = pn
 			break
 		}
 	}
 
-	if discordNotifier == nil {
-		http.Error(w, "discord notifier not configured", http.StatusBadRequest)
+	if pushoverNotifier == nil {
+		http.Error(w, "pushover notifier not configured", http.StatusBadRequest)
 		return
 	}
 
-	if err := discordNotifier.TestWebhook(); err != nil {
+	if err := pushoverNotifier.TestWebhook(); err != nil {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
@@ -687,21 +1914,21 @@ func (h *Handlers) TestWebhook(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(map[string]string{"status": "ok", "message": "Test notification sent successfully"})
 }
 
-func (h *Handlers) TestGotify(w http.ResponseWriter, r *http.Request) {
-	var gotifyNotifier *notifier.GotifyNotifier
+func (h *Handlers) TestApprise(w http.ResponseWriter, r *http.Request) {
+	var appriseNotifier *notifier.AppriseNotifier
 	for _, n := range h.notifiers {
-		if gn, ok := n.(*notifier.GotifyNotifier); ok {
-			gotifyNotifier = gn
+		if an, ok := n.(*notifier.AppriseNotifier); ok {
+			appriseNotifier = an
 			break
 		}
 	}
 
-	if gotifyNotifier == nil {
-		http.Error(w, "gotify notifier not configured", http.StatusBadRequest)
+	if appriseNotifier == nil {
+		http.Error(w, "apprise notifier not configured", http.StatusBadRequest)
 		return
 	}
 
-	if err := gotifyNotifier.TestWebhook(); err != nil {
+	if err := appriseNotifier.TestWebhook(); err != nil {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
@@ -800,6 +2027,37 @@ func (h *Handlers) TriggerCheckSnapshot(w http.ResponseWriter, r *http.Request)
 	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
 }
 
+// RefreshSnapshots starts a background refresh of all eligible snapshots. A refresh
+// that is already running is reported instead of starting another one.
+func (h *Handlers) RefreshSnapshots(w http.ResponseWriter, r *http.Request) {
+	if h.snapshotService == nil {
+		http.Error(w, "snapshot service not available", http.StatusNotImplemented)
+		return
+	}
+
+	status := "accepted"
+	if !h.snapshotService.TriggerRefresh() {
+		status = "already_running"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":   status,
+		"progress": h.snapshotService.RefreshStatus(),
+	})
+}
+
+func (h *Handlers) GetSnapshotRefreshStatus(w http.ResponseWriter, r *http.Request) {
+	if h.snapshotService == nil {
+		http.Error(w, "snapshot service not available", http.StatusNotImplemented)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.snapshotService.RefreshStatus())
+}
+
 func (h *Handlers) GetTailscaleDevices(w http.ResponseWriter, r *http.Request) {
 	apiKey, _ := h.db.GetSetting("tailscale_api_key")
 	tailnet, _ := h.db.GetSetting("tailscale_tailnet")
@@ -963,6 +2221,7 @@ func (h *Handlers) CreateGroup(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	h.audit(r, models.AuditCreate, models.AuditTargetGroup, group.ID, map[string]string{"name": group.Name})
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
@@ -1004,6 +2263,7 @@ func (h *Handlers) UpdateGroup(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	h.audit(r, models.AuditUpdate, models.AuditTargetGroup, group.ID, map[string]string{"name": group.Name})
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(group)
@@ -1021,6 +2281,7 @@ func (h *Handlers) DeleteGroup(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	h.audit(r, models.AuditDelete, models.AuditTargetGroup, id, nil)
 
 	w.WriteHeader(http.StatusNoContent)
 }
@@ -1044,19 +2305,29 @@ func (h *Handlers) CreateTag(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	req.Name = strings.TrimSpace(req.Name)
 	if req.Name == "" {
 		http.Error(w, "name is required", http.StatusBadRequest)
 		return
 	}
 	if req.Color == "" {
-		req.Color = "#6b7280"
+		req.Color = defaultTagColor
+	}
+	if !isHexColor(req.Color) {
+		http.Error(w, "color must be a hex color such as #6b7280", http.StatusBadRequest)
+		return
 	}
 
 	tag := models.Tag{Name: req.Name, Color: req.Color}
 	if err := h.db.CreateTag(&tag); err != nil {
+		if errors.Is(err, db.ErrConflict) {
+			http.Error(w, fmt.Sprintf("tag %q already exists", tag.Name), http.StatusConflict)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	h.audit(r, models.AuditCreate, models.AuditTargetTag, tag.ID, map[string]string{"name": tag.Name, "color": tag.Color})
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
@@ -1088,19 +2359,118 @@ func (h *Handlers) UpdateTag(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if req.Name != nil {
-		tag.Name = *req.Name
+		tag.Name = strings.TrimSpace(*req.Name)
+		if tag.Name == "" {
+			http.Error(w, "name is required", http.StatusBadRequest)
+			return
+		}
 	}
 	if req.Color != nil {
+		if !isHexColor(*req.Color) {
+			http.Error(w, "color must be a hex color such as #6b7280", http.StatusBadRequest)
+			return
+		}
 		tag.Color = *req.Color
 	}
 
 	if err := h.db.UpdateTag(tag); err != nil {
+		if errors.Is(err, db.ErrConflict) {
+			http.Error(w, fmt.Sprintf("tag %q already exists", tag.Name), http.StatusConflict)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Checks read tag name and color through the join, so the stored row is what
+	// every check sees; return it rather than echoing the request
+	stored, err := h.db.GetTag(id)
+	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	if stored == nil || stored.Name != tag.Name || stored.Color != tag.Color {
+		http.Error(w, "tag update was not persisted", http.StatusInternalServerError)
+		return
+	}
+	h.audit(r, models.AuditUpdate, models.AuditTargetTag, stored.ID, map[string]string{"name": stored.Name, "color": stored.Color})
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(tag)
+	json.NewEncoder(w).Encode(stored)
+}
+
+// UpdateTagChecks assigns and unassigns a tag across many checks at once
+func (h *Handlers) UpdateTagChecks(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	tag, err := h.db.GetTag(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if tag == nil {
+		http.Error(w, "tag not found", http.StatusNotFound)
+		return
+	}
+
+	var req models.TagChecksRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(req.Assign) == 0 && len(req.Unassign) == 0 {
+		http.Error(w, "assign or unassign is required", http.StatusBadRequest)
+		return
+	}
+
+	assigning := make(map[int64]bool, len(req.Assign))
+	for _, checkID := range req.Assign {
+		assigning[checkID] = true
+	}
+	for _, checkID := range req.Unassign {
+		if assigning[checkID] {
+			http.Error(w, fmt.Sprintf("check %d is in both assign and unassign", checkID), http.StatusBadRequest)
+			return
+		}
+	}
+
+	assigned, unassigned, err := h.db.UpdateTagChecks(id, req.Assign, req.Unassign)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.audit(r, models.AuditUpdate, models.AuditTargetTag, id, map[string]string{
+		"assigned":   strconv.FormatInt(assigned, 10),
+		"unassigned": strconv.FormatInt(unassigned, 10),
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.TagChecksResponse{
+		Tag:        *tag,
+		Assigned:   assigned,
+		Unassigned: unassigned,
+	})
+}
+
+// defaultTagColor is the color of tags created without one
+const defaultTagColor = "#6b7280"
+
+// isHexColor accepts #rgb and #rrggbb colors
+func isHexColor(color string) bool {
+	if len(color) != 4 && len(color) != 7 || color[0] != '#' {
+		return false
+	}
+	for _, c := range color[1:] {
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+			return false
+		}
+	}
+	return true
 }
 
 func (h *Handlers) DeleteTag(w http.ResponseWriter, r *http.Request) {
@@ -1115,6 +2485,7 @@ func (h *Handlers) DeleteTag(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	h.audit(r, models.AuditDelete, models.AuditTargetTag, id, nil)
 
 	w.WriteHeader(http.StatusNoContent)
 }
@@ -1126,6 +2497,42 @@ func (h *Handlers) GetGroupedChecks(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	tags, err := h.parseTagFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.groupedTimeout)
+	defer cancel()
+
+	result, err := h.buildGroupedChecks(ctx, since, tags)
+	if err != nil {
+		writeGroupedChecksError(w, err)
+		return
+	}
+	if !h.showsCredentials(r) {
+		redactGroupedChecks(result)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// writeGroupedChecksError reports a failed buildGroupedChecks, with 503 when it ran out of time
+func writeGroupedChecksError(w http.ResponseWriter, err error) {
+	if errors.Is(err, context.DeadlineExceeded) {
+		http.Error(w, "timed out loading check statuses, try again later", http.StatusServiceUnavailable)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
+
+// buildGroupedChecks returns all checks, or those matching tags, grouped with their last
+// status and history for the given range. Groups without a matching check are left out
+// when filtering by tags. Once ctx is done, in-flight queries are cancelled and ctx.Err() is
+// returned rather than a partial result, since missing statuses would read as down.
+func (h *Handlers) buildGroupedChecks(ctx context.Context, since *time.Time, tags models.TagFilter) ([]models.GroupWithChecks, error) {
 	// Determine aggregation strategy based on time range
 	var historyLimit int
 	var bucketMinutes int
@@ -1165,16 +2572,20 @@ func (h *Handlers) GetGroupedChecks(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	checks, err := h.db.GetAllChecks()
+	var checks []models.Check
+	var err error
+	if len(tags.IDs) > 0 {
+		checks, _, err = h.db.GetChecksFiltered(models.CheckFilter{Tags: tags})
+	} else {
+		checks, err = h.db.GetAllChecks()
+	}
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+		return nil, err
 	}
 
 	groups, err := h.db.GetAllGroups()
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+		return nil, err
 	}
 
 	groupMap := make(map[int64]*models.GroupWithChecks, len(groups))
@@ -1204,15 +2615,20 @@ func (h *Handlers) GetGroupedChecks(w http.ResponseWriter, r *http.Request) {
 	maxWorkers := 8
 	sem := make(chan struct{}, maxWorkers)
 
+dispatch:
 	for _, check := range checks {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			break dispatch
+		}
 		wg.Add(1)
-		sem <- struct{}{}
 		c := check
 		go func() {
 			defer wg.Done()
 			defer func() { <-sem }()
 
-			lastStatus, err := h.db.GetLastStatus(c.ID)
+			lastStatus, err := h.db.GetLastStatusContext(ctx, c.ID)
 			if err != nil {
 				mu.Lock()
 				if firstErr == nil {
@@ -1224,9 +2640,9 @@ func (h *Handlers) GetGroupedChecks(w http.ResponseWriter, r *http.Request) {
 
 			var history []models.CheckHistory
 			if bucketMinutes > 0 {
-				history, err = h.db.GetCheckHistoryAggregated(c.ID, since, bucketMinutes, historyLimit)
+				history, err = h.db.GetCheckHistoryAggregatedContext(ctx, c.ID, since, bucketMinutes, historyLimit)
 			} else {
-				history, err = h.db.GetCheckHistory(c.ID, since, historyLimit)
+				history, err = h.db.GetCheckHistoryContext(ctx, c.ID, since, historyLimit)
 			}
 			if err != nil {
 				mu.Lock()
@@ -1245,11 +2661,16 @@ func (h *Handlers) GetGroupedChecks(w http.ResponseWriter, r *http.Request) {
 	}
 
 	wg.Wait()
+	// Cancelled queries fail with driver errors, so report the deadline itself
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	if firstErr != nil {
-		http.Error(w, firstErr.Error(), http.StatusInternalServerError)
-		return
+		return nil, firstErr
 	}
 
+	regionsByCheck := h.lastStatusByRegion(checks)
+
 	for _, check := range checks {
 		lastStatus := lastStatusMap[check.ID]
 		history := historyMap[check.ID]
@@ -1258,6 +2679,9 @@ func (h *Handlers) GetGroupedChecks(w http.ResponseWriter, r *http.Request) {
 			Check:      check,
 			LastStatus: lastStatus,
 			History:    history,
+			Regions:    regionStatusMap(regionsByCheck[check.ID]),
+
+			Misconfigured: h.engine.Misconfiguration(check.ID),
 		}
 
 		if lastStatus != nil {
@@ -1303,7 +2727,7 @@ func (h *Handlers) GetGroupedChecks(w http.ResponseWriter, r *http.Request) {
 
 	result := make([]models.GroupWithChecks, 0, len(groups)+1)
 	for _, g := range groups {
-		if gwc, ok := groupMap[g.ID]; ok {
+		if gwc, ok := groupMap[g.ID]; ok && (len(tags.IDs) == 0 || len(gwc.Checks) > 0) {
 			result = append(result, *gwc)
 		}
 	}
@@ -1311,8 +2735,7 @@ func (h *Handlers) GetGroupedChecks(w http.ResponseWriter, r *http.Request) {
 		result = append(result, *ungrouped)
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(result)
+	return result, nil
 }
 
 func (h *Handlers) TriggerCheck(w http.ResponseWriter, r *http.Request) {
@@ -1323,8 +2746,25 @@ func (h *Handlers) TriggerCheck(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	h.triggerCheck(w, r, id)
+}
+
+// triggerCheck runs a check now and writes the response of the trigger endpoints
+func (h *Handlers) triggerCheck(w http.ResponseWriter, r *http.Request, id int64) {
+	// With ?wait=true the check runs synchronously and its result is returned
+	if wait, _ := strconv.ParseBool(r.URL.Query().Get("wait")); wait {
+		history, err := h.engine.RunCheck(r.Context(), id)
+		if err != nil {
+			http.Error(w, err.Error(), triggerErrorStatus(err))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(history)
+		return
+	}
+
 	if err := h.engine.TriggerCheck(id); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		http.Error(w, err.Error(), triggerErrorStatus(err))
 		return
 	}
 
@@ -1332,6 +2772,17 @@ func (h *Handlers) TriggerCheck(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(map[string]string{"status": "ok", "message": "Check triggered successfully"})
 }
 
+func triggerErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, checker.ErrCheckRunning):
+		return http.StatusConflict
+	case errors.Is(err, checker.ErrRunTimeout):
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusBadRequest
+	}
+}
+
 func (h *Handlers) TriggerCheckForRegion(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id, err := strconv.ParseInt(vars["id"], 10, 64)
@@ -1348,7 +2799,11 @@ func (h *Handlers) TriggerCheckForRegion(w http.ResponseWriter, r *http.Request)
 
 	check, err := h.db.GetCheck(id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if check == nil {
+		http.Error(w, "check not found", http.StatusNotFound)
 		return
 	}
 
@@ -1359,9 +2814,12 @@ func (h *Handlers) TriggerCheckForRegion(w http.ResponseWriter, r *http.Request)
 
 	if h.sentinelServer != nil {
 		if broadcaster, ok := h.sentinelServer.(interface {
-			BroadcastCheckToRegion(check models.Check, region string)
+			BroadcastCheckToRegion(check models.Check, region string) map[string]error
 		}); ok {
-			broadcaster.BroadcastCheckToRegion(*check, region)
+			if err := broadcaster.BroadcastCheckToRegion(*check, region)[region]; err != nil {
+				http.Error(w, fmt.Sprintf("failed to send check to region %s: %v", region, err), http.StatusBadGateway)
+				return
+			}
 		} else {
 			http.Error(w, "region-specific checks not supported", http.StatusInternalServerError)
 			return
@@ -1375,12 +2833,68 @@ func (h *Handlers) TriggerCheckForRegion(w http.ResponseWriter, r *http.Request)
 	json.NewEncoder(w).Encode(map[string]string{"status": "ok", "message": fmt.Sprintf("Check triggered for region %s", region)})
 }
 
+// TriggerCheckAllRegions runs a check on every connected probe and returns each
+// region's result, waiting up to the check timeout plus a grace period
+func (h *Handlers) TriggerCheckAllRegions(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	check, err := h.db.GetCheck(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if check == nil {
+		http.Error(w, "check not found", http.StatusNotFound)
+		return
+	}
+
+	if check.Type == models.CheckTypeTailscale || check.Type == models.CheckTypeTailscaleService {
+		http.Error(w, "Tailscale checks cannot be triggered for specific regions", http.StatusBadRequest)
+		return
+	}
+
+	fanout, ok := h.sentinelServer.(interface {
+		TriggerCheckAllRegions(ctx context.Context, check models.Check, timeout time.Duration) ([]models.RegionCheckResult, error)
+	})
+	if h.sentinelServer == nil || !ok {
+		http.Error(w, "no sentinel server available", http.StatusInternalServerError)
+		return
+	}
+
+	timeout := time.Duration(h.engine.Limits().ClampTimeout(check.TimeoutSeconds))*time.Second + regionResultGrace
+	results, err := fanout.TriggerCheckAllRegions(r.Context(), *check, timeout)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if len(results) == 0 {
+		http.Error(w, "no probes connected", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"check_id": check.ID,
+		"results":  results,
+	})
+}
+
 func (h *Handlers) GetProbes(w http.ResponseWriter, r *http.Request) {
 	probes, err := h.db.GetAllProbes()
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	if annotator, ok := h.sentinelServer.(interface {
+		AnnotateProbes(probes []models.Probe)
+	}); ok {
+		annotator.AnnotateProbes(probes)
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(probes)
@@ -1413,6 +2927,7 @@ func (h *Handlers) CreateProbe(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	h.locateProbe(probeID, req.IPAddress)
 
 	probe, err := h.db.GetProbeByID(probeID)
 	if err != nil {
@@ -1486,6 +3001,11 @@ func (h *Handlers) RegenerateProbeToken(w http.ResponseWriter, r *http.Request)
 	json.NewEncoder(w).Encode(RegenerateTokenResponse{Token: token})
 }
 
+const (
+	sseKeepaliveInterval = 15 * time.Second
+	sseWriteTimeout      = 10 * time.Second
+)
+
 func (h *Handlers) StreamCheckUpdates(w http.ResponseWriter, r *http.Request) {
 	// Set SSE headers
 	w.Header().Set("Content-Type", "text/event-stream")
@@ -1493,10 +3013,6 @@ func (h *Handlers) StreamCheckUpdates(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Connection", "keep-alive")
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 
-	// Subscribe to check updates
-	client := h.engine.Subscribe()
-	defer h.engine.Unsubscribe(client)
-
 	// Get flusher for sending data
 	flusher, ok := w.(http.Flusher)
 	if !ok {
@@ -1504,23 +3020,51 @@ func (h *Handlers) StreamCheckUpdates(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Subscribe to check updates; the deferred Unsubscribe also runs on a panic
+	client := h.engine.Subscribe()
+	defer h.engine.Unsubscribe(client)
+
+	// A client that stops reading makes a write time out instead of blocking forever
+	rc := http.NewResponseController(w)
+	send := func(format string, args ...interface{}) error {
+		rc.SetWriteDeadline(time.Now().Add(sseWriteTimeout))
+		if _, err := fmt.Fprintf(w, format, args...); err != nil {
+			return err
+		}
+		flusher.Flush()
+		return nil
+	}
+
 	// Send initial connection event
-	fmt.Fprintf(w, "event: connected\ndata: {\"message\":\"connected\"}\n\n")
-	flusher.Flush()
+	if err := send("event: connected\ndata: {\"message\":\"connected\"}\n\n"); err != nil {
+		return
+	}
+
+	// Comments keep idle proxies from closing the connection
+	keepalive := time.NewTicker(sseKeepaliveInterval)
+	defer keepalive.Stop()
 
 	// Stream updates
 	for {
+		var err error
 		select {
-		case event := <-client:
-			data, err := json.Marshal(event)
-			if err != nil {
+		case event, ok := <-client:
+			if !ok {
+				return
+			}
+			data, marshalErr := json.Marshal(event)
+			if marshalErr != nil {
 				continue
 			}
-			fmt.Fprintf(w, "event: check_update\ndata: %s\n\n", data)
-			flusher.Flush()
+			err = send("event: check_update\ndata: %s\n\n", data)
+		case <-keepalive.C:
+			err = send(": keepalive\n\n")
 		case <-r.Context().Done():
 			// Client disconnected
 			return
 		}
+		if err != nil {
+			return
+		}
 	}
 }
@@ -0,0 +1,353 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gocheck/internal/checker"
+	"gocheck/internal/db"
+	"gocheck/internal/models"
+
+	"github.com/gorilla/mux"
+)
+
+// tagDB stores tags in memory and enforces unique names like the real schema
+type tagDB struct {
+	auditDB
+	tags []models.Tag
+}
+
+func (d *tagDB) CreateTag(t *models.Tag) error {
+	for _, existing := range d.tags {
+		if existing.Name == t.Name {
+			return db.ErrDuplicateTag
+		}
+	}
+	t.ID = int64(len(d.tags) + 1)
+	d.tags = append(d.tags, *t)
+	return nil
+}
+
+func (d *tagDB) GetAllTags() ([]models.Tag, error) {
+	return d.tags, nil
+}
+
+func TestCreateTagDuplicateName(t *testing.T) {
+	h := &Handlers{db: &db.Database{DB: &tagDB{}}}
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"new tag", `{"name": "production", "color": "#ff0000"}`, http.StatusCreated},
+		{"duplicate name", `{"name": "production", "color": "#00ff00"}`, http.StatusConflict},
+		{"invalid color", `{"name": "staging", "color": "red"}`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/tags", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+			h.CreateTag(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d (body %q)", rec.Code, tt.status, rec.Body.String())
+			}
+			if tt.status == http.StatusConflict && !strings.Contains(rec.Body.String(), "already exists") {
+				t.Errorf("body = %q, want a clear duplicate message", rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestParseTagFilter(t *testing.T) {
+	h := &Handlers{db: &db.Database{DB: &tagDB{tags: []models.Tag{{ID: 1, Name: "env:prod"}, {ID: 2, Name: "team:payments"}}}}}
+
+	tests := []struct {
+		query string
+		want  models.TagFilter
+		err   bool
+	}{
+		{"", models.TagFilter{}, false},
+		{"tags=env:prod,+2", models.TagFilter{IDs: []int64{1, 2}}, false},
+		{"tags=7&match=all", models.TagFilter{IDs: []int64{7}, MatchAll: true}, false},
+		{"tags=env:staging", models.TagFilter{}, true},
+		{"tags=1&match=some", models.TagFilter{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got, err := h.parseTagFilter(httptest.NewRequest(http.MethodGet, "/api/checks?"+tt.query, nil))
+			if (err != nil) != tt.err {
+				t.Fatalf("error = %v, want error %v", err, tt.err)
+			}
+			if !tt.err && (fmt.Sprint(got.IDs) != fmt.Sprint(tt.want.IDs) || got.MatchAll != tt.want.MatchAll) {
+				t.Errorf("filter = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// slowDB has many checks whose statuses only load once the request is cancelled
+type slowDB struct {
+	db.DB
+}
+
+func (d *slowDB) GetAllChecks() ([]models.Check, error) {
+	checks := make([]models.Check, 20)
+	for i := range checks {
+		checks[i].ID = int64(i + 1)
+	}
+	return checks, nil
+}
+
+func (d *slowDB) GetAllGroups() ([]models.Group, error) {
+	return nil, nil
+}
+
+func (d *slowDB) GetLastStatusContext(ctx context.Context, checkID int64) (*models.CheckHistory, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestGetGroupedChecksTimeout(t *testing.T) {
+	h := &Handlers{db: &db.Database{DB: &slowDB{}}, groupedTimeout: 50 * time.Millisecond}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/checks/grouped", nil)
+	rec := httptest.NewRecorder()
+	start := time.Now()
+	h.GetGroupedChecks(rec, req)
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want %d (body %q)", rec.Code, http.StatusServiceUnavailable, rec.Body.String())
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("request took %v, want it bounded by the timeout", elapsed)
+	}
+}
+
+// bulkDB holds existing check IDs and deletes them like DeleteChecks
+type bulkDB struct {
+	auditDB
+	ids map[int64]bool
+}
+
+func (d *bulkDB) DeleteChecks(ids []int64) ([]int64, error) {
+	deleted := []int64{}
+	for _, id := range ids {
+		if d.ids[id] {
+			delete(d.ids, id)
+			deleted = append(deleted, id)
+		}
+	}
+	return deleted, nil
+}
+
+func TestBulkUpdateChecks(t *testing.T) {
+	database := &db.Database{DB: &bulkDB{ids: map[int64]bool{1: true, 2: true}}}
+	h := &Handlers{db: database, engine: checker.NewEngine(database, nil)}
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+		want   string
+	}{
+		{"delete", `{"ids": [1, 2, 2, 3], "action": "delete"}`, http.StatusOK, `{"action":"delete","succeeded":[1,2],"failed":[{"id":3,"error":"check not found"}]}`},
+		{"unknown action", `{"ids": [1], "action": "pause"}`, http.StatusBadRequest, ""},
+		{"no ids", `{"ids": [], "action": "delete"}`, http.StatusBadRequest, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/checks/bulk", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+			h.BulkUpdateChecks(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d (body %q)", rec.Code, tt.status, rec.Body.String())
+			}
+			if tt.want != "" && strings.TrimSpace(rec.Body.String()) != tt.want {
+				t.Errorf("body = %s, want %s", rec.Body.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateAuth(t *testing.T) {
+	tests := []struct {
+		name    string
+		check   models.Check
+		want    models.Check
+		wantErr string
+	}{
+		{
+			name:  "none drops credentials",
+			check: models.Check{Type: models.CheckTypeHTTP, AuthType: "none", AuthUsername: "u", AuthPassword: "p", AuthToken: "t"},
+			want:  models.Check{Type: models.CheckTypeHTTP},
+		},
+		{
+			name:  "basic drops token",
+			check: models.Check{Type: models.CheckTypeHTTP, AuthType: "basic", AuthUsername: "u", AuthPassword: "p", AuthToken: "t"},
+			want:  models.Check{Type: models.CheckTypeHTTP, AuthType: "basic", AuthUsername: "u", AuthPassword: "p"},
+		},
+		{
+			name:  "bearer drops username and password",
+			check: models.Check{Type: models.CheckTypeJSONHTTP, AuthType: "bearer", AuthUsername: "u", AuthPassword: "p", AuthToken: "t"},
+			want:  models.Check{Type: models.CheckTypeJSONHTTP, AuthType: "bearer", AuthToken: "t"},
+		},
+		{
+			name:    "basic needs a username",
+			check:   models.Check{Type: models.CheckTypeHTTP, AuthType: "basic", AuthPassword: "p"},
+			wantErr: "auth_username is required for basic auth",
+		},
+		{
+			name:    "bearer needs a token",
+			check:   models.Check{Type: models.CheckTypeHTTP, AuthType: "bearer"},
+			wantErr: "auth_token is required for bearer auth",
+		},
+		{
+			name:    "unknown scheme",
+			check:   models.Check{Type: models.CheckTypeHTTP, AuthType: "digest"},
+			wantErr: "auth_type must be none, basic or bearer",
+		},
+		{
+			name:    "not an http check",
+			check:   models.Check{Type: models.CheckTypeTCP, AuthType: "bearer", AuthToken: "t"},
+			wantErr: "auth_type is only supported for http and json_http checks",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check := tt.check
+			err := validateAuth(&check)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("validateAuth() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if check.AuthType != tt.want.AuthType || check.AuthUsername != tt.want.AuthUsername ||
+				check.AuthPassword != tt.want.AuthPassword || check.AuthToken != tt.want.AuthToken {
+				t.Errorf("validateAuth() = %+v, want %+v", check, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckJSONOmitsAuthSecrets(t *testing.T) {
+	check := models.Check{Type: models.CheckTypeHTTP, AuthType: "basic", AuthUsername: "monitor", AuthPassword: "hunter2", AuthToken: "s3cret"}
+	data, err := json.Marshal(check)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := string(data)
+	if strings.Contains(body, "hunter2") || strings.Contains(body, "s3cret") {
+		t.Errorf("check JSON leaks a secret: %s", body)
+	}
+	if !strings.Contains(body, `"auth_username":"monitor"`) {
+		t.Errorf("check JSON lacks the username: %s", body)
+	}
+}
+
+func TestValidateCheck(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/down" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer target.Close()
+
+	database := &db.Database{DB: &bulkDB{}}
+	h := &Handlers{db: database, engine: checker.NewEngine(database, nil)}
+
+	tests := []struct {
+		name    string
+		body    string
+		status  int
+		success bool
+	}{
+		{"up", `{"name": "site", "type": "http", "url": "` + target.URL + `", "interval_seconds": 60, "timeout_seconds": 5}`, http.StatusOK, true},
+		{"down", `{"name": "site", "type": "http", "url": "` + target.URL + `/down", "interval_seconds": 60, "timeout_seconds": 5}`, http.StatusOK, false},
+		{"invalid", `{"name": "site", "type": "http", "interval_seconds": 60}`, http.StatusBadRequest, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/checks/validate", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+			h.ValidateCheck(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d (body %q)", rec.Code, tt.status, rec.Body.String())
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			var history models.CheckHistory
+			if err := json.NewDecoder(rec.Body).Decode(&history); err != nil {
+				t.Fatal(err)
+			}
+			if history.Success != tt.success {
+				t.Errorf("success = %v, want %v (error %q)", history.Success, tt.success, history.ErrorMessage)
+			}
+		})
+	}
+}
+
+func TestUpdateCheckKeepsLegacyTiming(t *testing.T) {
+	// Checks saved before timing was validated may time out after their interval
+	fake := &applyDB{checks: []models.Check{{ID: 1, Name: "legacy", Type: models.CheckTypeHTTP, URL: "https://example.com", IntervalSeconds: 5, TimeoutSeconds: 10}}}
+	database := &db.Database{DB: fake}
+	h := &Handlers{db: database, engine: checker.NewEngine(database, nil)}
+
+	update := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/api/checks/1", strings.NewReader(body))
+		req = mux.SetURLVars(req, map[string]string{"id": "1"})
+		rec := httptest.NewRecorder()
+		h.UpdateCheck(rec, req)
+		return rec
+	}
+
+	if rec := update(`{"name": "renamed"}`); rec.Code != http.StatusOK {
+		t.Fatalf("rename = %d %s", rec.Code, rec.Body.String())
+	}
+	if fake.checks[0].Name != "renamed" {
+		t.Errorf("name = %q, want renamed", fake.checks[0].Name)
+	}
+	if rec := update(`{"interval_seconds": 8}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("interval change keeping the long timeout = %d, want 400", rec.Code)
+	}
+	if rec := update(`{"timeout_seconds": 4}`); rec.Code != http.StatusOK {
+		t.Errorf("timeout fix = %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestTriggerRegionsUnknownCheck(t *testing.T) {
+	database := &db.Database{DB: &applyDB{}}
+	h := &Handlers{db: database, engine: checker.NewEngine(database, nil)}
+
+	for name, handler := range map[string]http.HandlerFunc{
+		"all regions": h.TriggerCheckAllRegions,
+		"one region":  h.TriggerCheckForRegion,
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/api/checks/42/trigger/all", nil)
+		req = mux.SetURLVars(req, map[string]string{"id": "42", "region": "eu-west"})
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("%s: unknown check = %d, want 404", name, rec.Code)
+		}
+	}
+}
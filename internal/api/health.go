@@ -0,0 +1,40 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// readyTimeout bounds the database ping of a readiness probe
+const readyTimeout = 2 * time.Second
+
+// Healthz is the liveness probe: it answers as long as the process can serve requests
+func (h *Handlers) Healthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+// Readyz is the readiness probe: it answers 200 only once the check engine has started
+// and the database responds to a ping, and 503 otherwise
+func (h *Handlers) Readyz(w http.ResponseWriter, r *http.Request) {
+	status, reason := http.StatusOK, ""
+	if h.engine == nil || !h.engine.Started() {
+		status, reason = http.StatusServiceUnavailable, "check engine not started"
+	} else {
+		ctx, cancel := context.WithTimeout(r.Context(), readyTimeout)
+		defer cancel()
+		if err := h.db.Ping(ctx); err != nil {
+			status, reason = http.StatusServiceUnavailable, "database unreachable: "+err.Error()
+		}
+	}
+
+	body := map[string]string{"status": "ok"}
+	if status != http.StatusOK {
+		body = map[string]string{"status": "unavailable", "error": reason}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gocheck/internal/checker"
+	"gocheck/internal/db"
+	"gocheck/internal/models"
+)
+
+// pingDB has no checks and fails pings with err
+type pingDB struct {
+	db.DB
+	err error
+}
+
+func (d *pingDB) Ping(ctx context.Context) error                  { return d.err }
+func (d *pingDB) GetSetting(key string) (string, error)           { return "", nil }
+func (d *pingDB) GetEnabledChecks() ([]models.Check, error)       { return nil, nil }
+func (d *pingDB) GetAllCheckRegions() (map[int64][]string, error) { return nil, nil }
+
+func TestReadyz(t *testing.T) {
+	fake := &pingDB{}
+	database := &db.Database{DB: fake}
+	engine := checker.NewEngine(database, nil)
+	h := &Handlers{db: database, engine: engine}
+
+	ready := func() int {
+		rec := httptest.NewRecorder()
+		h.Readyz(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		return rec.Code
+	}
+
+	if code := ready(); code != http.StatusServiceUnavailable {
+		t.Errorf("before engine start: status = %d, want 503", code)
+	}
+
+	if err := engine.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer engine.Stop()
+	if code := ready(); code != http.StatusOK {
+		t.Errorf("started: status = %d, want 200", code)
+	}
+
+	fake.err = errors.New("connection refused")
+	if code := ready(); code != http.StatusServiceUnavailable {
+		t.Errorf("database down: status = %d, want 503", code)
+	}
+
+	rec := httptest.NewRecorder()
+	h.Healthz(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("healthz: status = %d, want 200", rec.Code)
+	}
+}
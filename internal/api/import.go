@@ -0,0 +1,248 @@
+package api
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"gocheck/internal/checker"
+	"gocheck/internal/models"
+)
+
+const (
+	// maxImportRows caps how many checks a single import may create
+	maxImportRows = 1000
+	// maxImportBytes caps the size of an import upload
+	maxImportBytes = 10 << 20
+)
+
+// errImportRowLimit stops reading once maxImportRows rows have been processed
+var errImportRowLimit = errors.New("row limit reached")
+
+// ImportChecks creates checks in bulk from a CSV file with a header row or a JSON array,
+// using the columns name, type, url, host, port, interval and timeout. The body is read
+// row by row; each row is validated like a created check and started right away. Rows
+// that fail validation or reuse an existing name are skipped with a reason.
+func (h *Handlers) ImportChecks(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "json"
+		if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); strings.Contains(mediaType, "csv") {
+			format = "csv"
+		}
+	}
+
+	existing, err := h.db.GetAllChecks()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	names := make(map[string]bool, len(existing))
+	for _, check := range existing {
+		names[check.Name] = true
+	}
+
+	resp := models.CheckImportResponse{Results: []models.CheckImportResult{}}
+	n := 0
+	importRow := func(row models.CheckImportRow, rowErr error) error {
+		if n == maxImportRows {
+			return errImportRowLimit
+		}
+		n++
+
+		result := models.CheckImportResult{Row: n, Name: row.Name, Status: "skipped"}
+		if rowErr != nil {
+			result.Reason = rowErr.Error()
+		} else {
+			result = h.importCheck(n, row, names)
+		}
+		if result.Status == "created" {
+			resp.Created++
+			h.audit(r, models.AuditCreate, models.AuditTargetCheck, result.ID, map[string]string{"name": result.Name, "source": "import"})
+		} else {
+			resp.Skipped++
+		}
+		resp.Results = append(resp.Results, result)
+		return nil
+	}
+
+	body := http.MaxBytesReader(w, r.Body, maxImportBytes)
+	switch format {
+	case "csv":
+		err = readImportCSV(body, importRow)
+	case "json":
+		err = readImportJSON(body, importRow)
+	default:
+		http.Error(w, "format must be csv or json", http.StatusBadRequest)
+		return
+	}
+
+	if errors.Is(err, errImportRowLimit) {
+		resp.Truncated = true
+		resp.Error = fmt.Sprintf("only the first %d rows were imported", maxImportRows)
+	} else if err != nil {
+		if n == 0 {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		resp.Error = fmt.Sprintf("stopped after row %d: %v", n, err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// importCheck validates and creates the check of one row
+func (h *Handlers) importCheck(rowNum int, row models.CheckImportRow, names map[string]bool) models.CheckImportResult {
+	result := models.CheckImportResult{Row: rowNum, Name: row.Name, Status: "skipped"}
+
+	if row.Type == "" {
+		row.Type = models.CheckTypeHTTP
+	}
+	if !row.Type.Valid() {
+		result.Reason = fmt.Sprintf("unknown check type %q", row.Type)
+		return result
+	}
+	if names[row.Name] {
+		result.Reason = "a check with this name already exists"
+		return result
+	}
+
+	req := models.CreateCheckRequest{
+		Name:            row.Name,
+		Type:            row.Type,
+		URL:             row.URL,
+		Host:            row.Host,
+		Port:            row.Port,
+		IntervalSeconds: row.Interval,
+		TimeoutSeconds:  row.Timeout,
+		Enabled:         true,
+	}
+	if row.Type == models.CheckTypeDNS {
+		req.DNSHostname = row.Host
+	}
+
+	check, err := h.newCheck(&req)
+	if err == nil {
+		// Imports only carry the basic columns, so reject rows that could never run
+		err = checker.ConfigError(check)
+	}
+	if err != nil {
+		result.Reason = err.Error()
+		return result
+	}
+
+	if err := h.db.CreateCheck(&check); err != nil {
+		result.Reason = fmt.Sprintf("failed to save: %v", err)
+		return result
+	}
+	h.engine.AddCheck(check)
+	names[check.Name] = true
+
+	result.Status = "created"
+	result.ID = check.ID
+	return result
+}
+
+// readImportJSON decodes a JSON array one element at a time. A value of the wrong type
+// only fails its own row; malformed JSON ends the import.
+func readImportJSON(body io.Reader, fn func(models.CheckImportRow, error) error) error {
+	dec := json.NewDecoder(body)
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+		return fmt.Errorf("expected a JSON array of checks")
+	}
+	for dec.More() {
+		var row models.CheckImportRow
+		err := dec.Decode(&row)
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return fmt.Errorf("invalid JSON: %w", err)
+		}
+		if err != nil {
+			err = fmt.Errorf("invalid row: %v", err)
+		}
+		if err := fn(row, err); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readImportCSV reads rows by the column names of the header row. Only name is
+// required; unknown columns are rejected so a typo doesn't silently drop data.
+func readImportCSV(body io.Reader, fn func(models.CheckImportRow, error) error) error {
+	cr := csv.NewReader(body)
+	cr.TrimLeadingSpace = true
+	cr.FieldsPerRecord = -1 // short rows leave the missing columns empty
+
+	header, err := cr.Read()
+	if err != nil {
+		return fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		switch name {
+		case "name", "type", "url", "host", "port", "interval", "timeout":
+			columns[name] = i
+		default:
+			return fmt.Errorf("unknown CSV column %q", name)
+		}
+	}
+	if _, ok := columns["name"]; !ok {
+		return fmt.Errorf("CSV header must include a name column")
+	}
+
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		number := func(name string) (models.FlexibleInt, error) {
+			value := field(name)
+			if value == "" {
+				return models.FlexibleInt{}, nil
+			}
+			v, err := strconv.Atoi(value)
+			if err != nil {
+				return models.FlexibleInt{}, fmt.Errorf("%s must be a number", name)
+			}
+			return models.FlexibleInt{Value: v, Set: true}, nil
+		}
+
+		row := models.CheckImportRow{
+			Name: field("name"),
+			Type: models.CheckType(field("type")),
+			URL:  field("url"),
+			Host: field("host"),
+		}
+		var rowErr error
+		for _, col := range []struct {
+			name   string
+			target *models.FlexibleInt
+		}{{"port", &row.Port}, {"interval", &row.Interval}, {"timeout", &row.Timeout}} {
+			if *col.target, err = number(col.name); err != nil && rowErr == nil {
+				rowErr = err
+			}
+		}
+		if err := fn(row, rowErr); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"gocheck/internal/checker"
+	"gocheck/internal/models"
+)
+
+// GetMonitoringStatus reports whether monitoring is globally paused
+func (h *Handlers) GetMonitoringStatus(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.engine.MonitoringStatus())
+}
+
+// UpdateMonitoringStatus pauses or resumes all scheduled checks and notifications
+func (h *Handlers) UpdateMonitoringStatus(w http.ResponseWriter, r *http.Request) {
+	var req models.UpdateMonitoringRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Paused == nil {
+		http.Error(w, "paused is required", http.StatusBadRequest)
+		return
+	}
+
+	status, err := h.engine.SetPaused(*req.Paused)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.audit(r, models.AuditUpdate, models.AuditTargetSetting, 0, map[string]string{
+		checker.MonitoringPausedSetting: strconv.FormatBool(*req.Paused),
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
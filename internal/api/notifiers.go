@@ -0,0 +1,580 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"gocheck/internal/checker"
+	"gocheck/internal/db"
+	"gocheck/internal/models"
+	"gocheck/internal/notifier"
+
+	"github.com/gorilla/mux"
+)
+
+// notifiersMigratedSetting is set once the notifier settings have been copied into
+// notifier rows
+const notifiersMigratedSetting = "notifiers_migrated"
+
+// notifierKind describes a notifier type: the config keys it needs, how it is built,
+// and the settings it was configured with before notifiers were stored as rows. The
+// settings API still reads and writes the first notifier of each type through them.
+type notifierKind struct {
+	typ      string
+	name     string // name of the notifier migrated from the settings
+	required []string
+	settings map[string]string // config key -> settings key
+	toggle   string            // settings key that enabled the notifier
+	validate func(config map[string]string) error
+	build    func(config map[string]string) notifier.Notifier
+}
+
+var notifierKinds = []notifierKind{
+	{
+		typ:      "discord",
+		name:     "Discord",
+		required: []string{"webhook_url"},
+		settings: map[string]string{"webhook_url": "discord_webhook_url"},
+		toggle:   "discord_enabled",
+		build: func(c map[string]string) notifier.Notifier {
+			return notifier.NewDiscordNotifier(c["webhook_url"])
+		},
+	},
+	{
+		typ:      "gotify",
+		name:     "Gotify",
+		required: []string{"server_url", "token"},
+		settings: map[string]string{"server_url": "gotify_server_url", "token": "gotify_token"},
+		toggle:   "gotify_enabled",
+		build: func(c map[string]string) notifier.Notifier {
+			return notifier.NewGotifyNotifier(c["server_url"], c["token"])
+		},
+	},
+	{
+		typ:      "slack",
+		name:     "Slack",
+		required: []string{"webhook_url"},
+		settings: map[string]string{"webhook_url": "slack_webhook_url"},
+		toggle:   "slack_enabled",
+		build: func(c map[string]string) notifier.Notifier {
+			return notifier.NewSlackNotifier(c["webhook_url"])
+		},
+	},
+	{
+		typ:      "email",
+		name:     "Email",
+		required: []string{"host", "to"},
+		settings: map[string]string{
+			"host":     "smtp_host",
+			"port":     "smtp_port",
+			"username": "smtp_username",
+			"password": "smtp_password",
+			"from":     "smtp_from",
+			"to":       "smtp_to",
+		},
+		toggle: "email_enabled",
+		validate: func(c map[string]string) error {
+			if port := c["port"]; port != "" {
+				if n, err := strconv.Atoi(port); err != nil || n <= 0 || n > 65535 {
+					return fmt.Errorf("port must be between 1 and 65535")
+				}
+			}
+			return nil
+		},
+		build: func(c map[string]string) notifier.Notifier {
+			port, _ := strconv.Atoi(c["port"])
+			return notifier.NewEmailNotifier(notifier.EmailConfig{
+				Host:     c["host"],
+				Port:     port,
+				Username: c["username"],
+				Password: c["password"],
+				From:     c["from"],
+				To:       notifier.ParseRecipients(c["to"]),
+			})
+		},
+	},
+	{
+		typ:      "webhook",
+		name:     "Webhook",
+		required: []string{"url"},
+		settings: map[string]string{
+			"url":      "generic_webhook_url",
+			"method":   "generic_webhook_method",
+			"template": "generic_webhook_template",
+		},
+		toggle: "generic_webhook_enabled",
+		validate: func(c map[string]string) error {
+			s := models.Settings{GenericWebhookURL: c["url"], GenericWebhookMethod: c["method"], GenericWebhookTemplate: c["template"]}
+			if err := validateGenericWebhook(&s); err != nil {
+				return err
+			}
+			c["method"] = s.GenericWebhookMethod
+			return nil
+		},
+		build: func(c map[string]string) notifier.Notifier {
+			return notifier.NewWebhookNotifier(c["url"], c["method"], c["template"])
+		},
+	},
+	{
+		typ:      "opsgenie",
+		name:     "Opsgenie",
+		required: []string{"api_key"},
+		settings: map[string]string{
+			"api_key":  "opsgenie_api_key",
+			"region":   "opsgenie_region",
+			"priority": "opsgenie_priority",
+		},
+		toggle: "opsgenie_enabled",
+		validate: func(c map[string]string) error {
+			s := models.Settings{OpsgenieRegion: c["region"], OpsgeniePriority: c["priority"]}
+			if err := validateOpsgenie(&s); err != nil {
+				return err
+			}
+			c["region"], c["priority"] = s.OpsgenieRegion, s.OpsgeniePriority
+			return nil
+		},
+		build: func(c map[string]string) notifier.Notifier {
+			return notifier.NewOpsgenieNotifier(c["api_key"], c["region"], c["priority"])
+		},
+	},
+	{
+		typ:      "pagerduty",
+		name:     "PagerDuty",
+		required: []string{"routing_key"},
+		settings: map[string]string{
+			"routing_key": "pagerduty_routing_key",
+			"severity":    "pagerduty_severity",
+		},
+		toggle: "pagerduty_enabled",
+		validate: func(c map[string]string) error {
+			s := models.Settings{PagerDutySeverity: c["severity"]}
+			if err := validatePagerDuty(&s); err != nil {
+				return err
+			}
+			c["severity"] = s.PagerDutySeverity
+			return nil
+		},
+		build: func(c map[string]string) notifier.Notifier {
+			return notifier.NewPagerDutyNotifier(c["routing_key"], c["severity"])
+		},
+	},
+	{
+		typ:      "pushover",
+		name:     "Pushover",
+		required: []string{"user_key", "api_token"},
+		settings: map[string]string{
+			"user_key":  "pushover_user_key",
+			"api_token": "pushover_api_token",
+			"priority":  "pushover_priority",
+			"sound":     "pushover_sound",
+		},
+		toggle: "pushover_enabled",
+		validate: func(c map[string]string) error {
+			s := models.Settings{PushoverPriority: c["priority"], PushoverSound: c["sound"]}
+			if err := validatePushover(&s); err != nil {
+				return err
+			}
+			c["priority"], c["sound"] = s.PushoverPriority, s.PushoverSound
+			return nil
+		},
+		build: func(c map[string]string) notifier.Notifier {
+			return notifier.NewPushoverNotifier(c["user_key"], c["api_token"], c["priority"], c["sound"])
+		},
+	},
+	{
+		typ:      "apprise",
+		name:     "Apprise",
+		required: []string{"url"},
+		settings: map[string]string{"url": "apprise_url", "urls": "apprise_urls"},
+		toggle:   "apprise_enabled",
+		validate: func(c map[string]string) error {
+			s := models.Settings{AppriseURL: c["url"]}
+			if err := validateApprise(&s); err != nil {
+				return err
+			}
+			c["url"] = s.AppriseURL
+			return nil
+		},
+		build: func(c map[string]string) notifier.Notifier {
+			return notifier.NewAppriseNotifier(c["url"], c["urls"])
+		},
+	},
+}
+
+func findNotifierKind(typ string) (notifierKind, bool) {
+	for _, kind := range notifierKinds {
+		if kind.typ == typ {
+			return kind, true
+		}
+	}
+	return notifierKind{}, false
+}
+
+// validateNotifierConfig checks a notifier's type and config, dropping keys the type
+// doesn't use and normalizing the rest
+func validateNotifierConfig(n *models.NotifierConfig) error {
+	kind, ok := findNotifierKind(n.Type)
+	if !ok {
+		types := make([]string, len(notifierKinds))
+		for i, k := range notifierKinds {
+			types[i] = k.typ
+		}
+		return fmt.Errorf("unsupported notifier type %q, expected one of %s", n.Type, strings.Join(types, ", "))
+	}
+
+	config := make(map[string]string, len(kind.settings))
+	for key := range kind.settings {
+		if value := strings.TrimSpace(n.Config[key]); value != "" {
+			config[key] = value
+		}
+	}
+	for _, key := range kind.required {
+		if config[key] == "" {
+			return fmt.Errorf("%s notifiers require config.%s", n.Type, key)
+		}
+	}
+	if kind.validate != nil {
+		if err := kind.validate(config); err != nil {
+			return err
+		}
+	}
+	n.Config = config
+	return nil
+}
+
+// settingEnabled reads a notifier toggle; notifiers are enabled unless explicitly disabled
+func settingEnabled(database *db.Database, key string) bool {
+	value, _ := database.GetSetting(key)
+	return value != "false"
+}
+
+// MigrateNotifierSettings copies the notifiers configured through settings into
+// notifier rows, once
+func MigrateNotifierSettings(database *db.Database) error {
+	if done, _ := database.GetSetting(notifiersMigratedSetting); done == "true" {
+		return nil
+	}
+
+	for _, kind := range notifierKinds {
+		n := models.NotifierConfig{Type: kind.typ, Name: kind.name, Config: map[string]string{}}
+		for key, setting := range kind.settings {
+			if value, _ := database.GetSetting(setting); value != "" {
+				n.Config[key] = value
+			}
+		}
+		if validateNotifierConfig(&n) != nil {
+			continue
+		}
+		n.Enabled = settingEnabled(database, kind.toggle)
+		if err := database.CreateNotifier(&n); err != nil {
+			return err
+		}
+		log.Printf("Migrated %s notifier settings to notifier %d", kind.name, n.ID)
+	}
+
+	return database.SetSetting(notifiersMigratedSetting, "true")
+}
+
+// notifierSettings returns the settings view of the first notifier of each type, the
+// values the settings API reports, keyed by settings key
+func notifierSettings(database *db.Database) map[string]string {
+	values := map[string]string{}
+	rows, _ := database.GetAllNotifiers()
+	for _, kind := range notifierKinds {
+		for _, n := range rows {
+			if n.Type != kind.typ {
+				continue
+			}
+			for key, setting := range kind.settings {
+				values[setting] = n.Config[key]
+			}
+			values[kind.toggle] = strconv.FormatBool(n.Enabled)
+			break
+		}
+	}
+	return values
+}
+
+// saveNotifierSettings applies the settings API to the first notifier of each type:
+// it is created once its required values are set, updated, or deleted when they are
+// cleared. A nil toggle leaves the notifier's enabled state unchanged.
+func saveNotifierSettings(database *db.Database, values map[string]string, toggles map[string]*bool) error {
+	rows, err := database.GetAllNotifiers()
+	if err != nil {
+		return err
+	}
+
+	for _, kind := range notifierKinds {
+		var existing *models.NotifierConfig
+		for i := range rows {
+			if rows[i].Type == kind.typ {
+				existing = &rows[i]
+				break
+			}
+		}
+
+		n := models.NotifierConfig{Type: kind.typ, Name: kind.name, Enabled: true, Config: map[string]string{}}
+		if existing != nil {
+			n = *existing
+			n.Config = map[string]string{}
+		}
+		for key, setting := range kind.settings {
+			n.Config[key] = values[setting]
+		}
+		if enabled := toggles[kind.toggle]; enabled != nil {
+			n.Enabled = *enabled
+		}
+
+		if validateNotifierConfig(&n) != nil {
+			if existing != nil {
+				if err := database.DeleteNotifier(existing.ID); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if existing != nil {
+			err = database.UpdateNotifier(&n)
+		} else {
+			err = database.CreateNotifier(&n)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fillNotifierToggles reports the enable state of the first notifier of each type
+// and of the misconfiguration notification
+func fillNotifierToggles(database *db.Database, settings *models.Settings) {
+	values := notifierSettings(database)
+	for key, target := range map[string]**bool{
+		"discord_enabled": &settings.DiscordEnabled,
+		"gotify_enabled":  &settings.GotifyEnabled,
+		"slack_enabled":   &settings.SlackEnabled,
+		"email_enabled":   &settings.EmailEnabled,
+
+		"generic_webhook_enabled": &settings.WebhookEnabled,
+		"opsgenie_enabled":        &settings.OpsgenieEnabled,
+		"pagerduty_enabled":       &settings.PagerDutyEnabled,
+		"pushover_enabled":        &settings.PushoverEnabled,
+		"apprise_enabled":         &settings.AppriseEnabled,
+	} {
+		enabled := values[key] != "false"
+		*target = &enabled
+	}
+
+	notifyMisconfigured := settingEnabled(database, checker.NotifyMisconfiguredSetting)
+	settings.NotifyMisconfigured = &notifyMisconfigured
+}
+
+// LoadNotifiers builds the stored notifiers. configured holds every notifier, so test
+// endpoints keep working while a notifier is disabled; enabled is the subset that
+// should receive status changes. Without a Discord notifier, DISCORD_WEBHOOK_URL
+// still adds one.
+func LoadNotifiers(database *db.Database) (configured, enabled []notifier.Notifier) {
+	rows, err := database.GetAllNotifiers()
+	if err != nil {
+		log.Printf("Failed to load notifiers: %v", err)
+	}
+
+	hasDiscord := false
+	for _, row := range rows {
+		if err := validateNotifierConfig(&row); err != nil {
+			log.Printf("Skipping notifier %d (%s): %v", row.ID, row.Name, err)
+			continue
+		}
+		kind, _ := findNotifierKind(row.Type)
+		n := kind.build(row.Config)
+		configured = append(configured, n)
+		if row.Enabled {
+			enabled = append(enabled, n)
+		}
+		hasDiscord = hasDiscord || row.Type == "discord"
+	}
+
+	if webhookURL := os.Getenv("DISCORD_WEBHOOK_URL"); webhookURL != "" && !hasDiscord {
+		n := notifier.NewDiscordNotifier(webhookURL)
+		configured = append(configured, n)
+		enabled = append(enabled, n)
+	}
+
+	return configured, enabled
+}
+
+// reloadNotifiers rebuilds the notifiers after they changed
+func (h *Handlers) reloadNotifiers() {
+	configured, enabled := LoadNotifiers(h.db)
+	h.notifiers = configured
+	h.engine.UpdateNotifiers(enabled)
+}
+
+func (h *Handlers) GetNotifiers(w http.ResponseWriter, r *http.Request) {
+	notifiers, err := h.db.GetAllNotifiers()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if notifiers == nil {
+		notifiers = []models.NotifierConfig{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(notifiers)
+}
+
+func (h *Handlers) CreateNotifier(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateNotifierRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	n := models.NotifierConfig{
+		Type:    strings.ToLower(strings.TrimSpace(req.Type)),
+		Name:    strings.TrimSpace(req.Name),
+		Config:  req.Config,
+		Enabled: req.Enabled == nil || *req.Enabled,
+	}
+	if err := validateNotifierConfig(&n); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if n.Name == "" {
+		kind, _ := findNotifierKind(n.Type)
+		n.Name = kind.name
+	}
+
+	if err := h.db.CreateNotifier(&n); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.reloadNotifiers()
+	h.audit(r, models.AuditCreate, models.AuditTargetNotifier, n.ID, notifierAuditDetails(&n))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(n)
+}
+
+func (h *Handlers) UpdateNotifier(w http.ResponseWriter, r *http.Request) {
+	n, ok := h.notifierFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateNotifierRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Name != nil {
+		n.Name = strings.TrimSpace(*req.Name)
+		if n.Name == "" {
+			http.Error(w, "name is required", http.StatusBadRequest)
+			return
+		}
+	}
+	if req.Config != nil {
+		n.Config = req.Config
+	}
+	if req.Enabled != nil {
+		n.Enabled = *req.Enabled
+	}
+	if err := validateNotifierConfig(n); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.db.UpdateNotifier(n); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.reloadNotifiers()
+	h.audit(r, models.AuditUpdate, models.AuditTargetNotifier, n.ID, notifierAuditDetails(n))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(n)
+}
+
+func (h *Handlers) DeleteNotifier(w http.ResponseWriter, r *http.Request) {
+	n, ok := h.notifierFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.db.DeleteNotifier(n.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.reloadNotifiers()
+	h.audit(r, models.AuditDelete, models.AuditTargetNotifier, n.ID, map[string]string{"type": n.Type, "name": n.Name})
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// TestNotifier sends a test notification through a notifier, enabled or not
+func (h *Handlers) TestNotifier(w http.ResponseWriter, r *http.Request) {
+	n, ok := h.notifierFromPath(w, r)
+	if !ok {
+		return
+	}
+	if err := validateNotifierConfig(n); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	kind, _ := findNotifierKind(n.Type)
+	if err := kind.build(n.Config).TestWebhook(); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok", "message": "Test notification sent successfully"})
+}
+
+// notifierAuditDetails describes a notifier for the audit log. Config values are
+// redacted like secret settings, since most of them are credentials.
+func notifierAuditDetails(n *models.NotifierConfig) map[string]string {
+	details := map[string]string{
+		"type":    n.Type,
+		"name":    n.Name,
+		"enabled": strconv.FormatBool(n.Enabled),
+	}
+	for key, value := range n.Config {
+		if isSecretSetting(key) || key == "url" || key == "urls" {
+			value = redactedValue
+		}
+		details["config."+key] = value
+	}
+	return details
+}
+
+// notifierFromPath loads the notifier named by the id path param, writing the error
+// response when there is none
+func (h *Handlers) notifierFromPath(w http.ResponseWriter, r *http.Request) (*models.NotifierConfig, bool) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return nil, false
+	}
+
+	n, err := h.db.GetNotifier(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	if n == nil {
+		http.Error(w, "notifier not found", http.StatusNotFound)
+		return nil, false
+	}
+	return n, true
+}
@@ -0,0 +1,172 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gocheck/internal/checker"
+	"gocheck/internal/db"
+	"gocheck/internal/models"
+)
+
+// notifierDB stores settings and notifier rows in memory
+type notifierDB struct {
+	auditDB
+	settings  map[string]string
+	notifiers []models.NotifierConfig
+}
+
+func (d *notifierDB) GetSetting(key string) (string, error) { return d.settings[key], nil }
+
+func (d *notifierDB) SetSetting(key, value string) error {
+	d.settings[key] = value
+	return nil
+}
+
+func (d *notifierDB) GetAllNotifiers() ([]models.NotifierConfig, error) {
+	return append([]models.NotifierConfig(nil), d.notifiers...), nil
+}
+
+func (d *notifierDB) GetNotifier(id int64) (*models.NotifierConfig, error) {
+	for _, n := range d.notifiers {
+		if n.ID == id {
+			return &n, nil
+		}
+	}
+	return nil, nil
+}
+
+func (d *notifierDB) CreateNotifier(n *models.NotifierConfig) error {
+	n.ID = int64(len(d.notifiers) + 1)
+	d.notifiers = append(d.notifiers, *n)
+	return nil
+}
+
+func (d *notifierDB) UpdateNotifier(n *models.NotifierConfig) error {
+	for i := range d.notifiers {
+		if d.notifiers[i].ID == n.ID {
+			d.notifiers[i] = *n
+		}
+	}
+	return nil
+}
+
+func (d *notifierDB) DeleteNotifier(id int64) error {
+	for i := range d.notifiers {
+		if d.notifiers[i].ID == id {
+			d.notifiers = append(d.notifiers[:i], d.notifiers[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func TestMigrateNotifierSettings(t *testing.T) {
+	fake := &notifierDB{settings: map[string]string{
+		"discord_webhook_url": "https://discord.example/hook",
+		"slack_webhook_url":   "https://hooks.slack.example/x",
+		"slack_enabled":       "false",
+		"gotify_server_url":   "https://gotify.example", // no token, so not configured
+	}}
+	database := &db.Database{DB: fake}
+
+	if err := MigrateNotifierSettings(database); err != nil {
+		t.Fatal(err)
+	}
+	// A second start doesn't migrate again
+	if err := MigrateNotifierSettings(database); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(fake.notifiers) != 2 {
+		t.Fatalf("migrated %+v, want discord and slack", fake.notifiers)
+	}
+	discord, slack := fake.notifiers[0], fake.notifiers[1]
+	if discord.Type != "discord" || discord.Config["webhook_url"] != "https://discord.example/hook" || !discord.Enabled {
+		t.Errorf("discord = %+v", discord)
+	}
+	if slack.Type != "slack" || slack.Enabled {
+		t.Errorf("slack = %+v, want it disabled", slack)
+	}
+
+	configured, enabled := LoadNotifiers(database)
+	if len(configured) != 2 || len(enabled) != 1 {
+		t.Errorf("configured %d, enabled %d notifiers, want 2 and 1", len(configured), len(enabled))
+	}
+}
+
+func TestSaveNotifierSettings(t *testing.T) {
+	fake := &notifierDB{settings: map[string]string{}}
+	database := &db.Database{DB: fake}
+	fake.CreateNotifier(&models.NotifierConfig{Type: "discord", Name: "Ops", Enabled: true, Config: map[string]string{"webhook_url": "https://discord.example/ops"}})
+	fake.CreateNotifier(&models.NotifierConfig{Type: "discord", Name: "Dev", Enabled: true, Config: map[string]string{"webhook_url": "https://discord.example/dev"}})
+
+	disabled := false
+	if err := saveNotifierSettings(database, map[string]string{
+		"discord_webhook_url":   "https://discord.example/new",
+		"pagerduty_routing_key": "key",
+	}, map[string]*bool{"discord_enabled": &disabled}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The settings apply to the first notifier of each type only
+	if ops := fake.notifiers[0]; ops.Name != "Ops" || ops.Config["webhook_url"] != "https://discord.example/new" || ops.Enabled {
+		t.Errorf("first discord notifier = %+v", ops)
+	}
+	if dev := fake.notifiers[1]; dev.Config["webhook_url"] != "https://discord.example/dev" || !dev.Enabled {
+		t.Errorf("second discord notifier = %+v", dev)
+	}
+	if len(fake.notifiers) != 3 || fake.notifiers[2].Type != "pagerduty" || fake.notifiers[2].Config["severity"] != "critical" {
+		t.Fatalf("notifiers = %+v, want a new pagerduty notifier", fake.notifiers)
+	}
+
+	values := notifierSettings(database)
+	if values["discord_webhook_url"] != "https://discord.example/new" || values["discord_enabled"] != "false" {
+		t.Errorf("settings view = %v", values)
+	}
+
+	// Clearing the settings deletes the notifier they describe
+	if err := saveNotifierSettings(database, map[string]string{"discord_webhook_url": "https://discord.example/new"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range fake.notifiers {
+		if n.Type == "pagerduty" {
+			t.Errorf("pagerduty notifier %+v was not deleted", n)
+		}
+	}
+}
+
+func TestCreateNotifier(t *testing.T) {
+	database := &db.Database{DB: &notifierDB{settings: map[string]string{}}}
+	h := &Handlers{db: database, engine: checker.NewEngine(database, nil)}
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"discord", `{"type": "discord", "config": {"webhook_url": "https://discord.example/hook"}}`, http.StatusCreated},
+		{"second discord", `{"type": "discord", "name": "Dev", "config": {"webhook_url": "https://discord.example/dev"}, "enabled": false}`, http.StatusCreated},
+		{"missing config", `{"type": "gotify", "config": {"server_url": "https://gotify.example"}}`, http.StatusBadRequest},
+		{"invalid option", `{"type": "opsgenie", "config": {"api_key": "key", "region": "apac"}}`, http.StatusBadRequest},
+		{"unknown type", `{"type": "carrier-pigeon", "config": {}}`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/notifiers", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+			h.CreateNotifier(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d (body %q)", rec.Code, tt.status, rec.Body.String())
+			}
+		})
+	}
+
+	if len(h.notifiers) != 2 {
+		t.Errorf("handlers have %d notifiers after creating two", len(h.notifiers))
+	}
+}
@@ -0,0 +1,45 @@
+package api
+
+import (
+	"log"
+	"net/netip"
+
+	"gocheck/internal/geoip"
+	"gocheck/internal/models"
+)
+
+// SetGeoIP sets the database new probes are located in; nil skips the lookup
+func (h *Handlers) SetGeoIP(reader *geoip.Reader) {
+	h.geoip = reader
+}
+
+// locateProbe looks up where a new probe is from the IP address it was created with
+// and stores its country, city and coordinates. A failed lookup only leaves the
+// location empty.
+func (h *Handlers) locateProbe(probeID int64, ipAddress string) {
+	if h.geoip == nil || ipAddress == "" {
+		return
+	}
+	addr, err := netip.ParseAddr(ipAddress)
+	if err != nil {
+		return
+	}
+	loc, ok, err := h.geoip.Lookup(addr)
+	if err != nil {
+		log.Printf("GeoIP lookup of probe %d (%s) failed: %v", probeID, ipAddress, err)
+		return
+	}
+	if !ok {
+		return
+	}
+	err = h.db.UpdateProbeLocation(probeID, models.ProbeLocation{
+		CountryCode: loc.CountryCode,
+		Country:     loc.Country,
+		City:        loc.City,
+		Latitude:    loc.Latitude,
+		Longitude:   loc.Longitude,
+	})
+	if err != nil {
+		log.Printf("Failed to store the location of probe %d: %v", probeID, err)
+	}
+}
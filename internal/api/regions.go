@@ -0,0 +1,220 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gocheck/internal/models"
+
+	"github.com/gorilla/mux"
+)
+
+// hostRegion is the region recorded for checks run by the server itself
+const hostRegion = "host"
+
+// defaultRegionRange is the window for recent latency when no range is given
+const defaultRegionRange = time.Hour
+
+func toRegionStatus(h *models.CheckHistory) models.RegionStatus {
+	return models.RegionStatus{
+		Region:         h.Region,
+		IsUp:           h.Success,
+		StatusCode:     h.StatusCode,
+		ResponseTimeMs: h.ResponseTimeMs,
+		ErrorMessage:   h.ErrorMessage,
+		LastCheckedAt:  &h.CheckedAt,
+	}
+}
+
+// regionStatusMap converts the latest results per region for CheckWithStatus. It returns
+// nil when only the server has run the check, so the field is omitted without probes.
+func regionStatusMap(lastByRegion map[string]*models.CheckHistory) map[string]models.RegionStatus {
+	if len(lastByRegion) == 0 {
+		return nil
+	}
+	if _, onlyHost := lastByRegion[hostRegion]; onlyHost && len(lastByRegion) == 1 {
+		return nil
+	}
+
+	regions := make(map[string]models.RegionStatus, len(lastByRegion))
+	for region, h := range lastByRegion {
+		regions[region] = toRegionStatus(h)
+	}
+	return regions
+}
+
+// lastStatusByRegion loads the latest result per region of the given checks. Failures
+// are logged and leave the regions out rather than failing the whole list.
+func (h *Handlers) lastStatusByRegion(checks []models.Check) map[int64]map[string]*models.CheckHistory {
+	ids := make([]int64, 0, len(checks))
+	for _, check := range checks {
+		ids = append(ids, check.ID)
+	}
+
+	byCheck, err := h.db.GetLastStatusByRegionForChecks(ids)
+	if err != nil {
+		log.Printf("Failed to get last status by region: %v", err)
+		return nil
+	}
+	return byCheck
+}
+
+// GetCheckRegions returns the probe regions assigned to a check and its latest status in
+// every region it has run in, with the number of runs and the average latency over the
+// range (default 1h)
+func (h *Handlers) GetCheckRegions(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+	since, err := parseRangeParam(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	check, err := h.db.GetCheck(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if check == nil {
+		http.Error(w, "check not found", http.StatusNotFound)
+		return
+	}
+
+	h.writeCheckRegions(w, id, since)
+}
+
+// SetCheckRegions replaces the probe regions a check is dispatched to. While any are
+// assigned, scheduled runs go to those probes instead of the server; an empty list
+// runs the check on the server again.
+func (h *Handlers) SetCheckRegions(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	var req models.SetCheckRegionsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	check, err := h.db.GetCheck(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if check == nil {
+		http.Error(w, "check not found", http.StatusNotFound)
+		return
+	}
+
+	regions, err := normalizeRegions(req.Assigned)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(regions) > 0 && (check.Type == models.CheckTypeTailscale || check.Type == models.CheckTypeTailscaleService) {
+		http.Error(w, "Tailscale checks cannot be assigned to regions", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.db.SetCheckRegions(id, regions); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.engine.SetCheckRegions(id, regions)
+	h.audit(r, models.AuditUpdate, models.AuditTargetCheck, id, map[string]string{"regions": strings.Join(regions, ",")})
+
+	h.writeCheckRegions(w, id, nil)
+}
+
+// normalizeRegions trims, deduplicates and sorts region codes
+func normalizeRegions(regions []string) ([]string, error) {
+	seen := make(map[string]bool, len(regions))
+	result := make([]string, 0, len(regions))
+	for _, region := range regions {
+		region = strings.TrimSpace(region)
+		if region == "" {
+			return nil, fmt.Errorf("region must not be empty")
+		}
+		if region == hostRegion {
+			return nil, fmt.Errorf("%q is the server itself; clear the regions to run the check there", hostRegion)
+		}
+		if !seen[region] {
+			seen[region] = true
+			result = append(result, region)
+		}
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// writeCheckRegions writes the CheckRegions of a check. Assigned regions that have not
+// reported yet are included without a status.
+func (h *Handlers) writeCheckRegions(w http.ResponseWriter, id int64, since *time.Time) {
+	if since == nil {
+		t := time.Now().Add(-defaultRegionRange)
+		since = &t
+	}
+
+	assigned, err := h.db.GetCheckRegions(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	lastByRegion, err := h.db.GetLastStatusByRegion(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	history, err := h.db.GetCheckHistory(id, since, h.maxHistoryRows)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	counts := make(map[string]int)
+	totals := make(map[string]int64)
+	for _, row := range history {
+		region := row.Region
+		if region == "" {
+			region = hostRegion
+		}
+		counts[region]++
+		totals[region] += int64(row.ResponseTimeMs)
+	}
+
+	regions := make([]models.RegionStatus, 0, len(lastByRegion)+len(assigned))
+	for _, last := range lastByRegion {
+		rs := toRegionStatus(last)
+		if n := counts[rs.Region]; n > 0 {
+			rs.RecentChecks = n
+			rs.AvgResponseTimeMs = int(totals[rs.Region] / int64(n))
+		}
+		regions = append(regions, rs)
+	}
+	for _, region := range assigned {
+		if _, ok := lastByRegion[region]; !ok {
+			regions = append(regions, models.RegionStatus{Region: region})
+		}
+	}
+	sort.Slice(regions, func(i, j int) bool {
+		return regions[i].Region < regions[j].Region
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.CheckRegions{Assigned: assigned, Regions: regions})
+}
@@ -0,0 +1,143 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gocheck/internal/checker"
+	"gocheck/internal/models"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	defaultSLARange  = 30 * 24 * time.Hour
+	defaultSLATarget = 99.9
+)
+
+// slaTracker accumulates how long each region of a check was seen up and down from its
+// results in chronological order
+type slaTracker struct {
+	check     models.Check
+	last      map[string]*models.CheckHistory
+	monitored time.Duration
+	down      time.Duration
+}
+
+func newSLATracker(check models.Check) *slaTracker {
+	return &slaTracker{check: check, last: make(map[string]*models.CheckHistory)}
+}
+
+// cover credits the status of result h up to until. A result vouches for the check only
+// until a run after the next scheduled one would have replaced it, which tolerates one
+// late or failed run; beyond that there is no data.
+func (t *slaTracker) cover(h *models.CheckHistory, until time.Time) {
+	deadline := checker.NextRun(t.check, h.CheckedAt)
+	if !deadline.IsZero() {
+		deadline = checker.NextRun(t.check, deadline)
+	}
+	if !deadline.IsZero() && deadline.Add(time.Duration(t.check.TimeoutSeconds)*time.Second).Before(until) {
+		until = deadline.Add(time.Duration(t.check.TimeoutSeconds) * time.Second)
+	}
+
+	span := until.Sub(h.CheckedAt)
+	if span <= 0 {
+		return
+	}
+	t.monitored += span
+	if !h.Success {
+		t.down += span
+	}
+}
+
+func (t *slaTracker) add(h *models.CheckHistory) error {
+	if prev := t.last[h.Region]; prev != nil {
+		t.cover(prev, h.CheckedAt)
+	}
+	t.last[h.Region] = h
+	return nil
+}
+
+// report covers the latest results up to to and builds the report
+func (t *slaTracker) report(from, to time.Time, target float64) models.SLAReport {
+	for _, h := range t.last {
+		t.cover(h, to)
+	}
+
+	minutes := func(d time.Duration) float64 {
+		if len(t.last) > 1 {
+			d /= time.Duration(len(t.last))
+		}
+		return math.Round(d.Minutes()*100) / 100
+	}
+
+	r := models.SLAReport{
+		CheckID:                    t.check.ID,
+		From:                       from,
+		To:                         to,
+		Target:                     target,
+		MonitoredMinutes:           minutes(t.monitored),
+		ErrorBudgetConsumedMinutes: minutes(t.down),
+	}
+	r.NoDataMinutes = math.Max(0, math.Round((to.Sub(from).Minutes()-r.MonitoredMinutes)*100)/100)
+	r.ErrorBudgetMinutes = math.Round(r.MonitoredMinutes*(100-target)) / 100
+	if t.monitored > 0 {
+		uptime := float64(t.monitored-t.down) / float64(t.monitored) * 100
+		met := uptime >= target
+		r.Uptime = &uptime
+		r.Met = &met
+	}
+	return r
+}
+
+// GetCheckSLA reports a check's uptime over range (default 30d) against target
+// (default 99.9) with the error budget that allows
+func (h *Handlers) GetCheckSLA(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+	since, err := parseRangeParam(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	to := time.Now().UTC()
+	if since == nil {
+		from := to.Add(-defaultSLARange)
+		since = &from
+	}
+
+	target := defaultSLATarget
+	if v := r.URL.Query().Get("target"); v != "" {
+		target, err = strconv.ParseFloat(v, 64)
+		if err != nil || target <= 0 || target > 100 {
+			http.Error(w, fmt.Sprintf("invalid target %q, must be a percentage above 0 and at most 100", v), http.StatusBadRequest)
+			return
+		}
+	}
+
+	check, err := h.db.GetCheck(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if check == nil {
+		http.Error(w, "check not found", http.StatusNotFound)
+		return
+	}
+
+	tracker := newSLATracker(*check)
+	if err := h.db.StreamCheckHistory(id, since, tracker.add); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tracker.report(since.UTC(), to, target))
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"gocheck/internal/models"
+)
+
+func TestSLATrackerGaps(t *testing.T) {
+	check := models.Check{ID: 1, IntervalSeconds: 60, TimeoutSeconds: 10}
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tracker := newSLATracker(check)
+
+	// Up, down for a minute, up, then disabled for an hour before running again
+	for _, r := range []struct {
+		offset  time.Duration
+		success bool
+	}{
+		{0, true},
+		{time.Minute, false},
+		{2 * time.Minute, true},
+		{62 * time.Minute, true},
+	} {
+		tracker.add(&models.CheckHistory{CheckedAt: from.Add(r.offset), Success: r.success, Region: "host"})
+	}
+	report := tracker.report(from, from.Add(63*time.Minute), 99.9)
+
+	// The result before the gap counts until two intervals plus the timeout later
+	monitored := (60 + 60 + 130 + 60) / 60.0
+	if report.MonitoredMinutes != math.Round(monitored*100)/100 {
+		t.Errorf("monitored = %v minutes, want %.2f", report.MonitoredMinutes, monitored)
+	}
+	if report.ErrorBudgetConsumedMinutes != 1 {
+		t.Errorf("consumed = %v minutes, want 1", report.ErrorBudgetConsumedMinutes)
+	}
+	if report.NoDataMinutes != math.Round((63-monitored)*100)/100 {
+		t.Errorf("no data = %v minutes, want %.2f", report.NoDataMinutes, 63-monitored)
+	}
+	if report.Uptime == nil || math.Abs(*report.Uptime-250.0/310*100) > 1e-9 {
+		t.Errorf("uptime = %v, want %v", report.Uptime, 250.0/310*100)
+	}
+	if report.Met == nil || *report.Met {
+		t.Errorf("met = %v, want false", report.Met)
+	}
+}
+
+func TestSLATrackerNoData(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	report := newSLATracker(models.Check{IntervalSeconds: 60}).report(from, from.Add(time.Hour), 99)
+
+	if report.Uptime != nil || report.Met != nil {
+		t.Errorf("uptime = %v, met = %v, want both unset without data", report.Uptime, report.Met)
+	}
+	if report.NoDataMinutes != 60 || report.ErrorBudgetMinutes != 0 {
+		t.Errorf("no data = %v, budget = %v, want 60 and 0", report.NoDataMinutes, report.ErrorBudgetMinutes)
+	}
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"strconv"
+
+	"gocheck/internal/db"
+	"gocheck/internal/models"
+	"gocheck/internal/snapshot"
+)
+
+// fillSnapshotOptions reports the effective snapshot options, defaults included
+func fillSnapshotOptions(database *db.Database, settings *models.Settings) {
+	opts := snapshot.LoadOptions(database)
+	settings.SnapshotIntervalHours = &opts.IntervalHours
+	settings.SnapshotWidth = &opts.Width
+	settings.SnapshotHeight = &opts.Height
+	settings.SnapshotFullPage = &opts.FullPage
+}
+
+func validateSnapshotOptions(settings *models.Settings) error {
+	if settings.SnapshotIntervalHours != nil {
+		if err := snapshot.ValidateIntervalHours(*settings.SnapshotIntervalHours); err != nil {
+			return err
+		}
+	}
+	if settings.SnapshotWidth != nil {
+		if err := snapshot.ValidateWidth(*settings.SnapshotWidth); err != nil {
+			return err
+		}
+	}
+	if settings.SnapshotHeight != nil {
+		if err := snapshot.ValidateHeight(*settings.SnapshotHeight); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// saveSnapshotOptions stores the snapshot options that were set, leaving the rest alone
+func saveSnapshotOptions(database *db.Database, settings *models.Settings) error {
+	values := map[string]string{}
+	if settings.SnapshotIntervalHours != nil {
+		values[snapshot.IntervalSetting] = strconv.Itoa(*settings.SnapshotIntervalHours)
+	}
+	if settings.SnapshotWidth != nil {
+		values[snapshot.WidthSetting] = strconv.Itoa(*settings.SnapshotWidth)
+	}
+	if settings.SnapshotHeight != nil {
+		values[snapshot.HeightSetting] = strconv.Itoa(*settings.SnapshotHeight)
+	}
+	if settings.SnapshotFullPage != nil {
+		values[snapshot.FullPageSetting] = strconv.FormatBool(*settings.SnapshotFullPage)
+	}
+	for key, value := range values {
+		if err := database.SetSetting(key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gocheck/internal/checker"
+	"gocheck/internal/db"
+	"gocheck/internal/models"
+)
+
+func TestStreamCheckUpdatesUnsubscribes(t *testing.T) {
+	engine := checker.NewEngine(&db.Database{}, nil)
+	defer engine.Stop()
+	h := &Handlers{engine: engine}
+
+	ctx, disconnect := context.WithCancel(context.Background())
+	req := httptest.NewRequest(http.MethodGet, "/api/stream/updates", nil).WithContext(ctx)
+	rec := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		h.StreamCheckUpdates(rec, req)
+	}()
+
+	// Wait for the handler to subscribe, then deliver one result
+	deadline := time.Now().Add(5 * time.Second)
+	for engine.Subscribers() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("handler never subscribed")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	engine.BroadcastCheckResult(models.Check{ID: 7}, &models.CheckHistory{CheckID: 7, Success: true})
+	time.Sleep(50 * time.Millisecond)
+
+	disconnect()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("handler kept running after the client disconnected")
+	}
+
+	if n := engine.Subscribers(); n != 0 {
+		t.Errorf("%d subscribers left after disconnect, want 0", n)
+	}
+	if !strings.Contains(rec.Body.String(), `"check_id":7`) {
+		t.Errorf("body = %q, want the check update", rec.Body.String())
+	}
+}
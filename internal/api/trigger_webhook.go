@@ -0,0 +1,74 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	// minTriggerSecretLen is the shortest trigger secret a check accepts
+	minTriggerSecretLen = 16
+
+	// maxTriggerBodyBytes caps the body a trigger webhook signs
+	maxTriggerBodyBytes = 64 << 10
+
+	// triggerSignatureHeader carries the hex HMAC-SHA256 of the request body, optionally
+	// prefixed with "sha256=" as GitHub and most CI systems send it
+	triggerSignatureHeader = "X-Signature"
+)
+
+// triggerSignature is the hex HMAC-SHA256 of body keyed with secret
+func triggerSignature(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// validTriggerSignature checks a signature header against the body in constant time
+func validTriggerSignature(secret string, body []byte, header string) bool {
+	got, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(header), "sha256="))
+	if err != nil || len(got) == 0 {
+		return false
+	}
+	want, _ := hex.DecodeString(triggerSignature(secret, body))
+	return hmac.Equal(got, want)
+}
+
+// TriggerCheckWebhook runs a check for external systems such as deploy pipelines. It
+// takes no session or API key; instead the X-Signature header must hold the
+// HMAC-SHA256 of the body keyed with the check's trigger_secret. Checks without a
+// secret can't be triggered this way.
+func (h *Handlers) TriggerCheckWebhook(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxTriggerBodyBytes))
+	if err != nil {
+		http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	check, err := h.db.GetCheck(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	// Unknown checks, checks without a secret and bad signatures look the same, so the
+	// endpoint doesn't reveal which checks exist
+	if check == nil || check.TriggerSecret == "" || !validTriggerSignature(check.TriggerSecret, body, r.Header.Get(triggerSignatureHeader)) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	h.triggerCheck(w, r, id)
+}
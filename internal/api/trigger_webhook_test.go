@@ -0,0 +1,68 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gocheck/internal/checker"
+	"gocheck/internal/db"
+	"gocheck/internal/models"
+
+	"github.com/gorilla/mux"
+)
+
+// webhookCheckDB holds a single check
+type webhookCheckDB struct {
+	db.DB
+	check models.Check
+}
+
+func (d *webhookCheckDB) GetCheck(id int64) (*models.Check, error) {
+	if id != d.check.ID {
+		return nil, nil
+	}
+	c := d.check
+	return &c, nil
+}
+
+func TestTriggerCheckWebhook(t *testing.T) {
+	const secret = "0123456789abcdef"
+	body := `{"ref": "refs/heads/main"}`
+	signature := triggerSignature(secret, []byte(body))
+
+	database := &db.Database{DB: &webhookCheckDB{check: models.Check{ID: 1, TriggerSecret: secret}}}
+	h := &Handlers{db: database, engine: checker.NewEngine(database, nil)}
+
+	tests := []struct {
+		name      string
+		id        string
+		signature string
+		status    int
+	}{
+		// The check isn't scheduled in the engine, so a verified trigger reaches it and
+		// fails there rather than at the signature
+		{"valid signature", "1", signature, http.StatusBadRequest},
+		{"github style prefix", "1", "sha256=" + signature, http.StatusBadRequest},
+		{"wrong signature", "1", triggerSignature("another secret!!", []byte(body)), http.StatusUnauthorized},
+		{"missing signature", "1", "", http.StatusUnauthorized},
+		{"unknown check", "2", signature, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/checks/"+tt.id+"/trigger/webhook", strings.NewReader(body))
+			req = mux.SetURLVars(req, map[string]string{"id": tt.id})
+			if tt.signature != "" {
+				req.Header.Set(triggerSignatureHeader, tt.signature)
+			}
+			rec := httptest.NewRecorder()
+			h.TriggerCheckWebhook(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d (body %q)", rec.Code, tt.status, rec.Body.String())
+			}
+		})
+	}
+}
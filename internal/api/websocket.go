@@ -0,0 +1,82 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	wsWriteWait  = 10 * time.Second
+	wsPongWait   = 60 * time.Second
+	wsPingPeriod = wsPongWait * 9 / 10
+)
+
+// wsUpgrader keeps gorilla's default same-origin check, so other sites can't open a
+// socket with the user's session cookie
+var wsUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 4096,
+}
+
+// StreamCheckUpdatesWS pushes the same check results as StreamCheckUpdates over a
+// WebSocket, one CheckResultEvent JSON message per frame, for clients behind proxies
+// that buffer SSE. The server pings every wsPingPeriod and drops clients that stop
+// answering.
+func (h *Handlers) StreamCheckUpdatesWS(w http.ResponseWriter, r *http.Request) {
+	// Subscribe before the handshake completes so no result is missed once the
+	// client sees the connection open
+	client := h.engine.Subscribe()
+	defer h.engine.Unsubscribe(client)
+
+	conn, err := wsUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade has already replied with an error
+		return
+	}
+	defer conn.Close()
+
+	// Clients only send control frames; reading processes pongs and notices a close
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		conn.SetReadLimit(512)
+		conn.SetReadDeadline(time.Now().Add(wsPongWait))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+		})
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ping := time.NewTicker(wsPingPeriod)
+	defer ping.Stop()
+
+	for {
+		select {
+		case event, ok := <-client:
+			if !ok {
+				return
+			}
+			conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := conn.WriteJSON(event); err != nil {
+				log.Printf("WebSocket write failed: %v", err)
+				return
+			}
+		case <-ping.C:
+			conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		case <-closed:
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gocheck/internal/checker"
+	"gocheck/internal/db"
+	"gocheck/internal/models"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestStreamCheckUpdatesWS(t *testing.T) {
+	engine := checker.NewEngine(&db.Database{}, nil)
+	defer engine.Stop()
+	h := &Handlers{engine: engine}
+
+	server := httptest.NewServer(http.HandlerFunc(h.StreamCheckUpdatesWS))
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	engine.BroadcastCheckResult(models.Check{ID: 7, Name: "site"}, &models.CheckHistory{CheckID: 7, Success: true})
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var event checker.CheckResultEvent
+	if err := conn.ReadJSON(&event); err != nil {
+		t.Fatal(err)
+	}
+	if event.CheckID != 7 || !event.IsUp {
+		t.Errorf("event = %+v, want check 7 up", event)
+	}
+
+	// Closing the socket unsubscribes the handler
+	conn.Close()
+	deadline := time.Now().Add(5 * time.Second)
+	for engine.Subscribers() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d subscribers left after the client closed", engine.Subscribers())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -6,7 +6,11 @@ import (
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
 
 	"gocheck/internal/db"
@@ -17,13 +21,17 @@ import (
 
 const sessionCookieName = "gocheck_session"
 
+const minPasswordLength = 6
+
 type AuthManager struct {
-	db *db.Database
+	db      *db.Database
+	limiter *loginLimiter
 }
 
 func NewAuthManager(database *db.Database) *AuthManager {
 	am := &AuthManager{
-		db: database,
+		db:      database,
+		limiter: newLoginLimiter(),
 	}
 	go am.cleanupExpiredSessions()
 	return am
@@ -35,6 +43,7 @@ func (am *AuthManager) cleanupExpiredSessions() {
 
 	for range ticker.C {
 		am.db.DeleteExpiredSessions()
+		am.limiter.prune()
 	}
 }
 
@@ -71,6 +80,13 @@ func (am *AuthManager) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	keys := loginKeys(r, req.Username)
+	if wait := am.limiter.retryAfter(keys...); wait > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+		http.Error(w, "too many failed login attempts, try again later", http.StatusTooManyRequests)
+		return
+	}
+
 	user, err := am.db.GetUserByUsername(req.Username)
 	if err != nil {
 		http.Error(w, "internal server error", http.StatusInternalServerError)
@@ -78,14 +94,17 @@ func (am *AuthManager) Login(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if user == nil {
+		am.limiter.fail(keys...)
 		http.Error(w, "invalid credentials", http.StatusUnauthorized)
 		return
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
+		am.limiter.fail(keys...)
 		http.Error(w, "invalid credentials", http.StatusUnauthorized)
 		return
 	}
+	am.limiter.reset(keys...)
 
 	token, err := generateSessionToken()
 	if err != nil {
@@ -98,6 +117,7 @@ func (am *AuthManager) Login(w http.ResponseWriter, r *http.Request) {
 		Token:     token,
 		UserID:    user.ID,
 		Username:  user.Username,
+		Role:      user.Role,
 		ExpiresAt: expiresAt,
 	}
 
@@ -120,6 +140,7 @@ func (am *AuthManager) Login(w http.ResponseWriter, r *http.Request) {
 		"user": map[string]interface{}{
 			"id":       user.ID,
 			"username": user.Username,
+			"role":     user.Role,
 		},
 	})
 }
@@ -156,6 +177,7 @@ func (am *AuthManager) CheckAuth(w http.ResponseWriter, r *http.Request) {
 		"user": map[string]interface{}{
 			"id":       session.UserID,
 			"username": session.Username,
+			"role":     session.Role,
 		},
 	})
 }
@@ -183,7 +205,7 @@ func (am *AuthManager) InitialSetup(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if len(req.Password) < 6 {
+	if len(req.Password) < minPasswordLength {
 		http.Error(w, "password must be at least 6 characters", http.StatusBadRequest)
 		return
 	}
@@ -194,12 +216,18 @@ func (am *AuthManager) InitialSetup(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// The setup user is the first admin
 	user := &models.User{
 		Username:     req.Username,
+		Role:         models.RoleAdmin,
 		PasswordHash: string(passwordHash),
 	}
 
 	if err := am.db.CreateUser(user); err != nil {
+		if errors.Is(err, db.ErrConflict) {
+			http.Error(w, fmt.Sprintf("username %q is already taken", user.Username), http.StatusConflict)
+			return
+		}
 		http.Error(w, "failed to create user", http.StatusInternalServerError)
 		return
 	}
@@ -215,6 +243,7 @@ func (am *AuthManager) InitialSetup(w http.ResponseWriter, r *http.Request) {
 		Token:     token,
 		UserID:    user.ID,
 		Username:  user.Username,
+		Role:      user.Role,
 		ExpiresAt: expiresAt,
 	}
 
@@ -238,6 +267,7 @@ func (am *AuthManager) InitialSetup(w http.ResponseWriter, r *http.Request) {
 		"user": map[string]interface{}{
 			"id":       user.ID,
 			"username": user.Username,
+			"role":     user.Role,
 		},
 	})
 }
@@ -270,6 +300,7 @@ func (am *AuthManager) GetSession(r *http.Request) (*models.Session, bool) {
 				return &models.Session{
 					UserID:    key.UserID,
 					Username:  user.Username,
+					Role:      user.Role,
 					ExpiresAt: time.Now().Add(24 * time.Hour),
 				}, true
 			}
@@ -324,6 +355,41 @@ func (am *AuthManager) OptionalAuth(next http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
+// RequireAdmin is RequireAuth for admins only; other roles get 403
+func (am *AuthManager) RequireAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		session, _ := am.GetSession(r)
+		if session == nil {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if session.Role != models.RoleAdmin {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// OptionalAdmin is OptionalAuth for routes that change state: once users exist, only
+// admins get through and viewers get 403
+func (am *AuthManager) OptionalAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		hasUsers, err := am.db.HasUsers()
+		if err != nil {
+			http.Error(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		if !hasUsers {
+			next(w, r)
+			return
+		}
+
+		am.RequireAdmin(next)(w, r)
+	}
+}
+
 func (am *AuthManager) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
 	session, _ := am.GetSession(r)
 	if session == nil {
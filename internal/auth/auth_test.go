@@ -0,0 +1,39 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gocheck/internal/db"
+	"gocheck/internal/models"
+)
+
+// userDB rejects every username as taken, as the unique index would
+type userDB struct {
+	db.DB
+}
+
+func (userDB) HasUsers() (bool, error) {
+	return false, nil
+}
+
+func (userDB) CreateUser(u *models.User) error {
+	return db.ErrDuplicateUser
+}
+
+func TestInitialSetupDuplicateUser(t *testing.T) {
+	am := &AuthManager{db: &db.Database{DB: userDB{}}}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/auth/setup", strings.NewReader(`{"username": "admin", "password": "secret123"}`))
+	rec := httptest.NewRecorder()
+	am.InitialSetup(rec, req)
+
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusConflict)
+	}
+	if !strings.Contains(rec.Body.String(), "already taken") {
+		t.Errorf("body = %q, want a clear duplicate message", rec.Body.String())
+	}
+}
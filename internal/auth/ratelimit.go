@@ -0,0 +1,130 @@
+package auth
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Login throttling: maxLoginFailures failed logins within loginFailureWindow lock a
+// client IP or username out for loginLockout, doubling with each further lockout up to
+// maxLoginLockout. An entry idle for maxLoginLockout is forgotten.
+const (
+	maxLoginFailures   = 5
+	loginFailureWindow = 15 * time.Minute
+	loginLockout       = 1 * time.Minute
+	maxLoginLockout    = 1 * time.Hour
+)
+
+type loginAttempts struct {
+	failures    int
+	windowStart time.Time
+	lockouts    int // lockouts so far, each doubling the next
+	lockedUntil time.Time
+}
+
+// idle reports whether the entry has neither a lockout nor a failure window running,
+// nor had one for maxLoginLockout
+func (a *loginAttempts) idle(now time.Time) bool {
+	last := a.windowStart.Add(loginFailureWindow)
+	if a.lockedUntil.After(last) {
+		last = a.lockedUntil
+	}
+	return now.Sub(last) > maxLoginLockout
+}
+
+// loginLimiter counts failed logins per key in memory, so counts start over on restart
+type loginLimiter struct {
+	mu       sync.Mutex
+	now      func() time.Time
+	attempts map[string]*loginAttempts
+}
+
+func newLoginLimiter() *loginLimiter {
+	return &loginLimiter{now: time.Now, attempts: make(map[string]*loginAttempts)}
+}
+
+// retryAfter returns how much longer the longest lockout among keys lasts, or zero
+func (l *loginLimiter) retryAfter(keys ...string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	var wait time.Duration
+	for _, key := range keys {
+		if a, ok := l.attempts[key]; ok {
+			if d := a.lockedUntil.Sub(now); d > wait {
+				wait = d
+			}
+		}
+	}
+	return wait
+}
+
+// fail records a failed login against each key, locking out those that reach
+// maxLoginFailures within the window
+func (l *loginLimiter) fail(keys ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	for _, key := range keys {
+		a, ok := l.attempts[key]
+		if !ok || a.idle(now) {
+			a = &loginAttempts{windowStart: now}
+			l.attempts[key] = a
+		}
+		if now.Sub(a.windowStart) > loginFailureWindow {
+			a.failures = 0
+			a.windowStart = now
+		}
+
+		a.failures++
+		if a.failures < maxLoginFailures {
+			continue
+		}
+		lockout := maxLoginLockout
+		if a.lockouts < 16 && loginLockout<<a.lockouts < maxLoginLockout {
+			lockout = loginLockout << a.lockouts
+		}
+		a.lockouts++
+		a.lockedUntil = now.Add(lockout)
+		a.failures = 0
+		a.windowStart = now
+	}
+}
+
+// reset forgets keys after a successful login
+func (l *loginLimiter) reset(keys ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, key := range keys {
+		delete(l.attempts, key)
+	}
+}
+
+// prune drops idle entries so spraying usernames can't grow the map forever
+func (l *loginLimiter) prune() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	for key, a := range l.attempts {
+		if a.idle(now) {
+			delete(l.attempts, key)
+		}
+	}
+}
+
+// loginKeys are the limiter keys of a login attempt: the client IP and the username.
+// The IP is the connection's peer, so behind a reverse proxy every client shares the
+// proxy's and the username limit does most of the work.
+func loginKeys(r *http.Request, username string) []string {
+	ip := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+	return []string{"ip:" + ip, "user:" + strings.ToLower(username)}
+}
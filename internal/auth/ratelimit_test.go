@@ -0,0 +1,161 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gocheck/internal/db"
+	"gocheck/internal/models"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// fakeClock is a settable time source for the limiter
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func newTestLimiter() (*loginLimiter, *fakeClock) {
+	clock := &fakeClock{t: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	l := newLoginLimiter()
+	l.now = clock.now
+	return l, clock
+}
+
+func TestLoginLimiterLockout(t *testing.T) {
+	l, clock := newTestLimiter()
+
+	for i := 0; i < maxLoginFailures-1; i++ {
+		l.fail("user:admin")
+	}
+	if wait := l.retryAfter("user:admin"); wait != 0 {
+		t.Fatalf("locked out after %d failures: %v", maxLoginFailures-1, wait)
+	}
+
+	l.fail("user:admin")
+	if wait := l.retryAfter("user:admin"); wait != loginLockout {
+		t.Fatalf("retryAfter = %v, want %v", wait, loginLockout)
+	}
+	if wait := l.retryAfter("user:other"); wait != 0 {
+		t.Errorf("other key locked out: %v", wait)
+	}
+
+	// The lockout expires, and the next one lasts twice as long
+	clock.t = clock.t.Add(loginLockout)
+	if wait := l.retryAfter("user:admin"); wait != 0 {
+		t.Fatalf("still locked out after the lockout: %v", wait)
+	}
+	for i := 0; i < maxLoginFailures; i++ {
+		l.fail("user:admin")
+	}
+	if wait := l.retryAfter("user:admin"); wait != 2*loginLockout {
+		t.Errorf("second lockout = %v, want %v", wait, 2*loginLockout)
+	}
+}
+
+func TestLoginLimiterBackoffCap(t *testing.T) {
+	l, clock := newTestLimiter()
+
+	var wait time.Duration
+	for lockout := 0; lockout < 10; lockout++ {
+		for i := 0; i < maxLoginFailures; i++ {
+			l.fail("ip:192.0.2.1")
+		}
+		wait = l.retryAfter("ip:192.0.2.1")
+		clock.t = clock.t.Add(wait)
+	}
+	if wait != maxLoginLockout {
+		t.Errorf("lockout = %v, want the %v cap", wait, maxLoginLockout)
+	}
+}
+
+func TestLoginLimiterWindow(t *testing.T) {
+	l, clock := newTestLimiter()
+
+	for i := 0; i < maxLoginFailures-1; i++ {
+		l.fail("user:admin")
+	}
+	// Failures older than the window no longer count
+	clock.t = clock.t.Add(loginFailureWindow + time.Second)
+	l.fail("user:admin")
+	if wait := l.retryAfter("user:admin"); wait != 0 {
+		t.Errorf("locked out by failures outside the window: %v", wait)
+	}
+}
+
+func TestLoginLimiterResetAndPrune(t *testing.T) {
+	l, clock := newTestLimiter()
+
+	for i := 0; i < maxLoginFailures; i++ {
+		l.fail("user:admin", "user:guest")
+	}
+	l.reset("user:admin")
+	if wait := l.retryAfter("user:admin"); wait != 0 {
+		t.Errorf("still locked out after reset: %v", wait)
+	}
+	if wait := l.retryAfter("user:guest"); wait == 0 {
+		t.Error("reset cleared another key")
+	}
+
+	clock.t = clock.t.Add(loginFailureWindow + maxLoginLockout + time.Second)
+	l.prune()
+	if len(l.attempts) != 0 {
+		t.Errorf("prune left %d idle entries", len(l.attempts))
+	}
+}
+
+// loginDB knows a single user
+type loginDB struct {
+	db.DB
+	user *models.User
+}
+
+func (d loginDB) GetUserByUsername(username string) (*models.User, error) {
+	if username == d.user.Username {
+		return d.user, nil
+	}
+	return nil, nil
+}
+
+func (loginDB) CreateSession(*models.Session) error {
+	return nil
+}
+
+func TestLoginRateLimited(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret123"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	am := &AuthManager{
+		db:      &db.Database{DB: loginDB{user: &models.User{ID: 1, Username: "admin", PasswordHash: string(hash)}}},
+		limiter: newLoginLimiter(),
+	}
+	login := func(password string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/auth/login", strings.NewReader(`{"username": "admin", "password": "`+password+`"}`))
+		rec := httptest.NewRecorder()
+		am.Login(rec, req)
+		return rec
+	}
+
+	// A success resets the count, so only the last maxLoginFailures failures lock out
+	login("wrong")
+	if rec := login("secret123"); rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	for i := 0; i < maxLoginFailures; i++ {
+		if rec := login("wrong"); rec.Code != http.StatusUnauthorized {
+			t.Fatalf("attempt %d: status = %d, want %d", i+1, rec.Code, http.StatusUnauthorized)
+		}
+	}
+
+	rec := login("secret123")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusTooManyRequests)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "60" {
+		t.Errorf("Retry-After = %q, want %q", got, "60")
+	}
+}
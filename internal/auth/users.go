@@ -0,0 +1,198 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"gocheck/internal/db"
+	"gocheck/internal/models"
+
+	"github.com/gorilla/mux"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func validRole(role string) bool {
+	return role == models.RoleAdmin || role == models.RoleViewer
+}
+
+// isLastAdmin reports whether user is the only admin left, so demoting or deleting it
+// would lock everyone out of user management
+func (am *AuthManager) isLastAdmin(user *models.User) (bool, error) {
+	if user.Role != models.RoleAdmin {
+		return false, nil
+	}
+	users, err := am.db.GetAllUsers()
+	if err != nil {
+		return false, err
+	}
+	for _, u := range users {
+		if u.Role == models.RoleAdmin && u.ID != user.ID {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// userFromPath loads the user named by the {id} route variable, writing the error
+// response itself when it returns nil
+func (am *AuthManager) userFromPath(w http.ResponseWriter, r *http.Request) *models.User {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "invalid user ID", http.StatusBadRequest)
+		return nil
+	}
+	user, err := am.db.GetUserByID(id)
+	if err != nil {
+		http.Error(w, "failed to get user", http.StatusInternalServerError)
+		return nil
+	}
+	if user == nil {
+		http.Error(w, "user not found", http.StatusNotFound)
+		return nil
+	}
+	return user
+}
+
+func (am *AuthManager) GetUsers(w http.ResponseWriter, r *http.Request) {
+	users, err := am.db.GetAllUsers()
+	if err != nil {
+		http.Error(w, "failed to get users", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(users)
+}
+
+func (am *AuthManager) CreateUser(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	if req.Username == "" || req.Password == "" {
+		http.Error(w, "username and password are required", http.StatusBadRequest)
+		return
+	}
+	if len(req.Password) < minPasswordLength {
+		http.Error(w, "password must be at least 6 characters", http.StatusBadRequest)
+		return
+	}
+	if req.Role == "" {
+		req.Role = models.RoleViewer
+	}
+	if !validRole(req.Role) {
+		http.Error(w, fmt.Sprintf("role must be %q or %q", models.RoleAdmin, models.RoleViewer), http.StatusBadRequest)
+		return
+	}
+
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	user := &models.User{
+		Username:     req.Username,
+		Role:         req.Role,
+		PasswordHash: string(passwordHash),
+	}
+	if err := am.db.CreateUser(user); err != nil {
+		if errors.Is(err, db.ErrConflict) {
+			http.Error(w, fmt.Sprintf("username %q is already taken", user.Username), http.StatusConflict)
+			return
+		}
+		http.Error(w, "failed to create user", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(user)
+}
+
+// UpdateUser changes a user's role or resets their password. A password reset signs
+// the user out everywhere.
+func (am *AuthManager) UpdateUser(w http.ResponseWriter, r *http.Request) {
+	user := am.userFromPath(w, r)
+	if user == nil {
+		return
+	}
+
+	var req models.UpdateUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	if req.Role != nil && *req.Role != user.Role {
+		if !validRole(*req.Role) {
+			http.Error(w, fmt.Sprintf("role must be %q or %q", models.RoleAdmin, models.RoleViewer), http.StatusBadRequest)
+			return
+		}
+		last, err := am.isLastAdmin(user)
+		if err != nil {
+			http.Error(w, "failed to get users", http.StatusInternalServerError)
+			return
+		}
+		if last {
+			http.Error(w, "cannot demote the last admin", http.StatusConflict)
+			return
+		}
+		user.Role = *req.Role
+	}
+
+	if req.Password != nil {
+		if len(*req.Password) < minPasswordLength {
+			http.Error(w, "password must be at least 6 characters", http.StatusBadRequest)
+			return
+		}
+		passwordHash, err := bcrypt.GenerateFromPassword([]byte(*req.Password), bcrypt.DefaultCost)
+		if err != nil {
+			http.Error(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+		user.PasswordHash = string(passwordHash)
+	}
+
+	if err := am.db.UpdateUser(user); err != nil {
+		http.Error(w, "failed to update user", http.StatusInternalServerError)
+		return
+	}
+	if req.Password != nil {
+		am.db.DeleteUserSessions(user.ID)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user)
+}
+
+func (am *AuthManager) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	user := am.userFromPath(w, r)
+	if user == nil {
+		return
+	}
+
+	last, err := am.isLastAdmin(user)
+	if err != nil {
+		http.Error(w, "failed to get users", http.StatusInternalServerError)
+		return
+	}
+	if last {
+		http.Error(w, "cannot delete the last admin", http.StatusConflict)
+		return
+	}
+
+	// Sessions, API keys and passkeys go with the user
+	if err := am.db.DeleteUser(user.ID); err != nil {
+		http.Error(w, "failed to delete user", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "deleted"})
+}
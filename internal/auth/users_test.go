@@ -0,0 +1,135 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gocheck/internal/db"
+	"gocheck/internal/models"
+
+	"github.com/gorilla/mux"
+)
+
+// rolesDB has an admin and a viewer, each with a session named after them
+type rolesDB struct {
+	db.DB
+	users []models.User
+}
+
+func (d *rolesDB) HasUsers() (bool, error) {
+	return len(d.users) > 0, nil
+}
+
+func (d *rolesDB) GetSessionByToken(token string) (*models.Session, error) {
+	for _, u := range d.users {
+		if u.Username == token {
+			return &models.Session{Token: token, UserID: u.ID, Username: u.Username, Role: u.Role}, nil
+		}
+	}
+	return nil, nil
+}
+
+func (d *rolesDB) GetAllUsers() ([]models.User, error) {
+	return d.users, nil
+}
+
+func (d *rolesDB) GetUserByID(id int64) (*models.User, error) {
+	for _, u := range d.users {
+		if u.ID == id {
+			return &u, nil
+		}
+	}
+	return nil, nil
+}
+
+func (d *rolesDB) UpdateUser(u *models.User) error {
+	for i := range d.users {
+		if d.users[i].ID == u.ID {
+			d.users[i] = *u
+		}
+	}
+	return nil
+}
+
+func (d *rolesDB) DeleteUser(id int64) error {
+	for i, u := range d.users {
+		if u.ID == id {
+			d.users = append(d.users[:i], d.users[i+1:]...)
+		}
+	}
+	return nil
+}
+
+func newRolesManager() (*AuthManager, *rolesDB) {
+	fake := &rolesDB{users: []models.User{
+		{ID: 1, Username: "admin", Role: models.RoleAdmin},
+		{ID: 2, Username: "viewer", Role: models.RoleViewer},
+	}}
+	return &AuthManager{db: &db.Database{DB: fake}}, fake
+}
+
+func TestOptionalAdmin(t *testing.T) {
+	am, _ := newRolesManager()
+	ok := func(w http.ResponseWriter, r *http.Request) {}
+
+	tests := []struct {
+		session string
+		want    int
+	}{
+		{"admin", http.StatusOK},
+		{"viewer", http.StatusForbidden},
+		{"", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/api/checks", nil)
+		if tt.session != "" {
+			req.AddCookie(&http.Cookie{Name: sessionCookieName, Value: tt.session})
+		}
+		rec := httptest.NewRecorder()
+		am.OptionalAdmin(ok)(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("session %q: status = %d, want %d", tt.session, rec.Code, tt.want)
+		}
+
+		// Viewers can still read through OptionalAuth
+		rec = httptest.NewRecorder()
+		am.OptionalAuth(ok)(rec, req)
+		if tt.session != "" && rec.Code != http.StatusOK {
+			t.Errorf("session %q: OptionalAuth status = %d, want %d", tt.session, rec.Code, http.StatusOK)
+		}
+	}
+}
+
+func TestLastAdminProtected(t *testing.T) {
+	am, fake := newRolesManager()
+	send := func(method, id, body string, handler http.HandlerFunc) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/api/users/"+id, strings.NewReader(body))
+		req = mux.SetURLVars(req, map[string]string{"id": id})
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		return rec
+	}
+
+	if rec := send(http.MethodDelete, "1", "", am.DeleteUser); rec.Code != http.StatusConflict {
+		t.Fatalf("delete last admin: status = %d, want %d", rec.Code, http.StatusConflict)
+	}
+	if rec := send(http.MethodPut, "1", `{"role": "viewer"}`, am.UpdateUser); rec.Code != http.StatusConflict {
+		t.Fatalf("demote last admin: status = %d, want %d", rec.Code, http.StatusConflict)
+	}
+
+	// With a second admin the first can step down
+	if rec := send(http.MethodPut, "2", `{"role": "admin"}`, am.UpdateUser); rec.Code != http.StatusOK {
+		t.Fatalf("promote viewer: status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if rec := send(http.MethodDelete, "1", "", am.DeleteUser); rec.Code != http.StatusOK {
+		t.Fatalf("delete admin: status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if len(fake.users) != 1 || fake.users[0].Role != models.RoleAdmin {
+		t.Errorf("users = %+v, want the promoted admin only", fake.users)
+	}
+	if rec := send(http.MethodPut, "2", `{"role": "owner"}`, am.UpdateUser); rec.Code != http.StatusBadRequest {
+		t.Errorf("unknown role: status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
@@ -3,6 +3,7 @@ package auth
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"io"
 	"log"
 	"net/http"
@@ -261,6 +262,10 @@ func (wm *WebAuthnManager) FinishRegistration(w http.ResponseWriter, r *http.Req
 	}
 
 	if err := wm.db.CreateWebAuthnCredential(cred); err != nil {
+		if errors.Is(err, db.ErrConflict) {
+			http.Error(w, "this passkey is already registered", http.StatusConflict)
+			return
+		}
 		http.Error(w, "failed to save credential", http.StatusInternalServerError)
 		return
 	}
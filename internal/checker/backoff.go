@@ -0,0 +1,51 @@
+package checker
+
+import (
+	"log"
+	"time"
+
+	"gocheck/internal/models"
+)
+
+// Caps on how far backoff stretches the interval of a check that stays down
+const (
+	maxBackoffMultiplier = 16
+	maxBackoffInterval   = time.Hour
+)
+
+// recordBackoff updates the backoff of a check from the result of a run. Once the
+// failures reach the confirmation threshold, so the outage has been notified, every
+// further failure doubles the multiplier; a success or turning backoff off resets it.
+func (s *checkState) recordBackoff(check models.Check, success bool) {
+	if !check.BackoffOnFailure || success {
+		if s.backoff > 1 && success {
+			log.Printf("Check %d (%s) recovered, back to its normal interval", check.ID, check.Name)
+		}
+		s.failures, s.backoff = 0, 1
+		return
+	}
+
+	s.failures++
+	threshold := check.ConfirmationThreshold
+	if threshold < 1 {
+		threshold = 1
+	}
+	if s.failures >= threshold && s.backoff < maxBackoffMultiplier {
+		s.backoff = max(s.backoff, 1) * 2
+		log.Printf("Check %d (%s) is still failing, backing off to %dx its interval", check.ID, check.Name, s.backoff)
+	}
+}
+
+// backoffDelay is how much later than its schedule the next run of a check starts.
+// The stretched interval is capped at maxBackoffInterval, or the interval itself if
+// that is longer.
+func (s *checkState) backoffDelay(interval time.Duration) time.Duration {
+	if s.backoff <= 1 {
+		return 0
+	}
+	stretched := interval * time.Duration(s.backoff)
+	if stretched > maxBackoffInterval {
+		stretched = max(interval, maxBackoffInterval)
+	}
+	return stretched - interval
+}
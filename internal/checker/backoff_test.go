@@ -0,0 +1,53 @@
+package checker
+
+import (
+	"testing"
+	"time"
+
+	"gocheck/internal/models"
+)
+
+func TestBackoff(t *testing.T) {
+	check := models.Check{ID: 1, Name: "api", BackoffOnFailure: true, ConfirmationThreshold: 2}
+	interval := time.Minute
+	state := &checkState{}
+
+	// The first failure is not confirmed yet, so the cadence holds
+	state.recordBackoff(check, false)
+	if d := state.backoffDelay(interval); d != 0 {
+		t.Fatalf("delay after 1 failure = %v, want 0", d)
+	}
+
+	// Confirmed failures double the interval up to the multiplier cap
+	for _, want := range []int{2, 4, 8, 16, 16} {
+		state.recordBackoff(check, false)
+		if state.backoff != want {
+			t.Fatalf("backoff = %d, want %d", state.backoff, want)
+		}
+	}
+	if d := state.backoffDelay(interval); d != 15*time.Minute {
+		t.Errorf("delay at 16x = %v, want 15m", d)
+	}
+	// Long intervals are capped at an hour, never shortened
+	if d := state.backoffDelay(10 * time.Minute); d != 50*time.Minute {
+		t.Errorf("delay of a 10m interval = %v, want 50m", d)
+	}
+	if d := state.backoffDelay(2 * time.Hour); d != 0 {
+		t.Errorf("delay of a 2h interval = %v, want 0", d)
+	}
+
+	// A success resets it
+	state.recordBackoff(check, true)
+	if state.backoff != 1 || state.failures != 0 || state.backoffDelay(interval) != 0 {
+		t.Errorf("after recovery: backoff %d, failures %d", state.backoff, state.failures)
+	}
+
+	// Checks without the option never back off
+	check.BackoffOnFailure = false
+	for i := 0; i < 5; i++ {
+		state.recordBackoff(check, false)
+	}
+	if d := state.backoffDelay(interval); d != 0 {
+		t.Errorf("delay without backoff = %v, want 0", d)
+	}
+}
@@ -0,0 +1,46 @@
+package checker
+
+import (
+	"fmt"
+	"io"
+)
+
+// MaxBodySizeBytes bounds the body size assertion. Bodies are counted up to one byte
+// past it, so a larger body still fails a max_response_bytes assertion without being
+// read to the end.
+const MaxBodySizeBytes = 64 << 20
+
+// ValidateBodySize checks the bounds of a body size assertion; zero disables a bound
+func ValidateBodySize(min, max int) error {
+	if min < 0 || max < 0 {
+		return fmt.Errorf("min_response_bytes and max_response_bytes cannot be negative")
+	}
+	if min > MaxBodySizeBytes || max > MaxBodySizeBytes {
+		return fmt.Errorf("min_response_bytes and max_response_bytes cannot exceed %d", MaxBodySizeBytes)
+	}
+	if max > 0 && min > max {
+		return fmt.Errorf("min_response_bytes (%d) cannot exceed max_response_bytes (%d)", min, max)
+	}
+	return nil
+}
+
+// countBody returns the size of a body whose first bytes were already read into
+// head, counting the rest of r up to MaxBodySizeBytes+1
+func countBody(head []byte, r io.Reader) (int64, error) {
+	rest, err := io.Copy(io.Discard, io.LimitReader(r, MaxBodySizeBytes+1-int64(len(head))))
+	return int64(len(head)) + rest, err
+}
+
+// MatchBodySize asserts a body size against the bounds; zero disables a bound
+func MatchBodySize(size int64, min, max int) error {
+	if min > 0 && size < int64(min) {
+		return fmt.Errorf("response body is %d bytes, expected at least %d", size, min)
+	}
+	if max > 0 && size > int64(max) {
+		if size > MaxBodySizeBytes {
+			return fmt.Errorf("response body is over %d bytes, expected at most %d", MaxBodySizeBytes, max)
+		}
+		return fmt.Errorf("response body is %d bytes, expected at most %d", size, max)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package checker
+
+import (
+	"sync/atomic"
+)
+
+// DefaultMaxConcurrentChecks is how many checks may run at once unless configured
+const DefaultMaxConcurrentChecks = 100
+
+// runSlots caps how many checks run at once, so hundreds of checks firing together
+// (on startup, or after a pause) can't exhaust file descriptors and connections.
+// Runs that find every slot taken wait for one rather than failing.
+type runSlots struct {
+	sem     chan struct{}
+	running atomic.Int64 // runs holding a slot
+	waiting atomic.Int64 // runs waiting for one
+}
+
+func newRunSlots(n int) *runSlots {
+	if n < 1 {
+		n = DefaultMaxConcurrentChecks
+	}
+	return &runSlots{sem: make(chan struct{}, n)}
+}
+
+// acquire waits for a free slot. It reports false when done is closed first.
+func (s *runSlots) acquire(done <-chan struct{}) bool {
+	select {
+	case s.sem <- struct{}{}:
+		s.running.Add(1)
+		return true
+	default:
+	}
+
+	s.waiting.Add(1)
+	defer s.waiting.Add(-1)
+	select {
+	case s.sem <- struct{}{}:
+		s.running.Add(1)
+		return true
+	case <-done:
+		return false
+	}
+}
+
+func (s *runSlots) release() {
+	s.running.Add(-1)
+	<-s.sem
+}
+
+// SetMaxConcurrentChecks sets how many checks may run at once, DefaultMaxConcurrentChecks
+// when n is below 1. Call before Start.
+func (e *Engine) SetMaxConcurrentChecks(n int) {
+	e.slots = newRunSlots(n)
+}
+
+// RunningChecks returns how many checks are running, how many are waiting for a free
+// slot, and how many may run at once
+func (e *Engine) RunningChecks() (running, waiting, limit int) {
+	return int(e.slots.running.Load()), int(e.slots.waiting.Load()), cap(e.slots.sem)
+}
@@ -0,0 +1,103 @@
+package checker
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gocheck/internal/db"
+	"gocheck/internal/models"
+)
+
+func TestRunSlots(t *testing.T) {
+	slots := newRunSlots(2)
+	done := make(chan struct{})
+	if !slots.acquire(done) || !slots.acquire(done) {
+		t.Fatal("free slots were not acquired")
+	}
+
+	// A third run waits until a slot is released
+	acquired := make(chan bool)
+	go func() { acquired <- slots.acquire(done) }()
+	deadline := time.Now().Add(time.Second)
+	for slots.waiting.Load() != 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("third run is not waiting")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if running := slots.running.Load(); running != 2 {
+		t.Errorf("running = %d, want 2", running)
+	}
+	slots.release()
+	if !<-acquired {
+		t.Fatal("waiting run did not get the released slot")
+	}
+	if slots.waiting.Load() != 0 || slots.running.Load() != 2 {
+		t.Errorf("waiting %d, running %d after handover", slots.waiting.Load(), slots.running.Load())
+	}
+
+	// Waiting gives up once done is closed
+	go func() { acquired <- slots.acquire(done) }()
+	close(done)
+	if <-acquired {
+		t.Error("acquired a slot while every slot was taken")
+	}
+
+	if n := cap(newRunSlots(0).sem); n != DefaultMaxConcurrentChecks {
+		t.Errorf("default cap = %d, want %d", n, DefaultMaxConcurrentChecks)
+	}
+}
+
+func TestRetryDelayFreesSlot(t *testing.T) {
+	var requests atomic.Int64
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer target.Close()
+
+	e := NewEngine(&db.Database{}, nil)
+	e.slots = newRunSlots(1)
+	check := models.Check{ID: 1, Name: "api", Type: models.CheckTypeHTTP, URL: target.URL, IntervalSeconds: 60, TimeoutSeconds: 2, Retries: 1, RetryDelaySeconds: 30}
+	e.checks[1] = &checkState{check: check, schedule: checkSchedule(check), stop: make(chan struct{})}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := e.RunCheck(context.Background(), 1)
+		done <- err
+	}()
+
+	// The failed first attempt gives its slot back for the retry delay
+	deadline := time.Now().Add(5 * time.Second)
+	for requests.Load() != 1 || e.slots.running.Load() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d requests, %d running: the first attempt never finished", requests.Load(), e.slots.running.Load())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	closed := make(chan struct{})
+	close(closed)
+	if !e.slots.acquire(closed) {
+		t.Fatal("the only slot is held while the check waits to retry")
+	}
+	e.slots.release()
+
+	// Stopping the engine ends the delay instead of sleeping it out
+	e.Stop()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("RunCheck = %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the retry delay ignored the engine stopping")
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("%d requests, want no retry after the engine stopped", n)
+	}
+}
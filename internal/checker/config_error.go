@@ -0,0 +1,157 @@
+package checker
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"gocheck/internal/dnsrecord"
+	"gocheck/internal/jsonpath"
+	"gocheck/internal/models"
+	"gocheck/internal/mongocheck"
+	"gocheck/internal/notifier"
+	"gocheck/internal/redis"
+)
+
+// NotifyMisconfiguredSetting toggles the one-off notification for misconfigured checks;
+// like the notifier toggles it is on unless set to "false"
+const NotifyMisconfiguredSetting = "notify_misconfigured"
+
+// ConfigError reports a check whose settings can never work, such as a Postgres check
+// without a connection string. Such checks fail on every run no matter the state of
+// the target, so they are flagged instead of being recorded as outages.
+func ConfigError(check models.Check) error {
+	switch check.Type {
+	case models.CheckTypePing, models.CheckTypeNTP, models.CheckTypeSSLCert, models.CheckTypeSMTP:
+		if check.Host == "" {
+			return fmt.Errorf("no host specified")
+		}
+	case models.CheckTypeTCP, models.CheckTypeGRPC:
+		if check.Host == "" {
+			return fmt.Errorf("no host specified")
+		}
+		if check.Port <= 0 || check.Port > 65535 {
+			return fmt.Errorf("invalid port")
+		}
+	case models.CheckTypePostgres:
+		if check.PostgresConnString == "" {
+			return fmt.Errorf("no connection string specified")
+		}
+	case models.CheckTypeMySQL:
+		if check.MySQLConnString == "" {
+			return fmt.Errorf("no connection string specified")
+		}
+	case models.CheckTypeRedis:
+		if _, err := redis.ParseURL(check.RedisConnString); err != nil {
+			return err
+		}
+	case models.CheckTypeMongo:
+		if err := mongocheck.ValidateConnString(check.MongoConnString, check.PostgresQuery != ""); err != nil {
+			return err
+		}
+		if check.PostgresQuery != "" {
+			if _, err := mongocheck.ParseQuery(check.PostgresQuery); err != nil {
+				return err
+			}
+		}
+	case models.CheckTypeDNS:
+		if check.DNSHostname == "" {
+			return fmt.Errorf("no hostname specified")
+		}
+		if check.DNSRecordType != "" && !dnsrecord.Types[strings.ToUpper(check.DNSRecordType)] {
+			return fmt.Errorf("unsupported record type: %s", check.DNSRecordType)
+		}
+		if check.DNSResolver != "" {
+			if _, err := dnsrecord.ServerAddr(check.DNSResolver); err != nil {
+				return err
+			}
+		}
+	case models.CheckTypeTailscale:
+		if check.TailscaleDeviceID == "" {
+			return fmt.Errorf("no device ID specified")
+		}
+	case models.CheckTypeTailscaleService:
+		if check.TailscaleServiceHost == "" {
+			return fmt.Errorf("no Tailscale host specified")
+		}
+		if check.TailscaleServicePort == 0 {
+			return fmt.Errorf("no port specified")
+		}
+		switch check.TailscaleServiceProtocol {
+		case "", "http", "https", "tcp":
+		default:
+			return fmt.Errorf("unsupported protocol: %s", check.TailscaleServiceProtocol)
+		}
+	default:
+		if check.URL == "" {
+			return fmt.Errorf("no URL specified")
+		}
+		if err := ValidateTemplate(check.URL); err != nil {
+			return fmt.Errorf("url: %w", err)
+		}
+		if err := ValidateKeyword(check.ResponseKeyword, check.ResponseKeywordMode); err != nil {
+			return fmt.Errorf("response_keyword: %w", err)
+		}
+		if err := ValidateHeaderAssertion(check.ExpectedHeaderName, check.ExpectedHeaderValue, check.ExpectedHeaderMode); err != nil {
+			return fmt.Errorf("expected_header: %w", err)
+		}
+		if check.ProxyURL != "" {
+			if _, err := ParseProxyURL(check.ProxyURL); err != nil {
+				return fmt.Errorf("proxy_url: %w", err)
+			}
+		}
+		if check.Type == models.CheckTypeJSONHTTP && check.JSONPath != "" {
+			if _, err := jsonpath.Compile(check.JSONPath); err != nil {
+				return fmt.Errorf("json_path: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// reportMisconfigured flags a check that failed ConfigError instead of running it. The
+// first time a given problem is seen it is notified once, unless disabled in the
+// settings; nothing is written to the history, so stats and events are unaffected.
+func (e *Engine) reportMisconfigured(state *checkState, cfgErr error) {
+	reason := cfgErr.Error()
+
+	e.mu.Lock()
+	changed := state.misconfigured != reason
+	state.misconfigured = reason
+	check := state.check
+	notifiers := e.notifiers
+	e.mu.Unlock()
+
+	if !changed {
+		return
+	}
+	log.Printf("Check %d (%s) is misconfigured: %s", check.ID, check.Name, reason)
+
+	if e.Paused() {
+		return
+	}
+	if value, _ := e.db.GetSetting(NotifyMisconfiguredSetting); value == "false" {
+		return
+	}
+	for _, n := range notifiers {
+		if n != nil {
+			notifier.SendStatusChange(n, notifier.StatusChange{
+				CheckID:   check.ID,
+				CheckName: check.Name,
+				CheckType: string(check.Type),
+				URL:       e.getCheckTarget(check),
+				ErrorMsg:  "check misconfigured: " + reason,
+			})
+		}
+	}
+}
+
+// Misconfiguration returns why a check was found misconfigured on its last run, or ""
+func (e *Engine) Misconfiguration(checkID int64) string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if state, ok := e.checks[checkID]; ok {
+		return state.misconfigured
+	}
+	return ""
+}
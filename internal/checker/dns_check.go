@@ -3,10 +3,10 @@ package checker
 import (
 	"context"
 	"fmt"
-	"net"
 	"strings"
 	"time"
 
+	"gocheck/internal/dnsrecord"
 	"gocheck/internal/models"
 )
 
@@ -23,52 +23,11 @@ func (e *Engine) performDNSCheck(check *models.Check, history *models.CheckHisto
 		recordType = "A"
 	}
 
-	resolver := &net.Resolver{
-		PreferGo: true,
-	}
-
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(check.TimeoutSeconds)*time.Second)
 	defer cancel()
 
-	var records []string
-	var err error
-
-	switch strings.ToUpper(recordType) {
-	case "A":
-		var ips []net.IP
-		ips, err = resolver.LookupIP(ctx, "ip4", check.DNSHostname)
-		for _, ip := range ips {
-			records = append(records, ip.String())
-		}
-	case "AAAA":
-		var ips []net.IP
-		ips, err = resolver.LookupIP(ctx, "ip6", check.DNSHostname)
-		for _, ip := range ips {
-			records = append(records, ip.String())
-		}
-	case "CNAME":
-		var cname string
-		cname, err = resolver.LookupCNAME(ctx, check.DNSHostname)
-		if err == nil {
-			records = append(records, cname)
-		}
-	case "MX":
-		var mxs []*net.MX
-		mxs, err = resolver.LookupMX(ctx, check.DNSHostname)
-		if err == nil {
-			for _, mx := range mxs {
-				records = append(records, fmt.Sprintf("%s (priority: %d)", mx.Host, mx.Pref))
-			}
-		}
-	case "TXT":
-		var txts []string
-		txts, err = resolver.LookupTXT(ctx, check.DNSHostname)
-		if err == nil {
-			records = txts
-		}
-	default:
-		err = fmt.Errorf("unsupported record type: %s", recordType)
-	}
+	resolver := &dnsrecord.Resolver{Server: check.DNSResolver}
+	records, err := resolver.Lookup(ctx, check.DNSHostname, recordType)
 
 	history.ResponseTimeMs = int(time.Since(start).Milliseconds())
 
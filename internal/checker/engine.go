@@ -2,13 +2,26 @@ package checker
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
+	"net"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"gocheck/internal/db"
+	"gocheck/internal/dbpool"
+	"gocheck/internal/metrics"
 	"gocheck/internal/models"
 	"gocheck/internal/notifier"
+	"gocheck/internal/redis"
+	"gocheck/internal/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Engine struct {
@@ -22,8 +35,20 @@ type Engine struct {
 	broadcast     chan *CheckResultEvent
 	clients       map[chan *CheckResultEvent]bool
 	clientsMu     sync.RWMutex
+	limits        Limits
+	sourceIP      string        // default source address, see SetSourceIP
+	pausedAt      *time.Time    // set while monitoring is globally paused
+	resumed       chan struct{} // closed when a pause ends
+	regions       map[int64][]string // probe regions assigned to checks, see SetCheckRegions
+	inFlight      map[int64]bool     // checks with a run in progress, see startRun
+	slots         *runSlots          // caps the checks running at once
+	postgresPools *dbpool.Cache      // connections of PostgreSQL checks, kept between runs
+	resultWebhooks *resultWebhooks
+	jitter         *startJitter
+	started        atomic.Bool // set once Start has scheduled the enabled checks
 	sentinelServer interface {
-		BroadcastCheckFull(check models.Check)
+		BroadcastCheckFull(check models.Check) map[string]error
+		BroadcastCheckToRegion(check models.Check, region string) map[string]error
 	}
 }
 
@@ -36,10 +61,36 @@ type CheckResultEvent struct {
 }
 
 type checkState struct {
-	check      models.Check
+	check      models.Check // replaced by runCheck on edits, guarded by Engine.mu
 	lastStatus *models.CheckHistory
-	ticker     *time.Ticker
+	schedule   schedule // owned by runCheck
 	stop       chan struct{}
+
+	// updates hands edits of a running check to runCheck; done is closed once
+	// runCheck returns
+	updates chan models.Check
+	done    chan struct{}
+
+	// confirmedUp is the last notified state; streak counts consecutive results that
+	// disagree with it, so flapping checks only notify once a new state is stable
+	confirmedUp *bool
+	streak      int
+	streakStart time.Time // when the first result of the streak was checked
+
+	// lastNotifiedAt is when the confirmed state was last notified, for re-notifying
+	// checks that stay down
+	lastNotifiedAt time.Time
+
+	// misconfigured is the ConfigError of the last run, guarded by Engine.mu
+	misconfigured string
+
+	// regionUp holds the latest result of each assigned region, guarded by Engine.mu
+	regionUp map[string]bool
+
+	// failures counts consecutive failed runs and backoff multiplies the interval
+	// of checks with BackoffOnFailure, see recordBackoff. Owned by runCheck.
+	failures int
+	backoff  int
 }
 
 func NewEngine(database *db.Database, notifiers []notifier.Notifier) *Engine {
@@ -52,18 +103,45 @@ func NewEngine(database *db.Database, notifiers []notifier.Notifier) *Engine {
 		cancel:    cancel,
 		broadcast: make(chan *CheckResultEvent, 100),
 		clients:   make(map[chan *CheckResultEvent]bool),
+		limits:    DefaultLimits,
+		regions:   make(map[int64][]string),
+		inFlight:  make(map[int64]bool),
+		slots:     newRunSlots(DefaultMaxConcurrentChecks),
+
+		postgresPools: dbpool.New(postgresPoolTTL),
+
+		resultWebhooks: newResultWebhooks(),
+		jitter:         newStartJitter(time.Now().UnixNano()),
 	}
 	go e.broadcaster()
+	e.startResultWebhookWorkers()
 	return e
 }
 
 func (e *Engine) SetSentinelServer(sentinelServer interface {
-	BroadcastCheckFull(check models.Check)
+	BroadcastCheckFull(check models.Check) map[string]error
+	BroadcastCheckToRegion(check models.Check, region string) map[string]error
 }) {
 	e.sentinelServer = sentinelServer
 }
 
+// SetLimits replaces the timing bounds applied to checks. Call before Start.
+func (e *Engine) SetLimits(limits Limits) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.limits = limits
+}
+
+func (e *Engine) Limits() Limits {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.limits
+}
+
 func (e *Engine) Start() error {
+	e.loadPauseState()
+	e.loadCheckRegions()
+
 	checks, err := e.db.GetEnabledChecks()
 	if err != nil {
 		return fmt.Errorf("failed to load checks: %w", err)
@@ -73,18 +151,24 @@ func (e *Engine) Start() error {
 		e.addCheck(check)
 	}
 
+	e.started.Store(true)
 	return nil
 }
 
+// Started reports whether Start has loaded and scheduled the checks
+func (e *Engine) Started() bool {
+	return e.started.Load()
+}
+
 func (e *Engine) Stop() {
 	e.cancel()
 	e.mu.Lock()
 	for _, state := range e.checks {
 		close(state.stop)
-		state.ticker.Stop()
 	}
 	e.mu.Unlock()
 	e.wg.Wait()
+	e.postgresPools.CloseAll()
 }
 
 func (e *Engine) UpdateNotifiers(notifiers []notifier.Notifier) {
@@ -111,9 +195,22 @@ func (e *Engine) RemoveCheck(checkID int64) {
 }
 
 func (e *Engine) addCheck(check models.Check) {
-	if state, exists := e.checks[check.ID]; exists {
-		close(state.stop)
-		state.ticker.Stop()
+	previous := e.checks[check.ID]
+	if previous != nil {
+		// Edits are applied by the running goroutine, which keeps the check's place in
+		// its schedule instead of starting over
+		select {
+		case <-previous.done:
+		default:
+			select {
+			case <-previous.updates:
+			default:
+			}
+			previous.updates <- check
+			return
+		}
+		close(previous.stop)
+		releasePostgresPool(e.postgresPools, previous.check, &check)
 	}
 
 	lastStatus, _ := e.db.GetLastStatus(check.ID)
@@ -121,8 +218,24 @@ func (e *Engine) addCheck(check models.Check) {
 	state := &checkState{
 		check:      check,
 		lastStatus: lastStatus,
-		ticker:     time.NewTicker(time.Duration(check.IntervalSeconds) * time.Second),
+		schedule:   checkSchedule(check),
 		stop:       make(chan struct{}),
+		updates:    make(chan models.Check, 1),
+		done:       make(chan struct{}),
+	}
+
+	// Keep the notification state across edits so updating a check does not re-alert
+	if previous != nil {
+		state.confirmedUp = previous.confirmedUp
+		state.streak = previous.streak
+		state.streakStart = previous.streakStart
+		state.lastNotifiedAt = previous.lastNotifiedAt
+	} else if lastStatus != nil {
+		up := lastStatus.Success
+		state.confirmedUp = &up
+		// The outage may have been notified before the restart; wait a full interval
+		// rather than re-notifying on the first result
+		state.lastNotifiedAt = time.Now()
 	}
 
 	e.checks[check.ID] = state
@@ -134,101 +247,211 @@ func (e *Engine) addCheck(check models.Check) {
 func (e *Engine) removeCheck(checkID int64) {
 	if state, exists := e.checks[checkID]; exists {
 		close(state.stop)
-		state.ticker.Stop()
 		delete(e.checks, checkID)
+		releasePostgresPool(e.postgresPools, state.check, nil)
 	}
+	metrics.Default.Forget(checkID)
 }
 
 func (e *Engine) runCheck(state *checkState) {
 	defer e.wg.Done()
+	defer close(state.done)
 
-	e.performCheck(state)
+	// Interval checks first run at a random point of their interval, see startJitter;
+	// cron checks wait for their first slot
+	next := state.schedule.Next(time.Now())
+	if interval, ok := state.schedule.(intervalSchedule); ok {
+		next = time.Now().Add(e.jitter.delay(interval.interval))
+	}
 
-	for {
+	// skipped is set when a run was missed while monitoring was paused
+	skipped := false
+
+	for !next.IsZero() {
+		timer := time.NewTimer(time.Until(next))
 		select {
-		case <-state.ticker.C:
-			e.performCheck(state)
+		case <-timer.C:
+			if e.Paused() {
+				skipped = true
+			} else if history, _ := e.performCheck(state, nil); history != nil {
+				e.mu.RLock()
+				check := state.check
+				e.mu.RUnlock()
+				state.recordBackoff(check, history.Success)
+			}
+		case <-e.resumeSignal():
+			timer.Stop()
+			if interval, ok := state.schedule.(intervalSchedule); ok && skipped {
+				next = time.Now().Add(resumeJitter(interval.interval))
+			}
+			skipped = false
+			continue
+		case check := <-state.updates:
+			timer.Stop()
+			next = e.reschedule(state, check, next)
+			continue
 		case <-state.stop:
+			timer.Stop()
 			return
 		case <-e.ctx.Done():
+			timer.Stop()
 			return
 		}
+
+		// Schedule from the planned time so slow checks don't drift, skipping
+		// slots that were missed while the check was running
+		now := time.Now()
+		next = state.schedule.Next(next)
+		if interval, ok := state.schedule.(intervalSchedule); ok && !next.IsZero() {
+			next = next.Add(state.backoffDelay(interval.interval))
+		}
+		if !next.IsZero() && next.Before(now) {
+			next = state.schedule.Next(now)
+		}
 	}
 }
 
-func (e *Engine) performCheck(state *checkState) {
-	check := state.check
+// reschedule applies an edit to a running check and returns its next run. The run
+// stays where it was unless the schedule changed: a new interval counts from the
+// previous run, a new cron expression from now. A run the new interval makes overdue
+// waits a full interval rather than firing right away.
+func (e *Engine) reschedule(state *checkState, check models.Check, next time.Time) time.Time {
+	e.mu.Lock()
+	previous := state.check
+	state.check = check
+	e.mu.Unlock()
+	releasePostgresPool(e.postgresPools, previous, &check)
+
+	// Turning backoff off brings a backed-off run back within one interval
+	if !check.BackoffOnFailure && state.backoff > 1 {
+		state.failures, state.backoff = 0, 1
+		if interval, ok := state.schedule.(intervalSchedule); ok {
+			if soon := time.Now().Add(interval.interval); soon.Before(next) {
+				next = soon
+			}
+		}
+	}
 
-	retries := check.Retries
-	if retries < 0 {
-		retries = 0
+	if check.IntervalSeconds == previous.IntervalSeconds && check.CronExpression == previous.CronExpression {
+		return next
 	}
-	if retries > 10 {
-		retries = 10
+
+	oldSchedule := state.schedule
+	state.schedule = checkSchedule(check)
+	now := time.Now()
+	oldInterval, wasInterval := oldSchedule.(intervalSchedule)
+	newInterval, isInterval := state.schedule.(intervalSchedule)
+	if wasInterval && isInterval {
+		if rescheduled := next.Add(newInterval.interval - oldInterval.interval); rescheduled.After(now) {
+			return rescheduled
+		}
+	}
+	return state.schedule.Next(now)
+}
+
+// performCheck runs a check and records its result, which it returns. It returns
+// ErrCheckRunning, the check's ConfigError or ErrRunOnProbes when there is no result
+// of its own, and the engine's context error when it stops first. onSlot, if set, is
+// called as each attempt takes and gives back its run slot.
+func (e *Engine) performCheck(state *checkState, onSlot func(held bool)) (*models.CheckHistory, error) {
+	e.mu.RLock()
+	check := state.check
+	e.mu.RUnlock()
+
+	if !e.startRun(check.ID) {
+		log.Printf("Check %d (%s) is still running, skipping this run", check.ID, check.Name)
+		return nil, ErrCheckRunning
 	}
-	delaySeconds := check.RetryDelaySeconds
-	if delaySeconds <= 0 {
-		delaySeconds = 5
+	defer e.finishRun(check.ID)
+
+	limits := e.Limits()
+	check.TimeoutSeconds = limits.ClampTimeout(check.TimeoutSeconds)
+	retries := limits.ClampRetries(check.Retries)
+	delaySeconds := limits.ClampRetryDelay(check.RetryDelaySeconds)
+
+	if err := ConfigError(check); err != nil {
+		e.reportMisconfigured(state, err)
+		return nil, err
 	}
-	if delaySeconds > 60 {
-		delaySeconds = 60
+	e.mu.Lock()
+	state.misconfigured = ""
+	e.mu.Unlock()
+
+	// Checks assigned to regions are run by those probes, whose results arrive through
+	// RecordProbeResult
+	regions := e.CheckRegions(check.ID)
+	if len(regions) > 0 && e.dispatchToRegions(state, regions) {
+		return nil, ErrRunOnProbes
 	}
 
+	ctx, span := tracing.Tracer().Start(e.ctx, "check "+string(check.Type), trace.WithAttributes(
+		attribute.Int64("check.id", check.ID),
+		attribute.String("check.type", string(check.Type)),
+		attribute.String("check.name", check.Name),
+	))
+	defer span.End()
+
 	var history models.CheckHistory
+	attempts := 0
 	for attempt := 0; attempt <= retries; attempt++ {
-		h := models.CheckHistory{CheckID: check.ID, CheckedAt: time.Now().UTC()}
-		start := time.Now()
-
-		switch check.Type {
-		case models.CheckTypePing:
-			e.performPingCheck(&check, &h, start)
-		case models.CheckTypePostgres:
-			e.performPostgresCheck(&check, &h, start)
-		case models.CheckTypeJSONHTTP:
-			e.performJSONHTTPCheck(&check, &h, start)
-		case models.CheckTypeDNS:
-			e.performDNSCheck(&check, &h, start)
-		case models.CheckTypeTailscale:
-			e.performTailscaleCheck(&check, &h, start)
-		case models.CheckTypeTailscaleService:
-			e.performTailscaleServiceCheck(&check, &h, start)
-		default:
-			e.performHTTPCheck(&check, &h, start)
+		if attempt > 0 {
+			delay := time.NewTimer(time.Duration(delaySeconds) * time.Second)
+			select {
+			case <-delay.C:
+			case <-e.ctx.Done():
+				delay.Stop()
+				return nil, e.ctx.Err()
+			}
 		}
 
-		history = h
+		// Wait for a free slot, see runSlots. Each attempt takes its own, so a check
+		// waiting out its retry delay doesn't hold up others.
+		if !e.slots.acquire(e.ctx.Done()) {
+			return nil, e.ctx.Err()
+		}
+		if onSlot != nil {
+			onSlot(true)
+		}
+		attempts++
+		history = e.runOnce(ctx, &check, e.postgresPools)
+		e.slots.release()
+		if onSlot != nil {
+			onSlot(false)
+		}
 		if history.Success {
 			break
 		}
-		if attempt < retries {
-			time.Sleep(time.Duration(delaySeconds) * time.Second)
-		}
 	}
 
-	e.db.AddHistory(&history)
+	span.SetAttributes(
+		attribute.Bool("check.success", history.Success),
+		attribute.Int("check.response_time_ms", history.ResponseTimeMs),
+		attribute.Int("check.attempts", attempts),
+	)
+	if history.StatusCode != 0 {
+		span.SetAttributes(attribute.Int("check.status_code", history.StatusCode))
+	}
+	if !history.Success {
+		span.SetStatus(codes.Error, history.ErrorMessage)
+	}
 
-	statusChanged := false
-	if state.lastStatus == nil {
-		statusChanged = true
-	} else {
-		statusChanged = state.lastStatus.Success != history.Success
-	}
-
-	if statusChanged {
-		e.mu.RLock()
-		notifiers := e.notifiers
-		e.mu.RUnlock()
-		for _, n := range notifiers {
-			if n != nil {
-				n.SendStatusChange(
-					check.Name,
-					e.getCheckTarget(check),
-					history.Success,
-					history.StatusCode,
-					history.ResponseTimeMs,
-					history.ErrorMessage,
-				)
-			}
+	e.db.AddHistory(&history)
+	metrics.Default.Observe(check, &history)
+
+	// Manual runs while paused don't notify or move the confirmed state, so a change
+	// that outlasts the pause is still notified once monitoring resumes
+	if !e.Paused() {
+		e.mu.Lock()
+		changed := state.confirmStatus(history.Success, history.CheckedAt)
+		renotify := !changed && state.renotifyDue(history.Success, history.CheckedAt)
+		changedAt := state.streakStart
+		e.mu.Unlock()
+
+		if changed {
+			e.trackIncident(check, history.Success, changedAt, history.ErrorMessage)
+		}
+		if changed || renotify {
+			e.notifyStatusChange(check, &history)
 		}
 	}
 
@@ -237,10 +460,136 @@ func (e *Engine) performCheck(state *checkState) {
 	// Broadcast the result to SSE clients
 	e.BroadcastCheckResult(check, &history)
 
-	// Broadcast to probes (skip Tailscale checks as they require local Tailscale access)
-	if e.sentinelServer != nil && check.Type != models.CheckTypeTailscale && check.Type != models.CheckTypeTailscaleService {
+	// Stream every result to the check's result webhook, if any
+	e.enqueueResultWebhook(check, &history)
+
+	// Broadcast to probes (skip Tailscale checks as they require local Tailscale access),
+	// unless the check is limited to regions whose probes are all offline
+	if len(regions) == 0 && e.canDispatch(check) {
 		e.sentinelServer.BroadcastCheckFull(check)
 	}
+	return &history, nil
+}
+
+// runOnce runs a single attempt of a check. It needs nothing but the check itself and
+// the pools PostgreSQL checks connect through, so it serves scheduled runs and dry runs
+// of unsaved checks alike.
+func (e *Engine) runOnce(ctx context.Context, check *models.Check, postgresPools *dbpool.Cache) models.CheckHistory {
+	h := models.CheckHistory{CheckID: check.ID, CheckedAt: time.Now().UTC()}
+	start := time.Now()
+
+	switch check.Type {
+	case models.CheckTypePing:
+		e.performPingCheck(check, &h, start)
+	case models.CheckTypePostgres:
+		e.performPostgresCheck(postgresPools, check, &h, start)
+	case models.CheckTypeRedis:
+		e.performRedisCheck(check, &h, start)
+	case models.CheckTypeMySQL:
+		e.performMySQLCheck(check, &h, start)
+	case models.CheckTypeMongo:
+		e.performMongoCheck(check, &h, start)
+	case models.CheckTypeJSONHTTP:
+		e.performJSONHTTPCheck(ctx, check, &h, start)
+	case models.CheckTypeDNS:
+		e.performDNSCheck(check, &h, start)
+	case models.CheckTypeTailscale:
+		e.performTailscaleCheck(check, &h, start)
+	case models.CheckTypeTailscaleService:
+		e.performTailscaleServiceCheck(check, &h, start)
+	case models.CheckTypeTCP:
+		e.performTCPCheck(check, &h, start)
+	case models.CheckTypeNTP:
+		e.performNTPCheck(check, &h, start)
+	case models.CheckTypeSSLCert:
+		e.performSSLCertCheck(check, &h, start)
+	case models.CheckTypeGRPC:
+		e.performGRPCCheck(check, &h, start)
+	case models.CheckTypeSMTP:
+		e.performSMTPCheck(check, &h, start)
+	default:
+		e.performHTTPCheck(ctx, check, &h, start)
+	}
+	applyResponseTimeThreshold(check, &h)
+	return h
+}
+
+// DryRunCheck runs a check that may not be saved once, without retries, and returns
+// its result without recording, notifying or broadcasting it
+func (e *Engine) DryRunCheck(ctx context.Context, check models.Check) (models.CheckHistory, error) {
+	check.TimeoutSeconds = e.Limits().ClampTimeout(check.TimeoutSeconds)
+	if err := ConfigError(check); err != nil {
+		return models.CheckHistory{}, err
+	}
+
+	// Don't keep a connection open for a check that may never be saved, nor close the
+	// shared pool of saved checks with the same connection string
+	pools := dbpool.New(postgresPoolTTL)
+	defer pools.CloseAll()
+	return e.runOnce(ctx, &check, pools), nil
+}
+
+// confirmStatus records a result and reports whether it completes a status change
+// that should be notified: the new state must hold for ConfirmationThreshold
+// consecutive results. A result matching the confirmed state resets the streak.
+// streakStart is left at the time of the first result of the change.
+func (s *checkState) confirmStatus(up bool, checkedAt time.Time) bool {
+	if s.confirmedUp != nil && *s.confirmedUp == up {
+		s.streak = 0
+		return false
+	}
+
+	s.streak++
+	if s.streak == 1 {
+		s.streakStart = checkedAt
+	}
+	threshold := s.check.ConfirmationThreshold
+	if threshold < 1 {
+		threshold = 1
+	}
+	if s.streak < threshold {
+		return false
+	}
+
+	s.confirmedUp = &up
+	s.streak = 0
+	s.lastNotifiedAt = checkedAt
+	return true
+}
+
+// renotifyDue reports whether a check that stays down should be notified again, a
+// RenotifyIntervalMinutes after its last notification, and records the notification
+func (s *checkState) renotifyDue(up bool, checkedAt time.Time) bool {
+	if up || s.check.RenotifyIntervalMinutes <= 0 || s.confirmedUp == nil || *s.confirmedUp {
+		return false
+	}
+	if checkedAt.Sub(s.lastNotifiedAt) < time.Duration(s.check.RenotifyIntervalMinutes)*time.Minute {
+		return false
+	}
+	s.lastNotifiedAt = checkedAt
+	return true
+}
+
+// notifyStatusChange sends a result to every notifier
+func (e *Engine) notifyStatusChange(check models.Check, history *models.CheckHistory) {
+	e.mu.RLock()
+	notifiers := e.notifiers
+	e.mu.RUnlock()
+	for _, n := range notifiers {
+		if n != nil {
+			notifier.SendStatusChange(n, notifier.StatusChange{
+				CheckID:        check.ID,
+				CheckName:      check.Name,
+				CheckType:      string(check.Type),
+				URL:            e.getCheckTarget(check),
+				IsUp:           history.Success,
+				StatusCode:     history.StatusCode,
+				ResponseTimeMs: history.ResponseTimeMs,
+				ErrorMsg:       history.ErrorMessage,
+				ResponseBody:   history.ResponseBody,
+			})
+		}
+	}
 }
 
 func (e *Engine) BroadcastCheckResult(check models.Check, history *models.CheckHistory) {
@@ -293,7 +642,8 @@ func (e *Engine) broadcaster() {
 				select {
 				case client <- event:
 				default:
-					// Client buffer full, skip
+					// The client is more than subscriberBuffer events behind; it
+					// misses this one rather than stalling every other client
 				}
 			}
 			e.clientsMu.RUnlock()
@@ -303,47 +653,183 @@ func (e *Engine) broadcaster() {
 	}
 }
 
+// subscriberBuffer is how many results a subscriber may fall behind by before the
+// broadcaster drops results for it. It covers a burst such as every check finishing
+// at once after startup.
+const subscriberBuffer = 64
+
+// Subscribe registers a channel that receives every check result. Callers must
+// Unsubscribe, typically with defer, when they stop reading.
 func (e *Engine) Subscribe() chan *CheckResultEvent {
-	client := make(chan *CheckResultEvent, 10)
+	client := make(chan *CheckResultEvent, subscriberBuffer)
 	e.clientsMu.Lock()
 	e.clients[client] = true
 	e.clientsMu.Unlock()
 	return client
 }
 
+// Unsubscribe removes and closes a subscriber channel. Calling it again is a no-op.
 func (e *Engine) Unsubscribe(client chan *CheckResultEvent) {
 	e.clientsMu.Lock()
-	delete(e.clients, client)
-	close(client)
-	e.clientsMu.Unlock()
+	defer e.clientsMu.Unlock()
+	if e.clients[client] {
+		delete(e.clients, client)
+		close(client)
+	}
+}
+
+// Subscribers returns how many subscriber channels are registered
+func (e *Engine) Subscribers() int {
+	e.clientsMu.RLock()
+	defer e.clientsMu.RUnlock()
+	return len(e.clients)
+}
+
+// ErrCheckRunning is returned by TriggerCheck while a run of the check is in progress
+var ErrCheckRunning = errors.New("check is already running")
+
+// ErrRunOnProbes is returned by RunCheck for a check assigned to regions, which was
+// sent to their probes instead of run here
+var ErrRunOnProbes = errors.New("check is assigned to regions and was sent to their probes; use /trigger/all to wait for their results")
+
+// ErrRunTimeout is returned by RunCheck when the run has not finished in time
+var ErrRunTimeout = errors.New("check did not finish in time")
+
+// runResultGrace is added to the longest a run can take before RunCheck gives up
+const runResultGrace = 5 * time.Second
+
+// startRun marks a check as running. It reports false when a run is already in
+// progress, so a run that outlasts its interval or a manual trigger never stacks
+// another run of the same check on top of it.
+func (e *Engine) startRun(checkID int64) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.inFlight[checkID] {
+		return false
+	}
+	e.inFlight[checkID] = true
+	return true
+}
+
+func (e *Engine) finishRun(checkID int64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.inFlight, checkID)
 }
 
 func (e *Engine) TriggerCheck(checkID int64) error {
 	e.mu.RLock()
 	state, exists := e.checks[checkID]
+	running := e.inFlight[checkID]
 	e.mu.RUnlock()
 
 	if !exists {
 		return fmt.Errorf("check not found or not enabled")
 	}
+	if running {
+		return ErrCheckRunning
+	}
 
-	go e.performCheck(state)
+	go e.performCheck(state, nil)
 	return nil
 }
 
+// RunCheck runs a check right away and waits for its result. Each attempt may take its
+// timeout plus runResultGrace once it has a run slot; waiting for a slot and retry
+// delays don't count, so a busy engine delays the result rather than failing it. The
+// wait is also bounded by ctx. The run itself is not cancelled and is still recorded
+// when it finishes.
+func (e *Engine) RunCheck(ctx context.Context, checkID int64) (*models.CheckHistory, error) {
+	e.mu.RLock()
+	state, exists := e.checks[checkID]
+	running := e.inFlight[checkID]
+	var check models.Check
+	if exists {
+		check = state.check
+	}
+	e.mu.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("check not found or not enabled")
+	}
+	if running {
+		return nil, ErrCheckRunning
+	}
+
+	attemptWait := time.Duration(e.Limits().ClampTimeout(check.TimeoutSeconds))*time.Second + runResultGrace
+
+	// The timer only runs while an attempt holds a slot
+	timer := time.NewTimer(attemptWait)
+	timer.Stop()
+	defer timer.Stop()
+	onSlot := func(held bool) {
+		if held {
+			timer.Reset(attemptWait)
+		} else {
+			timer.Stop()
+		}
+	}
+
+	type result struct {
+		history *models.CheckHistory
+		err     error
+	}
+	done := make(chan result, 1)
+	go func() {
+		history, err := e.performCheck(state, onSlot)
+		done <- result{history, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.history, r.err
+	case <-timer.C:
+		return nil, ErrRunTimeout
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
 func (e *Engine) getCheckTarget(check models.Check) string {
 	switch check.Type {
 	case models.CheckTypePing:
 		return check.Host
 	case models.CheckTypePostgres:
 		return "PostgreSQL: " + check.Name
+	case models.CheckTypeRedis:
+		// Never expose the credentials in the connection string
+		if opts, err := redis.ParseURL(check.RedisConnString); err == nil {
+			return "Redis: " + opts.Addr
+		}
+		return "Redis: " + check.Name
+	case models.CheckTypeMySQL:
+		return "MySQL: " + check.Name
+	case models.CheckTypeMongo:
+		return "MongoDB: " + check.Name
 	case models.CheckTypeDNS:
 		return check.DNSHostname
 	case models.CheckTypeTailscale:
 		return "Tailscale: " + check.TailscaleDeviceID
 	case models.CheckTypeTailscaleService:
 		return fmt.Sprintf("Tailscale Service: %s:%d", check.TailscaleServiceHost, check.TailscaleServicePort)
+	case models.CheckTypeTCP:
+		return net.JoinHostPort(check.Host, strconv.Itoa(check.Port))
+	case models.CheckTypeNTP:
+		return "NTP: " + ntpAddress(check)
+	case models.CheckTypeSSLCert:
+		return "TLS: " + certAddress(check)
+	case models.CheckTypeGRPC:
+		if check.GRPCService != "" {
+			return "gRPC: " + grpcAddress(check) + "/" + check.GRPCService
+		}
+		return "gRPC: " + grpcAddress(check)
+	case models.CheckTypeSMTP:
+		return "SMTP: " + smtpAddress(check)
 	default:
+		// Show the URL that was actually requested rather than the raw template
+		if expanded, err := ExpandTemplate(check.URL, time.Now().UTC()); err == nil {
+			return expanded
+		}
 		return check.URL
 	}
 }
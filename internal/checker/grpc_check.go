@@ -0,0 +1,48 @@
+package checker
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"gocheck/internal/grpchealth"
+	"gocheck/internal/models"
+
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func grpcAddress(check models.Check) string {
+	return net.JoinHostPort(check.Host, strconv.Itoa(check.Port))
+}
+
+// performGRPCCheck calls grpc.health.v1.Health/Check and passes on SERVING. The
+// response time covers only the RPC, not connecting or the TLS handshake.
+func (e *Engine) performGRPCCheck(check *models.Check, history *models.CheckHistory, start time.Time) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(check.TimeoutSeconds)*time.Second)
+	defer cancel()
+
+	var tlsConfig *tls.Config
+	if check.GRPCTLS {
+		tlsConfig = &tls.Config{ServerName: check.Host, InsecureSkipVerify: check.InsecureSkipVerify}
+	}
+
+	status, rtt, err := grpchealth.Check(ctx, grpcAddress(*check), check.GRPCService, tlsConfig)
+	if err != nil {
+		history.Success = false
+		history.ErrorMessage = err.Error()
+		history.ResponseTimeMs = int(time.Since(start).Milliseconds())
+		return
+	}
+	history.ResponseTimeMs = int(rtt.Milliseconds())
+	history.ResponseBody = status.String()
+
+	if status != healthpb.HealthCheckResponse_SERVING {
+		history.Success = false
+		history.ErrorMessage = fmt.Sprintf("status %s, expected SERVING", status)
+		return
+	}
+	history.Success = true
+}
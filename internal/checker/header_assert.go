@@ -0,0 +1,53 @@
+package checker
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	HeaderModeContains = "contains"
+	HeaderModeExact    = "exact"
+)
+
+// ValidateHeaderAssertion checks the header mode and that a value comes with a name
+func ValidateHeaderAssertion(name, value, mode string) error {
+	if name == "" {
+		if value != "" {
+			return fmt.Errorf("expected_header_value requires expected_header_name")
+		}
+		return nil
+	}
+	switch mode {
+	case "", HeaderModeContains, HeaderModeExact:
+		return nil
+	default:
+		return fmt.Errorf("unsupported header mode: %s", mode)
+	}
+}
+
+// MatchHeader asserts a response header. With an empty value the header only has to be
+// present. A repeated header is compared as its values joined with ", ".
+func MatchHeader(header http.Header, name, value, mode string) error {
+	values := header.Values(name)
+	if len(values) == 0 {
+		return fmt.Errorf("response header '%s' not found", name)
+	}
+	if value == "" {
+		return nil
+	}
+
+	got := strings.Join(values, ", ")
+	switch mode {
+	case HeaderModeExact:
+		if got != value {
+			return fmt.Errorf("response header '%s' is '%s', expected '%s'", name, got, value)
+		}
+	default:
+		if !strings.Contains(got, value) {
+			return fmt.Errorf("response header '%s' is '%s', expected it to contain '%s'", name, got, value)
+		}
+	}
+	return nil
+}
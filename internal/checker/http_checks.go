@@ -1,27 +1,126 @@
 package checker
 
 import (
+	"context"
+	"crypto/tls"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
+	"strings"
 	"time"
 
 	"gocheck/internal/models"
+	"gocheck/internal/statuscode"
+	"gocheck/internal/tracing"
 )
 
-func (e *Engine) performHTTPCheck(check *models.Check, history *models.CheckHistory, start time.Time) {
-	client := &http.Client{
-		Timeout: time.Duration(check.TimeoutSeconds) * time.Second,
+// newCheckHTTPClient builds the client for an HTTP or JSON HTTP check, honouring its
+// certificate verification, proxy and redirect settings
+func newCheckHTTPClient(check *models.Check, sourceIP net.IP) *http.Client {
+	client := newHTTPClient(time.Duration(check.TimeoutSeconds)*time.Second, sourceIP)
+	if check.InsecureSkipVerify {
+		ownTransport(client).TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
 	}
+	if check.ProxyURL != "" {
+		// ConfigError has already rejected an invalid proxy URL
+		if proxyURL, err := ParseProxyURL(check.ProxyURL); err == nil {
+			ownTransport(client).Proxy = http.ProxyURL(proxyURL)
+		}
+	}
+	if check.ExpectedHTTPVersion != "" {
+		// HTTP/2 has to be offered for the server to pick it, even with a custom TLS config
+		if transport, ok := client.Transport.(*http.Transport); ok {
+			transport.ForceAttemptHTTP2 = true
+		}
+	}
+	if !check.FollowRedirects {
+		client.CheckRedirect = func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	}
+	client.Transport = tracing.Transport(client.Transport)
+	return client
+}
+
+// applyHeaders sets the check's custom headers on req. Host is special-cased because
+// net/http ignores it in the header map.
+func applyHeaders(req *http.Request, headers map[string]string) {
+	for key, value := range headers {
+		if http.CanonicalHeaderKey(key) == "Host" {
+			req.Host = value
+			continue
+		}
+		req.Header.Set(key, value)
+	}
+}
+
+// applyCacheBypass asks every cache between us and the origin to revalidate, so a CDN
+// can't answer the check from a stale copy. Custom headers applied later still win.
+func applyCacheBypass(req *http.Request) {
+	req.Header.Set("Cache-Control", "no-cache")
+	req.Header.Set("Pragma", "no-cache")
+}
+
+// applyAuth sets the Authorization header for the check's auth scheme. A custom
+// Authorization header applied later still wins.
+func applyAuth(req *http.Request, check *models.Check) {
+	switch check.AuthType {
+	case models.AuthTypeBasic:
+		req.SetBasicAuth(check.AuthUsername, check.AuthPassword)
+	case models.AuthTypeBearer:
+		req.Header.Set("Authorization", "Bearer "+check.AuthToken)
+	}
+}
+
+// buildCheckRequest creates the HTTP request for a check, expanding templates in the
+// URL, body and headers. The body is only attached when one is configured.
+func buildCheckRequest(ctx context.Context, check *models.Check, method string, start time.Time) (*http.Request, error) {
+	expanded, err := ExpandCheckRequest(*check, start.UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	var body io.Reader
+	if expanded.Body != "" {
+		body = strings.NewReader(expanded.Body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, expanded.URL, body)
+	if err != nil {
+		return nil, fmt.Errorf("invalid request: %v", err)
+	}
+	if check.ContentType != "" {
+		req.Header.Set("Content-Type", check.ContentType)
+	}
+	if check.BypassCache {
+		applyCacheBypass(req)
+	}
+	applyAuth(req, check)
+	applyHeaders(req, expanded.Headers)
+	return req, nil
+}
+
+// performHTTPCheck requests the check's URL; ctx carries the trace of the run
+func (e *Engine) performHTTPCheck(ctx context.Context, check *models.Check, history *models.CheckHistory, start time.Time) {
+	sourceIP, err := e.checkSourceIP(check)
+	if err != nil {
+		history.Success = false
+		history.ErrorMessage = err.Error()
+		history.ResponseTimeMs = int(time.Since(start).Milliseconds())
+		return
+	}
+	client := newCheckHTTPClient(check, sourceIP)
 
 	method := check.Method
 	if method == "" {
 		method = "GET"
 	}
 
-	req, err := http.NewRequest(method, check.URL, nil)
+	req, err := buildCheckRequest(ctx, check, method, start)
 	if err != nil {
 		history.Success = false
-		history.ErrorMessage = fmt.Sprintf("invalid request: %v", err)
+		history.ErrorMessage = err.Error()
 		history.ResponseTimeMs = int(time.Since(start).Milliseconds())
 		return
 	}
@@ -39,30 +138,83 @@ func (e *Engine) performHTTPCheck(check *models.Check, history *models.CheckHist
 
 	history.StatusCode = resp.StatusCode
 
-	expectedStatusCodes := check.ExpectedStatusCodes
-	if len(expectedStatusCodes) == 0 {
-		expectedStatusCodes = []int{200}
+	// The expected codes are authoritative; only a check without any accepts 2xx/3xx
+	if !statuscode.Match(resp.StatusCode, check.ExpectedStatusCodes) {
+		history.Success = false
+		history.ErrorMessage = fmt.Sprintf("unexpected status code: %d (expected: %s)", resp.StatusCode, statuscode.Format(check.ExpectedStatusCodes))
+		return
 	}
 
-	success := false
-	for _, expectedCode := range expectedStatusCodes {
-		if resp.StatusCode == expectedCode {
-			success = true
-			break
+	if check.ExpectedHTTPVersion != "" {
+		history.ResponseBody = resp.Proto
+		if err := MatchHTTPVersion(resp, check.ExpectedHTTPVersion); err != nil {
+			history.Success = false
+			history.ErrorMessage = err.Error()
+			return
 		}
 	}
 
-	if !success {
-		// Fallback to 2xx range if no specific codes match
-		if resp.StatusCode >= 200 && resp.StatusCode < 400 {
-			success = true
+	if check.ExpectedHeaderName != "" {
+		if err := MatchHeader(resp.Header, check.ExpectedHeaderName, check.ExpectedHeaderValue, check.ExpectedHeaderMode); err != nil {
+			history.Success = false
+			history.ErrorMessage = err.Error()
+			return
 		}
 	}
 
-	if success {
+	checkSize := check.MinResponseBytes > 0 || check.MaxResponseBytes > 0
+	if check.ResponseKeyword == "" && check.ForbiddenBodyKeyword == "" && !checkSize {
 		history.Success = true
-	} else {
+		return
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxBodyBytes))
+	if err != nil {
 		history.Success = false
-		history.ErrorMessage = fmt.Sprintf("unexpected status code: %d (expected: %v)", resp.StatusCode, expectedStatusCodes)
+		history.ErrorMessage = fmt.Sprintf("failed to read body: %v", err)
+		return
 	}
+	body := string(data)
+
+	// The observed size is recorded even when it passes, so a shrinking body shows up
+	// in the history; a keyword assertion below replaces it with its match
+	if checkSize {
+		size, err := countBody(data, resp.Body)
+		if err != nil {
+			history.Success = false
+			history.ErrorMessage = fmt.Sprintf("failed to read body: %v", err)
+			return
+		}
+		history.ResponseBody = fmt.Sprintf("%d bytes", size)
+		if err := MatchBodySize(size, check.MinResponseBytes, check.MaxResponseBytes); err != nil {
+			history.Success = false
+			history.ErrorMessage = err.Error()
+			return
+		}
+	}
+
+	// The forbidden keyword is evaluated independently so soft-200 error pages fail
+	// even when the positive keyword also matches
+	if check.ForbiddenBodyKeyword != "" {
+		if matched, err := MatchKeyword(body, check.ForbiddenBodyKeyword, KeywordModeNotContains); err != nil {
+			history.Success = false
+			history.ResponseBody = matched
+			history.ErrorMessage = fmt.Sprintf("forbidden keyword '%s' found in response body", check.ForbiddenBodyKeyword)
+			return
+		}
+	}
+
+	if check.ResponseKeyword != "" {
+		matched, err := MatchKeyword(body, check.ResponseKeyword, check.ResponseKeywordMode)
+		if matched != "" {
+			history.ResponseBody = matched
+		}
+		if err != nil {
+			history.Success = false
+			history.ErrorMessage = err.Error()
+			return
+		}
+	}
+
+	history.Success = true
 }
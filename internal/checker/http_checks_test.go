@@ -0,0 +1,260 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gocheck/internal/models"
+)
+
+func TestHTTPCheckTLSAndRedirects(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/old" {
+			http.Redirect(w, r, "/new", http.StatusMovedPermanently)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name            string
+		skipVerify      bool
+		followRedirects bool
+		wantSuccess     bool
+		wantStatus      int
+		wantErr         string
+	}{
+		{"self-signed rejected", false, true, false, 0, "certificate"},
+		{"self-signed allowed", true, true, true, http.StatusOK, ""},
+		{"redirect kept", true, false, true, http.StatusMovedPermanently, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check := &models.Check{
+				Type:                models.CheckTypeHTTP,
+				URL:                 server.URL + "/old",
+				TimeoutSeconds:      5,
+				ExpectedStatusCodes: []int{tt.wantStatus},
+				InsecureSkipVerify:  tt.skipVerify,
+				FollowRedirects:     tt.followRedirects,
+			}
+			history := &models.CheckHistory{}
+
+			(&Engine{}).performHTTPCheck(context.Background(), check, history, time.Now())
+
+			if history.Success != tt.wantSuccess {
+				t.Fatalf("Success = %v, want %v (error: %s)", history.Success, tt.wantSuccess, history.ErrorMessage)
+			}
+			if history.StatusCode != tt.wantStatus {
+				t.Errorf("StatusCode = %d, want %d", history.StatusCode, tt.wantStatus)
+			}
+			if !strings.Contains(history.ErrorMessage, tt.wantErr) {
+				t.Errorf("ErrorMessage = %q, want it to mention %q", history.ErrorMessage, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHTTPCheckResponseHeader(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Server", "nginx/1.25.3")
+		w.Header().Add("Cache-Control", "public")
+		w.Header().Add("Cache-Control", "max-age=600")
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name, header, value, mode string
+		wantErr                   string
+	}{
+		{"present", "Server", "", "", ""},
+		{"contains", "server", "nginx", "", ""},
+		{"exact mismatch", "Server", "nginx", HeaderModeExact, "expected 'nginx'"},
+		{"repeated header", "Cache-Control", "public, max-age=600", HeaderModeExact, ""},
+		{"missing", "Strict-Transport-Security", "", "", "not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check := &models.Check{
+				Type:                models.CheckTypeHTTP,
+				URL:                 server.URL,
+				TimeoutSeconds:      5,
+				ExpectedHeaderName:  tt.header,
+				ExpectedHeaderValue: tt.value,
+				ExpectedHeaderMode:  tt.mode,
+			}
+			history := &models.CheckHistory{}
+
+			(&Engine{}).performHTTPCheck(context.Background(), check, history, time.Now())
+
+			if history.Success != (tt.wantErr == "") {
+				t.Fatalf("Success = %v, error %q", history.Success, history.ErrorMessage)
+			}
+			if !strings.Contains(history.ErrorMessage, tt.wantErr) {
+				t.Errorf("ErrorMessage = %q, want it to mention %q", history.ErrorMessage, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHTTPCheckBodySize(t *testing.T) {
+	// A body larger than maxBodyBytes is still counted to the end
+	body := strings.Repeat("x", maxBodyBytes+100)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		min, max int
+		wantErr  string
+	}{
+		{"within bounds", 1, len(body), ""},
+		{"truncated", len(body) + 1, 0, "expected at least"},
+		{"too large", 0, len(body) - 1, "expected at most"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check := &models.Check{
+				Type:             models.CheckTypeHTTP,
+				URL:              server.URL,
+				TimeoutSeconds:   5,
+				MinResponseBytes: tt.min,
+				MaxResponseBytes: tt.max,
+			}
+			history := &models.CheckHistory{}
+
+			(&Engine{}).performHTTPCheck(context.Background(), check, history, time.Now())
+
+			if history.Success != (tt.wantErr == "") {
+				t.Fatalf("Success = %v, error %q", history.Success, history.ErrorMessage)
+			}
+			if !strings.Contains(history.ErrorMessage, tt.wantErr) {
+				t.Errorf("ErrorMessage = %q, want it to mention %q", history.ErrorMessage, tt.wantErr)
+			}
+			if want := fmt.Sprintf("%d bytes", len(body)); history.ResponseBody != want {
+				t.Errorf("ResponseBody = %q, want %q", history.ResponseBody, want)
+			}
+		})
+	}
+}
+
+func TestHTTPCheckVersion(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	tests := []struct {
+		expected string
+		wantErr  string
+	}{
+		{HTTPVersion2, ""},
+		{HTTPVersion11, "served over HTTP/2, expected HTTP/1.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			check := &models.Check{
+				Type:                models.CheckTypeHTTP,
+				URL:                 server.URL,
+				TimeoutSeconds:      5,
+				InsecureSkipVerify:  true,
+				ExpectedHTTPVersion: tt.expected,
+			}
+			history := &models.CheckHistory{}
+
+			(&Engine{}).performHTTPCheck(context.Background(), check, history, time.Now())
+
+			if history.Success != (tt.wantErr == "") {
+				t.Fatalf("Success = %v, error %q", history.Success, history.ErrorMessage)
+			}
+			if !strings.Contains(history.ErrorMessage, tt.wantErr) {
+				t.Errorf("ErrorMessage = %q, want it to mention %q", history.ErrorMessage, tt.wantErr)
+			}
+			if history.ResponseBody != "HTTP/2.0" {
+				t.Errorf("ResponseBody = %q, want the negotiated HTTP/2.0", history.ResponseBody)
+			}
+		})
+	}
+}
+
+func TestHTTPCheckProxy(t *testing.T) {
+	// A plain HTTP proxy receives the absolute target URL as the request URI
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+	}))
+	defer proxy.Close()
+
+	check := &models.Check{
+		Type:           models.CheckTypeHTTP,
+		URL:            "http://internal.example/health",
+		TimeoutSeconds: 5,
+		ProxyURL:       proxy.URL,
+	}
+	history := &models.CheckHistory{}
+
+	(&Engine{}).performHTTPCheck(context.Background(), check, history, time.Now())
+
+	if !history.Success {
+		t.Fatalf("check failed: %s", history.ErrorMessage)
+	}
+	if proxied != check.URL {
+		t.Errorf("proxy received %q, want %q", proxied, check.URL)
+	}
+}
+
+func TestHTTPCheckExpectedStatusCodes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var status int
+		fmt.Sscanf(r.URL.Path, "/%d", &status)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		status      int
+		expected    []int
+		wantSuccess bool
+	}{
+		// Without expected codes any 2xx or 3xx passes
+		{http.StatusCreated, nil, true},
+		{http.StatusNoContent, nil, true},
+		{http.StatusFound, nil, true},
+		{http.StatusNotFound, nil, false},
+		// Expected codes are authoritative, even over other 2xx and 3xx codes
+		{http.StatusOK, []int{200}, true},
+		{http.StatusCreated, []int{200}, false},
+		{http.StatusFound, []int{200, 201, 202, 203, 204}, false},
+		{http.StatusNotFound, []int{404}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d expecting %v", tt.status, tt.expected), func(t *testing.T) {
+			check := &models.Check{
+				Type:                models.CheckTypeHTTP,
+				URL:                 fmt.Sprintf("%s/%d", server.URL, tt.status),
+				TimeoutSeconds:      5,
+				ExpectedStatusCodes: tt.expected,
+			}
+			history := &models.CheckHistory{}
+
+			(&Engine{}).performHTTPCheck(context.Background(), check, history, time.Now())
+
+			if history.Success != tt.wantSuccess {
+				t.Errorf("Success = %v, want %v (status %d, error: %s)", history.Success, tt.wantSuccess, history.StatusCode, history.ErrorMessage)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package checker
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// HTTP versions an HTTP check can expect the server to negotiate
+const (
+	HTTPVersion10 = "1.0"
+	HTTPVersion11 = "1.1"
+	HTTPVersion2  = "2"
+)
+
+// ValidateHTTPVersion checks an expected HTTP version; empty disables the assertion
+func ValidateHTTPVersion(version string) error {
+	switch version {
+	case "", HTTPVersion10, HTTPVersion11, HTTPVersion2:
+		return nil
+	default:
+		return fmt.Errorf("unsupported HTTP version %q, expected %s, %s or %s", version, HTTPVersion10, HTTPVersion11, HTTPVersion2)
+	}
+}
+
+// MatchHTTPVersion asserts the protocol a response was served over
+func MatchHTTPVersion(resp *http.Response, expected string) error {
+	got := fmt.Sprintf("%d.%d", resp.ProtoMajor, resp.ProtoMinor)
+	if resp.ProtoMajor >= 2 {
+		got = fmt.Sprintf("%d", resp.ProtoMajor)
+	}
+	if got != expected {
+		return fmt.Errorf("served over HTTP/%s, expected HTTP/%s", got, expected)
+	}
+	return nil
+}
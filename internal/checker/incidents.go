@@ -0,0 +1,25 @@
+package checker
+
+import (
+	"log"
+	"time"
+
+	"gocheck/internal/models"
+)
+
+// trackIncident records a confirmed status change of a check: going down opens an
+// incident that started at the first failing result, and recovering resolves it at
+// the first passing one.
+func (e *Engine) trackIncident(check models.Check, up bool, at time.Time, cause string) {
+	if up {
+		if err := e.db.ResolveIncident(check.ID, at); err != nil {
+			log.Printf("Failed to resolve incident for check %d (%s): %v", check.ID, check.Name, err)
+		}
+		return
+	}
+
+	incident := &models.Incident{CheckID: check.ID, Cause: cause, StartedAt: at}
+	if err := e.db.OpenIncident(incident); err != nil {
+		log.Printf("Failed to open incident for check %d (%s): %v", check.ID, check.Name, err)
+	}
+}
@@ -0,0 +1,31 @@
+package checker
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// startJitter picks when interval checks first run. Each one starts at a random point
+// of its first interval, so checks loaded at startup or created together don't all fire
+// at once. Later runs keep that offset because each is scheduled from the previous one,
+// so the interval itself is unchanged.
+type startJitter struct {
+	mu  sync.Mutex
+	rnd *rand.Rand
+}
+
+// newStartJitter returns a jitter source; the same seed gives the same delays
+func newStartJitter(seed int64) *startJitter {
+	return &startJitter{rnd: rand.New(rand.NewSource(seed))}
+}
+
+// delay returns a random duration in [0, interval)
+func (j *startJitter) delay(interval time.Duration) time.Duration {
+	if interval <= 0 {
+		return 0
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return time.Duration(j.rnd.Int63n(int64(interval)))
+}
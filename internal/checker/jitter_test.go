@@ -0,0 +1,30 @@
+package checker
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStartJitter(t *testing.T) {
+	a, b := newStartJitter(42), newStartJitter(42)
+	interval := time.Minute
+
+	seen := map[time.Duration]bool{}
+	for i := 0; i < 100; i++ {
+		d := a.delay(interval)
+		if d < 0 || d >= interval {
+			t.Fatalf("delay %v outside [0, %v)", d, interval)
+		}
+		if other := b.delay(interval); other != d {
+			t.Fatalf("same seed gave %v and %v", d, other)
+		}
+		seen[d] = true
+	}
+	if len(seen) < 50 {
+		t.Errorf("only %d distinct delays out of 100", len(seen))
+	}
+
+	if d := a.delay(0); d != 0 {
+		t.Errorf("delay(0) = %v", d)
+	}
+}
@@ -1,34 +1,43 @@
 package checker
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
-	"net/http"
 	"time"
 
+	"gocheck/internal/compare"
+	"gocheck/internal/jsonpath"
 	"gocheck/internal/models"
 )
 
-func (e *Engine) performJSONHTTPCheck(check *models.Check, history *models.CheckHistory, start time.Time) {
-	client := &http.Client{
-		Timeout: time.Duration(check.TimeoutSeconds) * time.Second,
+func (e *Engine) performJSONHTTPCheck(ctx context.Context, check *models.Check, history *models.CheckHistory, start time.Time) {
+	sourceIP, err := e.checkSourceIP(check)
+	if err != nil {
+		history.Success = false
+		history.ErrorMessage = err.Error()
+		history.ResponseTimeMs = int(time.Since(start).Milliseconds())
+		return
 	}
+	client := newCheckHTTPClient(check, sourceIP)
 
 	method := check.Method
 	if method == "" {
 		method = "GET"
 	}
 
-	req, err := http.NewRequest(method, check.URL, nil)
+	req, err := buildCheckRequest(ctx, check, method, start)
 	if err != nil {
 		history.Success = false
-		history.ErrorMessage = fmt.Sprintf("invalid request: %v", err)
+		history.ErrorMessage = err.Error()
 		history.ResponseTimeMs = int(time.Since(start).Milliseconds())
 		return
 	}
 
-	req.Header.Set("Accept", "application/json")
+	if req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", "application/json")
+	}
 
 	resp, err := client.Do(req)
 	history.ResponseTimeMs = int(time.Since(start).Milliseconds())
@@ -56,6 +65,14 @@ func (e *Engine) performJSONHTTPCheck(check *models.Check, history *models.Check
 		return
 	}
 
+	if check.ExpectedHeaderName != "" {
+		if err := MatchHeader(resp.Header, check.ExpectedHeaderName, check.ExpectedHeaderValue, check.ExpectedHeaderMode); err != nil {
+			history.Success = false
+			history.ErrorMessage = err.Error()
+			return
+		}
+	}
+
 	if check.JSONPath == "" {
 		history.Success = true
 		return
@@ -68,22 +85,22 @@ func (e *Engine) performJSONHTTPCheck(check *models.Check, history *models.Check
 		return
 	}
 
-	value, err := extractJSONValue(jsonData, check.JSONPath)
+	value, err := jsonpath.Get(jsonData, check.JSONPath)
 	if err != nil {
 		history.Success = false
 		history.ErrorMessage = fmt.Sprintf("JSON path error: %v", err)
 		return
 	}
 
-	history.ResponseBody = fmt.Sprintf("%v", value)
+	valueStr := jsonpath.Format(value)
+	history.ResponseBody = valueStr
 
 	if check.ExpectedJSONValue != "" {
-		valueStr := fmt.Sprintf("%v", value)
-		if valueStr == check.ExpectedJSONValue {
+		if compare.Match(valueStr, check.AssertionOperator, check.ExpectedJSONValue) {
 			history.Success = true
 		} else {
 			history.Success = false
-			history.ErrorMessage = fmt.Sprintf("expected '%s', got '%s'", check.ExpectedJSONValue, valueStr)
+			history.ErrorMessage = compare.Mismatch(valueStr, check.AssertionOperator, check.ExpectedJSONValue)
 		}
 	} else {
 		history.Success = true
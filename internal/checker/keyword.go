@@ -0,0 +1,79 @@
+package checker
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// maxBodyBytes caps how much of a response body is read for assertions
+const maxBodyBytes = 1 << 20
+
+const (
+	KeywordModeContains    = "contains"
+	KeywordModeNotContains = "not_contains"
+	KeywordModeRegex       = "regex"
+)
+
+const snippetRadius = 50
+
+// ValidateKeyword checks the keyword mode and, for regex mode, that the pattern compiles
+func ValidateKeyword(keyword, mode string) error {
+	if keyword == "" {
+		return nil
+	}
+	switch mode {
+	case "", KeywordModeContains, KeywordModeNotContains:
+		return nil
+	case KeywordModeRegex:
+		if _, err := regexp.Compile(keyword); err != nil {
+			return fmt.Errorf("invalid regex: %w", err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported keyword mode: %s", mode)
+	}
+}
+
+// MatchKeyword asserts the keyword against body according to mode. It returns the
+// matched snippet for display and an error describing why the assertion failed.
+func MatchKeyword(body, keyword, mode string) (string, error) {
+	switch mode {
+	case "", KeywordModeContains:
+		idx := strings.Index(body, keyword)
+		if idx < 0 {
+			return "", fmt.Errorf("keyword '%s' not found in response body", keyword)
+		}
+		return snippet(body, idx, idx+len(keyword)), nil
+	case KeywordModeNotContains:
+		if idx := strings.Index(body, keyword); idx >= 0 {
+			return snippet(body, idx, idx+len(keyword)), fmt.Errorf("keyword '%s' found in response body", keyword)
+		}
+		return "", nil
+	case KeywordModeRegex:
+		re, err := regexp.Compile(keyword)
+		if err != nil {
+			return "", fmt.Errorf("invalid regex: %v", err)
+		}
+		loc := re.FindStringIndex(body)
+		if loc == nil {
+			return "", fmt.Errorf("regex '%s' did not match response body", keyword)
+		}
+		return body[loc[0]:loc[1]], nil
+	default:
+		return "", fmt.Errorf("unsupported keyword mode: %s", mode)
+	}
+}
+
+// snippet returns the match with some surrounding context
+func snippet(body string, start, end int) string {
+	from := start - snippetRadius
+	if from < 0 {
+		from = 0
+	}
+	to := end + snippetRadius
+	if to > len(body) {
+		to = len(body)
+	}
+	return strings.ToValidUTF8(body[from:to], "")
+}
@@ -0,0 +1,92 @@
+package checker
+
+import (
+	"fmt"
+)
+
+// Limits bounds the timing settings a check may use. They are enforced when a check
+// is saved and again when it runs, so every checker can rely on a sane timeout.
+type Limits struct {
+	DefaultIntervalSeconds   int
+	DefaultTimeoutSeconds    int
+	MaxTimeoutSeconds        int
+	MaxRetries               int
+	DefaultRetryDelaySeconds int
+	MaxRetryDelaySeconds     int
+}
+
+// maxTimeoutCap keeps time.Duration(seconds) * time.Second far away from overflow
+const maxTimeoutCap = 24 * 60 * 60
+
+var DefaultLimits = Limits{
+	DefaultIntervalSeconds:   60,
+	DefaultTimeoutSeconds:    10,
+	MaxTimeoutSeconds:        300,
+	MaxRetries:               10,
+	DefaultRetryDelaySeconds: 5,
+	MaxRetryDelaySeconds:     60,
+}
+
+// WithMaxTimeout returns a copy of l using the given global timeout ceiling
+func (l Limits) WithMaxTimeout(seconds int) Limits {
+	if seconds <= 0 {
+		return l
+	}
+	if seconds > maxTimeoutCap {
+		seconds = maxTimeoutCap
+	}
+	l.MaxTimeoutSeconds = seconds
+	return l
+}
+
+// ValidateTiming rejects timeouts above the global maximum or as long as the check
+// interval, which would let executions of the same check overlap. A 1s interval may
+// use the shortest timeout, 1s, since the engine skips a run while the last is in flight.
+func (l Limits) ValidateTiming(intervalSeconds, timeoutSeconds int) error {
+	if intervalSeconds <= 0 {
+		return fmt.Errorf("interval_seconds must be positive")
+	}
+	if timeoutSeconds <= 0 {
+		return fmt.Errorf("timeout_seconds must be positive")
+	}
+	if timeoutSeconds > l.MaxTimeoutSeconds {
+		return fmt.Errorf("timeout_seconds cannot exceed %d", l.MaxTimeoutSeconds)
+	}
+	if timeoutSeconds >= intervalSeconds && timeoutSeconds > 1 {
+		return fmt.Errorf("timeout_seconds (%d) must be shorter than interval_seconds (%d)", timeoutSeconds, intervalSeconds)
+	}
+	return nil
+}
+
+// ClampTimeout returns a timeout within bounds, falling back to the default when unset
+func (l Limits) ClampTimeout(timeoutSeconds int) int {
+	if timeoutSeconds <= 0 {
+		return l.DefaultTimeoutSeconds
+	}
+	if timeoutSeconds > l.MaxTimeoutSeconds {
+		return l.MaxTimeoutSeconds
+	}
+	return timeoutSeconds
+}
+
+// ClampRetries bounds the number of retries to [0, MaxRetries]
+func (l Limits) ClampRetries(retries int) int {
+	if retries < 0 {
+		return 0
+	}
+	if retries > l.MaxRetries {
+		return l.MaxRetries
+	}
+	return retries
+}
+
+// ClampRetryDelay bounds the retry delay, falling back to the default when unset
+func (l Limits) ClampRetryDelay(delaySeconds int) int {
+	if delaySeconds <= 0 {
+		return l.DefaultRetryDelaySeconds
+	}
+	if delaySeconds > l.MaxRetryDelaySeconds {
+		return l.MaxRetryDelaySeconds
+	}
+	return delaySeconds
+}
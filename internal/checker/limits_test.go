@@ -0,0 +1,60 @@
+package checker
+
+import "testing"
+
+func TestValidateTiming(t *testing.T) {
+	limits := DefaultLimits.WithMaxTimeout(600)
+
+	tests := []struct {
+		name     string
+		interval int
+		timeout  int
+		wantErr  bool
+	}{
+		{"within bounds", 60, 10, false},
+		{"timeout just under interval", 120, 119, false},
+		{"timeout equals interval", 120, 120, true},
+		{"one second interval", 1, 1, false},
+		{"timeout exceeds one second interval", 1, 2, true},
+		{"long running check", 900, 600, false},
+		{"timeout exceeds interval", 30, 60, true},
+		{"timeout exceeds global max", 3600, 601, true},
+		{"zero timeout", 60, 0, true},
+		{"zero interval", 0, 10, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := limits.ValidateTiming(tt.interval, tt.timeout)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateTiming(%d, %d) error = %v, wantErr %v", tt.interval, tt.timeout, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWithMaxTimeoutCapped(t *testing.T) {
+	limits := DefaultLimits.WithMaxTimeout(1 << 40)
+	if limits.MaxTimeoutSeconds != maxTimeoutCap {
+		t.Errorf("MaxTimeoutSeconds = %d, want %d", limits.MaxTimeoutSeconds, maxTimeoutCap)
+	}
+	if got := DefaultLimits.WithMaxTimeout(0).MaxTimeoutSeconds; got != DefaultLimits.MaxTimeoutSeconds {
+		t.Errorf("WithMaxTimeout(0) changed max to %d", got)
+	}
+}
+
+func TestClamp(t *testing.T) {
+	l := DefaultLimits
+	if got := l.ClampTimeout(0); got != l.DefaultTimeoutSeconds {
+		t.Errorf("ClampTimeout(0) = %d", got)
+	}
+	if got := l.ClampTimeout(l.MaxTimeoutSeconds + 1); got != l.MaxTimeoutSeconds {
+		t.Errorf("ClampTimeout(max+1) = %d", got)
+	}
+	if got := l.ClampRetries(-1); got != 0 {
+		t.Errorf("ClampRetries(-1) = %d", got)
+	}
+	if got := l.ClampRetryDelay(120); got != l.MaxRetryDelaySeconds {
+		t.Errorf("ClampRetryDelay(120) = %d", got)
+	}
+}
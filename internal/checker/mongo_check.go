@@ -0,0 +1,35 @@
+package checker
+
+import (
+	"context"
+	"time"
+
+	"gocheck/internal/compare"
+	"gocheck/internal/models"
+	"gocheck/internal/mongocheck"
+)
+
+// performMongoCheck pings the server or runs a count/findOne query, comparing the result
+// with ExpectedQueryValue like the SQL checks do
+func (e *Engine) performMongoCheck(check *models.Check, history *models.CheckHistory, start time.Time) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(check.TimeoutSeconds)*time.Second)
+	defer cancel()
+
+	result, err := mongocheck.Check(ctx, check.MongoConnString, check.PostgresQuery)
+	history.ResponseTimeMs = int(time.Since(start).Milliseconds())
+	if err != nil {
+		history.Success = false
+		history.ErrorMessage = err.Error()
+		return
+	}
+
+	history.ResponseBody = result
+
+	if check.PostgresQuery != "" && check.ExpectedQueryValue != "" &&
+		!compare.Match(result, check.AssertionOperator, check.ExpectedQueryValue) {
+		history.Success = false
+		history.ErrorMessage = compare.Mismatch(result, check.AssertionOperator, check.ExpectedQueryValue)
+		return
+	}
+	history.Success = true
+}
@@ -0,0 +1,51 @@
+package checker
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	_ "github.com/go-sql-driver/mysql"
+	"gocheck/internal/models"
+)
+
+// performMySQLCheck pings the server or runs the check's query, sharing PostgresQuery
+// and its assertions with PostgreSQL checks
+func (e *Engine) performMySQLCheck(check *models.Check, history *models.CheckHistory, start time.Time) {
+	if check.MySQLConnString == "" {
+		history.Success = false
+		history.ErrorMessage = "no connection string specified"
+		history.ResponseTimeMs = int(time.Since(start).Milliseconds())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(check.TimeoutSeconds)*time.Second)
+	defer cancel()
+
+	db, err := sql.Open("mysql", check.MySQLConnString)
+	if err != nil {
+		history.Success = false
+		history.ErrorMessage = fmt.Sprintf("connection error: %v", err)
+		history.ResponseTimeMs = int(time.Since(start).Milliseconds())
+		return
+	}
+	defer db.Close()
+
+	db.SetConnMaxLifetime(time.Duration(check.TimeoutSeconds) * time.Second)
+	db.SetMaxOpenConns(1)
+
+	if check.PostgresQuery == "" {
+		err = db.PingContext(ctx)
+		history.ResponseTimeMs = int(time.Since(start).Milliseconds())
+		if err != nil {
+			history.Success = false
+			history.ErrorMessage = fmt.Sprintf("ping failed: %v", err)
+			return
+		}
+		history.Success = true
+		return
+	}
+
+	performQuery(ctx, db, check, history, start)
+}
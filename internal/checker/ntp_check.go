@@ -0,0 +1,59 @@
+package checker
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"gocheck/internal/models"
+	"gocheck/internal/ntp"
+)
+
+// defaultNTPMaxOffsetMs is used when a check doesn't set its own offset threshold
+const defaultNTPMaxOffsetMs = 1000
+
+func ntpAddress(check models.Check) string {
+	port := check.Port
+	if port <= 0 {
+		port = ntp.DefaultPort
+	}
+	return net.JoinHostPort(check.Host, strconv.Itoa(port))
+}
+
+// performNTPCheck queries the NTP server and fails when its clock offset exceeds the threshold
+func (e *Engine) performNTPCheck(check *models.Check, history *models.CheckHistory, start time.Time) {
+	if check.Host == "" {
+		history.Success = false
+		history.ErrorMessage = "no host specified"
+		history.ResponseTimeMs = int(time.Since(start).Milliseconds())
+		return
+	}
+
+	resp, err := ntp.Query(ntpAddress(*check), time.Duration(check.TimeoutSeconds)*time.Second)
+	history.ResponseTimeMs = int(time.Since(start).Milliseconds())
+	if err != nil {
+		history.Success = false
+		history.ErrorMessage = fmt.Sprintf("NTP query failed: %v", err)
+		return
+	}
+
+	history.ResponseBody = fmt.Sprintf("offset: %s, delay: %s, stratum: %d", resp.Offset, resp.Delay, resp.Stratum)
+
+	maxOffset := check.NTPMaxOffsetMs
+	if maxOffset <= 0 {
+		maxOffset = defaultNTPMaxOffsetMs
+	}
+
+	offset := resp.Offset
+	if offset < 0 {
+		offset = -offset
+	}
+	if offset > time.Duration(maxOffset)*time.Millisecond {
+		history.Success = false
+		history.ErrorMessage = fmt.Sprintf("clock offset %s exceeds %dms", resp.Offset, maxOffset)
+		return
+	}
+
+	history.Success = true
+}
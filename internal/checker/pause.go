@@ -0,0 +1,118 @@
+package checker
+
+import (
+	"math/rand"
+	"strconv"
+	"time"
+
+	"gocheck/internal/models"
+)
+
+// Settings keys persisting the global pause across restarts
+const (
+	MonitoringPausedSetting   = "monitoring_paused"
+	MonitoringPausedAtSetting = "monitoring_paused_at"
+)
+
+// maxResumeJitter spreads the checks skipped during a pause over at most this long
+// after resuming, so they don't all fire at once
+const maxResumeJitter = 30 * time.Second
+
+// loadPauseState restores the global pause from the settings
+func (e *Engine) loadPauseState() {
+	value, _ := e.db.GetSetting(MonitoringPausedSetting)
+	paused, _ := strconv.ParseBool(value)
+	if !paused {
+		return
+	}
+
+	var pausedAt *time.Time
+	if value, _ := e.db.GetSetting(MonitoringPausedAtSetting); value != "" {
+		if t, err := time.Parse(time.RFC3339, value); err == nil {
+			pausedAt = &t
+		}
+	}
+
+	e.mu.Lock()
+	e.pausedAt = pausedAt
+	if e.pausedAt == nil {
+		now := time.Now().UTC()
+		e.pausedAt = &now
+	}
+	e.resumed = make(chan struct{})
+	e.mu.Unlock()
+}
+
+// SetPaused pauses or resumes all scheduled checks. While paused, scheduled runs are
+// skipped and manual triggers don't notify. On resume, interval checks that missed a
+// run are rescheduled with a random delay of up to maxResumeJitter.
+func (e *Engine) SetPaused(paused bool) (models.MonitoringStatus, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if paused == (e.pausedAt != nil) {
+		return e.monitoringStatusLocked(), nil
+	}
+
+	pausedAt := ""
+	var now time.Time
+	if paused {
+		now = time.Now().UTC().Truncate(time.Second)
+		pausedAt = now.Format(time.RFC3339)
+	}
+	if err := e.db.SetSetting(MonitoringPausedSetting, strconv.FormatBool(paused)); err != nil {
+		return e.monitoringStatusLocked(), err
+	}
+	if err := e.db.SetSetting(MonitoringPausedAtSetting, pausedAt); err != nil {
+		return e.monitoringStatusLocked(), err
+	}
+
+	if paused {
+		e.pausedAt = &now
+		e.resumed = make(chan struct{})
+	} else {
+		e.pausedAt = nil
+		close(e.resumed)
+		e.resumed = nil
+	}
+	return e.monitoringStatusLocked(), nil
+}
+
+// MonitoringStatus reports whether monitoring is globally paused
+func (e *Engine) MonitoringStatus() models.MonitoringStatus {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.monitoringStatusLocked()
+}
+
+func (e *Engine) monitoringStatusLocked() models.MonitoringStatus {
+	return models.MonitoringStatus{Paused: e.pausedAt != nil, PausedAt: e.pausedAt}
+}
+
+// Paused reports whether monitoring is globally paused
+func (e *Engine) Paused() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.pausedAt != nil
+}
+
+// resumeSignal returns a channel closed when monitoring resumes, or nil when it is
+// not paused (a nil channel never fires in a select)
+func (e *Engine) resumeSignal() <-chan struct{} {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.resumed
+}
+
+// resumeJitter picks the delay before an interval check that missed runs during a
+// pause runs again
+func resumeJitter(interval time.Duration) time.Duration {
+	window := maxResumeJitter
+	if interval < window {
+		window = interval
+	}
+	if window <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(window)))
+}
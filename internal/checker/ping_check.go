@@ -2,14 +2,18 @@ package checker
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
+	"net"
 	"os/exec"
 	"regexp"
-	"runtime"
+	"strconv"
 	"strings"
 	"time"
 
 	"gocheck/internal/models"
+	"gocheck/internal/pinger"
 )
 
 func (e *Engine) performPingCheck(check *models.Check, history *models.CheckHistory, start time.Time) {
@@ -21,36 +25,121 @@ func (e *Engine) performPingCheck(check *models.Check, history *models.CheckHist
 		return
 	}
 
+	sourceIP, err := e.checkSourceIP(check)
+	if err != nil {
+		history.Success = false
+		history.ErrorMessage = err.Error()
+		history.ResponseTimeMs = int(time.Since(start).Milliseconds())
+		return
+	}
+
 	timeout := time.Duration(check.TimeoutSeconds) * time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
 
-	var cmd *exec.Cmd
-	if runtime.GOOS == "windows" {
-		cmd = exec.Command("ping", "-n", "1", "-w", fmt.Sprintf("%d", check.TimeoutSeconds*1000), host)
-	} else {
-		cmd = exec.Command("ping", "-c", "1", "-W", fmt.Sprintf("%d", check.TimeoutSeconds), host)
+	stats, err := pinger.Run(ctx, host, sourceIP, check.PingCount)
+	if errors.Is(err, pinger.ErrUnavailable) {
+		// Without ICMP socket permissions the ping binary, usually setuid, still works
+		e.performExecPing(check, history, start, sourceIP)
+		return
+	}
+
+	if check.ExpectUnreachable {
+		history.ResponseTimeMs = int(time.Since(start).Milliseconds())
+		switch {
+		case err == nil:
+			history.Success = false
+			history.ErrorMessage = "host responded to ping but was expected to be unreachable"
+		case errors.Is(err, pinger.ErrNoReply):
+			history.Success = true
+			history.ResponseBody = fmt.Sprintf("%s is unreachable: %v", host, err)
+		default:
+			history.Success = false
+			history.ErrorMessage = fmt.Sprintf("ping failed: %v", err)
+		}
+		return
+	}
+
+	if err != nil {
+		history.Success = false
+		history.ErrorMessage = fmt.Sprintf("ping failed: %v", err)
+		history.ResponseTimeMs = int(time.Since(start).Milliseconds())
+		if stats.Sent > 0 {
+			history.ResponseBody = stats.String()
+		}
+		return
+	}
+
+	history.ResponseTimeMs = int(stats.AvgRTT.Milliseconds())
+	applyPacketLoss(check, history, stats)
+}
+
+// applyPacketLoss records the loss of a ping run that got replies, failing the check
+// when it exceeds the check's threshold
+func applyPacketLoss(check *models.Check, history *models.CheckHistory, stats pinger.Stats) {
+	history.ResponseBody = stats.String()
+	if loss := stats.Loss(); check.MaxPacketLoss > 0 && loss > float64(check.MaxPacketLoss) {
+		history.Success = false
+		history.ErrorMessage = fmt.Sprintf("packet loss %g%% exceeds threshold %d%%", math.Round(loss*10)/10, check.MaxPacketLoss)
+		return
 	}
+	history.Success = true
+}
+
+// pingTimeRe matches the round-trip time ping prints, such as time=12.3 ms or time<1ms
+var pingTimeRe = regexp.MustCompile(`(?i)time[=<](\d+\.?\d*)`)
+
+// performExecPing runs the system ping binary, for hosts where ICMP sockets can't be
+// opened. The response time is the average round trip ping reports, or the run time of
+// the process when it prints none.
+func (e *Engine) performExecPing(check *models.Check, history *models.CheckHistory, start time.Time, sourceIP net.IP) {
+	host := check.Host
+	timeout := time.Duration(check.TimeoutSeconds) * time.Second
+
+	args := pinger.ExecArgs(check.PingCount, check.TimeoutSeconds)
+	args = append(args, pingSourceArgs(sourceIP)...)
+	args = append(args, host)
 
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
-	cmd = exec.CommandContext(ctx, cmd.Path, cmd.Args[1:]...)
+	cmd := exec.CommandContext(ctx, "ping", args...)
 
 	output, err := cmd.CombinedOutput()
 	history.ResponseTimeMs = int(time.Since(start).Milliseconds())
 
+	if check.ExpectUnreachable {
+		unreachable, reason := classifyPingResult(ctx, output, err)
+		history.Success = unreachable
+		if unreachable {
+			history.ResponseBody = fmt.Sprintf("%s is unreachable: %s", host, reason)
+		} else {
+			history.ErrorMessage = reason
+		}
+		return
+	}
+
 	if err != nil {
 		history.Success = false
 		history.ErrorMessage = fmt.Sprintf("ping failed: %v", err)
+		if stats, ok := pinger.ParseOutput(output); ok && stats.Sent > 0 {
+			history.ResponseBody = stats.String()
+		}
+		return
+	}
+
+	if stats, ok := pinger.ParseOutput(output); ok && stats.Received > 0 {
+		if stats.AvgRTT > 0 {
+			history.ResponseTimeMs = int(stats.AvgRTT.Milliseconds())
+		}
+		applyPacketLoss(check, history, stats)
 		return
 	}
 
 	outputStr := string(output)
-	if strings.Contains(outputStr, "time=") || strings.Contains(outputStr, "Time=") {
-		re := regexp.MustCompile(`time[=<](\d+\.?\d*)`)
-		matches := re.FindStringSubmatch(outputStr)
-		if len(matches) > 1 {
-			history.Success = true
-		} else {
-			history.Success = true
+	if matches := pingTimeRe.FindStringSubmatch(outputStr); len(matches) > 1 {
+		history.Success = true
+		if ms, err := strconv.ParseFloat(matches[1], 64); err == nil {
+			history.ResponseTimeMs = int(ms)
 		}
 	} else if strings.Contains(outputStr, "bytes from") || strings.Contains(outputStr, "Reply from") {
 		history.Success = true
@@ -59,3 +148,21 @@ func (e *Engine) performPingCheck(check *models.Check, history *models.CheckHist
 		history.ErrorMessage = "no response from host"
 	}
 }
+
+// classifyPingResult reports whether a ping run shows the host does not answer. ping
+// exits with 1 when no reply arrived; any other failure (unknown host, missing
+// permissions) says nothing about reachability and is reported as an error.
+func classifyPingResult(ctx context.Context, output []byte, err error) (unreachable bool, reason string) {
+	if err == nil {
+		return false, "host responded to ping but was expected to be unreachable"
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		return true, "no response before timeout"
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return true, "no response"
+	}
+	return false, fmt.Sprintf("ping failed: %v: %s", err, strings.TrimSpace(string(output)))
+}
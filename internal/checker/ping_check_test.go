@@ -0,0 +1,43 @@
+package checker
+
+import (
+	"testing"
+
+	"gocheck/internal/models"
+	"gocheck/internal/pinger"
+)
+
+func TestApplyPacketLoss(t *testing.T) {
+	tests := []struct {
+		name        string
+		maxLoss     int
+		stats       pinger.Stats
+		wantSuccess bool
+		wantErr     string
+		wantBody    string
+	}{
+		{"no loss", 10, pinger.Stats{Sent: 5, Received: 5}, true, "", "5/5 received, 0% packet loss"},
+		{"at threshold", 20, pinger.Stats{Sent: 5, Received: 4}, true, "", "4/5 received, 20% packet loss"},
+		{"over threshold", 10, pinger.Stats{Sent: 5, Received: 4}, false, "packet loss 20% exceeds threshold 10%", "4/5 received, 20% packet loss"},
+		{"threshold disabled", 0, pinger.Stats{Sent: 3, Received: 1}, true, "", "1/3 received, 66.7% packet loss"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check := &models.Check{Type: models.CheckTypePing, MaxPacketLoss: tt.maxLoss}
+			history := &models.CheckHistory{}
+
+			applyPacketLoss(check, history, tt.stats)
+
+			if history.Success != tt.wantSuccess {
+				t.Errorf("Success = %v, want %v", history.Success, tt.wantSuccess)
+			}
+			if history.ErrorMessage != tt.wantErr {
+				t.Errorf("ErrorMessage = %q, want %q", history.ErrorMessage, tt.wantErr)
+			}
+			if history.ResponseBody != tt.wantBody {
+				t.Errorf("ResponseBody = %q, want %q", history.ResponseBody, tt.wantBody)
+			}
+		})
+	}
+}
@@ -7,10 +7,30 @@ import (
 	"time"
 
 	_ "github.com/lib/pq"
+	"gocheck/internal/dbpool"
 	"gocheck/internal/models"
+	"gocheck/internal/sqlcheck"
 )
 
-func (e *Engine) performPostgresCheck(check *models.Check, history *models.CheckHistory, start time.Time) {
+// postgresPoolTTL is how long the pool of a PostgreSQL check stays open without a run
+const postgresPoolTTL = 10 * time.Minute
+
+// releasePostgresPool gives up the pooled connection of a PostgreSQL check that was
+// deleted or moved to another connection string. The pool stays open while other
+// checks share the string.
+func releasePostgresPool(pools *dbpool.Cache, old models.Check, updated *models.Check) {
+	if pools == nil || old.Type != models.CheckTypePostgres || old.PostgresConnString == "" {
+		return
+	}
+	if updated != nil && updated.Type == old.Type && updated.PostgresConnString == old.PostgresConnString {
+		return
+	}
+	pools.Release(old.ID, "postgres", old.PostgresConnString)
+}
+
+// performPostgresCheck pings the server or runs the check's query over a connection
+// from pools that is kept between runs; the check's timeout still bounds each run
+func (e *Engine) performPostgresCheck(pools *dbpool.Cache, check *models.Check, history *models.CheckHistory, start time.Time) {
 	if check.PostgresConnString == "" {
 		history.Success = false
 		history.ErrorMessage = "no connection string specified"
@@ -21,17 +41,13 @@ func (e *Engine) performPostgresCheck(check *models.Check, history *models.Check
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(check.TimeoutSeconds)*time.Second)
 	defer cancel()
 
-	db, err := sql.Open("postgres", check.PostgresConnString)
+	db, err := pools.Get(check.ID, "postgres", check.PostgresConnString)
 	if err != nil {
 		history.Success = false
 		history.ErrorMessage = fmt.Sprintf("connection error: %v", err)
 		history.ResponseTimeMs = int(time.Since(start).Milliseconds())
 		return
 	}
-	defer db.Close()
-
-	db.SetConnMaxLifetime(time.Duration(check.TimeoutSeconds) * time.Second)
-	db.SetMaxOpenConns(1)
 
 	if check.PostgresQuery == "" {
 		err = db.PingContext(ctx)
@@ -45,8 +61,23 @@ func (e *Engine) performPostgresCheck(check *models.Check, history *models.Check
 		return
 	}
 
-	var result string
-	err = db.QueryRowContext(ctx, check.PostgresQuery).Scan(&result)
+	performQuery(ctx, db, check, history, start)
+}
+
+// queryAssertion is what a PostgreSQL or MySQL check expects of its query's rows
+func queryAssertion(check *models.Check) sqlcheck.Assertion {
+	return sqlcheck.Assertion{
+		RowCount: check.ExpectedRowCount,
+		Column:   check.QueryColumn,
+		Operator: check.AssertionOperator,
+		Expected: check.ExpectedQueryValue,
+	}
+}
+
+// performQuery runs the query of a PostgreSQL or MySQL check, which may return any
+// number of rows and columns, and asserts on its rows
+func performQuery(ctx context.Context, db *sql.DB, check *models.Check, history *models.CheckHistory, start time.Time) {
+	result, err := sqlcheck.Query(ctx, db, check.PostgresQuery)
 	history.ResponseTimeMs = int(time.Since(start).Milliseconds())
 
 	if err != nil {
@@ -55,16 +86,12 @@ func (e *Engine) performPostgresCheck(check *models.Check, history *models.Check
 		return
 	}
 
-	history.ResponseBody = result
+	history.ResponseBody = result.Preview()
 
-	if check.ExpectedQueryValue != "" {
-		if result == check.ExpectedQueryValue {
-			history.Success = true
-		} else {
-			history.Success = false
-			history.ErrorMessage = fmt.Sprintf("expected '%s', got '%s'", check.ExpectedQueryValue, result)
-		}
-	} else {
-		history.Success = true
+	if err := result.Check(queryAssertion(check)); err != nil {
+		history.Success = false
+		history.ErrorMessage = err.Error()
+		return
 	}
+	history.Success = true
 }
@@ -0,0 +1,38 @@
+package checker
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// ParseProxyURL parses the proxy of an HTTP check: an http, https, socks5 or socks5h
+// URL with a host, optionally carrying credentials
+func ParseProxyURL(raw string) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %v", err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q, expected http, https, socks5 or socks5h", u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("proxy URL has no host")
+	}
+	return u, nil
+}
+
+// ownTransport returns the transport of client, first replacing the shared default
+// transport with a private one whose settings can be changed
+func ownTransport(client *http.Client) *http.Transport {
+	if transport, ok := client.Transport.(*http.Transport); ok {
+		return transport
+	}
+	// A private transport per run would otherwise leak its idle connections
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DisableKeepAlives = true
+	client.Transport = transport
+	return transport
+}
@@ -0,0 +1,64 @@
+package checker
+
+import (
+	"fmt"
+	"time"
+
+	"gocheck/internal/models"
+	"gocheck/internal/redis"
+)
+
+// performRedisCheck expects PONG from the server and, when a key is set, compares its
+// value. The response time covers only the PING round trip, not connecting or AUTH.
+func (e *Engine) performRedisCheck(check *models.Check, history *models.CheckHistory, start time.Time) {
+	opts, err := redis.ParseURL(check.RedisConnString)
+	if err != nil {
+		history.Success = false
+		history.ErrorMessage = err.Error()
+		history.ResponseTimeMs = int(time.Since(start).Milliseconds())
+		return
+	}
+
+	conn, err := redis.Dial(opts, time.Duration(check.TimeoutSeconds)*time.Second)
+	if err != nil {
+		history.Success = false
+		history.ErrorMessage = err.Error()
+		history.ResponseTimeMs = int(time.Since(start).Milliseconds())
+		return
+	}
+	defer conn.Close()
+
+	rtt, err := conn.Ping()
+	history.ResponseTimeMs = int(rtt.Milliseconds())
+	if err != nil {
+		history.Success = false
+		history.ErrorMessage = err.Error()
+		return
+	}
+
+	if check.RedisKey == "" {
+		history.Success = true
+		return
+	}
+
+	value, ok, err := conn.Get(check.RedisKey)
+	if err != nil {
+		history.Success = false
+		history.ErrorMessage = err.Error()
+		return
+	}
+	if !ok {
+		history.Success = false
+		history.ErrorMessage = fmt.Sprintf("key '%s' not found", check.RedisKey)
+		return
+	}
+
+	history.ResponseBody = value
+
+	if check.ExpectedRedisValue != "" && value != check.ExpectedRedisValue {
+		history.Success = false
+		history.ErrorMessage = fmt.Sprintf("expected '%s', got '%s'", check.ExpectedRedisValue, value)
+		return
+	}
+	history.Success = true
+}
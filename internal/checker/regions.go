@@ -0,0 +1,165 @@
+package checker
+
+import (
+	"errors"
+	"log"
+
+	"gocheck/internal/models"
+	"gocheck/internal/notifier"
+)
+
+// ErrCommandInFlight is reported by BroadcastCheckToRegion when a send timed out after
+// the command started going out, so the probe may still run the check
+var ErrCommandInFlight = errors.New("command may still reach the probe")
+
+// loadCheckRegions reads the probe regions assigned to checks. Failures are logged and
+// leave every check running on the server.
+func (e *Engine) loadCheckRegions() {
+	regions, err := e.db.GetAllCheckRegions()
+	if err != nil {
+		log.Printf("Failed to load check regions: %v", err)
+		return
+	}
+	e.mu.Lock()
+	e.regions = regions
+	e.mu.Unlock()
+}
+
+// SetCheckRegions sets the probe regions a check is dispatched to. With no regions the
+// check runs on the server again.
+func (e *Engine) SetCheckRegions(checkID int64, regions []string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if len(regions) == 0 {
+		delete(e.regions, checkID)
+	} else {
+		e.regions[checkID] = append([]string(nil), regions...)
+	}
+	if state, ok := e.checks[checkID]; ok {
+		state.regionUp = nil
+	}
+}
+
+// CheckRegions returns the probe regions assigned to a check, nil when it runs on the server
+func (e *Engine) CheckRegions(checkID int64) []string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.regions[checkID]
+}
+
+// canDispatch reports whether a check can be run by probes. Tailscale checks need the
+// server's tailnet access, so they always run locally.
+func (e *Engine) canDispatch(check models.Check) bool {
+	return e.sentinelServer != nil && check.Type != models.CheckTypeTailscale && check.Type != models.CheckTypeTailscaleService
+}
+
+// dispatchToRegions sends a check to the probes of its assigned regions instead of
+// running it here. It reports false when none of them has a connected probe, so the
+// server runs the check itself rather than leaving it unmonitored. A command that may
+// still reach its probe counts as sent, so the check isn't run both here and there;
+// a probe that never answers is dropped by the heartbeat sweeper, after which the
+// next run falls back to the server. A probe that connects mid-interval is sent the
+// check at its next scheduled run.
+func (e *Engine) dispatchToRegions(state *checkState, regions []string) bool {
+	e.mu.RLock()
+	check := state.check
+	e.mu.RUnlock()
+	if !e.canDispatch(check) {
+		return false
+	}
+
+	delivered, inFlight := 0, 0
+	for _, region := range regions {
+		err := e.sentinelServer.BroadcastCheckToRegion(check, region)[region]
+		if err == nil {
+			delivered++
+			continue
+		}
+		if errors.Is(err, ErrCommandInFlight) {
+			inFlight++
+			continue
+		}
+		// A region that can't be reached no longer counts towards the check's status
+		e.mu.Lock()
+		delete(state.regionUp, region)
+		e.mu.Unlock()
+	}
+
+	if delivered == 0 && inFlight > 0 {
+		log.Printf("Check %d (%s) may still reach its probes, not running it locally", check.ID, check.Name)
+		return true
+	}
+	if delivered == 0 {
+		log.Printf("No probe connected for the regions of check %d (%s), running it locally", check.ID, check.Name)
+		return false
+	}
+	return true
+}
+
+// RecordProbeResult feeds a probe result into the notification state of a check that
+// is dispatched to its assigned regions. The check counts as up while the latest result
+// of every assigned region that reported is a success. Results of checks without
+// assigned regions, or from other regions, are ignored.
+func (e *Engine) RecordProbeResult(checkID int64, history *models.CheckHistory) {
+	paused := e.Paused()
+
+	e.mu.Lock()
+	state, ok := e.checks[checkID]
+	if !ok || !containsRegion(e.regions[checkID], history.Region) {
+		e.mu.Unlock()
+		return
+	}
+	if state.regionUp == nil {
+		state.regionUp = make(map[string]bool)
+	}
+	state.regionUp[history.Region] = history.Success
+	up := true
+	for _, regionUp := range state.regionUp {
+		up = up && regionUp
+	}
+	state.lastStatus = history
+	changed := !paused && state.confirmStatus(up, history.CheckedAt)
+	renotify := !paused && !changed && state.renotifyDue(up, history.CheckedAt)
+	changedAt := state.streakStart
+	check := state.check
+	notifiers := e.notifiers
+	e.mu.Unlock()
+
+	if !changed && !renotify {
+		return
+	}
+
+	errorMessage := history.ErrorMessage
+	if errorMessage != "" {
+		errorMessage = history.Region + ": " + errorMessage
+	}
+	if changed {
+		e.trackIncident(check, up, changedAt, errorMessage)
+	}
+
+	for _, n := range notifiers {
+		if n != nil {
+			notifier.SendStatusChange(n, notifier.StatusChange{
+				CheckID:        check.ID,
+				CheckName:      check.Name,
+				CheckType:      string(check.Type),
+				URL:            e.getCheckTarget(check),
+				IsUp:           up,
+				StatusCode:     history.StatusCode,
+				ResponseTimeMs: history.ResponseTimeMs,
+				ErrorMsg:       errorMessage,
+				ResponseBody:   history.ResponseBody,
+			})
+		}
+	}
+}
+
+func containsRegion(regions []string, region string) bool {
+	for _, r := range regions {
+		if r == region {
+			return true
+		}
+	}
+	return false
+}
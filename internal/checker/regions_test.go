@@ -0,0 +1,122 @@
+package checker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"gocheck/internal/db"
+	"gocheck/internal/models"
+	"gocheck/internal/notifier"
+)
+
+type fakeSentinel struct {
+	connected map[string]bool
+	stalled   map[string]bool
+	sent      []string
+}
+
+func (f *fakeSentinel) BroadcastCheckFull(check models.Check) map[string]error {
+	return nil
+}
+
+func (f *fakeSentinel) BroadcastCheckToRegion(check models.Check, region string) map[string]error {
+	if f.stalled[region] {
+		return map[string]error{region: ErrCommandInFlight}
+	}
+	if !f.connected[region] {
+		return map[string]error{region: errors.New("probe not connected")}
+	}
+	f.sent = append(f.sent, region)
+	return map[string]error{region: nil}
+}
+
+type recordingNotifier struct {
+	changes []bool
+}
+
+func (n *recordingNotifier) TestWebhook() error { return nil }
+
+func (n *recordingNotifier) SendStatusChange(checkName, url string, isUp bool, statusCode int, responseTimeMs int, errorMsg string) error {
+	n.changes = append(n.changes, isUp)
+	return nil
+}
+
+// incidentDB records the incidents the engine opens and resolves
+type incidentDB struct {
+	db.DB
+	opened   []models.Incident
+	resolved []time.Time
+}
+
+func (d *incidentDB) OpenIncident(incident *models.Incident) error {
+	d.opened = append(d.opened, *incident)
+	return nil
+}
+
+func (d *incidentDB) ResolveIncident(checkID int64, resolvedAt time.Time) error {
+	d.resolved = append(d.resolved, resolvedAt)
+	return nil
+}
+
+func TestDispatchToRegions(t *testing.T) {
+	sentinel := &fakeSentinel{connected: map[string]bool{"eu": true}}
+	e := &Engine{sentinelServer: sentinel}
+	state := &checkState{check: models.Check{ID: 1, Type: models.CheckTypeHTTP}}
+
+	if !e.dispatchToRegions(state, []string{"eu", "us"}) {
+		t.Fatal("expected the check to be dispatched to the connected region")
+	}
+	if len(sentinel.sent) != 1 || sentinel.sent[0] != "eu" {
+		t.Errorf("sent to %v, want [eu]", sentinel.sent)
+	}
+
+	if e.dispatchToRegions(state, []string{"us"}) {
+		t.Error("expected a local fallback when no assigned probe is connected")
+	}
+
+	// A command that may still arrive must not also be run locally
+	sentinel.stalled = map[string]bool{"us": true}
+	if !e.dispatchToRegions(state, []string{"us"}) {
+		t.Error("ran the check locally while it may still reach the probe")
+	}
+
+	state.check.Type = models.CheckTypeTailscale
+	if e.dispatchToRegions(state, []string{"eu"}) {
+		t.Error("Tailscale checks must not be dispatched")
+	}
+}
+
+func TestRecordProbeResult(t *testing.T) {
+	n := &recordingNotifier{}
+	incidents := &incidentDB{}
+	up := true
+	state := &checkState{check: models.Check{ID: 1, Name: "site", ConfirmationThreshold: 1}, confirmedUp: &up}
+	e := &Engine{
+		db:        &db.Database{DB: incidents},
+		notifiers: []notifier.Notifier{n},
+		checks:    map[int64]*checkState{1: state},
+		regions:   map[int64][]string{1: {"eu", "us"}},
+	}
+
+	e.RecordProbeResult(1, &models.CheckHistory{Region: "eu", Success: true})
+	e.RecordProbeResult(1, &models.CheckHistory{Region: "ap", Success: false})
+	if len(n.changes) != 0 {
+		t.Fatalf("unexpected notifications %v", n.changes)
+	}
+
+	down := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	e.RecordProbeResult(1, &models.CheckHistory{Region: "us", Success: false, ErrorMessage: "timeout", CheckedAt: down})
+	e.RecordProbeResult(1, &models.CheckHistory{Region: "eu", Success: true, CheckedAt: down.Add(time.Minute)})
+	e.RecordProbeResult(1, &models.CheckHistory{Region: "us", Success: true, CheckedAt: down.Add(2 * time.Minute)})
+	if len(n.changes) != 2 || n.changes[0] || !n.changes[1] {
+		t.Errorf("notifications = %v, want [false true]", n.changes)
+	}
+
+	if len(incidents.opened) != 1 || incidents.opened[0].Cause != "us: timeout" || !incidents.opened[0].StartedAt.Equal(down) {
+		t.Errorf("opened incidents = %+v, want one from the us timeout", incidents.opened)
+	}
+	if len(incidents.resolved) != 1 || !incidents.resolved[0].Equal(down.Add(2*time.Minute)) {
+		t.Errorf("resolved incidents at %v, want once at the recovery", incidents.resolved)
+	}
+}
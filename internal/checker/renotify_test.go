@@ -0,0 +1,40 @@
+package checker
+
+import (
+	"testing"
+	"time"
+
+	"gocheck/internal/models"
+)
+
+func TestRenotifyDue(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	state := &checkState{check: models.Check{ConfirmationThreshold: 1, RenotifyIntervalMinutes: 30}}
+
+	if !state.confirmStatus(false, start) {
+		t.Fatal("the first failure was not notified")
+	}
+
+	var renotified []time.Duration
+	for minute := 5; minute <= 65; minute += 5 {
+		at := start.Add(time.Duration(minute) * time.Minute)
+		if state.confirmStatus(false, at) {
+			t.Fatalf("continued failure at %dm notified as a change", minute)
+		}
+		if state.renotifyDue(false, at) {
+			renotified = append(renotified, at.Sub(start))
+		}
+	}
+	if len(renotified) != 2 || renotified[0] != 30*time.Minute || renotified[1] != time.Hour {
+		t.Errorf("re-notified after %v, want [30m 1h]", renotified)
+	}
+
+	if state.renotifyDue(true, start.Add(2*time.Hour)) {
+		t.Error("re-notified a successful result")
+	}
+
+	state.check.RenotifyIntervalMinutes = 0
+	if state.renotifyDue(false, start.Add(3*time.Hour)) {
+		t.Error("re-notified with re-notification disabled")
+	}
+}
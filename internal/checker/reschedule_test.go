@@ -0,0 +1,53 @@
+package checker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gocheck/internal/models"
+)
+
+func TestReschedule(t *testing.T) {
+	e := &Engine{}
+	newState := func(intervalSeconds int) *checkState {
+		check := models.Check{ID: 1, IntervalSeconds: intervalSeconds}
+		return &checkState{check: check, schedule: checkSchedule(check)}
+	}
+	next := time.Now().Add(40 * time.Second)
+
+	state := newState(60)
+	if got := e.reschedule(state, models.Check{ID: 1, Name: "renamed", IntervalSeconds: 60}, next); !got.Equal(next) {
+		t.Errorf("an edit that keeps the interval moved the next run by %v", got.Sub(next))
+	}
+	if state.check.Name != "renamed" {
+		t.Errorf("check name = %q, want the edited check", state.check.Name)
+	}
+
+	state = newState(60)
+	if got := e.reschedule(state, models.Check{ID: 1, IntervalSeconds: 120}, next); !got.Equal(next.Add(time.Minute)) {
+		t.Errorf("a longer interval moved the next run by %v, want 1m", got.Sub(next))
+	}
+
+	// The previous run was 20s ago, so a 10s interval is overdue and waits a full interval
+	state = newState(60)
+	got := e.reschedule(state, models.Check{ID: 1, IntervalSeconds: 10}, next)
+	if wait := time.Until(got); wait < 9*time.Second || wait > 10*time.Second {
+		t.Errorf("an overdue run waits %v, want the new 10s interval", wait)
+	}
+}
+
+func TestRunCheckWhileRunning(t *testing.T) {
+	check := models.Check{ID: 1, IntervalSeconds: 60}
+	e := &Engine{
+		checks:   map[int64]*checkState{1: {check: check, schedule: checkSchedule(check)}},
+		inFlight: map[int64]bool{1: true},
+	}
+	if _, err := e.RunCheck(context.Background(), 1); !errors.Is(err, ErrCheckRunning) {
+		t.Errorf("RunCheck during a run = %v, want ErrCheckRunning", err)
+	}
+	if _, err := e.RunCheck(context.Background(), 2); err == nil {
+		t.Error("RunCheck of an unknown check succeeded")
+	}
+}
@@ -0,0 +1,29 @@
+package checker
+
+import (
+	"fmt"
+
+	"gocheck/internal/models"
+)
+
+// enforcesResponseTime reports whether a check type honours MaxResponseTimeMs
+func enforcesResponseTime(t models.CheckType) bool {
+	switch t {
+	case models.CheckTypeHTTP, models.CheckTypeJSONHTTP, models.CheckTypeTailscaleService, "":
+		return true
+	}
+	return false
+}
+
+// applyResponseTimeThreshold fails an otherwise successful result that took longer
+// than the check's MaxResponseTimeMs. A threshold of zero disables the limit and a
+// response exactly at the threshold still passes.
+func applyResponseTimeThreshold(check *models.Check, history *models.CheckHistory) {
+	if check.MaxResponseTimeMs <= 0 || !history.Success || !enforcesResponseTime(check.Type) {
+		return
+	}
+	if history.ResponseTimeMs > check.MaxResponseTimeMs {
+		history.Success = false
+		history.ErrorMessage = fmt.Sprintf("response time %dms exceeds threshold %dms", history.ResponseTimeMs, check.MaxResponseTimeMs)
+	}
+}
@@ -0,0 +1,48 @@
+package checker
+
+import (
+	"testing"
+
+	"gocheck/internal/models"
+)
+
+func TestApplyResponseTimeThreshold(t *testing.T) {
+	tests := []struct {
+		name        string
+		checkType   models.CheckType
+		threshold   int
+		elapsed     int
+		success     bool
+		wantSuccess bool
+		wantErr     string
+	}{
+		{"below threshold", models.CheckTypeHTTP, 500, 499, true, true, ""},
+		{"at threshold", models.CheckTypeHTTP, 500, 500, true, true, ""},
+		{"just over threshold", models.CheckTypeHTTP, 500, 501, true, false, "response time 501ms exceeds threshold 500ms"},
+		{"well over threshold", models.CheckTypeJSONHTTP, 500, 1200, true, false, "response time 1200ms exceeds threshold 500ms"},
+		{"tailscale service", models.CheckTypeTailscaleService, 100, 150, true, false, "response time 150ms exceeds threshold 100ms"},
+		{"threshold disabled", models.CheckTypeHTTP, 0, 100000, true, true, ""},
+		{"negative threshold", models.CheckTypeHTTP, -1, 100000, true, true, ""},
+		{"already failed keeps its error", models.CheckTypeHTTP, 500, 1200, false, false, "connection refused"},
+		{"other check types unaffected", models.CheckTypePing, 500, 1200, true, true, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check := &models.Check{Type: tt.checkType, MaxResponseTimeMs: tt.threshold}
+			history := &models.CheckHistory{Success: tt.success, ResponseTimeMs: tt.elapsed}
+			if !tt.success {
+				history.ErrorMessage = "connection refused"
+			}
+
+			applyResponseTimeThreshold(check, history)
+
+			if history.Success != tt.wantSuccess {
+				t.Errorf("Success = %v, want %v", history.Success, tt.wantSuccess)
+			}
+			if history.ErrorMessage != tt.wantErr {
+				t.Errorf("ErrorMessage = %q, want %q", history.ErrorMessage, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package checker
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"gocheck/internal/models"
+)
+
+const (
+	resultWebhookWorkers   = 4
+	resultWebhookQueueSize = 256
+	resultWebhookTimeout   = 10 * time.Second
+)
+
+// ResultWebhookPayload is POSTed to a check's result webhook after every execution
+type ResultWebhookPayload struct {
+	CheckID   int64               `json:"check_id"`
+	CheckName string              `json:"check_name"`
+	CheckType models.CheckType    `json:"check_type"`
+	Target    string              `json:"target"`
+	Result    models.CheckHistory `json:"result"`
+}
+
+type resultDelivery struct {
+	url     string
+	payload ResultWebhookPayload
+}
+
+// resultWebhooks delivers results with at-most-once semantics: a bounded queue feeds a
+// fixed pool of workers, and results are dropped when the queue is full so a slow
+// endpoint can never block the check loop. Failed deliveries are not retried.
+type resultWebhooks struct {
+	queue   chan resultDelivery
+	client  *http.Client
+	dropped atomic.Uint64
+}
+
+func newResultWebhooks() *resultWebhooks {
+	return &resultWebhooks{
+		queue:  make(chan resultDelivery, resultWebhookQueueSize),
+		client: &http.Client{Timeout: resultWebhookTimeout},
+	}
+}
+
+func (e *Engine) startResultWebhookWorkers() {
+	for i := 0; i < resultWebhookWorkers; i++ {
+		go func() {
+			for {
+				select {
+				case d := <-e.resultWebhooks.queue:
+					if err := e.resultWebhooks.deliver(d); err != nil {
+						log.Printf("Result webhook for check %d failed: %v", d.payload.CheckID, err)
+					}
+				case <-e.ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+}
+
+// enqueueResultWebhook queues the result for delivery without blocking
+func (e *Engine) enqueueResultWebhook(check models.Check, history *models.CheckHistory) {
+	if check.ResultWebhookURL == "" {
+		return
+	}
+
+	d := resultDelivery{
+		url: check.ResultWebhookURL,
+		payload: ResultWebhookPayload{
+			CheckID:   check.ID,
+			CheckName: check.Name,
+			CheckType: check.Type,
+			Target:    e.getCheckTarget(check),
+			Result:    *history,
+		},
+	}
+
+	select {
+	case e.resultWebhooks.queue <- d:
+	default:
+		dropped := e.resultWebhooks.dropped.Add(1)
+		log.Printf("Result webhook queue full, dropped result for check %d (%d dropped total)", check.ID, dropped)
+	}
+}
+
+// DroppedResultWebhooks returns how many results were dropped under backpressure
+func (e *Engine) DroppedResultWebhooks() uint64 {
+	return e.resultWebhooks.dropped.Load()
+}
+
+func (w *resultWebhooks) deliver(d resultDelivery) error {
+	payload, err := json.Marshal(d.payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", d.url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
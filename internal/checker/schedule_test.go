@@ -0,0 +1,116 @@
+package checker
+
+import (
+	"strings"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestCronNext(t *testing.T) {
+	// 2024-01-01 is a Monday
+	at := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2024, month, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		expr  string
+		after time.Time
+		want  time.Time
+	}{
+		{"*/15 * * * *", at(1, 1, 0, 0), at(1, 1, 0, 15)},
+		{"*/15 * * * *", at(1, 1, 0, 14), at(1, 1, 0, 15)},
+		{"0 9-17/4 * * *", at(1, 1, 0, 0), at(1, 1, 9, 0)},
+		{"0 9-17/4 * * *", at(1, 1, 9, 0), at(1, 1, 13, 0)},
+		{"0 9-17/4 * * *", at(1, 1, 17, 0), at(1, 2, 9, 0)},
+		{"5,10 * * * *", at(1, 1, 0, 5), at(1, 1, 0, 10)},
+		{"30 8 * * MON-fri", at(1, 6, 0, 0), at(1, 8, 8, 30)},
+		{"0 0 1 jan,Jul *", at(1, 1, 0, 0), at(7, 1, 0, 0)},
+		{"0 12 * * 7", at(1, 1, 0, 0), at(1, 7, 12, 0)},
+		{"0 12 * * 5-7", at(1, 1, 0, 0), at(1, 5, 12, 0)},
+		{"@hourly", at(1, 1, 0, 30), at(1, 1, 1, 0)},
+		{"@monthly", at(1, 15, 0, 0), at(2, 1, 0, 0)},
+		{"0 0 29 2 *", at(3, 1, 0, 0), time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+
+		// With both day fields restricted, either may match
+		{"0 0 13 * 5", at(1, 1, 0, 0), at(1, 5, 0, 0)},
+		{"0 0 13 * 5", at(1, 12, 0, 0), at(1, 13, 0, 0)},
+		// A day field starting with * only narrows the other: Mondays on odd days
+		{"0 0 */2 * 1", at(1, 1, 0, 0), at(1, 15, 0, 0)},
+		// */7 is Sunday (0 and 7): the first Sunday that is the 1st of a month
+		{"0 0 1 * */7", at(1, 1, 0, 0), at(9, 1, 0, 0)},
+		{"0 0 ? * 1", at(1, 1, 0, 0), at(1, 8, 0, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr+" after "+tt.after.Format(time.DateTime), func(t *testing.T) {
+			s, err := ParseCron(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseCron: %v", err)
+			}
+			s.location = time.UTC
+			if got := s.Next(tt.after); !got.Equal(tt.want) {
+				t.Errorf("Next = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCronErrors(t *testing.T) {
+	tests := []struct {
+		expr    string
+		wantErr string
+	}{
+		{"* * * *", "expected 5 fields"},
+		{"60 * * * *", "minute"},
+		{"* 24 * * *", "hour"},
+		{"* * 0 * *", "day of month"},
+		{"* * * mon *", "month"},
+		{"* * * * 8", "day of week"},
+		{"5-1 * * * *", "out of range"},
+		{"*/0 * * * *", "invalid step"},
+		{"0 0 30 2 *", "never matches"},
+		{"0 0 31 4,6,9,11 *", "never matches"},
+	}
+
+	for _, tt := range tests {
+		if _, err := ParseCron(tt.expr); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("ParseCron(%q) error = %v, want %q", tt.expr, err, tt.wantErr)
+		}
+	}
+}
+
+func TestCronNextAcrossDST(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		expr  string
+		after time.Time
+		want  time.Time
+	}{
+		// Clocks jump from 02:00 to 03:00 EST on 2024-03-10
+		{"skipped time waits a day", "30 2 * * *", time.Date(2024, 3, 10, 1, 0, 0, 0, ny), time.Date(2024, 3, 11, 2, 30, 0, 0, ny)},
+		{"steps continue after the jump", "*/15 * * * *", time.Date(2024, 3, 10, 1, 50, 0, 0, ny), time.Date(2024, 3, 10, 7, 0, 0, 0, time.UTC)},
+		{"daily time after the jump", "0 9 * * *", time.Date(2024, 3, 10, 1, 0, 0, 0, ny), time.Date(2024, 3, 10, 13, 0, 0, 0, time.UTC)},
+		// Clocks fall back from 02:00 EDT to 01:00 EST on 2024-11-03
+		{"repeated time runs again", "30 1 * * *", time.Date(2024, 11, 3, 5, 30, 0, 0, time.UTC), time.Date(2024, 11, 3, 6, 30, 0, 0, time.UTC)},
+		{"daily time after the fall back", "0 9 * * *", time.Date(2024, 11, 3, 0, 0, 0, 0, ny), time.Date(2024, 11, 3, 14, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := ParseCron(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseCron: %v", err)
+			}
+			s.location = ny
+			if got := s.Next(tt.after); !got.Equal(tt.want) {
+				t.Errorf("Next(%v) = %v, want %v", tt.after, got.In(ny), tt.want.In(ny))
+			}
+		})
+	}
+}
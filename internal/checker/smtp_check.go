@@ -0,0 +1,46 @@
+package checker
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"gocheck/internal/models"
+	"gocheck/internal/smtpcheck"
+)
+
+func smtpAddress(check models.Check) string {
+	port := check.Port
+	if port <= 0 {
+		port = smtpcheck.DefaultPort
+	}
+	return net.JoinHostPort(check.Host, strconv.Itoa(port))
+}
+
+// performSMTPCheck expects a 220 greeting and a successful EHLO, plus a STARTTLS
+// handshake when the check requires it. The response time covers the whole session.
+func (e *Engine) performSMTPCheck(check *models.Check, history *models.CheckHistory, start time.Time) {
+	var tlsConfig *tls.Config
+	if check.SMTPStartTLS {
+		tlsConfig = &tls.Config{ServerName: check.Host, InsecureSkipVerify: check.InsecureSkipVerify}
+	}
+
+	result, err := smtpcheck.Check(smtpAddress(*check), time.Duration(check.TimeoutSeconds)*time.Second, tlsConfig)
+	history.ResponseTimeMs = int(time.Since(start).Milliseconds())
+	if err != nil {
+		history.Success = false
+		history.ErrorMessage = err.Error()
+		return
+	}
+	history.ResponseBody = result.Greeting
+
+	if check.ExpectedBanner != "" && !strings.Contains(result.Greeting, check.ExpectedBanner) {
+		history.Success = false
+		history.ErrorMessage = fmt.Sprintf("expected banner '%s', got '%s'", check.ExpectedBanner, result.Greeting)
+		return
+	}
+	history.Success = true
+}
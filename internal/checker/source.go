@@ -0,0 +1,129 @@
+package checker
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"runtime"
+	"time"
+
+	"gocheck/internal/models"
+)
+
+// resolveSourceIP turns a source setting into a local IP. The setting is either an IP
+// address or an interface name, which uses the interface's first IPv4 address, or its
+// first IPv6 address when it has no IPv4 one.
+func resolveSourceIP(source string) (net.IP, error) {
+	if ip := net.ParseIP(source); ip != nil {
+		return ip, nil
+	}
+
+	iface, err := net.InterfaceByName(source)
+	if err != nil {
+		return nil, fmt.Errorf("%q is neither an IP address nor a network interface", source)
+	}
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list addresses of %s: %w", source, err)
+	}
+
+	var v6 net.IP
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || ipNet.IP.IsLinkLocalUnicast() {
+			continue
+		}
+		if ipNet.IP.To4() != nil {
+			return ipNet.IP, nil
+		}
+		if v6 == nil {
+			v6 = ipNet.IP
+		}
+	}
+	if v6 == nil {
+		return nil, fmt.Errorf("interface %s has no usable address", source)
+	}
+	return v6, nil
+}
+
+// ValidateSourceIP checks that source resolves to an address this host can bind to
+func ValidateSourceIP(source string) error {
+	ip, err := resolveSourceIP(source)
+	if err != nil {
+		return err
+	}
+	ln, err := net.Listen("tcp", net.JoinHostPort(ip.String(), "0"))
+	if err != nil {
+		return fmt.Errorf("cannot bind to %s: %w", ip, err)
+	}
+	return ln.Close()
+}
+
+// SetSourceIP sets the default source address (IP or interface name) for HTTP, TCP and
+// ping checks; an empty source leaves the choice to the OS. Call before Start.
+func (e *Engine) SetSourceIP(source string) error {
+	if source != "" {
+		if err := ValidateSourceIP(source); err != nil {
+			return err
+		}
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.sourceIP = source
+	return nil
+}
+
+// checkSourceIP returns the local address a check should originate from, or nil to let
+// the OS choose. A check's own setting overrides the global one.
+func (e *Engine) checkSourceIP(check *models.Check) (net.IP, error) {
+	source := check.SourceIP
+	if source == "" {
+		e.mu.RLock()
+		source = e.sourceIP
+		e.mu.RUnlock()
+	}
+	if source == "" {
+		return nil, nil
+	}
+
+	ip, err := resolveSourceIP(source)
+	if err != nil {
+		return nil, fmt.Errorf("source address: %w", err)
+	}
+	return ip, nil
+}
+
+// newDialer returns a dialer bound to sourceIP when one is set
+func newDialer(timeout time.Duration, sourceIP net.IP) *net.Dialer {
+	dialer := &net.Dialer{Timeout: timeout}
+	if sourceIP != nil {
+		dialer.LocalAddr = &net.TCPAddr{IP: sourceIP}
+	}
+	return dialer
+}
+
+// newHTTPClient returns a client whose connections originate from sourceIP when one is
+// set. Without one the shared default transport is used, keeping its connection pool.
+func newHTTPClient(timeout time.Duration, sourceIP net.IP) *http.Client {
+	client := &http.Client{Timeout: timeout}
+	if sourceIP != nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.DialContext = newDialer(timeout, sourceIP).DialContext
+		transport.DisableKeepAlives = true
+		client.Transport = transport
+	}
+	return client
+}
+
+// pingSourceArgs returns the ping flags selecting the source address
+func pingSourceArgs(sourceIP net.IP) []string {
+	if sourceIP == nil {
+		return nil
+	}
+	switch runtime.GOOS {
+	case "windows", "darwin", "freebsd":
+		return []string{"-S", sourceIP.String()}
+	default:
+		return []string{"-I", sourceIP.String()}
+	}
+}
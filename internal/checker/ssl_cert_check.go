@@ -0,0 +1,69 @@
+package checker
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"gocheck/internal/models"
+)
+
+// defaultCertExpiryThresholdDays is used when a check doesn't set its own threshold
+const defaultCertExpiryThresholdDays = 14
+
+func certAddress(check models.Check) string {
+	port := check.Port
+	if port <= 0 {
+		port = 443
+	}
+	return net.JoinHostPort(check.Host, strconv.Itoa(port))
+}
+
+// performSSLCertCheck fails when the leaf certificate expires within the threshold
+func (e *Engine) performSSLCertCheck(check *models.Check, history *models.CheckHistory, start time.Time) {
+	if check.Host == "" {
+		history.Success = false
+		history.ErrorMessage = "no host specified"
+		history.ResponseTimeMs = int(time.Since(start).Milliseconds())
+		return
+	}
+
+	dialer := &net.Dialer{Timeout: time.Duration(check.TimeoutSeconds) * time.Second}
+	conn, err := tls.DialWithDialer(dialer, "tcp", certAddress(*check), &tls.Config{
+		ServerName:         check.Host,
+		InsecureSkipVerify: check.InsecureSkipVerify,
+	})
+	history.ResponseTimeMs = int(time.Since(start).Milliseconds())
+	if err != nil {
+		history.Success = false
+		history.ErrorMessage = fmt.Sprintf("TLS handshake failed: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	certs := conn.ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		history.Success = false
+		history.ErrorMessage = "no peer certificates presented"
+		return
+	}
+
+	notAfter := certs[0].NotAfter
+	daysLeft := int(time.Until(notAfter).Hours() / 24)
+	history.ResponseBody = fmt.Sprintf("%d days until expiry", daysLeft)
+
+	threshold := check.CertExpiryThresholdDays
+	if threshold <= 0 {
+		threshold = defaultCertExpiryThresholdDays
+	}
+
+	if daysLeft < threshold {
+		history.Success = false
+		history.ErrorMessage = fmt.Sprintf("certificate expires on %s (%d days left, threshold %d)", notAfter.UTC().Format(time.RFC3339), daysLeft, threshold)
+		return
+	}
+
+	history.Success = true
+}
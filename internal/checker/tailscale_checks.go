@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"gocheck/internal/models"
+	"gocheck/internal/statuscode"
 
 	tailscale "tailscale.com/client/tailscale/v2"
 	"tailscale.com/tsnet"
@@ -34,6 +35,16 @@ func getTsnetServer() (*tsnet.Server, error) {
 	return tsnetServer, tsnetInitErr
 }
 
+// TailscaleDial connects to addr over the tailnet, through the same tsnet node the
+// Tailscale service checks use
+func TailscaleDial(ctx context.Context, network, addr string) (net.Conn, error) {
+	srv, err := getTsnetServer()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize Tailscale: %w", err)
+	}
+	return srv.Dial(ctx, network, addr)
+}
+
 // performTailscaleCheck checks if a Tailscale device is online
 func (e *Engine) performTailscaleCheck(check *models.Check, history *models.CheckHistory, start time.Time) {
 	if check.TailscaleDeviceID == "" {
@@ -173,29 +184,12 @@ func (e *Engine) performTailscaleServiceCheck(check *models.Check, history *mode
 
 		history.StatusCode = resp.StatusCode
 
-		expectedStatusCodes := check.ExpectedStatusCodes
-		if len(expectedStatusCodes) == 0 {
-			expectedStatusCodes = []int{200}
-		}
-
-		success := false
-		for _, expectedCode := range expectedStatusCodes {
-			if resp.StatusCode == expectedCode {
-				success = true
-				break
-			}
-		}
-
-		if !success && resp.StatusCode >= 200 && resp.StatusCode < 400 {
-			success = true
-		}
-
-		if success {
+		if statuscode.Match(resp.StatusCode, check.ExpectedStatusCodes) {
 			history.Success = true
 			history.ResponseBody = fmt.Sprintf("%s service responding on %s:%d", protocol, check.TailscaleServiceHost, check.TailscaleServicePort)
 		} else {
 			history.Success = false
-			history.ErrorMessage = fmt.Sprintf("unexpected status code: %d (expected: %v)", resp.StatusCode, expectedStatusCodes)
+			history.ErrorMessage = fmt.Sprintf("unexpected status code: %d (expected: %s)", resp.StatusCode, statuscode.Format(check.ExpectedStatusCodes))
 		}
 		return
 	}
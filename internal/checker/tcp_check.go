@@ -0,0 +1,123 @@
+package checker
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"gocheck/internal/models"
+)
+
+// performTCPCheck succeeds when a TCP connection to host:port can be opened. When an
+// expected banner is set, the first line sent by the server must contain it.
+func (e *Engine) performTCPCheck(check *models.Check, history *models.CheckHistory, start time.Time) {
+	if check.Host == "" {
+		history.Success = false
+		history.ErrorMessage = "no host specified"
+		history.ResponseTimeMs = int(time.Since(start).Milliseconds())
+		return
+	}
+
+	if check.Port <= 0 || check.Port > 65535 {
+		history.Success = false
+		history.ErrorMessage = "invalid port"
+		history.ResponseTimeMs = int(time.Since(start).Milliseconds())
+		return
+	}
+
+	sourceIP, err := e.checkSourceIP(check)
+	if err != nil {
+		history.Success = false
+		history.ErrorMessage = err.Error()
+		history.ResponseTimeMs = int(time.Since(start).Milliseconds())
+		return
+	}
+
+	timeout := time.Duration(check.TimeoutSeconds) * time.Second
+	target := net.JoinHostPort(check.Host, strconv.Itoa(check.Port))
+
+	conn, err := newDialer(timeout, sourceIP).Dial("tcp", target)
+	history.ResponseTimeMs = int(time.Since(start).Milliseconds())
+	if check.ExpectUnreachable {
+		if err == nil {
+			conn.Close()
+			history.Success = false
+			history.ErrorMessage = fmt.Sprintf("port %d is open but expected to be closed", check.Port)
+			return
+		}
+		closed, reason := classifyDialError(err)
+		history.Success = closed
+		if closed {
+			history.ResponseBody = fmt.Sprintf("%s is closed: %s", target, reason)
+		} else {
+			history.ErrorMessage = reason
+		}
+		return
+	}
+	if err != nil {
+		history.Success = false
+		history.ErrorMessage = fmt.Sprintf("connection failed: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	if check.ExpectedBanner == "" {
+		history.Success = true
+		history.ResponseBody = fmt.Sprintf("TCP connection successful to %s", target)
+		return
+	}
+
+	banner, err := readBanner(conn, start.Add(timeout))
+	if err != nil {
+		history.Success = false
+		history.ErrorMessage = fmt.Sprintf("failed to read banner: %v", err)
+		return
+	}
+
+	history.ResponseBody = banner
+	if strings.Contains(banner, check.ExpectedBanner) {
+		history.Success = true
+	} else {
+		history.Success = false
+		history.ErrorMessage = fmt.Sprintf("expected banner '%s', got '%s'", check.ExpectedBanner, banner)
+	}
+}
+
+// classifyDialError reports whether a failed dial proves the port is closed. A refusal
+// (RST) or an ICMP unreachable is definitive; a timeout is not, since a firewall that
+// silently drops packets looks the same and says nothing about the port itself.
+func classifyDialError(err error) (closed bool, reason string) {
+	switch {
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return true, "connection refused"
+	case errors.Is(err, syscall.EHOSTUNREACH), errors.Is(err, syscall.ENETUNREACH):
+		return true, "host unreachable"
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return false, "no response before timeout (filtered or dropped); cannot confirm the port is closed"
+	}
+	return false, fmt.Sprintf("connection failed: %v", err)
+}
+
+// readBanner reads what the server sends right after connecting, up to the first newline
+func readBanner(conn net.Conn, deadline time.Time) (string, error) {
+	conn.SetReadDeadline(deadline)
+
+	buf := make([]byte, 1024)
+	n, err := conn.Read(buf)
+	if n == 0 && err != nil {
+		return "", err
+	}
+
+	banner := string(buf[:n])
+	if idx := strings.IndexAny(banner, "\r\n"); idx >= 0 {
+		banner = banner[:idx]
+	}
+	return banner, nil
+}
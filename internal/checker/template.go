@@ -0,0 +1,98 @@
+package checker
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"gocheck/internal/models"
+)
+
+// templateVars holds the values available to templated check fields,
+// e.g. https://example.com/reports/{{.Date "2006-01-02"}}
+type templateVars struct {
+	now time.Time
+}
+
+// Now returns the current time in RFC3339 format
+func (v templateVars) Now() string {
+	return v.now.Format(time.RFC3339)
+}
+
+// Date formats the current date using the given Go layout (defaults to 2006-01-02)
+func (v templateVars) Date(layout ...string) string {
+	if len(layout) == 0 || layout[0] == "" {
+		return v.now.Format("2006-01-02")
+	}
+	return v.now.Format(layout[0])
+}
+
+// UnixTimestamp returns the current time in seconds since the Unix epoch
+func (v templateVars) UnixTimestamp() int64 {
+	return v.now.Unix()
+}
+
+func isTemplate(s string) bool {
+	return strings.Contains(s, "{{")
+}
+
+// ValidateTemplate parses a templated field and performs a trial expansion so that
+// invalid templates are rejected when the check is saved rather than at execution time.
+func ValidateTemplate(s string) error {
+	if !isTemplate(s) {
+		return nil
+	}
+	if _, err := ExpandTemplate(s, time.Now().UTC()); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ExpandTemplate expands date/time variables in s. Plain strings are returned unchanged.
+func ExpandTemplate(s string, now time.Time) (string, error) {
+	if !isTemplate(s) {
+		return s, nil
+	}
+
+	tmpl, err := template.New("check").Parse(s)
+	if err != nil {
+		return "", fmt.Errorf("invalid template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, templateVars{now: now}); err != nil {
+		return "", fmt.Errorf("invalid template: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// ExpandedRequest holds the templated HTTP fields of a check after expansion
+type ExpandedRequest struct {
+	URL     string
+	Body    string
+	Headers map[string]string
+}
+
+// ExpandCheckRequest expands the URL, request body and header values of a check
+func ExpandCheckRequest(check models.Check, now time.Time) (ExpandedRequest, error) {
+	var out ExpandedRequest
+	var err error
+
+	if out.URL, err = ExpandTemplate(check.URL, now); err != nil {
+		return out, err
+	}
+	if out.Body, err = ExpandTemplate(check.RequestBody, now); err != nil {
+		return out, err
+	}
+	if len(check.Headers) > 0 {
+		out.Headers = make(map[string]string, len(check.Headers))
+		for key, value := range check.Headers {
+			if out.Headers[key], err = ExpandTemplate(value, now); err != nil {
+				return out, err
+			}
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,53 @@
+package checker
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestExpandTemplate(t *testing.T) {
+	now := time.Date(2024, 3, 7, 15, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"plain URL", "https://example.com/health?x=1", "https://example.com/health?x=1"},
+		{"empty", "", ""},
+		{"default date", "https://example.com/reports/{{.Date}}", "https://example.com/reports/2024-03-07"},
+		{"date layout", `https://example.com/{{.Date "2006/01/02"}}`, "https://example.com/2024/03/07"},
+		{"now", "{{.Now}}", "2024-03-07T15:04:05Z"},
+		{"unix timestamp", "ts={{.UnixTimestamp}}", "ts=" + strconv.FormatInt(now.Unix(), 10)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExpandTemplate(tt.in, now)
+			if err != nil {
+				t.Fatalf("ExpandTemplate(%q) returned error: %v", tt.in, err)
+			}
+			if got != tt.want {
+				t.Errorf("ExpandTemplate(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandTemplateErrors(t *testing.T) {
+	for _, in := range []string{"https://example.com/{{.Date", "https://example.com/{{.Foo}}"} {
+		if _, err := ExpandTemplate(in, time.Now()); err == nil {
+			t.Errorf("ExpandTemplate(%q) expected error", in)
+		}
+		if err := ValidateTemplate(in); err == nil {
+			t.Errorf("ValidateTemplate(%q) expected error", in)
+		}
+	}
+}
+
+func TestValidateTemplatePlain(t *testing.T) {
+	if err := ValidateTemplate("https://example.com/"); err != nil {
+		t.Errorf("ValidateTemplate on plain URL returned error: %v", err)
+	}
+}
@@ -0,0 +1,78 @@
+// Package compare evaluates the assertion operators that JSON HTTP and database checks
+// apply between the value they read and the value they expect.
+package compare
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Assertion operators; an empty operator means Eq
+const (
+	Eq       = "eq"
+	Ne       = "ne"
+	Gt       = "gt"
+	Lt       = "lt"
+	Gte      = "gte"
+	Lte      = "lte"
+	Contains = "contains"
+)
+
+// symbols are the operators as written in failure messages
+var symbols = map[string]string{
+	Eq: "==", Ne: "!=", Gt: ">", Lt: "<", Gte: ">=", Lte: "<=", Contains: "contains",
+}
+
+// Valid reports whether op is a known operator or empty
+func Valid(op string) bool {
+	_, ok := symbols[op]
+	return ok || op == ""
+}
+
+// Match reports whether "actual op expected" holds. Both sides are compared as numbers
+// when both parse as one, so "9" < "10", and otherwise as strings. Contains is always
+// a substring test.
+func Match(actual, op, expected string) bool {
+	if op == Contains {
+		return strings.Contains(actual, expected)
+	}
+
+	cmp := strings.Compare(actual, expected)
+	if a, err := strconv.ParseFloat(strings.TrimSpace(actual), 64); err == nil {
+		if e, err := strconv.ParseFloat(strings.TrimSpace(expected), 64); err == nil {
+			switch {
+			case a < e:
+				cmp = -1
+			case a > e:
+				cmp = 1
+			default:
+				cmp = 0
+			}
+		}
+	}
+
+	switch op {
+	case "", Eq:
+		return cmp == 0
+	case Ne:
+		return cmp != 0
+	case Gt:
+		return cmp > 0
+	case Lt:
+		return cmp < 0
+	case Gte:
+		return cmp >= 0
+	case Lte:
+		return cmp <= 0
+	}
+	return false
+}
+
+// Mismatch describes a failed Match
+func Mismatch(actual, op, expected string) string {
+	if op == "" || op == Eq {
+		return fmt.Sprintf("expected '%s', got '%s'", expected, actual)
+	}
+	return fmt.Sprintf("expected value %s '%s', got '%s'", symbols[op], expected, actual)
+}
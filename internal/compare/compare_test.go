@@ -0,0 +1,46 @@
+package compare
+
+import "testing"
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		actual   string
+		op       string
+		expected string
+		want     bool
+	}{
+		{"ok", "", "ok", true},
+		{"ok", Eq, "OK", false},
+		{"1.0", Eq, "1", true},
+		{"ok", Ne, "down", true},
+		{"999", Lt, "1000", true},
+		{"1000", Lt, "1000", false},
+		{"9", Lt, "10", true},
+		{"5", Lte, "5", true},
+		{" 6 ", Lte, "5", false},
+		{"-2.5", Gt, "-3", true},
+		{"3", Gte, "3.0", true},
+		{"b", Gt, "a", true},
+		{"abc", Lt, "10", false},
+		{"replica lagging", Contains, "lag", true},
+		{"10", Contains, "1.0", false},
+		{"1", "between", "1", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.actual+" "+tt.op+" "+tt.expected, func(t *testing.T) {
+			if got := Match(tt.actual, tt.op, tt.expected); got != tt.want {
+				t.Errorf("Match(%q, %q, %q) = %v, want %v", tt.actual, tt.op, tt.expected, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMismatch(t *testing.T) {
+	if got, want := Mismatch("2", "", "1"), "expected '1', got '2'"; got != want {
+		t.Errorf("Mismatch() = %q, want %q", got, want)
+	}
+	if got, want := Mismatch("1500", Lt, "1000"), "expected value < '1000', got '1500'"; got != want {
+		t.Errorf("Mismatch() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,36 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// ErrConflict is wrapped by every error caused by a unique constraint, so callers
+// can map them all to 409 Conflict with errors.Is
+var ErrConflict = errors.New("already exists")
+
+var (
+	ErrDuplicateTag        = fmt.Errorf("tag name %w", ErrConflict)
+	ErrDuplicateUser       = fmt.Errorf("username %w", ErrConflict)
+	ErrDuplicateCredential = fmt.Errorf("credential %w", ErrConflict)
+)
+
+// pgUniqueViolation is the Postgres SQLSTATE for unique_violation
+const pgUniqueViolation = "23505"
+
+// isUniqueViolation reports whether err comes from a unique constraint. Postgres
+// errors are matched on their SQLSTATE; other drivers such as SQLite only expose
+// the message, so fall back to matching that.
+func isUniqueViolation(err error) bool {
+	if err == nil {
+		return false
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == pgUniqueViolation
+	}
+	return strings.Contains(err.Error(), "UNIQUE constraint failed")
+}
@@ -0,0 +1,40 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+func TestIsUniqueViolation(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"postgres unique violation", &pq.Error{Code: "23505"}, true},
+		{"wrapped postgres unique violation", fmt.Errorf("insert: %w", &pq.Error{Code: "23505"}), true},
+		{"postgres foreign key violation", &pq.Error{Code: "23503"}, false},
+		{"sqlite unique violation", errors.New("UNIQUE constraint failed: tags.name"), true},
+		{"unrelated error", errors.New("connection refused"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isUniqueViolation(tt.err); got != tt.want {
+				t.Errorf("isUniqueViolation(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDuplicateErrorsAreConflicts(t *testing.T) {
+	for _, err := range []error{ErrDuplicateTag, ErrDuplicateUser, ErrDuplicateCredential} {
+		if !errors.Is(err, ErrConflict) {
+			t.Errorf("%v does not wrap ErrConflict", err)
+		}
+	}
+}
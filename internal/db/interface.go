@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"time"
 
 	"gocheck/internal/models"
@@ -9,24 +10,43 @@ import (
 // DB defines the interface that all database implementations must satisfy
 type DB interface {
 	Close() error
+	// Ping checks that the database is reachable, for readiness probes
+	Ping(ctx context.Context) error
 
 	// Check operations
 	GetAllChecks() ([]models.Check, error)
+	GetChecksFiltered(filter models.CheckFilter) ([]models.Check, int, error)
 	GetCheck(id int64) (*models.Check, error)
 	CreateCheck(c *models.Check) error
 	UpdateCheck(c *models.Check) error
 	DeleteCheck(id int64) error
+	SetChecksEnabled(ids []int64, enabled bool) ([]int64, error)
+	DeleteChecks(ids []int64) ([]int64, error)
 	GetEnabledChecks() ([]models.Check, error)
 
 	// History operations
 	AddHistory(h *models.CheckHistory) error
 	GetCheckHistory(checkID int64, since *time.Time, limit int) ([]models.CheckHistory, error)
+	GetCheckHistoryContext(ctx context.Context, checkID int64, since *time.Time, limit int) ([]models.CheckHistory, error)
+	StreamCheckHistory(checkID int64, since *time.Time, fn func(h *models.CheckHistory) error) error
 	GetCheckHistoryAggregated(checkID int64, since *time.Time, bucketMinutes int, limit int) ([]models.CheckHistory, error)
+	GetCheckHistoryAggregatedContext(ctx context.Context, checkID int64, since *time.Time, bucketMinutes int, limit int) ([]models.CheckHistory, error)
+	RollupHistory(resolutionMinutes int) error
+	GetCheckHistoryRollup(checkID int64, since *time.Time, bucketMinutes int, limit int) ([]models.CheckHistory, error)
 	GetLastStatus(checkID int64) (*models.CheckHistory, error)
+	GetLastStatusContext(ctx context.Context, checkID int64) (*models.CheckHistory, error)
 	GetLastStatusByRegion(checkID int64) (map[string]*models.CheckHistory, error)
+	GetLastStatusByRegionForChecks(checkIDs []int64) (map[int64]map[string]*models.CheckHistory, error)
+	GetStatusEvents(since *time.Time, filter models.EventFilter) ([]models.StatusEvent, error)
+	GetLatencyPercentiles(checkID int64, since *time.Time) (*models.LatencyPercentiles, error)
+	OpenIncident(incident *models.Incident) error
+	ResolveIncident(checkID int64, resolvedAt time.Time) error
+	GetIncidents(since *time.Time, filter models.EventFilter) ([]models.Incident, error)
+	DeleteHistoryBefore(t time.Time) (int64, error)
+	PruneHistory(defaultDays int) (int64, error)
 
 	// Stats operations
-	GetStats(since *time.Time) (*models.Stats, error)
+	GetStats(since *time.Time, tags models.TagFilter) (*models.Stats, error)
 
 	// Settings operations
 	GetSetting(key string) (string, error)
@@ -50,12 +70,32 @@ type DB interface {
 	DeleteTag(id int64) error
 	GetCheckTags(checkID int64) ([]models.Tag, error)
 	SetCheckTags(checkID int64, tagIDs []int64) error
+	UpdateTagChecks(tagID int64, assign, unassign []int64) (assigned, unassigned int64, err error)
+
+	// Notifier operations
+	GetAllNotifiers() ([]models.NotifierConfig, error)
+	GetNotifier(id int64) (*models.NotifierConfig, error)
+	CreateNotifier(n *models.NotifierConfig) error
+	UpdateNotifier(n *models.NotifierConfig) error
+	DeleteNotifier(id int64) error
+
+	// Audit log operations
+	CreateAuditEntry(entry *models.AuditEntry) error
+	GetAuditEntries(filter models.AuditFilter) ([]models.AuditEntry, int, error)
+
+	// Region assignment operations
+	GetCheckRegions(checkID int64) ([]string, error)
+	GetAllCheckRegions() (map[int64][]string, error)
+	SetCheckRegions(checkID int64, regions []string) error
 
 	// User operations
 	GetUserByUsername(username string) (*models.User, error)
 	GetUserByID(id int64) (*models.User, error)
 	CreateUser(u *models.User) error
 	HasUsers() (bool, error)
+	GetAllUsers() ([]models.User, error)
+	UpdateUser(u *models.User) error
+	DeleteUser(id int64) error
 
 	// API Key operations
 	CreateAPIKey(key *models.APIKey) error
@@ -87,4 +127,5 @@ type DB interface {
 	GetProbeByID(id int64) (*models.Probe, error)
 	DeleteProbe(id int64) error
 	RegenerateProbeToken(id int64) (string, error)
+	UpdateProbeLocation(id int64, location models.ProbeLocation) error
 }
@@ -0,0 +1,31 @@
+package db
+
+import (
+	"strconv"
+)
+
+// HistoryRetentionSetting is the settings key holding the global history retention
+const HistoryRetentionSetting = "history_retention_days"
+
+// DefaultHistoryRetentionDays applies until history_retention_days is set
+const DefaultHistoryRetentionDays = 90
+
+// Resolutions of the history rollup tables, in minutes
+const (
+	RollupHourly = 60
+	RollupDaily  = 24 * 60
+)
+
+// HistoryRetentionDays returns the global history retention in days. 0 keeps history
+// forever; checks may still override it with their own retention_days.
+func (d *Database) HistoryRetentionDays() int {
+	value, err := d.GetSetting(HistoryRetentionSetting)
+	if err != nil || value == "" {
+		return DefaultHistoryRetentionDays
+	}
+	days, err := strconv.Atoi(value)
+	if err != nil || days < 0 {
+		return DefaultHistoryRetentionDays
+	}
+	return days
+}
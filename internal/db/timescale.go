@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"crypto/rand"
 	"crypto/sha256"
 	"database/sql"
@@ -13,7 +14,8 @@ import (
 
 	"gocheck/internal/models"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
+	"github.com/uptrace/opentelemetry-go-extra/otelsql"
 )
 
 type TimescaleDB struct {
@@ -55,7 +57,8 @@ func normalizeTimescaleConnString(connString string) string {
 
 func NewTimescaleDB(connString string) (*TimescaleDB, error) {
 	normalizedConnString := normalizeTimescaleConnString(connString)
-	db, err := sql.Open("postgres", normalizedConnString)
+	// Every query gets a span, which is a no-op unless tracing is configured
+	db, err := otelsql.Open("postgres", normalizedConnString, otelsql.WithDBSystem("postgresql"))
 	if err != nil {
 		return nil, fmt.Errorf("failed to open timescale connection: %w", err)
 	}
@@ -81,6 +84,10 @@ func (d *TimescaleDB) Close() error {
 	return d.db.Close()
 }
 
+func (d *TimescaleDB) Ping(ctx context.Context) error {
+	return d.db.PingContext(ctx)
+}
+
 func (d *TimescaleDB) initSchema() error {
 	schema := `
 	-- Groups table
@@ -110,7 +117,7 @@ func (d *TimescaleDB) initSchema() error {
 		retry_delay_seconds INTEGER NOT NULL DEFAULT 5,
 		enabled BOOLEAN NOT NULL DEFAULT true,
 		created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
-		expected_status_codes JSONB DEFAULT '[200]',
+		expected_status_codes JSONB DEFAULT '[]',
 		method TEXT DEFAULT 'GET',
 		json_path TEXT,
 		expected_json_value TEXT,
@@ -162,6 +169,44 @@ func (d *TimescaleDB) initSchema() error {
 		FOREIGN KEY (check_id) REFERENCES checks(id) ON DELETE CASCADE
 	);
 
+	-- Hourly and daily summaries of check_history for long-range queries
+	CREATE TABLE IF NOT EXISTS check_history_rollups (
+		check_id BIGINT NOT NULL REFERENCES checks(id) ON DELETE CASCADE,
+		region TEXT NOT NULL,
+		resolution_minutes INTEGER NOT NULL,
+		bucket_start TIMESTAMP WITH TIME ZONE NOT NULL,
+		sample_count INTEGER NOT NULL,
+		success_count INTEGER NOT NULL,
+		min_response_time_ms INTEGER NOT NULL,
+		max_response_time_ms INTEGER NOT NULL,
+		total_response_time_ms BIGINT NOT NULL,
+		PRIMARY KEY (check_id, resolution_minutes, bucket_start, region)
+	);
+
+	-- Notification channels; config holds the options of each type
+	CREATE TABLE IF NOT EXISTS notifiers (
+		id BIGSERIAL PRIMARY KEY,
+		type TEXT NOT NULL,
+		name TEXT NOT NULL,
+		config JSONB NOT NULL DEFAULT '{}',
+		enabled BOOLEAN NOT NULL DEFAULT true,
+		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+	);
+
+	-- Configuration changes made through the API. user_id has no foreign key so
+	-- entries outlive deleted users.
+	CREATE TABLE IF NOT EXISTS audit_log (
+		id BIGSERIAL PRIMARY KEY,
+		user_id BIGINT,
+		username TEXT NOT NULL DEFAULT '',
+		action TEXT NOT NULL,
+		target_type TEXT NOT NULL,
+		target_id BIGINT,
+		details JSONB,
+		created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+	);
+	CREATE INDEX IF NOT EXISTS idx_audit_log_created_at ON audit_log(created_at DESC);
+
 	-- Check tags junction table
 	CREATE TABLE IF NOT EXISTS check_tags (
 		check_id BIGINT NOT NULL,
@@ -171,6 +216,24 @@ func (d *TimescaleDB) initSchema() error {
 		FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE
 	);
 
+	-- Probe regions a check is dispatched to instead of running on the server
+	CREATE TABLE IF NOT EXISTS check_regions (
+		check_id BIGINT NOT NULL REFERENCES checks(id) ON DELETE CASCADE,
+		region TEXT NOT NULL,
+		PRIMARY KEY (check_id, region)
+	);
+
+	-- Downtime incidents, at most one unresolved per check
+	CREATE TABLE IF NOT EXISTS incidents (
+		id BIGSERIAL PRIMARY KEY,
+		check_id BIGINT NOT NULL REFERENCES checks(id) ON DELETE CASCADE,
+		cause TEXT NOT NULL DEFAULT '',
+		started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+		resolved_at TIMESTAMP WITH TIME ZONE
+	);
+	CREATE INDEX IF NOT EXISTS idx_incidents_check_started ON incidents(check_id, started_at DESC);
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_incidents_unresolved ON incidents(check_id) WHERE resolved_at IS NULL;
+
 	-- Settings table
 	CREATE TABLE IF NOT EXISTS settings (
 		key TEXT PRIMARY KEY,
@@ -190,6 +253,7 @@ func (d *TimescaleDB) initSchema() error {
 		id BIGSERIAL PRIMARY KEY,
 		username TEXT NOT NULL UNIQUE,
 		password_hash TEXT NOT NULL,
+		role TEXT NOT NULL DEFAULT 'admin',
 		created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
 	);
 
@@ -351,6 +415,230 @@ func (d *TimescaleDB) initSchema() error {
 					   WHERE table_name='checks' AND column_name='tailscale_service_path') THEN
 			ALTER TABLE checks ADD COLUMN tailscale_service_path TEXT;
 		END IF;
+
+		IF NOT EXISTS (SELECT 1 FROM information_schema.columns 
+					   WHERE table_name='checks' AND column_name='public') THEN
+			ALTER TABLE checks ADD COLUMN public BOOLEAN NOT NULL DEFAULT false;
+		END IF;
+
+		IF NOT EXISTS (SELECT 1 FROM information_schema.columns 
+					   WHERE table_name='checks' AND column_name='port') THEN
+			ALTER TABLE checks ADD COLUMN port INTEGER;
+		END IF;
+
+		IF NOT EXISTS (SELECT 1 FROM information_schema.columns 
+					   WHERE table_name='checks' AND column_name='expected_banner') THEN
+			ALTER TABLE checks ADD COLUMN expected_banner TEXT;
+		END IF;
+
+		IF NOT EXISTS (SELECT 1 FROM information_schema.columns 
+					   WHERE table_name='checks' AND column_name='headers') THEN
+			ALTER TABLE checks ADD COLUMN headers JSONB NOT NULL DEFAULT '{}';
+		END IF;
+
+		IF NOT EXISTS (SELECT 1 FROM information_schema.columns 
+					   WHERE table_name='checks' AND column_name='result_webhook_url') THEN
+			ALTER TABLE checks ADD COLUMN result_webhook_url TEXT;
+		END IF;
+
+		IF NOT EXISTS (SELECT 1 FROM information_schema.columns 
+					   WHERE table_name='checks' AND column_name='request_body') THEN
+			ALTER TABLE checks ADD COLUMN request_body TEXT;
+		END IF;
+
+		IF NOT EXISTS (SELECT 1 FROM information_schema.columns 
+					   WHERE table_name='checks' AND column_name='content_type') THEN
+			ALTER TABLE checks ADD COLUMN content_type TEXT;
+		END IF;
+
+		IF NOT EXISTS (SELECT 1 FROM information_schema.columns 
+					   WHERE table_name='checks' AND column_name='response_keyword') THEN
+			ALTER TABLE checks ADD COLUMN response_keyword TEXT;
+		END IF;
+
+		IF NOT EXISTS (SELECT 1 FROM information_schema.columns 
+					   WHERE table_name='checks' AND column_name='response_keyword_mode') THEN
+			ALTER TABLE checks ADD COLUMN response_keyword_mode TEXT;
+		END IF;
+
+		IF NOT EXISTS (SELECT 1 FROM information_schema.columns 
+					   WHERE table_name='checks' AND column_name='ntp_max_offset_ms') THEN
+			ALTER TABLE checks ADD COLUMN ntp_max_offset_ms INTEGER;
+		END IF;
+
+		IF NOT EXISTS (SELECT 1 FROM information_schema.columns 
+					   WHERE table_name='checks' AND column_name='cert_expiry_threshold_days') THEN
+			ALTER TABLE checks ADD COLUMN cert_expiry_threshold_days INTEGER;
+		END IF;
+
+		IF NOT EXISTS (SELECT 1 FROM information_schema.columns 
+					   WHERE table_name='checks' AND column_name='insecure_skip_verify') THEN
+			ALTER TABLE checks ADD COLUMN insecure_skip_verify BOOLEAN NOT NULL DEFAULT false;
+		END IF;
+
+		IF NOT EXISTS (SELECT 1 FROM information_schema.columns 
+					   WHERE table_name='checks' AND column_name='forbidden_body_keyword') THEN
+			ALTER TABLE checks ADD COLUMN forbidden_body_keyword TEXT;
+		END IF;
+
+		IF NOT EXISTS (SELECT 1 FROM information_schema.columns 
+					   WHERE table_name='checks' AND column_name='cron_expression') THEN
+			ALTER TABLE checks ADD COLUMN cron_expression TEXT;
+		END IF;
+
+		IF NOT EXISTS (SELECT 1 FROM information_schema.columns 
+					   WHERE table_name='checks' AND column_name='expect_unreachable') THEN
+			ALTER TABLE checks ADD COLUMN expect_unreachable BOOLEAN NOT NULL DEFAULT false;
+		END IF;
+
+		IF NOT EXISTS (SELECT 1 FROM information_schema.columns 
+					   WHERE table_name='checks' AND column_name='confirmation_threshold') THEN
+			ALTER TABLE checks ADD COLUMN confirmation_threshold INTEGER NOT NULL DEFAULT 1;
+		END IF;
+
+		IF NOT EXISTS (SELECT 1 FROM information_schema.columns 
+					   WHERE table_name='checks' AND column_name='max_response_time_ms') THEN
+			ALTER TABLE checks ADD COLUMN max_response_time_ms INTEGER NOT NULL DEFAULT 0;
+		END IF;
+
+		IF NOT EXISTS (SELECT 1 FROM information_schema.columns 
+					   WHERE table_name='checks' AND column_name='retention_days') THEN
+			ALTER TABLE checks ADD COLUMN retention_days INTEGER NOT NULL DEFAULT 0;
+		END IF;
+
+		IF NOT EXISTS (SELECT 1 FROM information_schema.columns 
+					   WHERE table_name='checks' AND column_name='bypass_cache') THEN
+			ALTER TABLE checks ADD COLUMN bypass_cache BOOLEAN NOT NULL DEFAULT FALSE;
+		END IF;
+
+		IF NOT EXISTS (SELECT 1 FROM information_schema.columns 
+					   WHERE table_name='checks' AND column_name='redis_conn_string') THEN
+			ALTER TABLE checks ADD COLUMN redis_conn_string TEXT;
+			ALTER TABLE checks ADD COLUMN redis_key TEXT;
+			ALTER TABLE checks ADD COLUMN expected_redis_value TEXT;
+		END IF;
+
+		IF NOT EXISTS (SELECT 1 FROM information_schema.columns 
+					   WHERE table_name='checks' AND column_name='source_ip') THEN
+			ALTER TABLE checks ADD COLUMN source_ip TEXT;
+		END IF;
+
+		IF NOT EXISTS (SELECT 1 FROM information_schema.columns 
+					   WHERE table_name='checks' AND column_name='mysql_conn_string') THEN
+			ALTER TABLE checks ADD COLUMN mysql_conn_string TEXT;
+		END IF;
+
+		IF NOT EXISTS (SELECT 1 FROM information_schema.columns 
+					   WHERE table_name='checks' AND column_name='dns_resolver') THEN
+			ALTER TABLE checks ADD COLUMN dns_resolver TEXT;
+		END IF;
+		IF NOT EXISTS (SELECT 1 FROM information_schema.columns 
+					   WHERE table_name='checks' AND column_name='ping_count') THEN
+			ALTER TABLE checks ADD COLUMN ping_count INTEGER NOT NULL DEFAULT 0;
+		END IF;
+		IF NOT EXISTS (SELECT 1 FROM information_schema.columns 
+					   WHERE table_name='checks' AND column_name='max_packet_loss') THEN
+			ALTER TABLE checks ADD COLUMN max_packet_loss INTEGER NOT NULL DEFAULT 0;
+		END IF;
+		IF NOT EXISTS (SELECT 1 FROM information_schema.columns 
+					   WHERE table_name='checks' AND column_name='auth_type') THEN
+			ALTER TABLE checks ADD COLUMN auth_type TEXT;
+		END IF;
+		IF NOT EXISTS (SELECT 1 FROM information_schema.columns 
+					   WHERE table_name='checks' AND column_name='auth_username') THEN
+			ALTER TABLE checks ADD COLUMN auth_username TEXT;
+		END IF;
+		IF NOT EXISTS (SELECT 1 FROM information_schema.columns 
+					   WHERE table_name='checks' AND column_name='auth_password') THEN
+			ALTER TABLE checks ADD COLUMN auth_password TEXT;
+		END IF;
+		IF NOT EXISTS (SELECT 1 FROM information_schema.columns 
+					   WHERE table_name='checks' AND column_name='auth_token') THEN
+			ALTER TABLE checks ADD COLUMN auth_token TEXT;
+		END IF;
+		IF NOT EXISTS (SELECT 1 FROM information_schema.columns 
+					   WHERE table_name='checks' AND column_name='follow_redirects') THEN
+			ALTER TABLE checks ADD COLUMN follow_redirects BOOLEAN NOT NULL DEFAULT TRUE;
+		END IF;
+		IF NOT EXISTS (SELECT 1 FROM information_schema.columns 
+					   WHERE table_name='checks' AND column_name='assertion_operator') THEN
+			ALTER TABLE checks ADD COLUMN assertion_operator TEXT;
+		END IF;
+		IF NOT EXISTS (SELECT 1 FROM information_schema.columns 
+					   WHERE table_name='checks' AND column_name='grpc_service') THEN
+			ALTER TABLE checks ADD COLUMN grpc_service TEXT;
+			ALTER TABLE checks ADD COLUMN grpc_tls BOOLEAN NOT NULL DEFAULT FALSE;
+		END IF;
+		IF NOT EXISTS (SELECT 1 FROM information_schema.columns 
+					   WHERE table_name='checks' AND column_name='smtp_starttls') THEN
+			ALTER TABLE checks ADD COLUMN smtp_starttls BOOLEAN NOT NULL DEFAULT FALSE;
+		END IF;
+		IF NOT EXISTS (SELECT 1 FROM information_schema.columns 
+					   WHERE table_name='checks' AND column_name='expected_header_name') THEN
+			ALTER TABLE checks ADD COLUMN expected_header_name TEXT;
+			ALTER TABLE checks ADD COLUMN expected_header_value TEXT;
+			ALTER TABLE checks ADD COLUMN expected_header_mode TEXT;
+		END IF;
+		IF NOT EXISTS (SELECT 1 FROM information_schema.columns 
+					   WHERE table_name='checks' AND column_name='mongo_conn_string') THEN
+			ALTER TABLE checks ADD COLUMN mongo_conn_string TEXT;
+		END IF;
+		IF NOT EXISTS (SELECT 1 FROM information_schema.columns 
+					   WHERE table_name='checks' AND column_name='renotify_interval_minutes') THEN
+			ALTER TABLE checks ADD COLUMN renotify_interval_minutes INTEGER NOT NULL DEFAULT 0;
+		END IF;
+		IF NOT EXISTS (SELECT 1 FROM information_schema.columns 
+					   WHERE table_name='checks' AND column_name='min_response_bytes') THEN
+			ALTER TABLE checks ADD COLUMN min_response_bytes INTEGER NOT NULL DEFAULT 0;
+			ALTER TABLE checks ADD COLUMN max_response_bytes INTEGER NOT NULL DEFAULT 0;
+		END IF;
+		IF NOT EXISTS (SELECT 1 FROM information_schema.columns 
+					   WHERE table_name='checks' AND column_name='expected_http_version') THEN
+			ALTER TABLE checks ADD COLUMN expected_http_version TEXT;
+		END IF;
+		IF NOT EXISTS (SELECT 1 FROM information_schema.columns 
+					   WHERE table_name='checks' AND column_name='proxy_url') THEN
+			ALTER TABLE checks ADD COLUMN proxy_url TEXT;
+		END IF;
+		IF NOT EXISTS (SELECT 1 FROM information_schema.columns 
+					   WHERE table_name='checks' AND column_name='trigger_secret') THEN
+			ALTER TABLE checks ADD COLUMN trigger_secret TEXT;
+		END IF;
+		IF NOT EXISTS (SELECT 1 FROM information_schema.columns 
+					   WHERE table_name='checks' AND column_name='backoff_on_failure') THEN
+			ALTER TABLE checks ADD COLUMN backoff_on_failure BOOLEAN NOT NULL DEFAULT FALSE;
+		END IF;
+		IF NOT EXISTS (SELECT 1 FROM information_schema.columns 
+					   WHERE table_name='checks' AND column_name='expected_row_count') THEN
+			ALTER TABLE checks ADD COLUMN expected_row_count INTEGER;
+		END IF;
+		IF NOT EXISTS (SELECT 1 FROM information_schema.columns 
+					   WHERE table_name='checks' AND column_name='query_column') THEN
+			ALTER TABLE checks ADD COLUMN query_column TEXT;
+		END IF;
+		IF NOT EXISTS (SELECT 1 FROM information_schema.columns 
+					   WHERE table_name='probes' AND column_name='country_code') THEN
+			ALTER TABLE probes ADD COLUMN country_code TEXT;
+			ALTER TABLE probes ADD COLUMN country TEXT;
+			ALTER TABLE probes ADD COLUMN city TEXT;
+			ALTER TABLE probes ADD COLUMN latitude DOUBLE PRECISION;
+			ALTER TABLE probes ADD COLUMN longitude DOUBLE PRECISION;
+		END IF;
+		-- Checks used to default to [200] while accepting any 2xx or 3xx code. Expected
+		-- codes are now authoritative, so the old default becomes the empty list, which
+		-- keeps that behaviour; the column default marks the conversion as done.
+		IF EXISTS (SELECT 1 FROM information_schema.columns
+				   WHERE table_name='checks' AND column_name='expected_status_codes'
+				   AND column_default LIKE '%[200]%') THEN
+			ALTER TABLE checks ALTER COLUMN expected_status_codes SET DEFAULT '[]';
+			UPDATE checks SET expected_status_codes = '[]'
+				WHERE expected_status_codes IS NULL OR expected_status_codes = '[200]'::jsonb;
+		END IF;
+		-- Users created before roles existed are the setup admin
+		IF NOT EXISTS (SELECT 1 FROM information_schema.columns 
+					   WHERE table_name='users' AND column_name='role') THEN
+			ALTER TABLE users ADD COLUMN role TEXT NOT NULL DEFAULT 'admin';
+		END IF;
 	END $$;
 
 	-- Indexes for probes table
@@ -366,136 +654,125 @@ func (d *TimescaleDB) initSchema() error {
 	return err
 }
 
+// parseStatusCodes reads a check's expected status codes. Unreadable data falls back
+// to none, which accepts any 2xx or 3xx code.
 func (d *TimescaleDB) parseStatusCodes(data interface{}) []int {
-	if data == nil {
-		return []int{200}
-	}
-
 	var codes []int
 	switch v := data.(type) {
 	case []byte:
 		if err := json.Unmarshal(v, &codes); err != nil {
-			return []int{200}
+			return nil
 		}
 	case string:
 		if err := json.Unmarshal([]byte(v), &codes); err != nil {
-			return []int{200}
+			return nil
 		}
 	default:
-		return []int{200}
+		return nil
 	}
 
 	if len(codes) == 0 {
-		return []int{200}
+		return nil
 	}
 	return codes
 }
 
 func (d *TimescaleDB) encodeStatusCodes(codes []int) []byte {
 	if len(codes) == 0 {
-		return []byte("[200]")
+		return []byte("[]")
 	}
 	data, _ := json.Marshal(codes)
 	return data
 }
 
-func (d *TimescaleDB) GetAllChecks() ([]models.Check, error) {
-	rows, err := d.db.Query(`
-		SELECT c.id, c.name, c.type, COALESCE(c.url, ''), c.interval_seconds, c.timeout_seconds, c.retries, c.retry_delay_seconds, 
-			enabled, created_at, COALESCE(expected_status_codes::text, '[200]'), method, 
-			COALESCE(json_path, ''), COALESCE(expected_json_value, ''),
-			COALESCE(postgres_conn_string, ''), COALESCE(postgres_query, ''), COALESCE(expected_query_value, ''), 
-			COALESCE(host, ''), COALESCE(dns_hostname, ''), COALESCE(dns_record_type, ''), 
-			COALESCE(expected_dns_value, ''), group_id, COALESCE(tailscale_device_id, ''), 
-			COALESCE(tailscale_service_host, ''), COALESCE(tailscale_service_port, 0), 
-			COALESCE(tailscale_service_protocol, ''), COALESCE(tailscale_service_path, ''),
-			cs.file_path, cs.taken_at, cs.last_error
-		FROM checks c
-		LEFT JOIN check_snapshots cs ON cs.check_id = c.id
-		ORDER BY c.created_at DESC
-	`)
-	if err != nil {
-		return nil, err
+func (d *TimescaleDB) parseHeaders(data string) map[string]string {
+	var headers map[string]string
+	if err := json.Unmarshal([]byte(data), &headers); err != nil || len(headers) == 0 {
+		return nil
 	}
-	defer rows.Close()
+	return headers
+}
 
-	var checks []models.Check
-	for rows.Next() {
-		var c models.Check
-		var statusCodesJSON string
-		var groupID sql.NullInt64
-		var filePath sql.NullString
-		var takenAt sql.NullTime
-		var lastError sql.NullString
-		if err := rows.Scan(&c.ID, &c.Name, &c.Type, &c.URL, &c.IntervalSeconds, &c.TimeoutSeconds,
-			&c.Retries, &c.RetryDelaySeconds, &c.Enabled, &c.CreatedAt,
-			&statusCodesJSON, &c.Method, &c.JSONPath, &c.ExpectedJSONValue,
-			&c.PostgresConnString, &c.PostgresQuery, &c.ExpectedQueryValue, &c.Host,
-			&c.DNSHostname, &c.DNSRecordType, &c.ExpectedDNSValue, &groupID, &c.TailscaleDeviceID, &c.TailscaleServiceHost, &c.TailscaleServicePort, &c.TailscaleServiceProtocol, &c.TailscaleServicePath,
-			&filePath, &takenAt, &lastError); err != nil {
-			return nil, err
-		}
-		c.ExpectedStatusCodes = d.parseStatusCodes(statusCodesJSON)
-		if groupID.Valid {
-			c.GroupID = &groupID.Int64
-		}
-		c.Tags, _ = d.GetCheckTags(c.ID)
-		if filePath.Valid {
-			c.SnapshotURL = fmt.Sprintf("/api/checks/%d/snapshot/image", c.ID)
-		}
-		if takenAt.Valid {
-			t := takenAt.Time
-			c.SnapshotTakenAt = &t
-		}
-		if lastError.Valid {
-			c.SnapshotError = lastError.String
-		}
-		checks = append(checks, c)
+func (d *TimescaleDB) encodeHeaders(headers map[string]string) []byte {
+	if len(headers) == 0 {
+		return []byte("{}")
 	}
+	data, _ := json.Marshal(headers)
+	return data
+}
 
-	return checks, rows.Err()
+// checkColumns is the column list shared by all check queries; keep it in sync with scanCheck
+const checkColumns = `c.id, c.name, c.type, COALESCE(c.url, ''), c.interval_seconds, c.timeout_seconds, c.retries, c.retry_delay_seconds,
+			enabled, created_at, COALESCE(expected_status_codes::text, '[]'), method,
+			COALESCE(json_path, ''), COALESCE(expected_json_value, ''),
+			COALESCE(postgres_conn_string, ''), COALESCE(postgres_query, ''), COALESCE(expected_query_value, ''),
+			COALESCE(host, ''), COALESCE(dns_hostname, ''), COALESCE(dns_record_type, ''),
+			COALESCE(expected_dns_value, ''), group_id, COALESCE(tailscale_device_id, ''),
+			COALESCE(tailscale_service_host, ''), COALESCE(tailscale_service_port, 0),
+			COALESCE(tailscale_service_protocol, ''), COALESCE(tailscale_service_path, ''),
+			c.public, COALESCE(c.port, 0), COALESCE(c.expected_banner, ''), COALESCE(c.headers::text, '{}'),
+			COALESCE(c.result_webhook_url, ''), COALESCE(c.request_body, ''), COALESCE(c.content_type, ''),
+			COALESCE(c.response_keyword, ''), COALESCE(c.response_keyword_mode, ''),
+			COALESCE(c.ntp_max_offset_ms, 0), COALESCE(c.cert_expiry_threshold_days, 0), c.insecure_skip_verify,
+			COALESCE(c.forbidden_body_keyword, ''), COALESCE(c.cron_expression, ''), c.expect_unreachable,
+			c.confirmation_threshold, c.max_response_time_ms, c.retention_days, c.bypass_cache,
+			COALESCE(c.redis_conn_string, ''), COALESCE(c.redis_key, ''), COALESCE(c.expected_redis_value, ''),
+			COALESCE(c.source_ip, ''), COALESCE(c.mysql_conn_string, ''), COALESCE(c.dns_resolver, ''), c.ping_count, c.max_packet_loss,
+			COALESCE(c.auth_type, ''), COALESCE(c.auth_username, ''), COALESCE(c.auth_password, ''), COALESCE(c.auth_token, ''), c.follow_redirects,
+			COALESCE(c.assertion_operator, ''), COALESCE(c.grpc_service, ''), c.grpc_tls, c.smtp_starttls,
+			COALESCE(c.expected_header_name, ''), COALESCE(c.expected_header_value, ''), COALESCE(c.expected_header_mode, ''),
+			COALESCE(c.mongo_conn_string, ''), c.renotify_interval_minutes,
+			c.min_response_bytes, c.max_response_bytes, COALESCE(c.expected_http_version, ''),
+			COALESCE(c.proxy_url, ''), COALESCE(c.trigger_secret, ''), c.backoff_on_failure,
+			c.expected_row_count, COALESCE(c.query_column, ''),
+			cs.file_path, cs.taken_at, cs.last_error`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
 }
 
-func (d *TimescaleDB) GetCheck(id int64) (*models.Check, error) {
+func (d *TimescaleDB) scanCheck(row rowScanner) (*models.Check, error) {
 	var c models.Check
 	var statusCodesJSON string
+	var headersJSON string
 	var groupID sql.NullInt64
+	var expectedRowCount sql.NullInt64
 	var filePath sql.NullString
 	var takenAt sql.NullTime
 	var lastError sql.NullString
-	err := d.db.QueryRow(`
-		SELECT c.id, c.name, c.type, COALESCE(c.url, ''), c.interval_seconds, c.timeout_seconds, c.retries, c.retry_delay_seconds, 
-			enabled, created_at, COALESCE(expected_status_codes::text, '[200]'), method, 
-			COALESCE(json_path, ''), COALESCE(expected_json_value, ''),
-			COALESCE(postgres_conn_string, ''), COALESCE(postgres_query, ''), COALESCE(expected_query_value, ''), 
-			COALESCE(host, ''), COALESCE(dns_hostname, ''), COALESCE(dns_record_type, ''), 
-			COALESCE(expected_dns_value, ''), group_id, COALESCE(tailscale_device_id, ''), 
-			COALESCE(tailscale_service_host, ''), COALESCE(tailscale_service_port, 0), 
-			COALESCE(tailscale_service_protocol, ''), COALESCE(tailscale_service_path, ''),
-			cs.file_path, cs.taken_at, cs.last_error
-		FROM checks c
-		LEFT JOIN check_snapshots cs ON cs.check_id = c.id
-		WHERE c.id = $1
-	`, id).Scan(&c.ID, &c.Name, &c.Type, &c.URL, &c.IntervalSeconds, &c.TimeoutSeconds,
+	if err := row.Scan(&c.ID, &c.Name, &c.Type, &c.URL, &c.IntervalSeconds, &c.TimeoutSeconds,
 		&c.Retries, &c.RetryDelaySeconds, &c.Enabled, &c.CreatedAt,
 		&statusCodesJSON, &c.Method, &c.JSONPath, &c.ExpectedJSONValue,
 		&c.PostgresConnString, &c.PostgresQuery, &c.ExpectedQueryValue, &c.Host,
 		&c.DNSHostname, &c.DNSRecordType, &c.ExpectedDNSValue, &groupID, &c.TailscaleDeviceID,
 		&c.TailscaleServiceHost, &c.TailscaleServicePort, &c.TailscaleServiceProtocol, &c.TailscaleServicePath,
-		&filePath, &takenAt, &lastError)
-
-	if err == sql.ErrNoRows {
-		return nil, nil
-	}
-	if err != nil {
+		&c.Public, &c.Port, &c.ExpectedBanner, &headersJSON, &c.ResultWebhookURL,
+		&c.RequestBody, &c.ContentType, &c.ResponseKeyword, &c.ResponseKeywordMode,
+		&c.NTPMaxOffsetMs, &c.CertExpiryThresholdDays, &c.InsecureSkipVerify,
+		&c.ForbiddenBodyKeyword, &c.CronExpression, &c.ExpectUnreachable,
+		&c.ConfirmationThreshold, &c.MaxResponseTimeMs, &c.RetentionDays, &c.BypassCache,
+		&c.RedisConnString, &c.RedisKey, &c.ExpectedRedisValue, &c.SourceIP,
+		&c.MySQLConnString, &c.DNSResolver, &c.PingCount, &c.MaxPacketLoss,
+		&c.AuthType, &c.AuthUsername, &c.AuthPassword, &c.AuthToken, &c.FollowRedirects,
+		&c.AssertionOperator, &c.GRPCService, &c.GRPCTLS, &c.SMTPStartTLS,
+		&c.ExpectedHeaderName, &c.ExpectedHeaderValue, &c.ExpectedHeaderMode,
+		&c.MongoConnString, &c.RenotifyIntervalMinutes,
+		&c.MinResponseBytes, &c.MaxResponseBytes, &c.ExpectedHTTPVersion,
+		&c.ProxyURL, &c.TriggerSecret, &c.BackoffOnFailure,
+		&expectedRowCount, &c.QueryColumn,
+		&filePath, &takenAt, &lastError); err != nil {
 		return nil, err
 	}
 
 	c.ExpectedStatusCodes = d.parseStatusCodes(statusCodesJSON)
+	c.Headers = d.parseHeaders(headersJSON)
 	if groupID.Valid {
 		c.GroupID = &groupID.Int64
 	}
-	c.Tags, _ = d.GetCheckTags(c.ID)
+	if expectedRowCount.Valid {
+		n := int(expectedRowCount.Int64)
+		c.ExpectedRowCount = &n
+	}
 	if filePath.Valid {
 		c.SnapshotURL = fmt.Sprintf("/api/checks/%d/snapshot/image", c.ID)
 	}
@@ -509,6 +786,131 @@ func (d *TimescaleDB) GetCheck(id int64) (*models.Check, error) {
 	return &c, nil
 }
 
+func (d *TimescaleDB) GetAllChecks() ([]models.Check, error) {
+	rows, err := d.db.Query(`
+		SELECT `+checkColumns+`
+		FROM checks c
+		LEFT JOIN check_snapshots cs ON cs.check_id = c.id
+		ORDER BY c.created_at DESC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var checks []models.Check
+	for rows.Next() {
+		c, err := d.scanCheck(rows)
+		if err != nil {
+			return nil, err
+		}
+		c.Tags, _ = d.GetCheckTags(c.ID)
+		checks = append(checks, *c)
+	}
+
+	return checks, rows.Err()
+}
+
+// likeEscaper escapes LIKE wildcards so search terms match literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// tagCondition matches the checks in checkColumn that carry any of the tags, or all of
+// them with MatchAll
+func tagCondition(checkColumn string, tags models.TagFilter, addArg func(interface{}) string) string {
+	ids := pq.Array(tags.IDs)
+	if !tags.MatchAll {
+		return "EXISTS (SELECT 1 FROM check_tags ct WHERE ct.check_id = " + checkColumn + " AND ct.tag_id = ANY(" + addArg(ids) + "))"
+	}
+	distinct := map[int64]bool{}
+	for _, id := range tags.IDs {
+		distinct[id] = true
+	}
+	return "(SELECT COUNT(DISTINCT ct.tag_id) FROM check_tags ct WHERE ct.check_id = " + checkColumn +
+		" AND ct.tag_id = ANY(" + addArg(ids) + ")) = " + addArg(len(distinct))
+}
+
+// GetChecksFiltered returns one page of the checks matching filter, newest first, along
+// with the total number of matching checks
+func (d *TimescaleDB) GetChecksFiltered(filter models.CheckFilter) ([]models.Check, int, error) {
+	conditions := []string{"1 = 1"}
+	args := []interface{}{}
+	addArg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.Type != "" {
+		conditions = append(conditions, "c.type = "+addArg(filter.Type))
+	}
+	if filter.Enabled != nil {
+		conditions = append(conditions, "c.enabled = "+addArg(*filter.Enabled))
+	}
+	if filter.GroupID != nil {
+		conditions = append(conditions, "c.group_id = "+addArg(*filter.GroupID))
+	}
+	if filter.Search != "" {
+		conditions = append(conditions, "c.name ILIKE '%' || "+addArg(likeEscaper.Replace(filter.Search))+" || '%'")
+	}
+	if len(filter.Tags.IDs) > 0 {
+		conditions = append(conditions, tagCondition("c.id", filter.Tags, addArg))
+	}
+	where := strings.Join(conditions, " AND ")
+
+	var total int
+	if err := d.db.QueryRow(`SELECT COUNT(*) FROM checks c WHERE `+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT ` + checkColumns + `
+		FROM checks c
+		LEFT JOIN check_snapshots cs ON cs.check_id = c.id
+		WHERE ` + where + `
+		ORDER BY c.created_at DESC, c.id DESC`
+	if filter.Limit > 0 {
+		query += " LIMIT " + addArg(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		query += " OFFSET " + addArg(filter.Offset)
+	}
+
+	rows, err := d.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	checks := []models.Check{}
+	for rows.Next() {
+		c, err := d.scanCheck(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		c.Tags, _ = d.GetCheckTags(c.ID)
+		checks = append(checks, *c)
+	}
+
+	return checks, total, rows.Err()
+}
+
+func (d *TimescaleDB) GetCheck(id int64) (*models.Check, error) {
+	c, err := d.scanCheck(d.db.QueryRow(`
+		SELECT `+checkColumns+`
+		FROM checks c
+		LEFT JOIN check_snapshots cs ON cs.check_id = c.id
+		WHERE c.id = $1
+	`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c.Tags, _ = d.GetCheckTags(c.ID)
+	return c, nil
+}
+
 func (d *TimescaleDB) CreateCheck(c *models.Check) error {
 	statusCodesJSON := d.encodeStatusCodes(c.ExpectedStatusCodes)
 	err := d.db.QueryRow(`
@@ -516,14 +918,43 @@ func (d *TimescaleDB) CreateCheck(c *models.Check) error {
 			enabled, expected_status_codes, method, json_path, expected_json_value,
 			postgres_conn_string, postgres_query, expected_query_value, host,
 			dns_hostname, dns_record_type, expected_dns_value, group_id, tailscale_device_id,
-			tailscale_service_host, tailscale_service_port, tailscale_service_protocol, tailscale_service_path)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25)
+			tailscale_service_host, tailscale_service_port, tailscale_service_protocol, tailscale_service_path,
+			public, port, expected_banner, headers, result_webhook_url, request_body, content_type,
+			response_keyword, response_keyword_mode, ntp_max_offset_ms,
+			cert_expiry_threshold_days, insecure_skip_verify, forbidden_body_keyword,
+			cron_expression, expect_unreachable, confirmation_threshold, max_response_time_ms,
+			retention_days, bypass_cache, redis_conn_string, redis_key, expected_redis_value,
+			source_ip, mysql_conn_string, dns_resolver, ping_count, max_packet_loss,
+			auth_type, auth_username, auth_password, auth_token, follow_redirects,
+			assertion_operator, grpc_service, grpc_tls, smtp_starttls,
+			expected_header_name, expected_header_value, expected_header_mode, mongo_conn_string,
+			renotify_interval_minutes, min_response_bytes, max_response_bytes,
+			expected_http_version, proxy_url, trigger_secret, backoff_on_failure,
+			expected_row_count, query_column)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25,
+			$26, $27, $28, $29, $30, $31, $32, $33, $34, $35, $36, $37, $38, $39, $40, $41, $42, $43, $44,
+			$45, $46, $47, $48, $49, $50, $51, $52, $53, $54, $55, $56, $57, $58, $59, $60, $61,
+			$62, $63, $64, $65, $66, $67, $68, $69, $70, $71, $72, $73, $74)
 		RETURNING id, created_at
 	`, c.Name, c.Type, c.URL, c.IntervalSeconds, c.TimeoutSeconds, c.Retries, c.RetryDelaySeconds,
 		c.Enabled, statusCodesJSON, c.Method, c.JSONPath, c.ExpectedJSONValue,
 		c.PostgresConnString, c.PostgresQuery, c.ExpectedQueryValue, c.Host,
 		c.DNSHostname, c.DNSRecordType, c.ExpectedDNSValue, c.GroupID, c.TailscaleDeviceID,
-		c.TailscaleServiceHost, c.TailscaleServicePort, c.TailscaleServiceProtocol, c.TailscaleServicePath).Scan(&c.ID, &c.CreatedAt)
+		c.TailscaleServiceHost, c.TailscaleServicePort, c.TailscaleServiceProtocol, c.TailscaleServicePath,
+		c.Public, c.Port, c.ExpectedBanner, d.encodeHeaders(c.Headers), c.ResultWebhookURL,
+		c.RequestBody, c.ContentType, c.ResponseKeyword, c.ResponseKeywordMode,
+		c.NTPMaxOffsetMs, c.CertExpiryThresholdDays, c.InsecureSkipVerify,
+		c.ForbiddenBodyKeyword, c.CronExpression, c.ExpectUnreachable, c.ConfirmationThreshold,
+		c.MaxResponseTimeMs, c.RetentionDays, c.BypassCache,
+		c.RedisConnString, c.RedisKey, c.ExpectedRedisValue, c.SourceIP,
+		c.MySQLConnString, c.DNSResolver, c.PingCount, c.MaxPacketLoss,
+		c.AuthType, c.AuthUsername, c.AuthPassword, c.AuthToken, c.FollowRedirects,
+		c.AssertionOperator, c.GRPCService, c.GRPCTLS, c.SMTPStartTLS,
+		c.ExpectedHeaderName, c.ExpectedHeaderValue, c.ExpectedHeaderMode,
+		c.MongoConnString, c.RenotifyIntervalMinutes,
+		c.MinResponseBytes, c.MaxResponseBytes, c.ExpectedHTTPVersion,
+		c.ProxyURL, c.TriggerSecret, c.BackoffOnFailure,
+		c.ExpectedRowCount, c.QueryColumn).Scan(&c.ID, &c.CreatedAt)
 
 	return err
 }
@@ -538,13 +969,42 @@ func (d *TimescaleDB) UpdateCheck(c *models.Check) error {
 			postgres_conn_string = $13, postgres_query = $14, expected_query_value = $15, host = $16,
 			dns_hostname = $17, dns_record_type = $18, expected_dns_value = $19, group_id = $20, 
 			tailscale_device_id = $21, tailscale_service_host = $22, tailscale_service_port = $23,
-			tailscale_service_protocol = $24, tailscale_service_path = $25
-		WHERE id = $26
+			tailscale_service_protocol = $24, tailscale_service_path = $25,
+			public = $26, port = $27, expected_banner = $28, headers = $29,
+			result_webhook_url = $30, request_body = $31, content_type = $32,
+			response_keyword = $33, response_keyword_mode = $34,
+			ntp_max_offset_ms = $35, cert_expiry_threshold_days = $36, insecure_skip_verify = $37,
+			forbidden_body_keyword = $38, cron_expression = $39, expect_unreachable = $40,
+			confirmation_threshold = $41, max_response_time_ms = $42, retention_days = $43,
+			bypass_cache = $44, redis_conn_string = $45, redis_key = $46, expected_redis_value = $47,
+			source_ip = $48, mysql_conn_string = $49, dns_resolver = $50, ping_count = $51, max_packet_loss = $52,
+			auth_type = $53, auth_username = $54, auth_password = $55, auth_token = $56,
+			follow_redirects = $57, assertion_operator = $58, grpc_service = $59, grpc_tls = $60,
+			smtp_starttls = $61, expected_header_name = $62, expected_header_value = $63,
+			expected_header_mode = $64, mongo_conn_string = $65, renotify_interval_minutes = $66,
+			min_response_bytes = $67, max_response_bytes = $68, expected_http_version = $69,
+			proxy_url = $70, trigger_secret = $71, backoff_on_failure = $72,
+			expected_row_count = $73, query_column = $74
+		WHERE id = $75
 	`, c.Name, c.Type, c.URL, c.IntervalSeconds, c.TimeoutSeconds, c.Retries, c.RetryDelaySeconds,
 		c.Enabled, statusCodesJSON, c.Method, c.JSONPath, c.ExpectedJSONValue,
 		c.PostgresConnString, c.PostgresQuery, c.ExpectedQueryValue, c.Host,
 		c.DNSHostname, c.DNSRecordType, c.ExpectedDNSValue, c.GroupID, c.TailscaleDeviceID,
-		c.TailscaleServiceHost, c.TailscaleServicePort, c.TailscaleServiceProtocol, c.TailscaleServicePath, c.ID)
+		c.TailscaleServiceHost, c.TailscaleServicePort, c.TailscaleServiceProtocol, c.TailscaleServicePath,
+		c.Public, c.Port, c.ExpectedBanner, d.encodeHeaders(c.Headers), c.ResultWebhookURL,
+		c.RequestBody, c.ContentType, c.ResponseKeyword, c.ResponseKeywordMode,
+		c.NTPMaxOffsetMs, c.CertExpiryThresholdDays, c.InsecureSkipVerify,
+		c.ForbiddenBodyKeyword, c.CronExpression, c.ExpectUnreachable, c.ConfirmationThreshold,
+		c.MaxResponseTimeMs, c.RetentionDays, c.BypassCache,
+		c.RedisConnString, c.RedisKey, c.ExpectedRedisValue, c.SourceIP,
+		c.MySQLConnString, c.DNSResolver, c.PingCount, c.MaxPacketLoss,
+		c.AuthType, c.AuthUsername, c.AuthPassword, c.AuthToken, c.FollowRedirects,
+		c.AssertionOperator, c.GRPCService, c.GRPCTLS, c.SMTPStartTLS,
+		c.ExpectedHeaderName, c.ExpectedHeaderValue, c.ExpectedHeaderMode,
+		c.MongoConnString, c.RenotifyIntervalMinutes,
+		c.MinResponseBytes, c.MaxResponseBytes, c.ExpectedHTTPVersion,
+		c.ProxyURL, c.TriggerSecret, c.BackoffOnFailure,
+		c.ExpectedRowCount, c.QueryColumn, c.ID)
 	return err
 }
 
@@ -553,19 +1013,45 @@ func (d *TimescaleDB) DeleteCheck(id int64) error {
 	return err
 }
 
-func (d *TimescaleDB) GetEnabledChecks() ([]models.Check, error) {
-	rows, err := d.db.Query(`
-		SELECT c.id, c.name, c.type, COALESCE(c.url, ''), c.interval_seconds, c.timeout_seconds, c.retries, c.retry_delay_seconds, 
-			enabled, created_at, COALESCE(expected_status_codes::text, '[200]'), method, 
-			COALESCE(json_path, ''), COALESCE(expected_json_value, ''),
-			COALESCE(postgres_conn_string, ''), COALESCE(postgres_query, ''), COALESCE(expected_query_value, ''), 
-			COALESCE(host, ''), COALESCE(dns_hostname, ''), COALESCE(dns_record_type, ''), 
-			COALESCE(expected_dns_value, ''), group_id, COALESCE(tailscale_device_id, ''), 
-			COALESCE(tailscale_service_host, ''), COALESCE(tailscale_service_port, 0), 
-			COALESCE(tailscale_service_protocol, ''), COALESCE(tailscale_service_path, ''),
-			cs.file_path, cs.taken_at, cs.last_error
-		FROM checks c
-		LEFT JOIN check_snapshots cs ON cs.check_id = c.id
+// SetChecksEnabled enables or disables many checks in a single statement, so either all
+// of them change or none do. It returns the IDs that matched a check.
+func (d *TimescaleDB) SetChecksEnabled(ids []int64, enabled bool) ([]int64, error) {
+	rows, err := d.db.Query(`UPDATE checks SET enabled = $1 WHERE id = ANY($2) RETURNING id`, enabled, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	return scanIDs(rows)
+}
+
+// DeleteChecks deletes many checks in a single statement and returns the IDs that
+// matched a check
+func (d *TimescaleDB) DeleteChecks(ids []int64) ([]int64, error) {
+	rows, err := d.db.Query(`DELETE FROM checks WHERE id = ANY($1) RETURNING id`, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	return scanIDs(rows)
+}
+
+func scanIDs(rows *sql.Rows) ([]int64, error) {
+	defer rows.Close()
+
+	ids := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (d *TimescaleDB) GetEnabledChecks() ([]models.Check, error) {
+	rows, err := d.db.Query(`
+		SELECT `+checkColumns+`
+		FROM checks c
+		LEFT JOIN check_snapshots cs ON cs.check_id = c.id
 		WHERE c.enabled = true
 	`)
 	if err != nil {
@@ -575,35 +1061,11 @@ func (d *TimescaleDB) GetEnabledChecks() ([]models.Check, error) {
 
 	checks := make([]models.Check, 0, 100)
 	for rows.Next() {
-		var c models.Check
-		var statusCodesJSON string
-		var groupID sql.NullInt64
-		var filePath sql.NullString
-		var takenAt sql.NullTime
-		var lastError sql.NullString
-		if err := rows.Scan(&c.ID, &c.Name, &c.Type, &c.URL, &c.IntervalSeconds, &c.TimeoutSeconds,
-			&c.Retries, &c.RetryDelaySeconds, &c.Enabled, &c.CreatedAt,
-			&statusCodesJSON, &c.Method, &c.JSONPath, &c.ExpectedJSONValue,
-			&c.PostgresConnString, &c.PostgresQuery, &c.ExpectedQueryValue, &c.Host,
-			&c.DNSHostname, &c.DNSRecordType, &c.ExpectedDNSValue, &groupID, &c.TailscaleDeviceID, &c.TailscaleServiceHost, &c.TailscaleServicePort, &c.TailscaleServiceProtocol, &c.TailscaleServicePath,
-			&filePath, &takenAt, &lastError); err != nil {
+		c, err := d.scanCheck(rows)
+		if err != nil {
 			return nil, err
 		}
-		c.ExpectedStatusCodes = d.parseStatusCodes(statusCodesJSON)
-		if groupID.Valid {
-			c.GroupID = &groupID.Int64
-		}
-		if filePath.Valid {
-			c.SnapshotURL = fmt.Sprintf("/api/checks/%d/snapshot/image", c.ID)
-		}
-		if takenAt.Valid {
-			t := takenAt.Time
-			c.SnapshotTakenAt = &t
-		}
-		if lastError.Valid {
-			c.SnapshotError = lastError.String
-		}
-		checks = append(checks, c)
+		checks = append(checks, *c)
 	}
 
 	return checks, rows.Err()
@@ -622,6 +1084,11 @@ func (d *TimescaleDB) AddHistory(h *models.CheckHistory) error {
 }
 
 func (d *TimescaleDB) GetCheckHistory(checkID int64, since *time.Time, limit int) ([]models.CheckHistory, error) {
+	return d.GetCheckHistoryContext(context.Background(), checkID, since, limit)
+}
+
+// GetCheckHistoryContext is GetCheckHistory with a context that cancels the query
+func (d *TimescaleDB) GetCheckHistoryContext(ctx context.Context, checkID int64, since *time.Time, limit int) ([]models.CheckHistory, error) {
 	query := `
 		SELECT id, check_id, status_code, response_time_ms, success, COALESCE(error_message, ''), checked_at, probe_id, COALESCE(NULLIF(region, ''), 'host'), COALESCE(response_body, '')
 		FROM check_history
@@ -639,7 +1106,7 @@ func (d *TimescaleDB) GetCheckHistory(checkID int64, since *time.Time, limit int
 		args = append(args, limit)
 	}
 
-	rows, err := d.db.Query(query, args...)
+	rows, err := d.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -658,13 +1125,60 @@ func (d *TimescaleDB) GetCheckHistory(checkID int64, since *time.Time, limit int
 		if h.Region == "" {
 			h.Region = "host"
 		}
+		h.CheckedAt = h.CheckedAt.UTC()
 		history = append(history, h)
 	}
 
 	return history, rows.Err()
 }
 
+// StreamCheckHistory calls fn for every raw history row of a check in chronological
+// order without loading them all into memory. Response bodies are not included.
+// Returning an error from fn stops the iteration and returns that error.
+func (d *TimescaleDB) StreamCheckHistory(checkID int64, since *time.Time, fn func(h *models.CheckHistory) error) error {
+	query := `
+		SELECT id, check_id, status_code, response_time_ms, success, COALESCE(error_message, ''), checked_at, probe_id, COALESCE(NULLIF(region, ''), 'host')
+		FROM check_history
+		WHERE check_id = $1`
+	args := []interface{}{checkID}
+
+	if since != nil {
+		query += " AND checked_at >= $2"
+		args = append(args, since.UTC())
+	}
+	query += " ORDER BY checked_at ASC"
+
+	rows, err := d.db.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var h models.CheckHistory
+		var probeID sql.NullInt64
+		if err := rows.Scan(&h.ID, &h.CheckID, &h.StatusCode, &h.ResponseTimeMs, &h.Success, &h.ErrorMessage, &h.CheckedAt, &probeID, &h.Region); err != nil {
+			return err
+		}
+		if probeID.Valid {
+			h.ProbeID = &probeID.Int64
+		}
+		h.CheckedAt = h.CheckedAt.UTC()
+		if err := fn(&h); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
 func (d *TimescaleDB) GetCheckHistoryAggregated(checkID int64, since *time.Time, bucketMinutes int, limit int) ([]models.CheckHistory, error) {
+	return d.GetCheckHistoryAggregatedContext(context.Background(), checkID, since, bucketMinutes, limit)
+}
+
+// GetCheckHistoryAggregatedContext is GetCheckHistoryAggregated with a context that
+// cancels the query
+func (d *TimescaleDB) GetCheckHistoryAggregatedContext(ctx context.Context, checkID int64, since *time.Time, bucketMinutes int, limit int) ([]models.CheckHistory, error) {
 	query := `
 		SELECT 
 			MAX(id) as id,
@@ -673,7 +1187,7 @@ func (d *TimescaleDB) GetCheckHistoryAggregated(checkID int64, since *time.Time,
 			CAST(AVG(response_time_ms) AS INTEGER) as response_time_ms,
 			BOOL_AND(success) as success,
 			'' as error_message,
-			time_bucket(INTERVAL '%d minutes', checked_at) as checked_at,
+			%s as checked_at,
 			NULL::BIGINT as probe_id,
 			region,
 			'' as response_body
@@ -686,115 +1200,555 @@ func (d *TimescaleDB) GetCheckHistoryAggregated(checkID int64, since *time.Time,
 			WHERE check_id = $1`
 	args := []interface{}{checkID}
 
-	if since != nil {
-		query += " AND checked_at >= $2"
-		args = append(args, since.UTC())
+	if since != nil {
+		query += " AND checked_at >= $2"
+		args = append(args, since.UTC())
+	}
+
+	query += ") AS transformed_history"
+	bucket := bucketExpr(bucketMinutes)
+	query = fmt.Sprintf(query, bucket)
+	query += fmt.Sprintf(" GROUP BY check_id, %s, region ORDER BY checked_at DESC, region", bucket)
+
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d", len(args)+1)
+		args = append(args, limit)
+	}
+
+	rows, err := d.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := make([]models.CheckHistory, 0, limit)
+	for rows.Next() {
+		var h models.CheckHistory
+		var probeID sql.NullInt64
+		if err := rows.Scan(&h.ID, &h.CheckID, &h.StatusCode, &h.ResponseTimeMs, &h.Success, &h.ErrorMessage, &h.CheckedAt, &probeID, &h.Region, &h.ResponseBody); err != nil {
+			return nil, err
+		}
+		if probeID.Valid {
+			h.ProbeID = &probeID.Int64
+		}
+		if h.Region == "" {
+			h.Region = "host"
+		}
+		h.CheckedAt = bucketStart(h.CheckedAt, bucketMinutes)
+		history = append(history, h)
+	}
+
+	return history, rows.Err()
+}
+
+func (d *TimescaleDB) GetLastStatus(checkID int64) (*models.CheckHistory, error) {
+	return d.GetLastStatusContext(context.Background(), checkID)
+}
+
+// GetLastStatusContext is GetLastStatus with a context that cancels the query
+func (d *TimescaleDB) GetLastStatusContext(ctx context.Context, checkID int64) (*models.CheckHistory, error) {
+	var h models.CheckHistory
+	var probeID sql.NullInt64
+	err := d.db.QueryRowContext(ctx, `
+		SELECT id, check_id, status_code, response_time_ms, success, COALESCE(error_message, ''), checked_at, probe_id, COALESCE(NULLIF(region, ''), 'host'), COALESCE(response_body, '')
+		FROM check_history
+		WHERE check_id = $1
+		ORDER BY checked_at DESC
+		LIMIT 1
+	`, checkID).Scan(&h.ID, &h.CheckID, &h.StatusCode, &h.ResponseTimeMs, &h.Success, &h.ErrorMessage, &h.CheckedAt, &probeID, &h.Region, &h.ResponseBody)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if probeID.Valid {
+		h.ProbeID = &probeID.Int64
+	}
+	if h.Region == "" {
+		h.Region = "host"
+	}
+
+	return &h, nil
+}
+
+// GetLatencyPercentiles computes response time percentiles over every result of a check
+// since since (all of its history when nil), from every region
+func (d *TimescaleDB) GetLatencyPercentiles(checkID int64, since *time.Time) (*models.LatencyPercentiles, error) {
+	query := `
+		SELECT
+			COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY response_time_ms), 0),
+			COALESCE(percentile_cont(0.9) WITHIN GROUP (ORDER BY response_time_ms), 0),
+			COALESCE(percentile_cont(0.95) WITHIN GROUP (ORDER BY response_time_ms), 0),
+			COALESCE(percentile_cont(0.99) WITHIN GROUP (ORDER BY response_time_ms), 0)
+		FROM check_history
+		WHERE check_id = $1`
+	args := []interface{}{checkID}
+	if since != nil {
+		query += ` AND checked_at >= $2`
+		args = append(args, since.UTC())
+	}
+
+	var p models.LatencyPercentiles
+	if err := d.db.QueryRow(query, args...).Scan(&p.P50, &p.P90, &p.P95, &p.P99); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (d *TimescaleDB) GetLastStatusByRegion(checkID int64) (map[string]*models.CheckHistory, error) {
+	rows, err := d.db.Query(`
+		SELECT DISTINCT ON (COALESCE(NULLIF(region, ''), 'host'))
+			id, check_id, status_code, response_time_ms, success, COALESCE(error_message, ''), checked_at, probe_id, COALESCE(NULLIF(region, ''), 'host'), COALESCE(response_body, '')
+		FROM check_history
+		WHERE check_id = $1
+		ORDER BY COALESCE(NULLIF(region, ''), 'host'), checked_at DESC
+	`, checkID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[string]*models.CheckHistory)
+	for rows.Next() {
+		var h models.CheckHistory
+		var probeID sql.NullInt64
+		if err := rows.Scan(&h.ID, &h.CheckID, &h.StatusCode, &h.ResponseTimeMs, &h.Success, &h.ErrorMessage, &h.CheckedAt, &probeID, &h.Region, &h.ResponseBody); err != nil {
+			return nil, err
+		}
+		if probeID.Valid {
+			h.ProbeID = &probeID.Int64
+		}
+		if h.Region == "" {
+			h.Region = "host"
+		}
+		result[h.Region] = &h
+	}
+
+	return result, rows.Err()
+}
+
+// GetLastStatusByRegionForChecks is GetLastStatusByRegion for many checks in one query,
+// keyed by check ID. Response bodies are left out.
+func (d *TimescaleDB) GetLastStatusByRegionForChecks(checkIDs []int64) (map[int64]map[string]*models.CheckHistory, error) {
+	result := make(map[int64]map[string]*models.CheckHistory)
+	if len(checkIDs) == 0 {
+		return result, nil
+	}
+
+	rows, err := d.db.Query(`
+		SELECT DISTINCT ON (check_id, COALESCE(NULLIF(region, ''), 'host'))
+			id, check_id, status_code, response_time_ms, success, COALESCE(error_message, ''), checked_at, probe_id, COALESCE(NULLIF(region, ''), 'host')
+		FROM check_history
+		WHERE check_id = ANY($1)
+		ORDER BY check_id, COALESCE(NULLIF(region, ''), 'host'), checked_at DESC
+	`, pq.Array(checkIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var h models.CheckHistory
+		var probeID sql.NullInt64
+		if err := rows.Scan(&h.ID, &h.CheckID, &h.StatusCode, &h.ResponseTimeMs, &h.Success, &h.ErrorMessage, &h.CheckedAt, &probeID, &h.Region); err != nil {
+			return nil, err
+		}
+		if probeID.Valid {
+			h.ProbeID = &probeID.Int64
+		}
+		if result[h.CheckID] == nil {
+			result[h.CheckID] = make(map[string]*models.CheckHistory)
+		}
+		result[h.CheckID][h.Region] = &h
+	}
+
+	return result, rows.Err()
+}
+
+// RollupHistory summarises raw history into resolutionMinutes buckets. Only complete
+// buckets are written, and the latest bucket already rolled up is recomputed so results
+// that probes report late are still counted.
+func (d *TimescaleDB) RollupHistory(resolutionMinutes int) error {
+	var from sql.NullTime
+	if err := d.db.QueryRow(`
+		SELECT MAX(bucket_start) FROM check_history_rollups WHERE resolution_minutes = $1
+	`, resolutionMinutes).Scan(&from); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO check_history_rollups (check_id, region, resolution_minutes, bucket_start,
+			sample_count, success_count, min_response_time_ms, max_response_time_ms, total_response_time_ms)
+		SELECT check_id, region, $1, %s AS bucket_start,
+			COUNT(*), COUNT(*) FILTER (WHERE success),
+			MIN(response_time_ms), MAX(response_time_ms), SUM(response_time_ms)
+		FROM (
+			SELECT check_id, COALESCE(NULLIF(region, ''), 'host') AS region, success,
+				COALESCE(response_time_ms, 0) AS response_time_ms, checked_at
+			FROM check_history
+			WHERE checked_at < $2`
+	args := []interface{}{resolutionMinutes, bucketStart(time.Now(), resolutionMinutes)}
+
+	if from.Valid {
+		query += " AND checked_at >= $3"
+		args = append(args, from.Time.UTC())
+	}
+
+	query += `
+		) AS raw
+		GROUP BY 1, 2, 4
+		ON CONFLICT (check_id, resolution_minutes, bucket_start, region) DO UPDATE SET
+			sample_count = EXCLUDED.sample_count,
+			success_count = EXCLUDED.success_count,
+			min_response_time_ms = EXCLUDED.min_response_time_ms,
+			max_response_time_ms = EXCLUDED.max_response_time_ms,
+			total_response_time_ms = EXCLUDED.total_response_time_ms`
+
+	_, err := d.db.Exec(fmt.Sprintf(query, bucketExpr(resolutionMinutes)), args...)
+	return err
+}
+
+// GetCheckHistoryRollup returns history in bucketMinutes buckets like
+// GetCheckHistoryAggregated, but reads the rollup tables instead of scanning raw rows.
+// Daily rollups are used when bucketMinutes is a whole number of days, hourly ones
+// otherwise. Time after the latest rollup is aggregated from raw rows, so recent
+// buckets are complete even before the rollup job has run.
+func (d *TimescaleDB) GetCheckHistoryRollup(checkID int64, since *time.Time, bucketMinutes int, limit int) ([]models.CheckHistory, error) {
+	resolution := RollupHourly
+	if bucketMinutes%RollupDaily == 0 {
+		resolution = RollupDaily
+	}
+
+	var from time.Time
+	if since != nil {
+		from = since.UTC()
+	}
+
+	query := fmt.Sprintf(`
+		WITH horizon AS (
+			SELECT COALESCE(MAX(bucket_start) + make_interval(mins => $2), '-infinity'::timestamptz) AS t
+			FROM check_history_rollups
+			WHERE check_id = $1 AND resolution_minutes = $2
+		), samples AS (
+			SELECT bucket_start AS checked_at, region, sample_count, success_count, total_response_time_ms
+			FROM check_history_rollups
+			WHERE check_id = $1 AND resolution_minutes = $2 AND bucket_start >= $3
+			UNION ALL
+			SELECT checked_at, COALESCE(NULLIF(region, ''), 'host'), 1,
+				CASE WHEN success THEN 1 ELSE 0 END, COALESCE(response_time_ms, 0)
+			FROM check_history, horizon
+			WHERE check_id = $1 AND checked_at >= GREATEST($3, horizon.t)
+		)
+		SELECT
+			CAST(SUM(total_response_time_ms) / SUM(sample_count) AS INTEGER) AS response_time_ms,
+			SUM(success_count) = SUM(sample_count) AS success,
+			%s AS checked_at,
+			region
+		FROM samples
+		GROUP BY 3, region
+		ORDER BY checked_at DESC, region`, bucketExpr(bucketMinutes))
+	args := []interface{}{checkID, resolution, from}
+
+	if limit > 0 {
+		query += " LIMIT $4"
+		args = append(args, limit)
+	}
+
+	rows, err := d.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := make([]models.CheckHistory, 0, limit)
+	for rows.Next() {
+		h := models.CheckHistory{CheckID: checkID}
+		if err := rows.Scan(&h.ResponseTimeMs, &h.Success, &h.CheckedAt, &h.Region); err != nil {
+			return nil, err
+		}
+		h.CheckedAt = bucketStart(h.CheckedAt, bucketMinutes)
+		history = append(history, h)
+	}
+
+	return history, rows.Err()
+}
+
+// DeleteHistoryBefore deletes all history older than t. On TimescaleDB whole chunks are
+// dropped first, so the returned count only includes rows deleted individually.
+func (d *TimescaleDB) DeleteHistoryBefore(t time.Time) (int64, error) {
+	var timescale bool
+	if err := d.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'timescaledb')`).Scan(&timescale); err != nil {
+		return 0, err
+	}
+	if timescale {
+		// Fails when check_history is not a hypertable, the DELETE below covers that case
+		d.db.Exec(`SELECT drop_chunks('check_history', older_than => $1::timestamptz)`, t)
+	}
+
+	result, err := d.db.Exec(`DELETE FROM check_history WHERE checked_at < $1`, t)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// PruneHistory deletes history older than each check's retention_days, or defaultDays for
+// checks without an override (0 keeps history forever). Checks are pruned in groups that
+// share a retention value so every DELETE has a constant cutoff, which lets TimescaleDB
+// skip chunks newer than it instead of evaluating a per-row interval. When no check keeps
+// history longer than defaultDays, the global cutoff goes through DeleteHistoryBefore.
+func (d *TimescaleDB) PruneHistory(defaultDays int) (int64, error) {
+	rows, err := d.db.Query(`SELECT DISTINCT retention_days FROM checks WHERE retention_days > 0`)
+	if err != nil {
+		return 0, err
+	}
+	var overrides []int
+	for rows.Next() {
+		var days int
+		if err := rows.Scan(&days); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		overrides = append(overrides, days)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	var total int64
+	prune := func(retentionDays, days int) error {
+		result, err := d.db.Exec(`
+			DELETE FROM check_history
+			WHERE checked_at < NOW() - make_interval(days => $2)
+			  AND check_id IN (SELECT id FROM checks WHERE retention_days = $1)
+		`, retentionDays, days)
+		if err != nil {
+			return err
+		}
+		n, _ := result.RowsAffected()
+		total += n
+		return nil
+	}
+
+	// swept is set once everything older than defaultDays is gone, whatever the check
+	swept := false
+	if defaultDays > 0 {
+		longer := false
+		for _, days := range overrides {
+			if days > defaultDays {
+				longer = true
+			}
+		}
+		if longer {
+			if err := prune(0, defaultDays); err != nil {
+				return total, err
+			}
+		} else {
+			n, err := d.DeleteHistoryBefore(time.Now().AddDate(0, 0, -defaultDays))
+			total += n
+			if err != nil {
+				return total, err
+			}
+			swept = true
+		}
+	}
+	for _, days := range overrides {
+		if swept && days == defaultDays {
+			continue
+		}
+		if err := prune(days, days); err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// GetStatusEvents detects up/down transitions per check and region and returns them in
+// chronological order. Each event lasts until the next transition of the same series.
+func (d *TimescaleDB) GetStatusEvents(since *time.Time, filter models.EventFilter) ([]models.StatusEvent, error) {
+	conditions := []string{"1 = 1"}
+	args := []interface{}{}
+	addArg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
 	}
 
-	query += ") AS transformed_history"
-	bucketInterval := fmt.Sprintf("%d minutes", bucketMinutes)
-	query = fmt.Sprintf(query, bucketMinutes)
-	query += fmt.Sprintf(" GROUP BY check_id, time_bucket(INTERVAL '%s', checked_at), region ORDER BY checked_at DESC, region", bucketInterval)
+	if since != nil {
+		conditions = append(conditions, "h.checked_at >= "+addArg(since.UTC()))
+	}
+	if filter.CheckID != nil {
+		conditions = append(conditions, "h.check_id = "+addArg(*filter.CheckID))
+	}
+	if filter.GroupID != nil {
+		conditions = append(conditions, "c.group_id = "+addArg(*filter.GroupID))
+	}
+	if filter.TagID != nil {
+		conditions = append(conditions, "EXISTS (SELECT 1 FROM check_tags ct WHERE ct.check_id = h.check_id AND ct.tag_id = "+addArg(*filter.TagID)+")")
+	}
+	if filter.PublicOnly {
+		conditions = append(conditions, "c.public = true")
+	}
 
-	if limit > 0 {
-		query += fmt.Sprintf(" LIMIT $%d", len(args)+1)
-		args = append(args, limit)
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 500
 	}
 
+	query := fmt.Sprintf(`
+		WITH ordered AS (
+			SELECT h.check_id, c.name, COALESCE(NULLIF(h.region, ''), 'host') AS region, h.success,
+				COALESCE(h.status_code, 0) AS status_code, COALESCE(h.error_message, '') AS error_message, h.checked_at,
+				LAG(h.success) OVER (PARTITION BY h.check_id, COALESCE(NULLIF(h.region, ''), 'host') ORDER BY h.checked_at) AS prev_success
+			FROM check_history h
+			JOIN checks c ON c.id = h.check_id
+			WHERE %s
+		), transitions AS (
+			SELECT check_id, name, region, success, status_code, error_message, checked_at,
+				LEAD(checked_at) OVER (PARTITION BY check_id, region ORDER BY checked_at) AS ended_at
+			FROM ordered
+			WHERE prev_success IS NOT NULL AND prev_success <> success
+		)
+		SELECT check_id, name, region, success, status_code, error_message, checked_at, ended_at
+		FROM (
+			SELECT * FROM transitions ORDER BY checked_at DESC LIMIT %s
+		) recent
+		ORDER BY checked_at ASC`, strings.Join(conditions, " AND "), addArg(limit))
+
 	rows, err := d.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	history := make([]models.CheckHistory, 0, limit)
+	events := make([]models.StatusEvent, 0)
 	for rows.Next() {
-		var h models.CheckHistory
-		var probeID sql.NullInt64
-		if err := rows.Scan(&h.ID, &h.CheckID, &h.StatusCode, &h.ResponseTimeMs, &h.Success, &h.ErrorMessage, &h.CheckedAt, &probeID, &h.Region, &h.ResponseBody); err != nil {
+		var e models.StatusEvent
+		var endedAt sql.NullTime
+		if err := rows.Scan(&e.CheckID, &e.CheckName, &e.Region, &e.IsUp, &e.StatusCode, &e.ErrorMessage, &e.StartedAt, &endedAt); err != nil {
 			return nil, err
 		}
-		if probeID.Valid {
-			h.ProbeID = &probeID.Int64
-		}
-		if h.Region == "" {
-			h.Region = "host"
+		e.StartedAt = e.StartedAt.UTC()
+		if endedAt.Valid {
+			ended := endedAt.Time.UTC()
+			duration := int64(ended.Sub(e.StartedAt).Seconds())
+			e.EndedAt = &ended
+			e.DurationSeconds = &duration
 		}
-		history = append(history, h)
+		events = append(events, e)
 	}
 
-	return history, rows.Err()
+	return events, rows.Err()
 }
 
-func (d *TimescaleDB) GetLastStatus(checkID int64) (*models.CheckHistory, error) {
-	var h models.CheckHistory
-	var probeID sql.NullInt64
+// OpenIncident starts an incident for a check, unless one is already unresolved
+func (d *TimescaleDB) OpenIncident(incident *models.Incident) error {
 	err := d.db.QueryRow(`
-		SELECT id, check_id, status_code, response_time_ms, success, COALESCE(error_message, ''), checked_at, probe_id, COALESCE(NULLIF(region, ''), 'host'), COALESCE(response_body, '')
-		FROM check_history
-		WHERE check_id = $1
-		ORDER BY checked_at DESC
-		LIMIT 1
-	`, checkID).Scan(&h.ID, &h.CheckID, &h.StatusCode, &h.ResponseTimeMs, &h.Success, &h.ErrorMessage, &h.CheckedAt, &probeID, &h.Region, &h.ResponseBody)
-
+		INSERT INTO incidents (check_id, cause, started_at) VALUES ($1, $2, $3)
+		ON CONFLICT (check_id) WHERE resolved_at IS NULL DO NOTHING
+		RETURNING id
+	`, incident.CheckID, incident.Cause, incident.StartedAt.UTC()).Scan(&incident.ID)
 	if err == sql.ErrNoRows {
-		return nil, nil
+		return nil
 	}
-	if err != nil {
-		return nil, err
+	return err
+}
+
+// ResolveIncident ends the unresolved incident of a check, if any
+func (d *TimescaleDB) ResolveIncident(checkID int64, resolvedAt time.Time) error {
+	_, err := d.db.Exec(`UPDATE incidents SET resolved_at = GREATEST($2, started_at) WHERE check_id = $1 AND resolved_at IS NULL`,
+		checkID, resolvedAt.UTC())
+	return err
+}
+
+// GetIncidents returns incidents that overlap the time since since, newest first
+func (d *TimescaleDB) GetIncidents(since *time.Time, filter models.EventFilter) ([]models.Incident, error) {
+	conditions := []string{"1 = 1"}
+	args := []interface{}{}
+	addArg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
 	}
 
-	if probeID.Valid {
-		h.ProbeID = &probeID.Int64
+	if since != nil {
+		conditions = append(conditions, "(i.resolved_at IS NULL OR i.resolved_at >= "+addArg(since.UTC())+")")
 	}
-	if h.Region == "" {
-		h.Region = "host"
+	if filter.CheckID != nil {
+		conditions = append(conditions, "i.check_id = "+addArg(*filter.CheckID))
+	}
+	if filter.GroupID != nil {
+		conditions = append(conditions, "c.group_id = "+addArg(*filter.GroupID))
+	}
+	if filter.TagID != nil {
+		conditions = append(conditions, "EXISTS (SELECT 1 FROM check_tags ct WHERE ct.check_id = i.check_id AND ct.tag_id = "+addArg(*filter.TagID)+")")
+	}
+	if filter.PublicOnly {
+		conditions = append(conditions, "c.public = true")
 	}
 
-	return &h, nil
-}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 500
+	}
 
-func (d *TimescaleDB) GetLastStatusByRegion(checkID int64) (map[string]*models.CheckHistory, error) {
-	rows, err := d.db.Query(`
-		SELECT DISTINCT ON (COALESCE(NULLIF(region, ''), 'host'))
-			id, check_id, status_code, response_time_ms, success, COALESCE(error_message, ''), checked_at, probe_id, COALESCE(NULLIF(region, ''), 'host'), COALESCE(response_body, '')
-		FROM check_history
-		WHERE check_id = $1
-		ORDER BY COALESCE(NULLIF(region, ''), 'host'), checked_at DESC
-	`, checkID)
+	query := fmt.Sprintf(`
+		SELECT i.id, i.check_id, c.name, i.cause, i.started_at, i.resolved_at
+		FROM incidents i
+		JOIN checks c ON c.id = i.check_id
+		WHERE %s
+		ORDER BY i.started_at DESC
+		LIMIT %s`, strings.Join(conditions, " AND "), addArg(limit))
+
+	rows, err := d.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	result := make(map[string]*models.CheckHistory)
+	now := time.Now().UTC()
+	incidents := make([]models.Incident, 0)
 	for rows.Next() {
-		var h models.CheckHistory
-		var probeID sql.NullInt64
-		if err := rows.Scan(&h.ID, &h.CheckID, &h.StatusCode, &h.ResponseTimeMs, &h.Success, &h.ErrorMessage, &h.CheckedAt, &probeID, &h.Region, &h.ResponseBody); err != nil {
+		var i models.Incident
+		var resolvedAt sql.NullTime
+		if err := rows.Scan(&i.ID, &i.CheckID, &i.CheckName, &i.Cause, &i.StartedAt, &resolvedAt); err != nil {
 			return nil, err
 		}
-		if probeID.Valid {
-			h.ProbeID = &probeID.Int64
-		}
-		if h.Region == "" {
-			h.Region = "host"
+		i.StartedAt = i.StartedAt.UTC()
+		end := now
+		if resolvedAt.Valid {
+			resolved := resolvedAt.Time.UTC()
+			i.ResolvedAt = &resolved
+			end = resolved
 		}
-		result[h.Region] = &h
+		i.DurationSeconds = int64(end.Sub(i.StartedAt).Seconds())
+		incidents = append(incidents, i)
 	}
 
-	return result, rows.Err()
+	return incidents, rows.Err()
 }
 
-func (d *TimescaleDB) GetStats(since *time.Time) (*models.Stats, error) {
+// GetStats counts the checks matching tags and their uptime, over results from since on
+func (d *TimescaleDB) GetStats(since *time.Time, tags models.TagFilter) (*models.Stats, error) {
 	var stats models.Stats
 
-	err := d.db.QueryRow("SELECT COUNT(*) FROM checks").Scan(&stats.TotalChecks)
+	tagWhere := ""
+	args := []interface{}{}
+	if len(tags.IDs) > 0 {
+		tagWhere = " AND " + tagCondition("c.id", tags, func(v interface{}) string {
+			args = append(args, v)
+			return fmt.Sprintf("$%d", len(args))
+		})
+	}
+
+	err := d.db.QueryRow("SELECT COUNT(*) FROM checks c WHERE 1 = 1"+tagWhere, args...).Scan(&stats.TotalChecks)
 	if err != nil {
 		return nil, err
 	}
 
-	err = d.db.QueryRow("SELECT COUNT(*) FROM checks WHERE enabled = true").Scan(&stats.ActiveChecks)
+	err = d.db.QueryRow("SELECT COUNT(*) FROM checks c WHERE c.enabled = true"+tagWhere, args...).Scan(&stats.ActiveChecks)
 	if err != nil {
 		return nil, err
 	}
@@ -804,14 +1758,14 @@ func (d *TimescaleDB) GetStats(since *time.Time) (*models.Stats, error) {
 			SELECT DISTINCT ON (c.id) c.id, h.success
 			FROM checks c
 			LEFT JOIN check_history h ON h.check_id = c.id
-			WHERE c.enabled = true
+			WHERE c.enabled = true`+tagWhere+`
 			ORDER BY c.id, h.checked_at DESC
 		)
 		SELECT 
 			COUNT(*) FILTER (WHERE success = true) as up_count,
 			COUNT(*) FILTER (WHERE success = false OR success IS NULL) as down_count
 		FROM latest_status
-	`)
+	`, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -831,11 +1785,11 @@ func (d *TimescaleDB) GetStats(since *time.Time) (*models.Stats, error) {
 		SELECT COUNT(*), COUNT(*) FILTER (WHERE h.success = true)
 		FROM check_history h
 		JOIN checks c ON h.check_id = c.id
-		WHERE c.enabled = true`
-	uptimeArgs := []interface{}{}
+		WHERE c.enabled = true` + tagWhere
+	uptimeArgs := append([]interface{}{}, args...)
 	if since != nil {
-		uptimeQuery += " AND h.checked_at >= $1"
 		uptimeArgs = append(uptimeArgs, since)
+		uptimeQuery += fmt.Sprintf(" AND h.checked_at >= $%d", len(uptimeArgs))
 	}
 	err = d.db.QueryRow(uptimeQuery, uptimeArgs...).Scan(&totalChecks, &successfulChecks)
 	if err == nil && totalChecks > 0 {
@@ -1023,11 +1977,17 @@ func (d *TimescaleDB) CreateTag(t *models.Tag) error {
 		INSERT INTO tags (name, color) VALUES ($1, $2)
 		RETURNING id
 	`, t.Name, t.Color).Scan(&t.ID)
+	if isUniqueViolation(err) {
+		return ErrDuplicateTag
+	}
 	return err
 }
 
 func (d *TimescaleDB) UpdateTag(t *models.Tag) error {
 	_, err := d.db.Exec(`UPDATE tags SET name = $1, color = $2 WHERE id = $3`, t.Name, t.Color, t.ID)
+	if isUniqueViolation(err) {
+		return ErrDuplicateTag
+	}
 	return err
 }
 
@@ -1036,6 +1996,152 @@ func (d *TimescaleDB) DeleteTag(id int64) error {
 	return err
 }
 
+const notifierColumns = `id, type, name, config, enabled, created_at`
+
+func scanNotifier(row rowScanner) (*models.NotifierConfig, error) {
+	var n models.NotifierConfig
+	var config []byte
+	if err := row.Scan(&n.ID, &n.Type, &n.Name, &config, &n.Enabled, &n.CreatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(config, &n.Config); err != nil {
+		return nil, fmt.Errorf("notifier %d has an invalid config: %w", n.ID, err)
+	}
+	return &n, nil
+}
+
+func (d *TimescaleDB) GetAllNotifiers() ([]models.NotifierConfig, error) {
+	rows, err := d.db.Query(`SELECT ` + notifierColumns + ` FROM notifiers ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notifiers := []models.NotifierConfig{}
+	for rows.Next() {
+		n, err := scanNotifier(rows)
+		if err != nil {
+			return nil, err
+		}
+		notifiers = append(notifiers, *n)
+	}
+	return notifiers, rows.Err()
+}
+
+func (d *TimescaleDB) GetNotifier(id int64) (*models.NotifierConfig, error) {
+	n, err := scanNotifier(d.db.QueryRow(`SELECT `+notifierColumns+` FROM notifiers WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return n, err
+}
+
+func (d *TimescaleDB) CreateNotifier(n *models.NotifierConfig) error {
+	config, err := json.Marshal(n.Config)
+	if err != nil {
+		return err
+	}
+	return d.db.QueryRow(`
+		INSERT INTO notifiers (type, name, config, enabled) VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`, n.Type, n.Name, config, n.Enabled).Scan(&n.ID, &n.CreatedAt)
+}
+
+func (d *TimescaleDB) UpdateNotifier(n *models.NotifierConfig) error {
+	config, err := json.Marshal(n.Config)
+	if err != nil {
+		return err
+	}
+	_, err = d.db.Exec(`UPDATE notifiers SET name = $1, config = $2, enabled = $3 WHERE id = $4`,
+		n.Name, config, n.Enabled, n.ID)
+	return err
+}
+
+func (d *TimescaleDB) DeleteNotifier(id int64) error {
+	_, err := d.db.Exec(`DELETE FROM notifiers WHERE id = $1`, id)
+	return err
+}
+
+func (d *TimescaleDB) CreateAuditEntry(entry *models.AuditEntry) error {
+	var details []byte
+	if len(entry.Details) > 0 {
+		var err error
+		if details, err = json.Marshal(entry.Details); err != nil {
+			return err
+		}
+	}
+	return d.db.QueryRow(`
+		INSERT INTO audit_log (user_id, username, action, target_type, target_id, details)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`, entry.UserID, entry.Username, entry.Action, entry.TargetType, entry.TargetID, details).Scan(&entry.ID, &entry.CreatedAt)
+}
+
+// GetAuditEntries returns a page of the audit log, newest first, and the number of
+// entries matching the filter
+func (d *TimescaleDB) GetAuditEntries(filter models.AuditFilter) ([]models.AuditEntry, int, error) {
+	conditions := []string{"1 = 1"}
+	args := []interface{}{}
+	addArg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.Since != nil {
+		conditions = append(conditions, "created_at >= "+addArg(filter.Since.UTC()))
+	}
+	if filter.Until != nil {
+		conditions = append(conditions, "created_at < "+addArg(filter.Until.UTC()))
+	}
+	where := strings.Join(conditions, " AND ")
+
+	var total int
+	if err := d.db.QueryRow(`SELECT COUNT(*) FROM audit_log WHERE `+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+	query := fmt.Sprintf(`
+		SELECT id, user_id, username, action, target_type, target_id, details, created_at
+		FROM audit_log
+		WHERE %s
+		ORDER BY created_at DESC, id DESC
+		LIMIT %s OFFSET %s`, where, addArg(limit), addArg(filter.Offset))
+
+	rows, err := d.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	entries := []models.AuditEntry{}
+	for rows.Next() {
+		var e models.AuditEntry
+		var userID, targetID sql.NullInt64
+		var details []byte
+		if err := rows.Scan(&e.ID, &userID, &e.Username, &e.Action, &e.TargetType, &targetID, &details, &e.CreatedAt); err != nil {
+			return nil, 0, err
+		}
+		if userID.Valid {
+			e.UserID = &userID.Int64
+		}
+		if targetID.Valid {
+			e.TargetID = &targetID.Int64
+		}
+		if len(details) > 0 {
+			if err := json.Unmarshal(details, &e.Details); err != nil {
+				return nil, 0, fmt.Errorf("audit entry %d has invalid details: %w", e.ID, err)
+			}
+		}
+		e.CreatedAt = e.CreatedAt.UTC()
+		entries = append(entries, e)
+	}
+	return entries, total, rows.Err()
+}
+
 func (d *TimescaleDB) GetCheckTags(checkID int64) ([]models.Tag, error) {
 	rows, err := d.db.Query(`
 		SELECT t.id, t.name, t.color 
@@ -1082,10 +2188,104 @@ func (d *TimescaleDB) SetCheckTags(checkID int64, tagIDs []int64) error {
 	return tx.Commit()
 }
 
+// GetCheckRegions returns the probe regions assigned to a check, sorted
+func (d *TimescaleDB) GetCheckRegions(checkID int64) ([]string, error) {
+	rows, err := d.db.Query(`SELECT region FROM check_regions WHERE check_id = $1 ORDER BY region`, checkID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	regions := []string{}
+	for rows.Next() {
+		var region string
+		if err := rows.Scan(&region); err != nil {
+			return nil, err
+		}
+		regions = append(regions, region)
+	}
+	return regions, rows.Err()
+}
+
+// GetAllCheckRegions returns the assigned regions of every check that has any
+func (d *TimescaleDB) GetAllCheckRegions() (map[int64][]string, error) {
+	rows, err := d.db.Query(`SELECT check_id, region FROM check_regions ORDER BY check_id, region`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[int64][]string)
+	for rows.Next() {
+		var checkID int64
+		var region string
+		if err := rows.Scan(&checkID, &region); err != nil {
+			return nil, err
+		}
+		result[checkID] = append(result[checkID], region)
+	}
+	return result, rows.Err()
+}
+
+// SetCheckRegions replaces the probe regions assigned to a check
+func (d *TimescaleDB) SetCheckRegions(checkID int64, regions []string) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`DELETE FROM check_regions WHERE check_id = $1`, checkID)
+	if err != nil {
+		return err
+	}
+
+	for _, region := range regions {
+		_, err := tx.Exec(`INSERT INTO check_regions (check_id, region) VALUES ($1, $2)`, checkID, region)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// UpdateTagChecks adds and removes a tag on many checks in one transaction. Unknown
+// check IDs are ignored; the returned counts only include rows that changed.
+func (d *TimescaleDB) UpdateTagChecks(tagID int64, assign, unassign []int64) (assigned, unassigned int64, err error) {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback()
+
+	if len(assign) > 0 {
+		res, err := tx.Exec(`
+			INSERT INTO check_tags (check_id, tag_id)
+			SELECT id, $1 FROM checks WHERE id = ANY($2)
+			ON CONFLICT DO NOTHING
+		`, tagID, pq.Array(assign))
+		if err != nil {
+			return 0, 0, err
+		}
+		assigned, _ = res.RowsAffected()
+	}
+
+	if len(unassign) > 0 {
+		res, err := tx.Exec(`DELETE FROM check_tags WHERE tag_id = $1 AND check_id = ANY($2)`, tagID, pq.Array(unassign))
+		if err != nil {
+			return 0, 0, err
+		}
+		unassigned, _ = res.RowsAffected()
+	}
+
+	return assigned, unassigned, tx.Commit()
+}
+
 func (d *TimescaleDB) GetUserByUsername(username string) (*models.User, error) {
 	var u models.User
-	err := d.db.QueryRow(`SELECT id, username, password_hash, created_at FROM users WHERE username = $1`, username).
-		Scan(&u.ID, &u.Username, &u.PasswordHash, &u.CreatedAt)
+	err := d.db.QueryRow(`SELECT id, username, role, password_hash, created_at FROM users WHERE username = $1`, username).
+		Scan(&u.ID, &u.Username, &u.Role, &u.PasswordHash, &u.CreatedAt)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -1097,8 +2297,8 @@ func (d *TimescaleDB) GetUserByUsername(username string) (*models.User, error) {
 
 func (d *TimescaleDB) GetUserByID(id int64) (*models.User, error) {
 	var u models.User
-	err := d.db.QueryRow(`SELECT id, username, password_hash, created_at FROM users WHERE id = $1`, id).
-		Scan(&u.ID, &u.Username, &u.PasswordHash, &u.CreatedAt)
+	err := d.db.QueryRow(`SELECT id, username, role, password_hash, created_at FROM users WHERE id = $1`, id).
+		Scan(&u.ID, &u.Username, &u.Role, &u.PasswordHash, &u.CreatedAt)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -1110,9 +2310,12 @@ func (d *TimescaleDB) GetUserByID(id int64) (*models.User, error) {
 
 func (d *TimescaleDB) CreateUser(u *models.User) error {
 	err := d.db.QueryRow(`
-		INSERT INTO users (username, password_hash) VALUES ($1, $2)
+		INSERT INTO users (username, password_hash, role) VALUES ($1, $2, $3)
 		RETURNING id, created_at
-	`, u.Username, u.PasswordHash).Scan(&u.ID, &u.CreatedAt)
+	`, u.Username, u.PasswordHash, u.Role).Scan(&u.ID, &u.CreatedAt)
+	if isUniqueViolation(err) {
+		return ErrDuplicateUser
+	}
 	return err
 }
 
@@ -1125,6 +2328,34 @@ func (d *TimescaleDB) HasUsers() (bool, error) {
 	return count > 0, nil
 }
 
+func (d *TimescaleDB) GetAllUsers() ([]models.User, error) {
+	rows, err := d.db.Query(`SELECT id, username, role, password_hash, created_at FROM users ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := make([]models.User, 0, 10)
+	for rows.Next() {
+		var u models.User
+		if err := rows.Scan(&u.ID, &u.Username, &u.Role, &u.PasswordHash, &u.CreatedAt); err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	return users, rows.Err()
+}
+
+func (d *TimescaleDB) UpdateUser(u *models.User) error {
+	_, err := d.db.Exec(`UPDATE users SET role = $1, password_hash = $2 WHERE id = $3`, u.Role, u.PasswordHash, u.ID)
+	return err
+}
+
+func (d *TimescaleDB) DeleteUser(id int64) error {
+	_, err := d.db.Exec(`DELETE FROM users WHERE id = $1`, id)
+	return err
+}
+
 func (d *TimescaleDB) CreateAPIKey(key *models.APIKey) error {
 	err := d.db.QueryRow(`
 		INSERT INTO api_keys (user_id, name, key_hash) VALUES ($1, $2, $3)
@@ -1193,8 +2424,11 @@ func (d *TimescaleDB) CreateSession(session *models.Session) error {
 
 func (d *TimescaleDB) GetSessionByToken(token string) (*models.Session, error) {
 	var s models.Session
-	err := d.db.QueryRow(`SELECT id, token, user_id, username, expires_at, created_at FROM sessions WHERE token = $1 AND expires_at > CURRENT_TIMESTAMP`,
-		token).Scan(&s.ID, &s.Token, &s.UserID, &s.Username, &s.ExpiresAt, &s.CreatedAt)
+	// The role comes from users so a role change applies to sessions already open
+	err := d.db.QueryRow(`SELECT s.id, s.token, s.user_id, s.username, u.role, s.expires_at, s.created_at
+		FROM sessions s JOIN users u ON u.id = s.user_id
+		WHERE s.token = $1 AND s.expires_at > CURRENT_TIMESTAMP`,
+		token).Scan(&s.ID, &s.Token, &s.UserID, &s.Username, &s.Role, &s.ExpiresAt, &s.CreatedAt)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -1225,6 +2459,9 @@ func (d *TimescaleDB) CreateWebAuthnCredential(cred *models.WebAuthnCredential)
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		RETURNING id, created_at
 	`, cred.UserID, cred.CredentialID, cred.PublicKey, cred.AttestationType, cred.AAGUID, cred.SignCount, cred.CloneWarning, cred.Name).Scan(&cred.ID, &cred.CreatedAt)
+	if isUniqueViolation(err) {
+		return ErrDuplicateCredential
+	}
 	return err
 }
 
@@ -1338,9 +2575,30 @@ func (d *TimescaleDB) UpdateProbeLastSeen(probeID int64) error {
 	return err
 }
 
+// probeColumns is the column list shared by probe queries; keep it in sync with scanProbe
+const probeColumns = `id, region_code, COALESCE(ip_address, ''), COALESCE(version, ''), status, last_seen_at,
+		COALESCE(country_code, ''), COALESCE(country, ''), COALESCE(city, ''), latitude, longitude`
+
+func scanProbe(row rowScanner) (*models.Probe, error) {
+	var p models.Probe
+	var lastSeenAt sql.NullTime
+	var latitude, longitude sql.NullFloat64
+	if err := row.Scan(&p.ID, &p.RegionCode, &p.IPAddress, &p.Version, &p.Status, &lastSeenAt,
+		&p.CountryCode, &p.Country, &p.City, &latitude, &longitude); err != nil {
+		return nil, err
+	}
+	if lastSeenAt.Valid {
+		p.LastSeenAt = &lastSeenAt.Time
+	}
+	if latitude.Valid && longitude.Valid {
+		p.Latitude, p.Longitude = &latitude.Float64, &longitude.Float64
+	}
+	return &p, nil
+}
+
 func (d *TimescaleDB) GetAllProbes() ([]models.Probe, error) {
 	rows, err := d.db.Query(`
-		SELECT id, region_code, COALESCE(ip_address, ''), COALESCE(version, ''), status, last_seen_at
+		SELECT ` + probeColumns + `
 		FROM probes
 		ORDER BY region_code
 	`)
@@ -1351,37 +2609,36 @@ func (d *TimescaleDB) GetAllProbes() ([]models.Probe, error) {
 
 	probes := make([]models.Probe, 0)
 	for rows.Next() {
-		var p models.Probe
-		var lastSeenAt sql.NullTime
-		if err := rows.Scan(&p.ID, &p.RegionCode, &p.IPAddress, &p.Version, &p.Status, &lastSeenAt); err != nil {
+		p, err := scanProbe(rows)
+		if err != nil {
 			return nil, err
 		}
-		if lastSeenAt.Valid {
-			p.LastSeenAt = &lastSeenAt.Time
-		}
-		probes = append(probes, p)
+		probes = append(probes, *p)
 	}
 	return probes, rows.Err()
 }
 
 func (d *TimescaleDB) GetProbeByID(id int64) (*models.Probe, error) {
-	var p models.Probe
-	var lastSeenAt sql.NullTime
-	err := d.db.QueryRow(`
-		SELECT id, region_code, COALESCE(ip_address, ''), COALESCE(version, ''), status, last_seen_at
+	p, err := scanProbe(d.db.QueryRow(`
+		SELECT `+probeColumns+`
 		FROM probes
 		WHERE id = $1
-	`, id).Scan(&p.ID, &p.RegionCode, &p.IPAddress, &p.Version, &p.Status, &lastSeenAt)
+	`, id))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
-	if err != nil {
-		return nil, err
-	}
-	if lastSeenAt.Valid {
-		p.LastSeenAt = &lastSeenAt.Time
-	}
-	return &p, nil
+	return p, err
+}
+
+// UpdateProbeLocation stores where a probe is; empty fields are stored as NULL
+func (d *TimescaleDB) UpdateProbeLocation(id int64, location models.ProbeLocation) error {
+	_, err := d.db.Exec(`
+		UPDATE probes
+		SET country_code = NULLIF($1, ''), country = NULLIF($2, ''), city = NULLIF($3, ''),
+			latitude = $4, longitude = $5
+		WHERE id = $6
+	`, location.CountryCode, location.Country, location.City, location.Latitude, location.Longitude, id)
+	return err
 }
 
 func (d *TimescaleDB) DeleteProbe(id int64) error {
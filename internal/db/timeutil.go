@@ -0,0 +1,32 @@
+package db
+
+import (
+	"fmt"
+	"time"
+)
+
+// bucketExpr returns a SQL expression that floors checked_at to an epoch-aligned bucket.
+// Unlike time_bucket it works on plain PostgreSQL as well as TimescaleDB, and it matches
+// bucketStart so both backends report identical bucket timestamps.
+func bucketExpr(bucketMinutes int) string {
+	seconds := bucketSeconds(bucketMinutes)
+	return fmt.Sprintf("to_timestamp(floor(extract(epoch FROM checked_at) / %d) * %d)", seconds, seconds)
+}
+
+// bucketStart floors t to the start of its epoch-aligned bucket in UTC
+func bucketStart(t time.Time, bucketMinutes int) time.Time {
+	seconds := int64(bucketSeconds(bucketMinutes))
+	unix := t.Unix()
+	floored := unix - unix%seconds
+	if unix%seconds < 0 {
+		floored -= seconds
+	}
+	return time.Unix(floored, 0).UTC()
+}
+
+func bucketSeconds(bucketMinutes int) int {
+	if bucketMinutes <= 0 {
+		bucketMinutes = 1
+	}
+	return bucketMinutes * 60
+}
@@ -0,0 +1,55 @@
+package db
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBucketStart(t *testing.T) {
+	jakarta := time.FixedZone("WIB", 7*60*60)
+
+	tests := []struct {
+		name    string
+		in      time.Time
+		minutes int
+		want    time.Time
+	}{
+		{"5 minute", time.Date(2024, 5, 1, 10, 7, 59, 0, time.UTC), 5, time.Date(2024, 5, 1, 10, 5, 0, 0, time.UTC)},
+		{"exact boundary", time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC), 30, time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)},
+		{"2 hour", time.Date(2024, 5, 1, 13, 59, 0, 0, time.UTC), 120, time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)},
+		{"non-UTC input", time.Date(2024, 5, 1, 17, 7, 0, 0, jakarta), 60, time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)},
+		{"sub-second", time.Date(2024, 5, 1, 10, 4, 59, 999000000, time.UTC), 5, time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := bucketStart(tt.in, tt.minutes)
+			if !got.Equal(tt.want) || got.Location() != time.UTC {
+				t.Errorf("bucketStart(%v, %d) = %v, want %v", tt.in, tt.minutes, got, tt.want)
+			}
+		})
+	}
+}
+
+// Identical data must land in identical buckets whether it was bucketed by the
+// database (TimescaleDB or plain PostgreSQL) or re-normalized in Go.
+func TestBucketStartIdempotent(t *testing.T) {
+	base := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	for _, minutes := range []int{5, 30, 60, 120, 360} {
+		for i := 0; i < 24*60; i += 7 {
+			ts := base.Add(time.Duration(i) * time.Minute)
+			once := bucketStart(ts, minutes)
+			if twice := bucketStart(once, minutes); !twice.Equal(once) {
+				t.Fatalf("bucketStart not idempotent for %v/%d: %v != %v", ts, minutes, twice, once)
+			}
+		}
+	}
+}
+
+func TestBucketExpr(t *testing.T) {
+	got := bucketExpr(30)
+	if !strings.Contains(got, "/ 1800) * 1800") {
+		t.Errorf("bucketExpr(30) = %q, want 1800 second buckets", got)
+	}
+}
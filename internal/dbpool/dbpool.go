@@ -0,0 +1,106 @@
+// Package dbpool keeps database/sql pools open between runs of database checks, so a
+// check that runs every few seconds reuses its connection instead of opening a new
+// one against the monitored server each time. It is shared by the server and probes.
+package dbpool
+
+import (
+	"database/sql"
+	"sync"
+	"time"
+)
+
+type key struct {
+	driver, dsn string
+}
+
+type entry struct {
+	db       *sql.DB
+	lastUsed time.Time
+	owners   map[int64]struct{} // checks that have used the pool
+}
+
+// Cache holds one single-connection pool per driver and connection string, shared by
+// every check that uses the string. Pools unused for longer than the TTL are closed the
+// next time the cache is used.
+type Cache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu    sync.Mutex
+	pools map[key]*entry
+}
+
+// New creates a cache whose pools are closed after ttl without use
+func New(ttl time.Duration) *Cache {
+	return &Cache{ttl: ttl, now: time.Now, pools: make(map[key]*entry)}
+}
+
+// Get returns the pool for a connection string, opening it on first use, and counts
+// owner among its users until Release. Callers bound each use with a context; they
+// must not close the pool.
+func (c *Cache) Get(owner int64, driver, dsn string) (*sql.DB, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	c.evictIdle(now)
+
+	k := key{driver, dsn}
+	if e, ok := c.pools[k]; ok {
+		e.lastUsed = now
+		e.owners[owner] = struct{}{}
+		return e.db, nil
+	}
+
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+	// A single connection is enough for a check and keeps the footprint on the
+	// monitored server minimal; recycling it picks up credential and server changes
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(1)
+	db.SetConnMaxIdleTime(c.ttl)
+	db.SetConnMaxLifetime(c.ttl)
+	c.pools[k] = &entry{db: db, lastUsed: now, owners: map[int64]struct{}{owner: {}}}
+	return db, nil
+}
+
+// Release stops counting owner among the users of a connection string's pool, such as
+// when its check is deleted or moves to another connection string. The pool is closed
+// once no other check uses it.
+func (c *Cache) Release(owner int64, driver, dsn string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	k := key{driver, dsn}
+	e, ok := c.pools[k]
+	if !ok {
+		return
+	}
+	delete(e.owners, owner)
+	if len(e.owners) == 0 {
+		e.db.Close()
+		delete(c.pools, k)
+	}
+}
+
+// CloseAll closes every pool
+func (c *Cache) CloseAll() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for k, e := range c.pools {
+		e.db.Close()
+		delete(c.pools, k)
+	}
+}
+
+func (c *Cache) evictIdle(now time.Time) {
+	for k, e := range c.pools {
+		if now.Sub(e.lastUsed) > c.ttl {
+			e.db.Close()
+			delete(c.pools, k)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package dbpool
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"strings"
+	"testing"
+	"time"
+)
+
+// nopDriver never connects; sql.Open only validates the driver name
+type nopDriver struct{}
+
+func (nopDriver) Open(string) (driver.Conn, error) { return nil, driver.ErrBadConn }
+
+func init() {
+	sql.Register("dbpool-test", nopDriver{})
+}
+
+func TestCacheReusesAndEvicts(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := New(10 * time.Minute)
+	c.now = func() time.Time { return now }
+
+	first, err := c.Get(1, "dbpool-test", "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := c.Get(1, "dbpool-test", "a"); again != first {
+		t.Error("the same connection string opened a second pool")
+	}
+	if other, _ := c.Get(1, "dbpool-test", "b"); other == first {
+		t.Error("different connection strings share a pool")
+	}
+
+	// "a" stays in use while "b" goes idle past the TTL
+	now = now.Add(6 * time.Minute)
+	c.Get(1, "dbpool-test", "a")
+	now = now.Add(6 * time.Minute)
+	c.Get(1, "dbpool-test", "a")
+	if len(c.pools) != 1 {
+		t.Errorf("%d pools open, want the idle one evicted", len(c.pools))
+	}
+
+	c.Release(1, "dbpool-test", "a")
+	if len(c.pools) != 0 {
+		t.Errorf("%d pools open after Release", len(c.pools))
+	}
+	if reopened, _ := c.Get(1, "dbpool-test", "a"); reopened == first {
+		t.Error("a closed pool was handed out again")
+	}
+}
+
+func TestCacheKeepsSharedPoolOpen(t *testing.T) {
+	c := New(10 * time.Minute)
+	closed := func(db *sql.DB) bool {
+		err := db.Ping()
+		return err != nil && strings.Contains(err.Error(), "database is closed")
+	}
+
+	// Two checks share a connection string, then the first is deleted
+	shared, _ := c.Get(1, "dbpool-test", "shared")
+	if again, _ := c.Get(2, "dbpool-test", "shared"); again != shared {
+		t.Fatal("checks with the same connection string got different pools")
+	}
+	c.Release(1, "dbpool-test", "shared")
+	if closed(shared) {
+		t.Fatal("releasing one check closed the pool the other still uses")
+	}
+	if again, _ := c.Get(2, "dbpool-test", "shared"); again != shared {
+		t.Error("the remaining check got a new pool")
+	}
+
+	// Releasing twice, or for a check that never used the pool, doesn't close it
+	c.Release(1, "dbpool-test", "shared")
+	c.Release(3, "dbpool-test", "shared")
+	if closed(shared) {
+		t.Fatal("a stale release closed the pool")
+	}
+
+	c.Release(2, "dbpool-test", "shared")
+	if !closed(shared) {
+		t.Error("the pool stayed open after its last check was released")
+	}
+}
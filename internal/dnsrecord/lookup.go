@@ -0,0 +1,227 @@
+// Package dnsrecord resolves the record types supported by DNS checks and formats each
+// record as a single string for expected-value matching.
+package dnsrecord
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// Types are the supported record types
+var Types = map[string]bool{
+	"A": true, "AAAA": true, "CNAME": true, "MX": true, "TXT": true,
+	"SRV": true, "NS": true, "PTR": true, "CAA": true, "SOA": true,
+}
+
+// DefaultPort is the nameserver port used when a resolver address has none
+const DefaultPort = "53"
+
+// resolvConf lists the system's nameservers for queries the stdlib resolver can't make
+const resolvConf = "/etc/resolv.conf"
+
+// Resolver looks up records, by default through the system's nameservers
+type Resolver struct {
+	// Server is the nameserver to ask instead of the system's, as accepted by ServerAddr
+	Server string
+}
+
+// ServerAddr validates a nameserver address and returns it as host:port. The port
+// defaults to 53, so 8.8.8.8, ns1.example.com:5353 and [2001:4860:4860::8888]:53 are
+// all accepted, as is a bare IPv6 address.
+func ServerAddr(server string) (string, error) {
+	server = strings.TrimSpace(server)
+	if ip := net.ParseIP(strings.Trim(server, "[]")); ip != nil {
+		return net.JoinHostPort(ip.String(), DefaultPort), nil
+	}
+
+	host, port, err := net.SplitHostPort(server)
+	if err != nil {
+		host, port = server, DefaultPort
+	}
+	if host == "" || strings.ContainsAny(host, " /[]") {
+		return "", fmt.Errorf("invalid nameserver address %q", server)
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		return "", fmt.Errorf("invalid nameserver port %q", port)
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+// Lookup resolves records of recordType for name through the system's nameservers
+func Lookup(ctx context.Context, name, recordType string) ([]string, error) {
+	return (&Resolver{}).Lookup(ctx, name, recordType)
+}
+
+// Lookup resolves records of recordType (case-insensitive, A when empty) for name. SRV
+// names include the service and protocol labels, such as _sip._tcp.example.com, and PTR
+// lookups take an IP address. Records are formatted as:
+//
+//	A, AAAA, CNAME, NS, PTR, TXT   the address, name or text
+//	MX    mail.example.com. (priority: 10)
+//	SRV   sip.example.com.:5060 (priority: 10, weight: 5)
+//	CAA   0 issue "letsencrypt.org"
+//	SOA   ns1.example.com. hostmaster.example.com. 2024010101 7200 3600 1209600 300
+func (r *Resolver) Lookup(ctx context.Context, name, recordType string) ([]string, error) {
+	if recordType == "" {
+		recordType = "A"
+	}
+
+	var server string
+	if r.Server != "" {
+		var err error
+		if server, err = ServerAddr(r.Server); err != nil {
+			return nil, err
+		}
+	}
+
+	resolver := &net.Resolver{
+		PreferGo: true,
+	}
+	if server != "" {
+		// Send every query to the chosen nameserver, whatever the system config says
+		resolver.Dial = func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, server)
+		}
+	}
+
+	var records []string
+	switch strings.ToUpper(recordType) {
+	case "A", "AAAA":
+		network := "ip4"
+		if strings.ToUpper(recordType) == "AAAA" {
+			network = "ip6"
+		}
+		ips, err := resolver.LookupIP(ctx, network, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, ip := range ips {
+			records = append(records, ip.String())
+		}
+	case "CNAME":
+		cname, err := resolver.LookupCNAME(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, cname)
+	case "MX":
+		mxs, err := resolver.LookupMX(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, mx := range mxs {
+			records = append(records, fmt.Sprintf("%s (priority: %d)", mx.Host, mx.Pref))
+		}
+	case "TXT":
+		txts, err := resolver.LookupTXT(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		records = txts
+	case "SRV":
+		_, srvs, err := resolver.LookupSRV(ctx, "", "", name)
+		if err != nil {
+			return nil, err
+		}
+		for _, srv := range srvs {
+			records = append(records, formatSRV(srv))
+		}
+	case "NS":
+		nss, err := resolver.LookupNS(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, ns := range nss {
+			records = append(records, ns.Host)
+		}
+	case "PTR":
+		if net.ParseIP(name) == nil {
+			return nil, fmt.Errorf("PTR lookups need an IP address, got %q", name)
+		}
+		names, err := resolver.LookupAddr(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		records = names
+	case "CAA", "SOA":
+		// The stdlib resolver has no lookups for these, so query the nameserver directly
+		answers, err := query(ctx, server, name, dns.StringToType[strings.ToUpper(recordType)])
+		if err != nil {
+			return nil, err
+		}
+		for _, rr := range answers {
+			switch rr := rr.(type) {
+			case *dns.CAA:
+				records = append(records, formatCAA(rr))
+			case *dns.SOA:
+				records = append(records, formatSOA(rr))
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported record type: %s", recordType)
+	}
+	return records, nil
+}
+
+// query asks server, or else the system's nameservers in turn, for records of qtype,
+// retrying over TCP when a UDP answer is truncated, and returns the answer section of
+// the first reply
+func query(ctx context.Context, server, name string, qtype uint16) ([]dns.RR, error) {
+	servers := []string{server}
+	if server == "" {
+		config, err := dns.ClientConfigFromFile(resolvConf)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read nameservers: %w", err)
+		}
+		servers = servers[:0]
+		for _, server := range config.Servers {
+			servers = append(servers, net.JoinHostPort(server, config.Port))
+		}
+		if len(servers) == 0 {
+			return nil, fmt.Errorf("no nameservers configured in %s", resolvConf)
+		}
+	}
+
+	msg := new(dns.Msg)
+	msg.SetQuestion(dns.Fqdn(name), qtype)
+
+	var lastErr error
+	for _, server := range servers {
+		reply, _, err := (&dns.Client{}).ExchangeContext(ctx, msg, server)
+		if err == nil && reply.Truncated {
+			reply, _, err = (&dns.Client{Net: "tcp"}).ExchangeContext(ctx, msg, server)
+		}
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		switch reply.Rcode {
+		case dns.RcodeSuccess:
+			return reply.Answer, nil
+		case dns.RcodeNameError:
+			return nil, fmt.Errorf("no such host: %s", name)
+		default:
+			lastErr = fmt.Errorf("%s answered %s", server, dns.RcodeToString[reply.Rcode])
+		}
+	}
+	return nil, lastErr
+}
+
+func formatSRV(srv *net.SRV) string {
+	return fmt.Sprintf("%s:%d (priority: %d, weight: %d)", srv.Target, srv.Port, srv.Priority, srv.Weight)
+}
+
+func formatCAA(caa *dns.CAA) string {
+	return fmt.Sprintf("%d %s %q", caa.Flag, caa.Tag, caa.Value)
+}
+
+func formatSOA(soa *dns.SOA) string {
+	return fmt.Sprintf("%s %s %d %d %d %d %d", soa.Ns, soa.Mbox, soa.Serial, soa.Refresh, soa.Retry, soa.Expire, soa.Minttl)
+}
@@ -0,0 +1,117 @@
+package dnsrecord
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// startServer serves the given records over UDP on a local port
+func startServer(t *testing.T, records ...string) string {
+	t.Helper()
+
+	zone := make(map[string][]dns.RR)
+	for _, record := range records {
+		rr, err := dns.NewRR(record)
+		if err != nil {
+			t.Fatal(err)
+		}
+		key := dns.CanonicalName(rr.Header().Name) + dns.TypeToString[rr.Header().Rrtype]
+		zone[key] = append(zone[key], rr)
+	}
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := &dns.Server{PacketConn: pc, Handler: dns.HandlerFunc(func(w dns.ResponseWriter, req *dns.Msg) {
+		reply := new(dns.Msg)
+		reply.SetReply(req)
+		q := req.Question[0]
+		answers, ok := zone[dns.CanonicalName(q.Name)+dns.TypeToString[q.Qtype]]
+		if !ok {
+			reply.Rcode = dns.RcodeNameError
+		}
+		reply.Answer = answers
+		w.WriteMsg(reply)
+	})}
+	go server.ActivateAndServe()
+	t.Cleanup(func() { server.Shutdown() })
+
+	return pc.LocalAddr().String()
+}
+
+func TestLookup(t *testing.T) {
+	addr := startServer(t,
+		"_sip._tcp.example.com. 300 IN SRV 10 5 5060 sip.example.com.",
+		"4.3.2.1.in-addr.arpa. 300 IN PTR host.example.com.",
+		"example.com. 300 IN NS ns1.example.com.",
+		`example.com. 300 IN CAA 0 issue "letsencrypt.org"`,
+		"example.com. 300 IN SOA ns1.example.com. hostmaster.example.com. 2024010101 7200 3600 1209600 300",
+	)
+	r := &Resolver{Server: addr}
+
+	tests := []struct {
+		name       string
+		recordType string
+		want       []string
+		wantErr    bool
+	}{
+		{"_sip._tcp.example.com", "SRV", []string{"sip.example.com.:5060 (priority: 10, weight: 5)"}, false},
+		{"1.2.3.4", "ptr", []string{"host.example.com."}, false},
+		{"example.com", "NS", []string{"ns1.example.com."}, false},
+		{"example.com", "CAA", []string{`0 issue "letsencrypt.org"`}, false},
+		{"example.com", "SOA", []string{"ns1.example.com. hostmaster.example.com. 2024010101 7200 3600 1209600 300"}, false},
+		{"missing.example.com", "CAA", nil, true},
+		{"example.com", "PTR", nil, true},
+		{"example.com", "HINFO", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.recordType+" "+tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			got, err := r.Lookup(ctx, tt.name, tt.recordType)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Lookup() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Lookup() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServerAddr(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"8.8.8.8", "8.8.8.8:53", false},
+		{"8.8.8.8:5353", "8.8.8.8:5353", false},
+		{"ns1.example.com", "ns1.example.com:53", false},
+		{"2001:4860:4860::8888", "[2001:4860:4860::8888]:53", false},
+		{"[2001:4860:4860::8888]:853", "[2001:4860:4860::8888]:853", false},
+		{"8.8.8.8:dns", "", true},
+		{"8.8.8.8:0", "", true},
+		{"", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ServerAddr(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ServerAddr() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ServerAddr() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
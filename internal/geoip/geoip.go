@@ -0,0 +1,365 @@
+// Package geoip looks up where an IP address is in a MaxMind DB file, such as
+// GeoLite2-City or GeoLite2-Country, to place probes on a map.
+package geoip
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"net/netip"
+	"os"
+)
+
+// metadataMarker starts the metadata section at the end of the file
+var metadataMarker = []byte("\xAB\xCD\xEFMaxMind.com")
+
+// Location is where an address is, as far as the database knows. Fields it doesn't
+// have are left empty.
+type Location struct {
+	CountryCode string   // ISO 3166-1 alpha-2, e.g. "DE"
+	Country     string   // English name
+	City        string   // English name
+	Latitude    *float64 // approximate
+	Longitude   *float64
+}
+
+// Reader reads a MaxMind DB file held in memory
+type Reader struct {
+	buf        []byte
+	data       []byte // the data section
+	nodeCount  uint
+	recordSize uint
+	ipVersion  uint
+	ipv4Start  uint // node IPv4 addresses start from in an IPv6 tree
+}
+
+// Open reads the database at path
+func Open(path string) (*Reader, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return New(buf)
+}
+
+// New reads a database from its contents
+func New(buf []byte) (*Reader, error) {
+	start := bytes.LastIndex(buf, metadataMarker)
+	if start < 0 {
+		return nil, errors.New("not a MaxMind DB file: no metadata")
+	}
+	start += len(metadataMarker)
+	meta, _, err := (&decoder{buf: buf[start:]}).decode(0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid metadata: %w", err)
+	}
+	fields, ok := meta.(map[string]any)
+	if !ok {
+		return nil, errors.New("invalid metadata: not a map")
+	}
+
+	r := &Reader{buf: buf}
+	r.nodeCount = metaUint(fields, "node_count")
+	r.recordSize = metaUint(fields, "record_size")
+	r.ipVersion = metaUint(fields, "ip_version")
+	if r.recordSize != 24 && r.recordSize != 28 && r.recordSize != 32 {
+		return nil, fmt.Errorf("unsupported record size %d", r.recordSize)
+	}
+	if r.ipVersion != 4 && r.ipVersion != 6 {
+		return nil, fmt.Errorf("unsupported IP version %d", r.ipVersion)
+	}
+
+	treeSize := r.nodeCount * r.recordSize / 4
+	dataEnd := uint(start - len(metadataMarker))
+	if treeSize+16 > dataEnd {
+		return nil, errors.New("search tree is larger than the file")
+	}
+	r.data = buf[treeSize+16 : dataEnd]
+
+	if r.ipVersion == 6 {
+		node := uint(0)
+		for i := 0; i < 96 && node < r.nodeCount; i++ {
+			if node, err = r.record(node, 0); err != nil {
+				return nil, err
+			}
+		}
+		r.ipv4Start = node
+	}
+	return r, nil
+}
+
+func metaUint(fields map[string]any, key string) uint {
+	v, _ := fields[key].(uint64)
+	return uint(v)
+}
+
+// Lookup returns where ip is. It reports false when the database doesn't know the
+// address.
+func (r *Reader) Lookup(ip netip.Addr) (Location, bool, error) {
+	ip = ip.Unmap()
+	node := uint(0)
+	if ip.Is4() && r.ipVersion == 6 {
+		node = r.ipv4Start
+	} else if ip.Is6() && r.ipVersion == 4 {
+		return Location{}, false, nil
+	}
+
+	addr := ip.AsSlice()
+	var err error
+	for i := 0; i < len(addr)*8 && node < r.nodeCount; i++ {
+		bit := uint(addr[i/8]>>(7-i%8)) & 1
+		if node, err = r.record(node, bit); err != nil {
+			return Location{}, false, err
+		}
+	}
+	if node <= r.nodeCount {
+		return Location{}, false, nil
+	}
+
+	offset := node - r.nodeCount - 16
+	value, _, err := (&decoder{buf: r.data}).decode(offset, 0)
+	if err != nil {
+		return Location{}, false, fmt.Errorf("invalid record for %s: %w", ip, err)
+	}
+	record, _ := value.(map[string]any)
+	return location(record), true, nil
+}
+
+// record returns the left (bit 0) or right (bit 1) record of a node
+func (r *Reader) record(node, bit uint) (uint, error) {
+	size := r.recordSize / 4
+	off := node * size
+	if off+size > uint(len(r.buf)) {
+		return 0, errors.New("search tree node out of range")
+	}
+	b := r.buf[off : off+size]
+	switch r.recordSize {
+	case 24:
+		b = b[bit*3:]
+		return uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2]), nil
+	case 28:
+		if bit == 0 {
+			return uint(b[3]&0xF0)<<20 | uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2]), nil
+		}
+		return uint(b[3]&0x0F)<<24 | uint(b[4])<<16 | uint(b[5])<<8 | uint(b[6]), nil
+	default:
+		return uint(binary.BigEndian.Uint32(b[bit*4:])), nil
+	}
+}
+
+func location(record map[string]any) Location {
+	var loc Location
+	if country, ok := record["country"].(map[string]any); ok {
+		loc.CountryCode, _ = country["iso_code"].(string)
+		loc.Country = englishName(country)
+	}
+	if city, ok := record["city"].(map[string]any); ok {
+		loc.City = englishName(city)
+	}
+	if l, ok := record["location"].(map[string]any); ok {
+		lat, latOK := l["latitude"].(float64)
+		lon, lonOK := l["longitude"].(float64)
+		if latOK && lonOK {
+			loc.Latitude, loc.Longitude = &lat, &lon
+		}
+	}
+	return loc
+}
+
+func englishName(place map[string]any) string {
+	names, _ := place["names"].(map[string]any)
+	name, _ := names["en"].(string)
+	return name
+}
+
+// Data section types
+const (
+	typeExtended = iota
+	typePointer
+	typeString
+	typeDouble
+	typeBytes
+	typeUint16
+	typeUint32
+	typeMap
+	typeInt32
+	typeUint64
+	typeUint128
+	typeArray
+	typeContainer
+	typeEndMarker
+	typeBool
+	typeFloat
+)
+
+// maxDepth bounds nested maps, arrays and pointers, so a corrupt file can't recurse
+// forever
+const maxDepth = 32
+
+// decoder decodes values of a data section. Pointers are offsets into buf.
+type decoder struct {
+	buf []byte
+}
+
+var errTruncated = errors.New("unexpected end of data")
+
+// decode decodes the value at offset and returns it with the offset after it
+func (d *decoder) decode(offset uint, depth int) (any, uint, error) {
+	if depth > maxDepth {
+		return nil, 0, errors.New("data nested too deeply")
+	}
+	if offset >= uint(len(d.buf)) {
+		return nil, 0, errTruncated
+	}
+	ctrl := d.buf[offset]
+	offset++
+	kind := uint(ctrl >> 5)
+
+	if kind == typePointer {
+		target, next, err := d.pointer(ctrl, offset)
+		if err != nil {
+			return nil, 0, err
+		}
+		value, _, err := d.decode(target, depth+1)
+		return value, next, err
+	}
+
+	if kind == typeExtended {
+		if offset >= uint(len(d.buf)) {
+			return nil, 0, errTruncated
+		}
+		kind = 7 + uint(d.buf[offset])
+		offset++
+	}
+
+	size, offset, err := d.size(ctrl, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	switch kind {
+	case typeMap:
+		m := make(map[string]any, min(size, 64))
+		for range size {
+			var key, value any
+			if key, offset, err = d.decode(offset, depth+1); err != nil {
+				return nil, 0, err
+			}
+			name, ok := key.(string)
+			if !ok {
+				return nil, 0, errors.New("map key is not a string")
+			}
+			if value, offset, err = d.decode(offset, depth+1); err != nil {
+				return nil, 0, err
+			}
+			m[name] = value
+		}
+		return m, offset, nil
+	case typeArray:
+		a := make([]any, 0, min(size, 64))
+		for range size {
+			var value any
+			if value, offset, err = d.decode(offset, depth+1); err != nil {
+				return nil, 0, err
+			}
+			a = append(a, value)
+		}
+		return a, offset, nil
+	case typeBool:
+		return size != 0, offset, nil
+	case typeContainer, typeEndMarker:
+		return nil, offset, nil
+	}
+
+	if offset+size > uint(len(d.buf)) {
+		return nil, 0, errTruncated
+	}
+	b := d.buf[offset : offset+size]
+	offset += size
+
+	switch kind {
+	case typeString:
+		return string(b), offset, nil
+	case typeDouble:
+		if size != 8 {
+			return nil, 0, fmt.Errorf("invalid double size %d", size)
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), offset, nil
+	case typeFloat:
+		if size != 4 {
+			return nil, 0, fmt.Errorf("invalid float size %d", size)
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b))), offset, nil
+	case typeUint16, typeUint32, typeUint64:
+		if size > 8 {
+			return nil, 0, fmt.Errorf("invalid integer size %d", size)
+		}
+		var v uint64
+		for _, c := range b {
+			v = v<<8 | uint64(c)
+		}
+		return v, offset, nil
+	case typeInt32:
+		if size > 4 {
+			return nil, 0, fmt.Errorf("invalid integer size %d", size)
+		}
+		var v uint32
+		for _, c := range b {
+			v = v<<8 | uint32(c)
+		}
+		return int64(int32(v)), offset, nil
+	case typeBytes, typeUint128:
+		return b, offset, nil
+	}
+	return nil, 0, fmt.Errorf("unknown data type %d", kind)
+}
+
+// size reads the payload size following a control byte
+func (d *decoder) size(ctrl byte, offset uint) (uint, uint, error) {
+	size := uint(ctrl & 0x1f)
+	if size < 29 {
+		return size, offset, nil
+	}
+	n := size - 28
+	if offset+n > uint(len(d.buf)) {
+		return 0, 0, errTruncated
+	}
+	var v uint
+	for _, c := range d.buf[offset : offset+n] {
+		v = v<<8 | uint(c)
+	}
+	switch size {
+	case 29:
+		v += 29
+	case 30:
+		v += 285
+	default:
+		v += 65821
+	}
+	return v, offset + n, nil
+}
+
+// pointer reads the target of a pointer whose control byte is ctrl
+func (d *decoder) pointer(ctrl byte, offset uint) (uint, uint, error) {
+	n := uint(ctrl>>3&0x3) + 1
+	if offset+n > uint(len(d.buf)) {
+		return 0, 0, errTruncated
+	}
+	b := d.buf[offset : offset+n]
+	var v uint
+	if n < 4 {
+		v = uint(ctrl & 0x7)
+	}
+	for _, c := range b {
+		v = v<<8 | uint(c)
+	}
+	switch n {
+	case 2:
+		v += 2048
+	case 3:
+		v += 526336
+	}
+	return v, offset + n, nil
+}
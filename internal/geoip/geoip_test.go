@@ -0,0 +1,98 @@
+package geoip
+
+import (
+	"encoding/binary"
+	"math"
+	"net/netip"
+	"testing"
+)
+
+func encString(s string) []byte {
+	return append([]byte{typeString<<5 | byte(len(s))}, s...)
+}
+
+func encMap(pairs ...[]byte) []byte {
+	b := []byte{typeMap<<5 | byte(len(pairs)/2)}
+	for _, p := range pairs {
+		b = append(b, p...)
+	}
+	return b
+}
+
+func encUint16(v uint16) []byte {
+	return []byte{typeUint16<<5 | 2, byte(v >> 8), byte(v)}
+}
+
+func encDouble(v float64) []byte {
+	return binary.BigEndian.AppendUint64([]byte{typeDouble<<5 | 8}, math.Float64bits(v))
+}
+
+// testDB builds an IPv4 database of one node: addresses below 128.0.0.0 are in
+// Berlin, the rest are unknown. The country name is stored once and pointed to.
+func testDB(t *testing.T) *Reader {
+	t.Helper()
+	data := encString("Germany")
+	record := encMap(
+		encString("country"), encMap(
+			encString("iso_code"), encString("DE"),
+			encString("names"), encMap(encString("en"), []byte{typePointer << 5, 0}),
+		),
+		encString("city"), encMap(encString("names"), encMap(encString("en"), encString("Berlin"))),
+		encString("location"), encMap(
+			encString("latitude"), encDouble(52.52),
+			encString("longitude"), encDouble(13.4),
+		),
+	)
+	recordOffset := len(data)
+	data = append(data, record...)
+
+	const nodeCount = 1
+	left := nodeCount + 16 + recordOffset
+	tree := []byte{byte(left >> 16), byte(left >> 8), byte(left), 0, 0, nodeCount}
+
+	buf := append(tree, make([]byte, 16)...)
+	buf = append(buf, data...)
+	buf = append(buf, metadataMarker...)
+	buf = append(buf, encMap(
+		encString("node_count"), []byte{typeUint32<<5 | 1, nodeCount},
+		encString("record_size"), encUint16(24),
+		encString("ip_version"), encUint16(4),
+	)...)
+
+	r, err := New(buf)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return r
+}
+
+func TestLookup(t *testing.T) {
+	r := testDB(t)
+
+	loc, ok, err := r.Lookup(netip.MustParseAddr("::ffff:10.1.2.3"))
+	if err != nil || !ok {
+		t.Fatalf("Lookup = %v, %v", ok, err)
+	}
+	if loc.CountryCode != "DE" || loc.Country != "Germany" || loc.City != "Berlin" {
+		t.Errorf("Lookup = %+v", loc)
+	}
+	if loc.Latitude == nil || *loc.Latitude != 52.52 || loc.Longitude == nil || *loc.Longitude != 13.4 {
+		t.Errorf("coordinates = %v, %v", loc.Latitude, loc.Longitude)
+	}
+
+	for _, addr := range []string{"192.0.2.1", "2001:db8::1"} {
+		if _, ok, err := r.Lookup(netip.MustParseAddr(addr)); ok || err != nil {
+			t.Errorf("Lookup(%s) = %v, %v, want not found", addr, ok, err)
+		}
+	}
+}
+
+func TestNewRejectsInvalidFiles(t *testing.T) {
+	if _, err := New([]byte("not a database")); err == nil {
+		t.Error("New accepted a file without metadata")
+	}
+	buf := append(append([]byte{}, metadataMarker...), encMap(encString("node_count"), encUint16(1000))...)
+	if _, err := New(buf); err == nil {
+		t.Error("New accepted metadata without a record size")
+	}
+}
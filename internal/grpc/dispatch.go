@@ -0,0 +1,155 @@
+package grpc_server
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gocheck/internal/checker"
+	"gocheck/proto/pb"
+)
+
+const (
+	// DefaultDispatchConcurrency is how many probes are sent a command at once
+	DefaultDispatchConcurrency = 16
+
+	// probeSendTimeout bounds a single send, including waiting for an earlier send to
+	// the same probe, so a stalled probe can't hold up dispatch to the others
+	probeSendTimeout = 5 * time.Second
+)
+
+var (
+	errProbeNotConnected = errors.New("no probe connected")
+	errProbeSendTimeout  = errors.New("timed out sending command to probe")
+	// errProbeSendInFlight is a timeout while the command was being written, which
+	// the probe may still receive
+	errProbeSendInFlight = fmt.Errorf("%w: %w", errProbeSendTimeout, checker.ErrCommandInFlight)
+)
+
+// probeConn is a connected probe. gRPC streams don't allow concurrent Send calls, so
+// sends are serialised through sem, which also lets a send give up on a stalled probe
+// without piling goroutines onto it.
+type probeConn struct {
+	stream   pb.Sentinel_EstablishConnectionServer
+	sem      chan struct{}
+	probeID  int64
+	lastSeen atomic.Int64 // unix nanoseconds of the last message from the probe
+}
+
+func newProbeConn(stream pb.Sentinel_EstablishConnectionServer, probeID int64) *probeConn {
+	c := &probeConn{stream: stream, sem: make(chan struct{}, 1), probeID: probeID}
+	c.touch(time.Now())
+	return c
+}
+
+func (c *probeConn) touch(at time.Time) {
+	c.lastSeen.Store(at.UnixNano())
+}
+
+func (c *probeConn) lastSeenAt() time.Time {
+	return time.Unix(0, c.lastSeen.Load())
+}
+
+func (c *probeConn) send(cmd *pb.ServerCommand, timeout time.Duration) error {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case c.sem <- struct{}{}:
+	case <-timer.C:
+		return errProbeSendTimeout
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		defer func() { <-c.sem }()
+		done <- c.stream.Send(cmd)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		return errProbeSendInFlight
+	}
+}
+
+// SetDispatchConcurrency sets how many probes are sent a command in parallel;
+// values <= 0 keep the default
+func (s *SentinelServer) SetDispatchConcurrency(n int) {
+	if n <= 0 {
+		n = DefaultDispatchConcurrency
+	}
+	s.dispatchConcurrency = n
+}
+
+// connectedRegions returns the regions with a connected probe, sorted
+func (s *SentinelServer) connectedRegions() []string {
+	var regions []string
+	s.registry.Range(func(key, value interface{}) bool {
+		regions = append(regions, key.(string))
+		return true
+	})
+	sort.Strings(regions)
+	return regions
+}
+
+// sendToRegion sends cmd to the probe of a region. A probe whose stream fails is
+// dropped from the registry; a slow one is kept, since it may still catch up.
+func (s *SentinelServer) sendToRegion(region string, cmd *pb.ServerCommand) error {
+	value, ok := s.registry.Load(region)
+	if !ok {
+		return errProbeNotConnected
+	}
+	conn := value.(*probeConn)
+
+	timeout := s.sendTimeout
+	if timeout <= 0 {
+		timeout = probeSendTimeout
+	}
+	err := conn.send(cmd, timeout)
+	if err != nil && !errors.Is(err, errProbeSendTimeout) {
+		s.registry.CompareAndDelete(region, conn)
+	}
+	return err
+}
+
+// dispatch sends cmd to the given regions concurrently, with at most
+// dispatchConcurrency sends in flight. It returns each region's send error, nil when
+// the command was delivered.
+func (s *SentinelServer) dispatch(cmd *pb.ServerCommand, regions []string) map[string]error {
+	concurrency := s.dispatchConcurrency
+	if concurrency <= 0 {
+		concurrency = DefaultDispatchConcurrency
+	}
+
+	results := make(map[string]error, len(regions))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+
+	for _, region := range regions {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(region string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			err := s.sendToRegion(region, cmd)
+			if err != nil {
+				log.Printf("Failed to send check %d to probe %s: %v", cmd.CheckId, region, err)
+			}
+
+			mu.Lock()
+			results[region] = err
+			mu.Unlock()
+		}(region)
+	}
+
+	wg.Wait()
+	return results
+}
@@ -0,0 +1,94 @@
+package grpc_server
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gocheck/internal/checker"
+	"gocheck/proto/pb"
+)
+
+// fakeStream records sent commands; block stalls every Send until closed
+type fakeStream struct {
+	pb.Sentinel_EstablishConnectionServer
+	sent    atomic.Int32
+	block   chan struct{}
+	sendErr error
+}
+
+func (f *fakeStream) Send(cmd *pb.ServerCommand) error {
+	if f.block != nil {
+		<-f.block
+	}
+	f.sent.Add(1)
+	return f.sendErr
+}
+
+func TestDispatchIsolatesSlowAndFailingProbes(t *testing.T) {
+	s := &SentinelServer{dispatchConcurrency: 2, sendTimeout: 100 * time.Millisecond}
+
+	stalled := &fakeStream{block: make(chan struct{})}
+	defer close(stalled.block)
+	broken := &fakeStream{sendErr: errors.New("stream closed")}
+	healthy := map[string]*fakeStream{"eu": {}, "us": {}, "ap": {}}
+
+	s.registry.Store("slow", newProbeConn(stalled, 1))
+	s.registry.Store("broken", newProbeConn(broken, 2))
+	for region, stream := range healthy {
+		s.registry.Store(region, newProbeConn(stream, 3))
+	}
+
+	start := time.Now()
+	results := s.dispatch(&pb.ServerCommand{CheckId: 1}, append(s.connectedRegions(), "gone"))
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("dispatch took %v, a stalled probe should only cost its own timeout", elapsed)
+	}
+
+	for region, stream := range healthy {
+		if err := results[region]; err != nil {
+			t.Errorf("%s: unexpected error %v", region, err)
+		}
+		if stream.sent.Load() != 1 {
+			t.Errorf("%s: got %d sends, want 1", region, stream.sent.Load())
+		}
+	}
+	if !errors.Is(results["slow"], errProbeSendTimeout) {
+		t.Errorf("slow: got %v, want timeout", results["slow"])
+	}
+	if _, ok := s.registry.Load("slow"); !ok {
+		t.Error("slow probe should stay registered")
+	}
+	if results["broken"] == nil {
+		t.Error("broken: expected send error")
+	}
+	if _, ok := s.registry.Load("broken"); ok {
+		t.Error("broken probe should be removed from the registry")
+	}
+	if !errors.Is(results["gone"], errProbeNotConnected) {
+		t.Errorf("gone: got %v, want not connected", results["gone"])
+	}
+}
+
+func TestProbeConnSerializesSends(t *testing.T) {
+	stream := &fakeStream{block: make(chan struct{})}
+	conn := newProbeConn(stream, 1)
+
+	// The first send holds the stream; a second one must give up rather than call Send
+	// concurrently on the same stream. Only the first may still reach the probe.
+	if err := conn.send(&pb.ServerCommand{}, 20*time.Millisecond); !errors.Is(err, checker.ErrCommandInFlight) {
+		t.Fatalf("first send: got %v, want an in-flight timeout", err)
+	}
+	if err := conn.send(&pb.ServerCommand{}, 20*time.Millisecond); !errors.Is(err, errProbeSendTimeout) || errors.Is(err, checker.ErrCommandInFlight) {
+		t.Fatalf("second send: got %v, want a timeout before sending", err)
+	}
+
+	close(stream.block)
+	if err := conn.send(&pb.ServerCommand{}, time.Second); err != nil {
+		t.Fatalf("send after the stream recovered: %v", err)
+	}
+	if got := stream.sent.Load(); got != 2 {
+		t.Fatalf("got %d sends, want 2 (the stalled one and the last one)", got)
+	}
+}
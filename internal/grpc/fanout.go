@@ -0,0 +1,110 @@
+package grpc_server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"gocheck/internal/models"
+	"gocheck/proto/pb"
+)
+
+// regionResult is a probe result delivered to a waiting fan-out request
+type regionResult struct {
+	region string
+	result *pb.CheckResult
+}
+
+func newRequestID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// deliverResult hands a correlated probe result to the request waiting for it, if any
+func (s *SentinelServer) deliverResult(region string, result *pb.CheckResult) {
+	if result.RequestId == "" {
+		return
+	}
+	if waiter, ok := s.pending.Load(result.RequestId); ok {
+		select {
+		case waiter.(chan regionResult) <- regionResult{region: region, result: result}:
+		default:
+		}
+	}
+}
+
+// TriggerCheckAllRegions sends CHECK_NOW to every connected probe and waits for their
+// results. Regions that do not answer before the timeout or the context ends are
+// reported with status "no_response". Results are sorted by region.
+func (s *SentinelServer) TriggerCheckAllRegions(ctx context.Context, check models.Check, timeout time.Duration) ([]models.RegionCheckResult, error) {
+	cmd, err := buildCommand(check)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expand templates: %w", err)
+	}
+
+	requestID, err := newRequestID()
+	if err != nil {
+		return nil, err
+	}
+	cmd.RequestId = requestID
+
+	regions := s.connectedRegions()
+
+	waiter := make(chan regionResult, len(regions))
+	s.pending.Store(requestID, waiter)
+	defer s.pending.Delete(requestID)
+
+	results := make(map[string]*models.RegionCheckResult, len(regions))
+	waiting := 0
+	for region, err := range s.dispatch(cmd, regions) {
+		results[region] = &models.RegionCheckResult{Region: region, Status: models.RegionStatusNoResponse}
+
+		switch {
+		case errors.Is(err, errProbeNotConnected):
+			results[region].ErrorMessage = "probe disconnected"
+		case err != nil:
+			results[region].Status = models.RegionStatusSendFailed
+			results[region].ErrorMessage = err.Error()
+		default:
+			waiting++
+		}
+	}
+
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+
+	for waiting > 0 {
+		select {
+		case rr := <-waiter:
+			res, ok := results[rr.region]
+			if !ok || res.Status != models.RegionStatusNoResponse {
+				continue
+			}
+			res.Status = models.RegionStatusDown
+			if rr.result.Success {
+				res.Status = models.RegionStatusUp
+			}
+			res.Success = rr.result.Success
+			res.StatusCode = int(rr.result.StatusCode)
+			res.ResponseTimeMs = int(rr.result.LatencyMs)
+			res.ErrorMessage = rr.result.ErrorMessage
+			waiting--
+		case <-deadline.C:
+			waiting = 0
+		case <-ctx.Done():
+			waiting = 0
+		}
+	}
+
+	out := make([]models.RegionCheckResult, 0, len(regions))
+	for _, region := range regions {
+		out = append(out, *results[region])
+	}
+	return out, nil
+}
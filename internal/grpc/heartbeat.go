@@ -0,0 +1,83 @@
+package grpc_server
+
+import (
+	"log"
+	"time"
+
+	"gocheck/internal/models"
+)
+
+const (
+	// probeHeartbeatTimeout is how long a probe may go without a heartbeat or result
+	// before it is marked offline. Probes send a heartbeat every 30s, so this allows
+	// two to go missing.
+	probeHeartbeatTimeout = 90 * time.Second
+
+	// probeStaleAfter is when a connected probe that hasn't been heard from, having
+	// missed a heartbeat, is reported as stale
+	probeStaleAfter = 45 * time.Second
+
+	probeSweepInterval = 15 * time.Second
+)
+
+// touchProbe records a message from a probe. A probe the sweeper dropped is
+// registered again, unless another connection took over its region meanwhile.
+func (s *SentinelServer) touchProbe(region string, conn *probeConn) {
+	conn.touch(time.Now())
+	if _, loaded := s.registry.LoadOrStore(region, conn); !loaded {
+		log.Printf("Probe %s (ID: %d) is sending again, marking online", region, conn.probeID)
+		if err := s.db.UpdateProbeStatus(conn.probeID, "ONLINE"); err != nil {
+			log.Printf("Failed to update probe status: %v", err)
+		}
+	}
+}
+
+// sweepProbes drops probes not heard from within probeHeartbeatTimeout from the
+// registry and marks them offline. A half-open stream can go unnoticed by gRPC for a
+// long time, and until then the probe would keep being sent checks it never runs.
+func (s *SentinelServer) sweepProbes(now time.Time) {
+	s.registry.Range(func(key, value interface{}) bool {
+		region, conn := key.(string), value.(*probeConn)
+		if now.Sub(conn.lastSeenAt()) <= probeHeartbeatTimeout {
+			return true
+		}
+		if !s.registry.CompareAndDelete(region, conn) {
+			return true
+		}
+		log.Printf("Probe %s (ID: %d) missed its heartbeats, marking offline", region, conn.probeID)
+		if err := s.db.UpdateProbeStatus(conn.probeID, "OFFLINE"); err != nil {
+			log.Printf("Failed to update probe status: %v", err)
+		}
+		return true
+	})
+}
+
+func (s *SentinelServer) runProbeSweeper() {
+	ticker := time.NewTicker(probeSweepInterval)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		s.sweepProbes(now)
+	}
+}
+
+// AnnotateProbes fills in when each connected probe was last heard from and whether
+// it has gone quiet, so stale probes show before the sweeper marks them offline
+func (s *SentinelServer) AnnotateProbes(probes []models.Probe) {
+	now := time.Now()
+	lastSeen := make(map[int64]time.Time)
+	s.registry.Range(func(key, value interface{}) bool {
+		conn := value.(*probeConn)
+		lastSeen[conn.probeID] = conn.lastSeenAt()
+		return true
+	})
+
+	for i := range probes {
+		at, ok := lastSeen[probes[i].ID]
+		if !ok {
+			continue
+		}
+		at = at.UTC()
+		probes[i].LastHeartbeatAt = &at
+		probes[i].Stale = now.Sub(at) > probeStaleAfter
+	}
+}
@@ -0,0 +1,67 @@
+package grpc_server
+
+import (
+	"testing"
+	"time"
+
+	"gocheck/internal/db"
+	"gocheck/internal/models"
+)
+
+// statusDB records probe status updates
+type statusDB struct {
+	db.DB
+	status map[int64]string
+}
+
+func (d *statusDB) UpdateProbeStatus(id int64, status string) error {
+	d.status[id] = status
+	return nil
+}
+
+func TestSweepProbesMarksSilentProbesOffline(t *testing.T) {
+	fake := &statusDB{status: make(map[int64]string)}
+	s := &SentinelServer{db: &db.Database{DB: fake}}
+
+	quiet := newProbeConn(&fakeStream{}, 1)
+	quiet.touch(time.Now().Add(-probeHeartbeatTimeout - time.Second))
+	lagging := newProbeConn(&fakeStream{}, 2)
+	lagging.touch(time.Now().Add(-probeStaleAfter - time.Second))
+	s.registry.Store("eu", quiet)
+	s.registry.Store("us", lagging)
+	s.registry.Store("ap", newProbeConn(&fakeStream{}, 3))
+
+	probes := []models.Probe{{ID: 1}, {ID: 2}, {ID: 3}, {ID: 4}}
+	s.AnnotateProbes(probes)
+	if !probes[0].Stale || !probes[1].Stale || probes[2].Stale {
+		t.Errorf("stale = %v %v %v, want true true false", probes[0].Stale, probes[1].Stale, probes[2].Stale)
+	}
+	if probes[3].LastHeartbeatAt != nil || probes[3].Stale {
+		t.Error("a probe that isn't connected should not be annotated")
+	}
+
+	s.sweepProbes(time.Now())
+	if got := s.connectedRegions(); len(got) != 2 || got[0] != "ap" || got[1] != "us" {
+		t.Fatalf("connected regions = %v, want [ap us]", got)
+	}
+	if fake.status[1] != "OFFLINE" || len(fake.status) != 1 {
+		t.Fatalf("status updates = %v, want only probe 1 offline", fake.status)
+	}
+
+	// A swept probe that turns out to be alive is registered again
+	s.touchProbe("eu", quiet)
+	if _, ok := s.registry.Load("eu"); !ok {
+		t.Error("probe should be registered again after a heartbeat")
+	}
+	if fake.status[1] != "ONLINE" {
+		t.Errorf("status = %q, want ONLINE", fake.status[1])
+	}
+
+	// ...but not over a connection that replaced it
+	replacement := newProbeConn(&fakeStream{}, 3)
+	s.registry.Store("ap", replacement)
+	s.touchProbe("ap", newProbeConn(&fakeStream{}, 3))
+	if value, _ := s.registry.Load("ap"); value != replacement {
+		t.Error("an old connection took over the region from its replacement")
+	}
+}
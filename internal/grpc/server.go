@@ -5,6 +5,7 @@ import (
 	"sync"
 	"time"
 
+	"gocheck/internal/checker"
 	"gocheck/internal/db"
 	"gocheck/internal/models"
 	"gocheck/proto/pb"
@@ -16,35 +17,47 @@ import (
 type SentinelServer struct {
 	pb.UnimplementedSentinelServer
 	db       *db.Database
-	registry sync.Map
+	registry sync.Map // region -> *probeConn
+	pending  sync.Map // request ID -> chan regionResult for fan-out triggers
 	engine   interface {
 		BroadcastCheckResult(check models.Check, history *models.CheckHistory)
+		RecordProbeResult(checkID int64, history *models.CheckHistory)
 	}
+	dispatchConcurrency int
+	sendTimeout         time.Duration // defaults to probeSendTimeout
 }
 
 func NewSentinelServer(database *db.Database) *SentinelServer {
 	return &SentinelServer{
-		db: database,
+		db:                  database,
+		dispatchConcurrency: DefaultDispatchConcurrency,
 	}
 }
 
+// NewSentinelServerWithEngine also starts the sweeper that marks probes offline when
+// their heartbeats stop
 func NewSentinelServerWithEngine(database *db.Database, engine interface {
 	BroadcastCheckResult(check models.Check, history *models.CheckHistory)
+	RecordProbeResult(checkID int64, history *models.CheckHistory)
 }) *SentinelServer {
-	return &SentinelServer{
-		db:     database,
-		engine: engine,
+	s := &SentinelServer{
+		db:                  database,
+		engine:              engine,
+		dispatchConcurrency: DefaultDispatchConcurrency,
 	}
+	go s.runProbeSweeper()
+	return s
 }
 
 func (s *SentinelServer) EstablishConnection(stream pb.Sentinel_EstablishConnectionServer) error {
 	var region string
 	var probeID int64
+	var conn *probeConn
 
 	for {
 		msg, err := stream.Recv()
 		if err != nil {
-			s.disconnect(region, probeID)
+			s.disconnect(region, probeID, conn)
 			return err
 		}
 
@@ -55,13 +68,15 @@ func (s *SentinelServer) EstablishConnection(stream pb.Sentinel_EstablishConnect
 				return err
 			}
 			region = payload.Register.RegionCode
-			s.registry.Store(region, stream)
+			conn = newProbeConn(stream, probeID)
+			s.registry.Store(region, conn)
 			log.Printf("Probe connected: %s (ID: %d)", region, probeID)
 
 		case *pb.ProbeMessage_Result:
 			if probeID == 0 {
 				return status.Error(codes.Unauthenticated, "not registered")
 			}
+			s.touchProbe(region, conn)
 			err = s.handleCheckResult(probeID, region, payload.Result)
 			if err != nil {
 				log.Printf("Failed to save check result: %v", err)
@@ -71,6 +86,7 @@ func (s *SentinelServer) EstablishConnection(stream pb.Sentinel_EstablishConnect
 			if probeID == 0 {
 				return status.Error(codes.Unauthenticated, "not registered")
 			}
+			s.touchProbe(region, conn)
 			err = s.db.UpdateProbeLastSeen(probeID)
 			if err != nil {
 				log.Printf("Failed to update probe last seen: %v", err)
@@ -107,6 +123,8 @@ func (s *SentinelServer) handleCheckResult(probeID int64, region string, result
 
 	log.Printf("[PROBE] Received check result: check_id=%d, region=%s, success=%v, latency=%dms", result.CheckId, region, result.Success, result.LatencyMs)
 
+	s.deliverResult(region, result)
+
 	err := s.db.AddHistory(history)
 	if err != nil {
 		return err
@@ -114,6 +132,8 @@ func (s *SentinelServer) handleCheckResult(probeID int64, region string, result
 
 	// Broadcast to SSE clients if engine is available
 	if s.engine != nil {
+		s.engine.RecordProbeResult(result.CheckId, history)
+
 		check, err := s.db.GetCheck(result.CheckId)
 		if err == nil {
 			s.engine.BroadcastCheckResult(*check, history)
@@ -125,9 +145,11 @@ func (s *SentinelServer) handleCheckResult(probeID int64, region string, result
 	return nil
 }
 
-func (s *SentinelServer) disconnect(region string, probeID int64) {
-	if region != "" {
-		s.registry.Delete(region)
+// disconnect unregisters a probe. Only its own connection is removed, so a probe that
+// already reconnected for the same region stays registered.
+func (s *SentinelServer) disconnect(region string, probeID int64, conn *probeConn) {
+	if region != "" && conn != nil {
+		s.registry.CompareAndDelete(region, conn)
 		log.Printf("Probe disconnected: %s", region)
 	}
 	if probeID != 0 {
@@ -138,55 +160,136 @@ func (s *SentinelServer) disconnect(region string, probeID int64) {
 	}
 }
 
-func (s *SentinelServer) BroadcastCheckFull(check models.Check) {
-	s.BroadcastCheckToRegion(check, "")
+// BroadcastCheckFull sends a check to every connected probe and returns each region's
+// send error, nil when the command was delivered
+func (s *SentinelServer) BroadcastCheckFull(check models.Check) map[string]error {
+	return s.BroadcastCheckToRegion(check, "")
 }
 
-func (s *SentinelServer) BroadcastCheckToRegion(check models.Check, region string) {
+// buildCommand turns a check into a CHECK_NOW command with its templates expanded
+func buildCommand(check models.Check) (*pb.ServerCommand, error) {
 	timeoutSeconds := int32(check.TimeoutSeconds)
 	if timeoutSeconds == 0 {
 		timeoutSeconds = 10
 	}
 
+	// Expand templates here so probes receive a concrete URL, body and headers
+	expanded, err := checker.ExpandCheckRequest(check, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
 	cmd := &pb.ServerCommand{
-		CommandType:        "CHECK_NOW",
-		CheckId:            check.ID,
-		CheckType:          string(check.Type),
-		Url:                check.URL,
-		Host:               check.Host,
-		PostgresConnString: check.PostgresConnString,
-		PostgresQuery:      check.PostgresQuery,
-		ExpectedQueryValue: check.ExpectedQueryValue,
-		DnsHostname:        check.DNSHostname,
-		DnsRecordType:      check.DNSRecordType,
-		ExpectedDnsValue:   check.ExpectedDNSValue,
-		Method:             check.Method,
-		TimeoutSeconds:     timeoutSeconds,
-		JsonPath:           check.JSONPath,
-		ExpectedJsonValue:  check.ExpectedJSONValue,
-	}
-
-	if region != "" {
-		if stream, ok := s.registry.Load(region); ok {
-			if err := stream.(pb.Sentinel_EstablishConnectionServer).Send(cmd); err != nil {
-				log.Printf("Failed to send command to probe %s: %v", region, err)
-				s.registry.Delete(region)
-			} else {
-				log.Printf("Triggered check %d for region %s", check.ID, region)
-			}
-		} else {
-			log.Printf("No probe connected for region %s", region)
-		}
-		return
+		CommandType:             "CHECK_NOW",
+		CheckId:                 check.ID,
+		CheckType:               string(check.Type),
+		Url:                     expanded.URL,
+		Host:                    check.Host,
+		PostgresConnString:      check.PostgresConnString,
+		PostgresQuery:           check.PostgresQuery,
+		ExpectedQueryValue:      check.ExpectedQueryValue,
+		DnsHostname:             check.DNSHostname,
+		DnsRecordType:           check.DNSRecordType,
+		ExpectedDnsValue:        check.ExpectedDNSValue,
+		Method:                  check.Method,
+		TimeoutSeconds:          timeoutSeconds,
+		JsonPath:                check.JSONPath,
+		ExpectedJsonValue:       check.ExpectedJSONValue,
+		Port:                    int32(check.Port),
+		ExpectedBanner:          check.ExpectedBanner,
+		Headers:                 expanded.Headers,
+		RequestBody:             expanded.Body,
+		ContentType:             check.ContentType,
+		ResponseKeyword:         check.ResponseKeyword,
+		ResponseKeywordMode:     check.ResponseKeywordMode,
+		NtpMaxOffsetMs:          int32(check.NTPMaxOffsetMs),
+		CertExpiryThresholdDays: int32(check.CertExpiryThresholdDays),
+		InsecureSkipVerify:      check.InsecureSkipVerify,
+		ForbiddenBodyKeyword:    check.ForbiddenBodyKeyword,
+		ExpectUnreachable:       check.ExpectUnreachable,
+		MaxResponseTimeMs:       int32(check.MaxResponseTimeMs),
+		BypassCache:             check.BypassCache,
+		RedisConnString:         check.RedisConnString,
+		RedisKey:                check.RedisKey,
+		ExpectedRedisValue:      check.ExpectedRedisValue,
+		MysqlConnString:         check.MySQLConnString,
+		DnsResolver:             check.DNSResolver,
+		PingCount:               int32(check.PingCount),
+		MaxPacketLoss:           int32(check.MaxPacketLoss),
+		AuthType:                check.AuthType,
+		AuthUsername:            check.AuthUsername,
+		AuthPassword:            check.AuthPassword,
+		AuthToken:               check.AuthToken,
+		NoFollowRedirects:       !check.FollowRedirects,
+		AssertionOperator:       check.AssertionOperator,
+		GrpcService:             check.GRPCService,
+		GrpcTls:                 check.GRPCTLS,
+		SmtpStarttls:            check.SMTPStartTLS,
+		ExpectedHeaderName:      check.ExpectedHeaderName,
+		ExpectedHeaderValue:     check.ExpectedHeaderValue,
+		ExpectedHeaderMode:      check.ExpectedHeaderMode,
+		MongoConnString:         check.MongoConnString,
+		MinResponseBytes:        int32(check.MinResponseBytes),
+		MaxResponseBytes:        int32(check.MaxResponseBytes),
+		ExpectedHttpVersion:     check.ExpectedHTTPVersion,
+		ProxyUrl:                check.ProxyURL,
+		QueryColumn:             check.QueryColumn,
+	}
+	for _, code := range check.ExpectedStatusCodes {
+		cmd.ExpectedStatusCodes = append(cmd.ExpectedStatusCodes, int32(code))
+	}
+	if check.ExpectedRowCount != nil {
+		cmd.ExpectedRowCount = int32(*check.ExpectedRowCount)
+		cmd.AssertRowCount = true
+	}
+
+	return cmd, nil
+}
+
+// BroadcastCheckToRegion sends a check to the probe of one region, or to every
+// connected probe when region is empty. Probes are sent the command concurrently and
+// the result holds each targeted region's send error, nil on success.
+func (s *SentinelServer) BroadcastCheckToRegion(check models.Check, region string) map[string]error {
+	regions := []string{region}
+	if region == "" {
+		regions = s.connectedRegions()
 	}
 
-	s.registry.Range(func(key, value interface{}) bool {
-		stream := value.(pb.Sentinel_EstablishConnectionServer)
-		if err := stream.Send(cmd); err != nil {
-			log.Printf("Failed to send command to probe %v: %v", key, err)
-			s.registry.Delete(key)
+	cmd, err := buildCommand(check)
+	if err != nil {
+		log.Printf("Failed to expand templates for check %d: %v", check.ID, err)
+		s.recordDispatchFailure(check, regions, err)
+		results := make(map[string]error, len(regions))
+		for _, r := range regions {
+			results[r] = err
 		}
-		return true
-	})
+		return results
+	}
+
+	results := s.dispatch(cmd, regions)
+	if region != "" && results[region] == nil {
+		log.Printf("Triggered check %d for region %s", check.ID, region)
+	}
+	return results
 }
 
+// recordDispatchFailure stores a failed result for every targeted region when a
+// command could not be built, so regional history reflects the error.
+func (s *SentinelServer) recordDispatchFailure(check models.Check, regions []string, cause error) {
+	for _, r := range regions {
+		history := &models.CheckHistory{
+			CheckID:      check.ID,
+			Success:      false,
+			ErrorMessage: cause.Error(),
+			CheckedAt:    time.Now().UTC(),
+			Region:       r,
+		}
+		if err := s.db.AddHistory(history); err != nil {
+			log.Printf("Failed to save dispatch failure for check %d: %v", check.ID, err)
+			continue
+		}
+		if s.engine != nil {
+			s.engine.BroadcastCheckResult(check, history)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+// Package grpchealth calls the standard grpc.health.v1 health service, for gRPC checks
+// run by the server and by probes.
+package grpchealth
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// Check asks the server at addr for the health of service, the server as a whole when
+// it is empty. With tlsConfig nil the connection is plaintext. The returned duration
+// is the Check round trip only, not connecting or the TLS handshake.
+func Check(ctx context.Context, addr, service string, tlsConfig *tls.Config) (healthpb.HealthCheckResponse_ServingStatus, time.Duration, error) {
+	creds := insecure.NewCredentials()
+	if tlsConfig != nil {
+		creds = credentials.NewTLS(tlsConfig)
+	}
+	conn, err := grpc.NewClient("passthrough:///"+addr, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return healthpb.HealthCheckResponse_UNKNOWN, 0, err
+	}
+	defer conn.Close()
+
+	// Connect before timing the RPC. A failed connection is left for the RPC to
+	// report, since it fails fast with the underlying error.
+	conn.Connect()
+	for state := conn.GetState(); state != connectivity.Ready && state != connectivity.TransientFailure; state = conn.GetState() {
+		if !conn.WaitForStateChange(ctx, state) {
+			break
+		}
+	}
+
+	start := time.Now()
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+	rtt := time.Since(start)
+	if err != nil {
+		return healthpb.HealthCheckResponse_UNKNOWN, rtt, fmt.Errorf("health check failed: %s", status.Convert(err).Message())
+	}
+	return resp.GetStatus(), rtt, nil
+}
@@ -0,0 +1,62 @@
+package grpchealth
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestCheck(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := grpc.NewServer()
+	hs := health.NewServer()
+	hs.SetServingStatus("up", healthpb.HealthCheckResponse_SERVING)
+	hs.SetServingStatus("down", healthpb.HealthCheckResponse_NOT_SERVING)
+	healthpb.RegisterHealthServer(srv, hs)
+	go srv.Serve(lis)
+	defer srv.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for service, want := range map[string]healthpb.HealthCheckResponse_ServingStatus{
+		"":     healthpb.HealthCheckResponse_SERVING,
+		"up":   healthpb.HealthCheckResponse_SERVING,
+		"down": healthpb.HealthCheckResponse_NOT_SERVING,
+	} {
+		got, _, err := Check(ctx, lis.Addr().String(), service, nil)
+		if err != nil {
+			t.Fatalf("%q: %v", service, err)
+		}
+		if got != want {
+			t.Errorf("%q: status = %v, want %v", service, got, want)
+		}
+	}
+
+	if _, _, err := Check(ctx, lis.Addr().String(), "missing", nil); err == nil {
+		t.Error("expected an error for an unknown service")
+	}
+}
+
+func TestCheckUnreachable(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := lis.Addr().String()
+	lis.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, _, err := Check(ctx, addr, "", nil); err == nil {
+		t.Error("expected an error for a closed port")
+	}
+}
@@ -0,0 +1,133 @@
+package grpctls
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testCA issues certificates and writes them as PEM files into dir
+type testCA struct {
+	t    *testing.T
+	dir  string
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCA(t *testing.T) *testCA {
+	ca := &testCA{t: t, dir: t.TempDir()}
+	ca.cert, ca.key = ca.issue("test CA", nil, true)
+	return ca
+}
+
+// issue creates a certificate for name, self-signed when ca.cert is still nil
+func (ca *testCA) issue(name string, usage []x509.ExtKeyUsage, isCA bool) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		ca.t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		DNSNames:              []string{name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		ExtKeyUsage:           usage,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+	}
+	parent, signer := template, key
+	if ca.cert != nil {
+		parent, signer = ca.cert, ca.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, signer)
+	if err != nil {
+		ca.t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		ca.t.Fatal(err)
+	}
+	return cert, key
+}
+
+func (ca *testCA) write(file string, blockType string, der []byte) string {
+	path := filepath.Join(ca.dir, file)
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600); err != nil {
+		ca.t.Fatal(err)
+	}
+	return path
+}
+
+// pair issues a certificate and returns its certificate and key file paths
+func (ca *testCA) pair(name string, usage x509.ExtKeyUsage) (string, string) {
+	cert, key := ca.issue(name, []x509.ExtKeyUsage{usage}, false)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		ca.t.Fatal(err)
+	}
+	return ca.write(name+".crt", "CERTIFICATE", cert.Raw), ca.write(name+".key", "EC PRIVATE KEY", keyDER)
+}
+
+func TestMutualTLSHandshake(t *testing.T) {
+	ca := newTestCA(t)
+	caFile := ca.write("ca.crt", "CERTIFICATE", ca.cert.Raw)
+	serverCert, serverKey := ca.pair("sentinel.test", x509.ExtKeyUsageServerAuth)
+	probeCert, probeKey := ca.pair("probe", x509.ExtKeyUsageClientAuth)
+
+	server, err := ServerCredentials(serverCert, serverKey, caFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handshake := func(certFile, keyFile string) error {
+		client, err := ClientCredentials(caFile, certFile, keyFile, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		clientConn, serverConn := net.Pipe()
+		defer clientConn.Close()
+		defer serverConn.Close()
+
+		serverErr := make(chan error, 1)
+		go func() {
+			_, _, err := server.ServerHandshake(serverConn)
+			serverConn.Close()
+			serverErr <- err
+		}()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_, _, clientErr := client.ClientHandshake(ctx, "sentinel.test:50051", clientConn)
+		// net.Pipe is unbuffered, so a rejecting server blocks on its alert until the
+		// client side goes away
+		clientConn.Close()
+		if err := <-serverErr; err != nil {
+			return err
+		}
+		return clientErr
+	}
+
+	if err := handshake(probeCert, probeKey); err != nil {
+		t.Errorf("handshake with a client certificate failed: %v", err)
+	}
+	if err := handshake("", ""); err == nil {
+		t.Error("handshake without a client certificate succeeded, want it rejected")
+	}
+}
+
+func TestServerCredentialsRequiresKeyPair(t *testing.T) {
+	if _, err := ServerCredentials("server.crt", "", ""); err == nil {
+		t.Error("expected an error without a key file")
+	}
+}
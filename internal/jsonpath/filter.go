@@ -0,0 +1,280 @@
+package jsonpath
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// filterExpr is a parsed [?...] expression, evaluated for each candidate value
+type filterExpr interface {
+	eval(root, current interface{}) bool
+}
+
+type orExpr struct{ left, right filterExpr }
+
+func (e orExpr) eval(root, current interface{}) bool {
+	return e.left.eval(root, current) || e.right.eval(root, current)
+}
+
+type andExpr struct{ left, right filterExpr }
+
+func (e andExpr) eval(root, current interface{}) bool {
+	return e.left.eval(root, current) && e.right.eval(root, current)
+}
+
+type notExpr struct{ expr filterExpr }
+
+func (e notExpr) eval(root, current interface{}) bool {
+	return !e.expr.eval(root, current)
+}
+
+// existsExpr is a bare query, true when it selects anything
+type existsExpr struct{ query query }
+
+func (e existsExpr) eval(root, current interface{}) bool {
+	return len(e.query.nodes(root, current)) > 0
+}
+
+type compareExpr struct {
+	left, right operand
+	op          string
+}
+
+func (e compareExpr) eval(root, current interface{}) bool {
+	a, aok := e.left.value(root, current)
+	b, bok := e.right.value(root, current)
+	return compare(a, aok, e.op, b, bok)
+}
+
+// operand is one side of a comparison; ok is false when a query selects nothing
+type operand interface {
+	value(root, current interface{}) (v interface{}, ok bool)
+}
+
+type literal struct{ v interface{} }
+
+func (l literal) value(_, _ interface{}) (interface{}, bool) {
+	return l.v, true
+}
+
+// query is a path inside a filter, from @ (the candidate) or $ (the document root)
+type query struct {
+	fromRoot bool
+	segments []segment
+}
+
+func (q query) nodes(root, current interface{}) []interface{} {
+	nodes := []interface{}{current}
+	if q.fromRoot {
+		nodes[0] = root
+	}
+	for _, seg := range q.segments {
+		if nodes = seg.apply(root, nodes); len(nodes) == 0 {
+			break
+		}
+	}
+	return nodes
+}
+
+// value is the single value the query selects; selecting several compares as nothing
+func (q query) value(root, current interface{}) (interface{}, bool) {
+	nodes := q.nodes(root, current)
+	if len(nodes) != 1 {
+		return nil, false
+	}
+	return nodes[0], true
+}
+
+// compare applies op to a and b. Missing values only equal each other, numbers and
+// strings are ordered, and any other pair of types is only compared for equality.
+func compare(a interface{}, aok bool, op string, b interface{}, bok bool) bool {
+	if !aok || !bok {
+		switch op {
+		case "==":
+			return aok == bok
+		case "!=":
+			return aok != bok
+		}
+		return false
+	}
+
+	if x, ok := toFloat(a); ok {
+		if y, ok := toFloat(b); ok {
+			switch op {
+			case "==":
+				return x == y
+			case "!=":
+				return x != y
+			case "<":
+				return x < y
+			case "<=":
+				return x <= y
+			case ">":
+				return x > y
+			case ">=":
+				return x >= y
+			}
+		}
+	}
+	if x, ok := a.(string); ok {
+		if y, ok := b.(string); ok {
+			switch op {
+			case "<":
+				return x < y
+			case "<=":
+				return x <= y
+			case ">":
+				return x > y
+			case ">=":
+				return x >= y
+			}
+		}
+	}
+
+	switch op {
+	case "==":
+		return reflect.DeepEqual(a, b)
+	case "!=":
+		return !reflect.DeepEqual(a, b)
+	}
+	return false
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+// parseOr is the entry point of the filter grammar:
+//
+//	or      = and { "||" and }
+//	and     = unary { "&&" unary }
+//	unary   = "!" unary | "(" or ")" | compare
+//	compare = operand [ op operand ]
+func (p *parser) parseOr() (filterExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.consume("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orExpr{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (filterExpr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.consume("&&") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andExpr{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (filterExpr, error) {
+	p.skipSpace()
+	if p.peek() == '!' && !strings.HasPrefix(p.src[p.pos:], "!=") {
+		p.pos++
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notExpr{expr}, nil
+	}
+	if p.consume("(") {
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.consume(")") {
+			return nil, p.errorf("expected )")
+		}
+		return expr, nil
+	}
+	return p.parseComparison()
+}
+
+// comparisonOps are tried in order, so two-character operators win
+var comparisonOps = []string{"==", "!=", "<=", ">=", "<", ">"}
+
+func (p *parser) parseComparison() (filterExpr, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, op := range comparisonOps {
+		if p.consume(op) {
+			right, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+			return compareExpr{left: left, right: right, op: op}, nil
+		}
+	}
+
+	q, ok := left.(query)
+	if !ok {
+		return nil, p.errorf("expected a comparison after the literal")
+	}
+	return existsExpr{query: q}, nil
+}
+
+func (p *parser) parseOperand() (operand, error) {
+	p.skipSpace()
+	switch c := p.peek(); {
+	case c == '@' || c == '$':
+		p.pos++
+		segments, err := p.parseSegments(true)
+		if err != nil {
+			return nil, err
+		}
+		return query{fromRoot: c == '$', segments: segments}, nil
+	case c == '\'' || c == '"':
+		s, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		return literal{s}, nil
+	case c == '-' || isDigit(c):
+		start := p.pos
+		for !p.eof() && strings.ContainsRune("+-.eE0123456789", rune(p.peek())) {
+			p.pos++
+		}
+		f, err := strconv.ParseFloat(p.src[start:p.pos], 64)
+		if err != nil {
+			p.pos = start
+			return nil, p.errorf("invalid number")
+		}
+		return literal{f}, nil
+	}
+
+	for word, v := range map[string]interface{}{"true": true, "false": false, "null": nil} {
+		if strings.HasPrefix(p.src[p.pos:], word) {
+			p.pos += len(word)
+			return literal{v}, nil
+		}
+	}
+	if p.eof() {
+		return nil, p.errorf("unexpected end of filter")
+	}
+	return nil, p.errorf("unexpected %q in filter", p.peek())
+}
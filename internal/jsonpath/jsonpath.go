@@ -0,0 +1,362 @@
+// Package jsonpath evaluates JSONPath expressions against decoded JSON, as produced by
+// encoding/json into interface{} values.
+//
+// Supported syntax:
+//
+//	$                 the root (optional, so data.items works like $.data.items)
+//	.name ['name']    an object member; a numeric .name also indexes arrays, so the
+//	                  older dotted paths items.2 and items.[2] keep working
+//	[2] [-1]          an array element, negative indices counting from the end
+//	[0:5:2]           a slice, with optional start, end and step
+//	['a','b'] [0,3]   a union of names or indices
+//	.* [*]            every member or element
+//	..name ..[0] ..*  recursive descent
+//	[?(@.price < 10)] a filter over members or elements, with @ the current one and
+//	                  $ the root. Filters compare with == != < <= > >=, combine with
+//	                  && || ! and parentheses, and test existence with a bare @.path.
+//	                  Literals are numbers, 'strings', "strings", true, false, null.
+package jsonpath
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrNoMatch is wrapped by the errors of paths that are valid but select nothing
+var ErrNoMatch = errors.New("path did not match")
+
+// SyntaxError reports an expression that can't be parsed
+type SyntaxError struct {
+	Expr   string
+	Offset int
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("invalid JSONPath %q at offset %d: %s", e.Expr, e.Offset, e.Msg)
+}
+
+// Path is a compiled JSONPath expression
+type Path struct {
+	expr     string
+	segments []segment
+	definite bool
+}
+
+// Compile parses expr, returning a *SyntaxError when it is malformed
+func Compile(expr string) (*Path, error) {
+	expr = strings.TrimSpace(expr)
+	p := &parser{src: expr}
+	if expr == "" {
+		return nil, p.errorf("empty expression")
+	}
+
+	var segments []segment
+	switch p.peek() {
+	case '$':
+		p.pos++
+	case '.', '[':
+	default:
+		// A dotted path without the leading $. or .
+		name, err := p.parseName()
+		if err != nil {
+			return nil, err
+		}
+		segments = append(segments, segment{selectors: []selector{name}})
+	}
+
+	rest, err := p.parseSegments(false)
+	if err != nil {
+		return nil, err
+	}
+	segments = append(segments, rest...)
+
+	path := &Path{expr: expr, segments: segments, definite: true}
+	for _, seg := range segments {
+		if !seg.definite() {
+			path.definite = false
+		}
+	}
+	return path, nil
+}
+
+// Get compiles expr and evaluates it against data
+func Get(data interface{}, expr string) (interface{}, error) {
+	path, err := Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	return path.Get(data)
+}
+
+// String returns the expression the path was compiled from
+func (p *Path) String() string {
+	return p.expr
+}
+
+// Definite reports whether the path selects at most one value: it has no wildcards,
+// slices, unions, filters or recursive descent
+func (p *Path) Definite() bool {
+	return p.definite
+}
+
+// Get evaluates the path against data. A definite path returns the value it selects,
+// any other path a []interface{} of every match in document order (object members
+// by key). Either way an error wrapping ErrNoMatch is returned when nothing matches.
+func (p *Path) Get(data interface{}) (interface{}, error) {
+	nodes := []interface{}{data}
+	for _, seg := range p.segments {
+		next := seg.apply(data, nodes)
+		if len(next) == 0 {
+			if p.definite {
+				return nil, fmt.Errorf("%w: %s", ErrNoMatch, seg.selectors[0].(missingReporter).missing(nodes[0]))
+			}
+			return nil, fmt.Errorf("%w: %s", ErrNoMatch, p.expr)
+		}
+		nodes = next
+	}
+
+	if p.definite {
+		return nodes[0], nil
+	}
+	return nodes, nil
+}
+
+// segment is one step of a path, applying its selectors to every node it is given, or
+// with recursive descent to those nodes and all their descendants
+type segment struct {
+	recursive bool
+	selectors []selector
+}
+
+func (s segment) definite() bool {
+	if s.recursive || len(s.selectors) != 1 {
+		return false
+	}
+	_, ok := s.selectors[0].(missingReporter)
+	return ok
+}
+
+func (s segment) apply(root interface{}, nodes []interface{}) []interface{} {
+	if s.recursive {
+		var all []interface{}
+		for _, node := range nodes {
+			all = descendants(node, all)
+		}
+		nodes = all
+	}
+
+	var out []interface{}
+	for _, node := range nodes {
+		for _, sel := range s.selectors {
+			out = sel.apply(root, node, out)
+		}
+	}
+	return out
+}
+
+// descendants appends node and everything below it, depth first
+func descendants(node interface{}, out []interface{}) []interface{} {
+	out = append(out, node)
+	for _, child := range children(node) {
+		out = descendants(child, out)
+	}
+	return out
+}
+
+// children lists the members of an object, ordered by key, or the elements of an array
+func children(node interface{}) []interface{} {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		out := make([]interface{}, len(keys))
+		for i, key := range keys {
+			out[i] = v[key]
+		}
+		return out
+	case []interface{}:
+		return v
+	}
+	return nil
+}
+
+type selector interface {
+	apply(root, node interface{}, out []interface{}) []interface{}
+}
+
+// missingReporter is implemented by the selectors of definite paths, to explain
+// what was missing when they select nothing
+type missingReporter interface {
+	missing(node interface{}) string
+}
+
+type nameSelector struct {
+	name string
+	// dotted is set for .name, which also indexes arrays when name is a number
+	dotted bool
+}
+
+func (s nameSelector) apply(_, node interface{}, out []interface{}) []interface{} {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		if value, ok := v[s.name]; ok {
+			out = append(out, value)
+		}
+	case []interface{}:
+		if idx, ok := s.index(); ok {
+			return indexSelector(idx).apply(nil, node, out)
+		}
+	}
+	return out
+}
+
+func (s nameSelector) index() (int, bool) {
+	if !s.dotted {
+		return 0, false
+	}
+	idx, err := strconv.Atoi(s.name)
+	return idx, err == nil && idx >= 0
+}
+
+func (s nameSelector) missing(node interface{}) string {
+	switch node.(type) {
+	case map[string]interface{}:
+		return fmt.Sprintf("key '%s' not found", s.name)
+	case []interface{}:
+		if idx, ok := s.index(); ok {
+			return indexSelector(idx).missing(node)
+		}
+	}
+	return fmt.Sprintf("cannot select '%s' from %s", s.name, typeName(node))
+}
+
+type indexSelector int
+
+func (s indexSelector) apply(_, node interface{}, out []interface{}) []interface{} {
+	if arr, ok := node.([]interface{}); ok {
+		idx := int(s)
+		if idx < 0 {
+			idx += len(arr)
+		}
+		if idx >= 0 && idx < len(arr) {
+			out = append(out, arr[idx])
+		}
+	}
+	return out
+}
+
+func (s indexSelector) missing(node interface{}) string {
+	if arr, ok := node.([]interface{}); ok {
+		return fmt.Sprintf("index %d out of range (length %d)", int(s), len(arr))
+	}
+	return fmt.Sprintf("cannot index %s", typeName(node))
+}
+
+type wildcardSelector struct{}
+
+func (wildcardSelector) apply(_, node interface{}, out []interface{}) []interface{} {
+	return append(out, children(node)...)
+}
+
+type sliceSelector struct {
+	start, end *int
+	step       int
+}
+
+func (s sliceSelector) apply(_, node interface{}, out []interface{}) []interface{} {
+	arr, ok := node.([]interface{})
+	if !ok {
+		return out
+	}
+	n := len(arr)
+	bound := func(p *int, def int) int {
+		if p == nil {
+			return def
+		}
+		i := *p
+		if i < 0 {
+			i += n
+		}
+		return i
+	}
+
+	if s.step > 0 {
+		start, end := clamp(bound(s.start, 0), 0, n), clamp(bound(s.end, n), 0, n)
+		for i := start; i < end; i += s.step {
+			out = append(out, arr[i])
+		}
+	} else {
+		start, end := clamp(bound(s.start, n-1), -1, n-1), clamp(bound(s.end, -n-1), -1, n-1)
+		for i := start; i > end; i += s.step {
+			out = append(out, arr[i])
+		}
+	}
+	return out
+}
+
+func clamp(i, lo, hi int) int {
+	if i < lo {
+		return lo
+	}
+	if i > hi {
+		return hi
+	}
+	return i
+}
+
+type filterSelector struct {
+	expr filterExpr
+}
+
+func (s filterSelector) apply(root, node interface{}, out []interface{}) []interface{} {
+	for _, child := range children(node) {
+		if s.expr.eval(root, child) {
+			out = append(out, child)
+		}
+	}
+	return out
+}
+
+// typeName names the JSON type of a decoded value
+func typeName(v interface{}) string {
+	switch v.(type) {
+	case map[string]interface{}:
+		return "an object"
+	case []interface{}:
+		return "an array"
+	case string:
+		return "a string"
+	case float64, int, int64:
+		return "a number"
+	case bool:
+		return "a boolean"
+	case nil:
+		return "null"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// Format renders a selected value for comparison with an expected value: strings as
+// they are, numbers and booleans as Go prints them, null as null, and arrays and
+// objects as JSON
+func Format(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case nil:
+		return "null"
+	case map[string]interface{}, []interface{}:
+		if data, err := json.Marshal(v); err == nil {
+			return string(data)
+		}
+	}
+	return fmt.Sprintf("%v", v)
+}
@@ -0,0 +1,124 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+const doc = `{
+	"status": "ok",
+	"data": {
+		"items": [
+			{"name": "alpha", "price": 5, "tags": ["a"]},
+			{"name": "beta", "price": 12, "stock": 0},
+			{"name": "gamma", "price": 8, "stock": 3}
+		],
+		"total": 3,
+		"odd key": true
+	}
+}`
+
+func TestGet(t *testing.T) {
+	var data interface{}
+	if err := json.Unmarshal([]byte(doc), &data); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		expr string
+		want interface{}
+	}{
+		// Older dotted paths
+		{"status", "ok"},
+		{"data.total", 3.0},
+		{"data.items.[2].name", "gamma"},
+		{"data.items.1.name", "beta"},
+		{".data.total", 3.0},
+
+		{"$.data.items[2].name", "gamma"},
+		{"$.data.items[-1].name", "gamma"},
+		{"$['data']['odd key']", true},
+		{`$["data"].items[0].tags[0]`, "a"},
+		{"$.data.items[*].name", []interface{}{"alpha", "beta", "gamma"}},
+		{"$.data.items[0:2].price", []interface{}{5.0, 12.0}},
+		{"$.data.items[::-1].name", []interface{}{"gamma", "beta", "alpha"}},
+		{"$.data.items[0,2].name", []interface{}{"alpha", "gamma"}},
+		{"$..name", []interface{}{"alpha", "beta", "gamma"}},
+		{"$.data.items[?(@.price > 6)].name", []interface{}{"beta", "gamma"}},
+		{"$.data.items[?@.price <= 8 && @.name != 'alpha'].name", []interface{}{"gamma"}},
+		{"$.data.items[?(@.stock)].name", []interface{}{"beta", "gamma"}},
+		{"$.data.items[?(!@.stock || @.stock == 0)].name", []interface{}{"alpha", "beta"}},
+		{`$.data.items[?(@.name == "beta")].price`, []interface{}{12.0}},
+		{"$.data.items[?(@.price == $.data.total + 0)]", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := Get(data, tt.expr)
+			if tt.want == nil {
+				var syntaxErr *SyntaxError
+				if !errors.As(err, &syntaxErr) {
+					t.Fatalf("Get() error = %v, want a syntax error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Get() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetNoMatch(t *testing.T) {
+	var data interface{}
+	if err := json.Unmarshal([]byte(doc), &data); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		expr    string
+		wantErr string
+	}{
+		{"data.missing", "path did not match: key 'missing' not found"},
+		{"$.data.items[7]", "path did not match: index 7 out of range (length 3)"},
+		{"$.status.code", "path did not match: cannot select 'code' from a string"},
+		{"$.data.items[?(@.price > 100)]", "path did not match: $.data.items[?(@.price > 100)]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := Get(data, tt.expr)
+			if !errors.Is(err, ErrNoMatch) || err.Error() != tt.wantErr {
+				t.Errorf("Get() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"$.items[",
+		"$.items[0",
+		"$.items['name",
+		"$.items[1:2:0]",
+		"$.items[?(@.price >)]",
+		"$.items[?(@.price > 1]",
+		"$.items[?('a')]",
+		"$.items]",
+		"$.",
+	} {
+		t.Run(expr, func(t *testing.T) {
+			_, err := Compile(expr)
+			var syntaxErr *SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Errorf("Compile() error = %v, want a syntax error", err)
+			}
+		})
+	}
+}
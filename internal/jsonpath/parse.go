@@ -0,0 +1,254 @@
+package jsonpath
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type parser struct {
+	src string
+	pos int
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return &SyntaxError{Expr: p.src, Offset: p.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *parser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *parser) skipSpace() {
+	for !p.eof() && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// consume skips spaces and then s, reporting whether it was there
+func (p *parser) consume(s string) bool {
+	p.skipSpace()
+	if strings.HasPrefix(p.src[p.pos:], s) {
+		p.pos += len(s)
+		return true
+	}
+	return false
+}
+
+// parseSegments reads segments until the end of the expression or, inside a filter,
+// until the first character that can't continue the path
+func (p *parser) parseSegments(inFilter bool) ([]segment, error) {
+	var segments []segment
+	for !p.eof() {
+		var seg segment
+		switch p.peek() {
+		case '.':
+			p.pos++
+			if p.peek() == '.' {
+				p.pos++
+				seg.recursive = true
+			}
+			switch p.peek() {
+			case '[':
+				// .[2] is accepted for the older dotted syntax
+				sels, err := p.parseBracket()
+				if err != nil {
+					return nil, err
+				}
+				seg.selectors = sels
+			case '*':
+				p.pos++
+				seg.selectors = []selector{wildcardSelector{}}
+			default:
+				name, err := p.parseName()
+				if err != nil {
+					return nil, err
+				}
+				seg.selectors = []selector{name}
+			}
+		case '[':
+			sels, err := p.parseBracket()
+			if err != nil {
+				return nil, err
+			}
+			seg.selectors = sels
+		default:
+			if inFilter {
+				return segments, nil
+			}
+			return nil, p.errorf("unexpected %q", p.peek())
+		}
+		segments = append(segments, seg)
+	}
+	return segments, nil
+}
+
+// parseName reads a dotted member name, which runs to the next . or [ (or, inside a
+// filter, to anything that ends an operand)
+func (p *parser) parseName() (nameSelector, error) {
+	start := p.pos
+	for !p.eof() && !strings.ContainsRune(".[]()=!<>&|, \t", rune(p.src[p.pos])) {
+		p.pos++
+	}
+	if p.pos == start {
+		return nameSelector{}, p.errorf("expected a member name")
+	}
+	return nameSelector{name: p.src[start:p.pos], dotted: true}, nil
+}
+
+// parseBracket reads [...]: a wildcard, a filter, or a union of names, indices and slices
+func (p *parser) parseBracket() ([]selector, error) {
+	p.pos++ // [
+	p.skipSpace()
+
+	switch p.peek() {
+	case '*':
+		p.pos++
+		if !p.consume("]") {
+			return nil, p.errorf("expected ]")
+		}
+		return []selector{wildcardSelector{}}, nil
+	case '?':
+		p.pos++
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.consume("]") {
+			return nil, p.errorf("expected ] after filter")
+		}
+		return []selector{filterSelector{expr: expr}}, nil
+	}
+
+	var sels []selector
+	for {
+		p.skipSpace()
+		switch c := p.peek(); {
+		case c == '\'' || c == '"':
+			name, err := p.parseString()
+			if err != nil {
+				return nil, err
+			}
+			sels = append(sels, nameSelector{name: name})
+		case c == '-' || c == ':' || isDigit(c):
+			sel, err := p.parseIndexOrSlice()
+			if err != nil {
+				return nil, err
+			}
+			sels = append(sels, sel)
+		case c == 0:
+			return nil, p.errorf("unterminated [")
+		default:
+			return nil, p.errorf("unexpected %q in brackets", c)
+		}
+
+		p.skipSpace()
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ']':
+			p.pos++
+			return sels, nil
+		default:
+			return nil, p.errorf("expected , or ]")
+		}
+	}
+}
+
+func (p *parser) parseIndexOrSlice() (selector, error) {
+	var parts [3]*int
+	n := 0
+	for {
+		p.skipSpace()
+		if c := p.peek(); c == '-' || isDigit(c) {
+			i, err := p.parseInt()
+			if err != nil {
+				return nil, err
+			}
+			parts[n] = &i
+		}
+		p.skipSpace()
+		if p.peek() != ':' {
+			break
+		}
+		if n == 2 {
+			return nil, p.errorf("a slice has at most three parts")
+		}
+		p.pos++
+		n++
+	}
+
+	if n == 0 {
+		if parts[0] == nil {
+			return nil, p.errorf("expected an index")
+		}
+		return indexSelector(*parts[0]), nil
+	}
+	step := 1
+	if parts[2] != nil {
+		step = *parts[2]
+	}
+	if step == 0 {
+		return nil, p.errorf("slice step cannot be zero")
+	}
+	return sliceSelector{start: parts[0], end: parts[1], step: step}, nil
+}
+
+func (p *parser) parseInt() (int, error) {
+	start := p.pos
+	if p.peek() == '-' {
+		p.pos++
+	}
+	for isDigit(p.peek()) {
+		p.pos++
+	}
+	i, err := strconv.Atoi(p.src[start:p.pos])
+	if err != nil {
+		p.pos = start
+		return 0, p.errorf("invalid integer")
+	}
+	return i, nil
+}
+
+// parseString reads a single- or double-quoted string, with backslash escapes
+func (p *parser) parseString() (string, error) {
+	quote := p.peek()
+	p.pos++
+	var b strings.Builder
+	for !p.eof() {
+		c := p.src[p.pos]
+		p.pos++
+		switch c {
+		case quote:
+			return b.String(), nil
+		case '\\':
+			if p.eof() {
+				return "", p.errorf("unterminated string")
+			}
+			esc := p.src[p.pos]
+			p.pos++
+			switch esc {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			default:
+				b.WriteByte(esc)
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
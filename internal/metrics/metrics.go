@@ -0,0 +1,124 @@
+// Package metrics keeps per-check Prometheus metrics and renders them in the
+// Prometheus text exposition format. It is written against the standard library
+// only; the format is small enough that client_golang is not needed.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gocheck/internal/models"
+)
+
+type checkMetrics struct {
+	name           string
+	checkType      string
+	up             bool
+	responseTimeMs int
+	runs           uint64
+	failures       uint64
+}
+
+// Registry holds the latest metrics of every check that has run
+type Registry struct {
+	mu     sync.RWMutex
+	checks map[int64]*checkMetrics
+}
+
+func NewRegistry() *Registry {
+	return &Registry{checks: make(map[int64]*checkMetrics)}
+}
+
+// Default is the registry the check engine records into
+var Default = NewRegistry()
+
+// Observe records the result of one check run
+func (r *Registry) Observe(check models.Check, history *models.CheckHistory) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	m, ok := r.checks[check.ID]
+	if !ok {
+		m = &checkMetrics{}
+		r.checks[check.ID] = m
+	}
+	m.name = check.Name
+	m.checkType = string(check.Type)
+	if m.checkType == "" {
+		m.checkType = string(models.CheckTypeHTTP)
+	}
+	m.up = history.Success
+	m.responseTimeMs = history.ResponseTimeMs
+	m.runs++
+	if !history.Success {
+		m.failures++
+	}
+}
+
+// Forget drops the metrics of a deleted or disabled check
+func (r *Registry) Forget(checkID int64) {
+	r.mu.Lock()
+	delete(r.checks, checkID)
+	r.mu.Unlock()
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// WriteTo renders all metrics in the Prometheus text format, ordered by check ID
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mu.RLock()
+	ids := make([]int64, 0, len(r.checks))
+	snapshot := make(map[int64]checkMetrics, len(r.checks))
+	for id, m := range r.checks {
+		ids = append(ids, id)
+		snapshot[id] = *m
+	}
+	r.mu.RUnlock()
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	labels := func(id int64) string {
+		m := snapshot[id]
+		return fmt.Sprintf(`{check_id="%d",check_name="%s",check_type="%s"}`,
+			id, labelEscaper.Replace(m.name), labelEscaper.Replace(m.checkType))
+	}
+
+	var b strings.Builder
+	family := func(name, help, kind string, value func(m checkMetrics) string) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+		for _, id := range ids {
+			fmt.Fprintf(&b, "%s%s %s\n", name, labels(id), value(snapshot[id]))
+		}
+	}
+
+	family("gocheck_check_up", "Whether the last run of the check succeeded (1) or failed (0).", "gauge",
+		func(m checkMetrics) string {
+			if m.up {
+				return "1"
+			}
+			return "0"
+		})
+	family("gocheck_check_response_time_seconds", "Response time of the last run of the check.", "gauge",
+		func(m checkMetrics) string {
+			return strconv.FormatFloat(float64(m.responseTimeMs)/1000, 'f', -1, 64)
+		})
+	family("gocheck_check_runs_total", "Number of times the check has run since startup.", "counter",
+		func(m checkMetrics) string { return strconv.FormatUint(m.runs, 10) })
+	family("gocheck_check_failures_total", "Number of failed runs of the check since startup.", "counter",
+		func(m checkMetrics) string { return strconv.FormatUint(m.failures, 10) })
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// Handler serves the registry for Prometheus scrapes
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.WriteTo(w)
+	})
+}
@@ -2,8 +2,11 @@ package models
 
 import (
 	"encoding/json"
+	"fmt"
 	"strconv"
 	"time"
+
+	"gocheck/internal/statuscode"
 )
 
 type CheckType string
@@ -80,6 +83,38 @@ func (f FlexibleInt) MarshalJSON() ([]byte, error) {
 	return json.Marshal(f.Value)
 }
 
+// StatusCodes accepts expected status codes as numbers and as strings holding codes,
+// ranges such as "200-299" or classes such as "2xx", and holds them expanded
+type StatusCodes []int
+
+func (s *StatusCodes) UnmarshalJSON(data []byte) error {
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		// A single string such as "200-204,301"
+		var spec string
+		if json.Unmarshal(data, &spec) != nil {
+			return err
+		}
+		items = []json.RawMessage{data}
+	}
+
+	specs := make([]string, len(items))
+	for i, item := range items {
+		var code int
+		if err := json.Unmarshal(item, &code); err == nil {
+			specs[i] = strconv.Itoa(code)
+		} else if err := json.Unmarshal(item, &specs[i]); err != nil {
+			return fmt.Errorf("expected_status_codes: %s is neither a code nor a range", item)
+		}
+	}
+	codes, err := statuscode.Parse(specs)
+	if err != nil {
+		return fmt.Errorf("expected_status_codes: %w", err)
+	}
+	*s = codes
+	return nil
+}
+
 const (
 	CheckTypeHTTP             CheckType = "http"
 	CheckTypePing             CheckType = "ping"
@@ -88,8 +123,34 @@ const (
 	CheckTypeDNS              CheckType = "dns"
 	CheckTypeTailscale        CheckType = "tailscale"
 	CheckTypeTailscaleService CheckType = "tailscale_service"
+	CheckTypeTCP              CheckType = "tcp"
+	CheckTypeNTP              CheckType = "ntp"
+	CheckTypeSSLCert          CheckType = "ssl_cert"
+	CheckTypeRedis            CheckType = "redis"
+	CheckTypeMySQL            CheckType = "mysql"
+	CheckTypeGRPC             CheckType = "grpc"
+	CheckTypeSMTP             CheckType = "smtp"
+	CheckTypeMongo            CheckType = "mongo"
+)
+
+// HTTP authentication schemes for HTTP and JSON HTTP checks
+const (
+	AuthTypeNone   = "none"
+	AuthTypeBasic  = "basic"
+	AuthTypeBearer = "bearer"
 )
 
+// Valid reports whether t is a known check type
+func (t CheckType) Valid() bool {
+	switch t {
+	case CheckTypeHTTP, CheckTypePing, CheckTypePostgres, CheckTypeJSONHTTP, CheckTypeDNS,
+		CheckTypeTailscale, CheckTypeTailscaleService, CheckTypeTCP, CheckTypeNTP,
+		CheckTypeSSLCert, CheckTypeRedis, CheckTypeMySQL, CheckTypeGRPC, CheckTypeSMTP, CheckTypeMongo:
+		return true
+	}
+	return false
+}
+
 type Group struct {
 	ID        int64     `json:"id"`
 	Name      string    `json:"name"`
@@ -109,6 +170,7 @@ type Check struct {
 	Type              CheckType `json:"type"`
 	URL               string    `json:"url"`
 	IntervalSeconds   int       `json:"interval_seconds"`
+	CronExpression    string    `json:"cron_expression,omitempty"` // overrides the interval when set
 	TimeoutSeconds    int       `json:"timeout_seconds"`
 	Retries           int       `json:"retries,omitempty"`
 	RetryDelaySeconds int       `json:"retry_delay_seconds,omitempty"`
@@ -116,27 +178,152 @@ type Check struct {
 	CreatedAt         time.Time `json:"created_at"`
 	GroupID           *int64    `json:"group_id,omitempty"`
 	Tags              []Tag     `json:"tags,omitempty"`
+	Public            bool      `json:"public"` // included in the public incident feeds
+
+	// MaxResponseTimeMs fails HTTP, JSON HTTP and Tailscale service checks that respond
+	// slower than this, even with a good status (0 disables the limit)
+	MaxResponseTimeMs int `json:"max_response_time_ms,omitempty"`
+
+	// RetentionDays overrides the global history retention for this check
+	// (0 uses the global policy)
+	RetentionDays int `json:"retention_days,omitempty"`
+
+	// ConfirmationThreshold is how many consecutive results in a new state are needed
+	// before a status change is notified (defaults to 1)
+	ConfirmationThreshold int `json:"confirmation_threshold,omitempty"`
+
+	// RenotifyIntervalMinutes repeats the DOWN notification on this cadence while the
+	// check stays down (0 notifies only on the transition)
+	RenotifyIntervalMinutes int `json:"renotify_interval_minutes,omitempty"`
+
+	// ResultWebhookURL receives every result of this check, not just status changes
+	ResultWebhookURL string `json:"result_webhook_url,omitempty"`
+
+	// TriggerSecret enables the signed trigger webhook; requests must carry an
+	// HMAC-SHA256 of their body keyed with it. Write-only, like the auth password.
+	TriggerSecret string `json:"-"`
+
+	// BackoffOnFailure stretches the interval of a check that stays down, doubling it
+	// with each failure once the outage is confirmed, up to a cap, until it recovers.
+	// Cron checks keep their schedule.
+	BackoffOnFailure bool `json:"backoff_on_failure,omitempty"`
 
 	// HTTP specific
-	ExpectedStatusCodes []int  `json:"expected_status_codes,omitempty"`
-	Method              string `json:"method,omitempty"`
+	ExpectedStatusCodes []int             `json:"expected_status_codes,omitempty"`
+	Method              string            `json:"method,omitempty"`
+	Headers             map[string]string `json:"headers,omitempty"`
+	RequestBody         string            `json:"request_body,omitempty"`
+	ContentType         string            `json:"content_type,omitempty"`
+	// BypassCache asks caches and CDNs to revalidate with the origin (no-cache)
+	BypassCache bool `json:"bypass_cache,omitempty"`
+	// AuthType is none (default), basic or bearer. The password and token are
+	// write-only: they are never returned by the API, like a user's password hash.
+	AuthType     string `json:"auth_type,omitempty"`
+	AuthUsername string `json:"auth_username,omitempty"`
+	AuthPassword string `json:"-"`
+	AuthToken    string `json:"-"`
+	// FollowRedirects follows 3xx responses (the default); without it the check sees
+	// the redirect itself
+	FollowRedirects bool `json:"follow_redirects"`
+
+	// Body assertion: contains (default), not_contains or regex
+	ResponseKeyword     string `json:"response_keyword,omitempty"`
+	ResponseKeywordMode string `json:"response_keyword_mode,omitempty"`
+	// ForbiddenBodyKeyword fails the check whenever the body contains it
+	ForbiddenBodyKeyword string `json:"forbidden_body_keyword,omitempty"`
+
+	// MinResponseBytes and MaxResponseBytes bound the size of the body (0 disables a
+	// bound), to catch truncated or empty responses with a good status
+	MinResponseBytes int `json:"min_response_bytes,omitempty"`
+	MaxResponseBytes int `json:"max_response_bytes,omitempty"`
+
+	// ExpectedHTTPVersion fails the check unless the response is served over this
+	// HTTP version: "1.0", "1.1" or "2"
+	ExpectedHTTPVersion string `json:"expected_http_version,omitempty"`
 
-	// JSON HTTP specific - JSONata expression for assertion
+	// ProxyURL routes HTTP and JSON HTTP requests through an http(s) or socks5 proxy
+	ProxyURL string `json:"proxy_url,omitempty"`
+	// HTTP/JSON HTTP: response header that must be present and, when a value is set,
+	// contain it (ExpectedHeaderMode "contains", the default) or equal it ("exact")
+	ExpectedHeaderName  string `json:"expected_header_name,omitempty"`
+	ExpectedHeaderValue string `json:"expected_header_value,omitempty"`
+	ExpectedHeaderMode  string `json:"expected_header_mode,omitempty"`
+
+	// JSON HTTP specific - JSONPath expression for assertion
 	JSONPath          string `json:"json_path,omitempty"`
 	ExpectedJSONValue string `json:"expected_json_value,omitempty"`
 
-	// PostgreSQL specific
+	// PostgreSQL specific (PostgresQuery and ExpectedQueryValue are shared with MySQL)
 	PostgresConnString string `json:"postgres_conn_string,omitempty"`
 	PostgresQuery      string `json:"postgres_query,omitempty"`
 	ExpectedQueryValue string `json:"expected_query_value,omitempty"`
 
-	// Ping specific
+	// PostgreSQL/MySQL queries may return any number of rows and columns.
+	// ExpectedRowCount is the exact number of rows to expect (nil requires at least
+	// one), and QueryColumn the column compared with ExpectedQueryValue in every row
+	// (the first when empty).
+	ExpectedRowCount *int   `json:"expected_row_count,omitempty"`
+	QueryColumn      string `json:"query_column,omitempty"`
+
+	// AssertionOperator compares the JSON or query value with the expected one: eq
+	// (default), ne, gt, lt, gte, lte or contains, numerically when both are numbers
+	AssertionOperator string `json:"assertion_operator,omitempty"`
+
+	// MySQL/MariaDB specific - DSN such as user:password@tcp(host:3306)/dbname
+	MySQLConnString string `json:"mysql_conn_string,omitempty"`
+
+	// MongoDB specific - mongodb:// or mongodb+srv:// URI. PostgresQuery holds
+	// <collection>.count(<filter>) or <collection>.findOne(<filter>) for MongoDB checks.
+	MongoConnString string `json:"mongo_conn_string,omitempty"`
+
+	// Redis specific - redis://[[user]:password@]host[:port][/db], rediss:// for TLS
+	RedisConnString    string `json:"redis_conn_string,omitempty"`
+	RedisKey           string `json:"redis_key,omitempty"`
+	ExpectedRedisValue string `json:"expected_redis_value,omitempty"`
+
+	// Ping specific (also used by TCP and NTP)
 	Host string `json:"host,omitempty"`
 
+	// TCP specific (Port is also used by NTP, defaulting to 123, and SMTP, defaulting to
+	// 25; SMTP checks match ExpectedBanner against the 220 greeting)
+	Port           int    `json:"port,omitempty"`
+	ExpectedBanner string `json:"expected_banner,omitempty"`
+
+	// SMTP specific - require the server to offer STARTTLS and complete the handshake
+	SMTPStartTLS bool `json:"smtp_starttls,omitempty"`
+
+	// Ping/TCP: succeed only when the host does not answer or the port is closed
+	ExpectUnreachable bool `json:"expect_unreachable,omitempty"`
+
+	// Ping specific: echo requests sent per run (defaults to 1), and the packet loss
+	// percentage above which the check fails (0 fails only when every request is lost)
+	PingCount     int `json:"ping_count,omitempty"`
+	MaxPacketLoss int `json:"max_packet_loss,omitempty"`
+
+	// HTTP/TCP/Ping: local IP address or interface name the check originates from,
+	// overriding the global source address. Ignored by probes.
+	SourceIP string `json:"source_ip,omitempty"`
+
+	// gRPC specific (uses Host and Port) - service name sent to grpc.health.v1.Health/Check,
+	// empty for the server's overall health, and whether to connect over TLS
+	GRPCService string `json:"grpc_service,omitempty"`
+	GRPCTLS     bool   `json:"grpc_tls,omitempty"`
+
+	// NTP specific - maximum allowed clock offset (defaults to 1000ms)
+	NTPMaxOffsetMs int `json:"ntp_max_offset_ms,omitempty"`
+
+	// SSL certificate specific (uses Host and Port, defaulting to 443)
+	CertExpiryThresholdDays int `json:"cert_expiry_threshold_days,omitempty"`
+	// InsecureSkipVerify skips certificate verification for SSL certificate, HTTP, JSON
+	// HTTP, gRPC and SMTP checks, for self-signed endpoints
+	InsecureSkipVerify bool `json:"insecure_skip_verify,omitempty"`
+
 	// DNS specific
 	DNSHostname      string `json:"dns_hostname,omitempty"`
 	DNSRecordType    string `json:"dns_record_type,omitempty"`
 	ExpectedDNSValue string `json:"expected_dns_value,omitempty"`
+	// DNSResolver is the nameserver (host:port) to ask instead of the system's
+	DNSResolver string `json:"dns_resolver,omitempty"`
 
 	// Tailscale specific
 	TailscaleDeviceID string `json:"tailscale_device_id,omitempty"`
@@ -159,6 +346,168 @@ type CheckWithStatus struct {
 	History       []CheckHistory `json:"history,omitempty"`
 	IsUp          bool           `json:"is_up"`
 	LastCheckedAt *time.Time     `json:"last_checked_at,omitempty"`
+	// Regions is the latest status per region, set once probes have run the check
+	Regions map[string]RegionStatus `json:"regions,omitempty"`
+	// Misconfigured explains why the check cannot run as configured; such checks are
+	// skipped rather than recorded as down
+	Misconfigured string `json:"misconfigured,omitempty"`
+}
+
+// RegionStatus is the latest result of a check in one region ("host" for the server).
+// The recent latency fields are only filled in by the regions endpoint.
+type RegionStatus struct {
+	Region            string     `json:"region"`
+	IsUp              bool       `json:"is_up"`
+	StatusCode        int        `json:"status_code,omitempty"`
+	ResponseTimeMs    int        `json:"response_time_ms"`
+	ErrorMessage      string     `json:"error_message,omitempty"`
+	LastCheckedAt     *time.Time `json:"last_checked_at,omitempty"`
+	RecentChecks      int        `json:"recent_checks,omitempty"`
+	AvgResponseTimeMs int        `json:"avg_response_time_ms,omitempty"`
+}
+
+// CheckRegions is a check's assigned probe regions along with its status in every
+// region it has run in
+type CheckRegions struct {
+	Assigned []string       `json:"assigned"`
+	Regions  []RegionStatus `json:"regions"`
+}
+
+// SetCheckRegionsRequest replaces the probe regions a check is dispatched to
+type SetCheckRegionsRequest struct {
+	Assigned []string `json:"assigned"`
+}
+
+// MonitoringStatus reports whether scheduled checks are globally paused
+type MonitoringStatus struct {
+	Paused   bool       `json:"paused"`
+	PausedAt *time.Time `json:"paused_at,omitempty"`
+}
+
+type UpdateMonitoringRequest struct {
+	Paused *bool `json:"paused"`
+}
+
+// CheckFilter narrows and paginates the checks list; zero values match everything
+type CheckFilter struct {
+	Type    CheckType
+	Enabled *bool
+	GroupID *int64
+	Search  string // case-insensitive substring of the name
+	Tags    TagFilter
+	Limit   int // 0 returns all matching checks
+	Offset  int
+}
+
+// TagFilter restricts checks to those carrying any of the tags, or all of them with
+// MatchAll. No IDs means no restriction.
+type TagFilter struct {
+	IDs      []int64
+	MatchAll bool
+}
+
+// CheckImportRow is one check in a bulk import, as a JSON object or a CSV row with
+// the same column names
+type CheckImportRow struct {
+	Name     string      `json:"name"`
+	Type     CheckType   `json:"type"`
+	URL      string      `json:"url"`
+	Host     string      `json:"host"`
+	Port     FlexibleInt `json:"port"`
+	Interval FlexibleInt `json:"interval"`
+	Timeout  FlexibleInt `json:"timeout"`
+}
+
+// CheckImportResult is the outcome of one imported row; Status is "created" or "skipped"
+type CheckImportResult struct {
+	Row    int    `json:"row"`
+	Name   string `json:"name,omitempty"`
+	Status string `json:"status"`
+	ID     int64  `json:"id,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// CheckImportResponse summarises a bulk import. Error is set when reading stopped
+// early; rows before it were still imported.
+type CheckImportResponse struct {
+	Created   int                 `json:"created"`
+	Skipped   int                 `json:"skipped"`
+	Results   []CheckImportResult `json:"results"`
+	Truncated bool                `json:"truncated,omitempty"`
+	Error     string              `json:"error,omitempty"`
+}
+
+// CheckApplyResult is the outcome for one check of a declarative apply. Action is
+// "create", "update", "unchanged" or "delete"; Error is set when saving it failed.
+type CheckApplyResult struct {
+	Name   string `json:"name"`
+	Action string `json:"action"`
+	ID     int64  `json:"id,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// CheckApplyResponse summarises a declarative apply. With DryRun the results are
+// what would have been done, and nothing was changed.
+type CheckApplyResponse struct {
+	DryRun    bool               `json:"dry_run,omitempty"`
+	Created   int                `json:"created"`
+	Updated   int                `json:"updated"`
+	Unchanged int                `json:"unchanged"`
+	Deleted   int                `json:"deleted"`
+	Results   []CheckApplyResult `json:"results"`
+	// Groups and tags referenced by name that did not exist and were (or would be) created
+	CreatedGroups []string `json:"created_groups,omitempty"`
+	CreatedTags   []string `json:"created_tags,omitempty"`
+}
+
+// ConfigExportVersion is the format version of a config export
+const ConfigExportVersion = 1
+
+// ConfigExport is a portable copy of the checks, groups and tags of an instance. Checks
+// refer to their group and tags by name, so the document can be applied to another
+// instance where the IDs differ. Secrets says whether write-only secrets are included.
+type ConfigExport struct {
+	Version    int             `json:"version"`
+	ExportedAt time.Time       `json:"exported_at"`
+	Secrets    bool            `json:"secrets"`
+	Groups     []ExportedGroup `json:"groups"`
+	Tags       []ExportedTag   `json:"tags"`
+	Checks     []ExportedCheck `json:"checks"`
+}
+
+type ExportedGroup struct {
+	Name      string `json:"name"`
+	SortOrder int    `json:"sort_order"`
+}
+
+type ExportedTag struct {
+	Name  string `json:"name"`
+	Color string `json:"color,omitempty"`
+}
+
+// ExportedCheck is a check in a config export. The outer fields shadow the
+// instance-specific ones of Check (left zero so they are omitted) and add the group
+// and tag names and the write-only secrets.
+type ExportedCheck struct {
+	Check
+	ID              int64      `json:"id,omitempty"`
+	CreatedAt       *time.Time `json:"created_at,omitempty"`
+	GroupID         *int64     `json:"group_id,omitempty"`
+	SnapshotTakenAt *time.Time `json:"snapshot_taken_at,omitempty"`
+
+	Group         string   `json:"group,omitempty"`
+	Tags          []string `json:"tags"`
+	AuthPassword  string   `json:"auth_password,omitempty"`
+	AuthToken     string   `json:"auth_token,omitempty"`
+	TriggerSecret string   `json:"trigger_secret,omitempty"`
+}
+
+// CheckListResponse is the paginated checks list returned when filters are used
+type CheckListResponse struct {
+	Checks []CheckWithStatus `json:"checks"`
+	Total  int               `json:"total"`
+	Limit  int               `json:"limit,omitempty"`
+	Offset int               `json:"offset"`
 }
 
 type GroupWithChecks struct {
@@ -175,6 +524,12 @@ type Stats struct {
 	UpChecks     int     `json:"up_checks"`
 	DownChecks   int     `json:"down_checks"`
 	TotalUptime  float64 `json:"total_uptime"`
+
+	// Engine load: checks running now, waiting for a free slot, and the cap on
+	// simultaneous checks. Set by the API rather than the database.
+	RunningChecks       int `json:"running_checks"`
+	WaitingChecks       int `json:"waiting_checks"`
+	MaxConcurrentChecks int `json:"max_concurrent_checks"`
 }
 
 type RegionStats struct {
@@ -194,12 +549,40 @@ type CheckStats struct {
 	SuccessCount int           `json:"success_count"`
 	SuccessRate  float64       `json:"success_rate"`
 	AvgLatency   int           `json:"avg_latency"`
+	P50Latency   int           `json:"p50_latency"`
 	P90Latency   int           `json:"p90_latency"`
+	P95Latency   int           `json:"p95_latency"`
 	P99Latency   int           `json:"p99_latency"`
 	DownCount    int           `json:"down_count"`
 	Regions      []RegionStats `json:"regions"`
 }
 
+// SLAReport is a check's uptime over a range measured against a target. Each result
+// counts for the time until the next one, but no longer than the check's schedule
+// allows, so time the check was disabled, paused or not yet created is "no data" and
+// neither uptime nor downtime. Minutes are averaged across the regions that reported.
+type SLAReport struct {
+	CheckID                    int64     `json:"check_id"`
+	From                       time.Time `json:"from"`
+	To                         time.Time `json:"to"`
+	Target                     float64   `json:"target"`
+	Uptime                     *float64  `json:"uptime"` // nil without any data in the range
+	Met                        *bool     `json:"met"`
+	MonitoredMinutes           float64   `json:"monitored_minutes"`
+	NoDataMinutes              float64   `json:"no_data_minutes"`
+	ErrorBudgetMinutes         float64   `json:"error_budget_minutes"` // allowed downtime of the monitored time
+	ErrorBudgetConsumedMinutes float64   `json:"error_budget_consumed_minutes"`
+}
+
+// LatencyPercentiles are response time percentiles in milliseconds, interpolated
+// between results; all zero when there are no results
+type LatencyPercentiles struct {
+	P50 float64 `json:"p50"`
+	P90 float64 `json:"p90"`
+	P95 float64 `json:"p95"`
+	P99 float64 `json:"p99"`
+}
+
 type CreateCheckRequest struct {
 	Name                string        `json:"name"`
 	Type                CheckType     `json:"type"`
@@ -211,22 +594,71 @@ type CreateCheckRequest struct {
 	Enabled             bool          `json:"enabled"`
 	GroupID             FlexibleInt64 `json:"group_id,omitempty"`
 	TagIDs              []int64       `json:"tag_ids,omitempty"`
-	ExpectedStatusCodes []int         `json:"expected_status_codes,omitempty"`
+	ExpectedStatusCodes StatusCodes   `json:"expected_status_codes,omitempty"`
 	Method              string        `json:"method,omitempty"`
 	JSONPath            string        `json:"json_path,omitempty"`
 	ExpectedJSONValue   string        `json:"expected_json_value,omitempty"`
 	PostgresConnString  string        `json:"postgres_conn_string,omitempty"`
 	PostgresQuery       string        `json:"postgres_query,omitempty"`
 	ExpectedQueryValue  string        `json:"expected_query_value,omitempty"`
+	AssertionOperator   string        `json:"assertion_operator,omitempty"`
+	MySQLConnString     string        `json:"mysql_conn_string,omitempty"`
+	MongoConnString     string        `json:"mongo_conn_string,omitempty"`
+	RedisConnString     string        `json:"redis_conn_string,omitempty"`
+	RedisKey            string        `json:"redis_key,omitempty"`
+	ExpectedRedisValue  string        `json:"expected_redis_value,omitempty"`
 	Host                string        `json:"host,omitempty"`
 	DNSHostname         string        `json:"dns_hostname,omitempty"`
 	DNSRecordType       string        `json:"dns_record_type,omitempty"`
 	ExpectedDNSValue    string        `json:"expected_dns_value,omitempty"`
+	DNSResolver         string        `json:"dns_resolver,omitempty"`
 	TailscaleDeviceID   string        `json:"tailscale_device_id,omitempty"`
 	TailscaleServiceHost     string   `json:"tailscale_service_host,omitempty"`
 	TailscaleServicePort     FlexibleInt `json:"tailscale_service_port,omitempty"`
 	TailscaleServiceProtocol string   `json:"tailscale_service_protocol,omitempty"`
 	TailscaleServicePath     string   `json:"tailscale_service_path,omitempty"`
+	Public                   bool     `json:"public,omitempty"`
+	Port                     FlexibleInt `json:"port,omitempty"`
+	ExpectedBanner           string   `json:"expected_banner,omitempty"`
+	GRPCService              string   `json:"grpc_service,omitempty"`
+	GRPCTLS                  bool     `json:"grpc_tls,omitempty"`
+	SMTPStartTLS             bool     `json:"smtp_starttls,omitempty"`
+	Headers                  map[string]string `json:"headers,omitempty"`
+	ResultWebhookURL         string   `json:"result_webhook_url,omitempty"`
+	RequestBody              string   `json:"request_body,omitempty"`
+	ContentType              string   `json:"content_type,omitempty"`
+	AuthType                 string   `json:"auth_type,omitempty"`
+	AuthUsername             string   `json:"auth_username,omitempty"`
+	AuthPassword             string   `json:"auth_password,omitempty"`
+	AuthToken                string   `json:"auth_token,omitempty"`
+	FollowRedirects          *bool    `json:"follow_redirects,omitempty"`
+	ResponseKeyword          string   `json:"response_keyword,omitempty"`
+	ResponseKeywordMode      string   `json:"response_keyword_mode,omitempty"`
+	ForbiddenBodyKeyword     string   `json:"forbidden_body_keyword,omitempty"`
+	MinResponseBytes         FlexibleInt `json:"min_response_bytes,omitempty"`
+	MaxResponseBytes         FlexibleInt `json:"max_response_bytes,omitempty"`
+	ExpectedHTTPVersion      string   `json:"expected_http_version,omitempty"`
+	ProxyURL                 string   `json:"proxy_url,omitempty"`
+	TriggerSecret            string   `json:"trigger_secret,omitempty"`
+	BackoffOnFailure         bool     `json:"backoff_on_failure,omitempty"`
+	ExpectedRowCount         *int     `json:"expected_row_count,omitempty"`
+	QueryColumn              string   `json:"query_column,omitempty"`
+	ExpectedHeaderName       string   `json:"expected_header_name,omitempty"`
+	ExpectedHeaderValue      string   `json:"expected_header_value,omitempty"`
+	ExpectedHeaderMode       string   `json:"expected_header_mode,omitempty"`
+	NTPMaxOffsetMs           FlexibleInt `json:"ntp_max_offset_ms,omitempty"`
+	CertExpiryThresholdDays  FlexibleInt `json:"cert_expiry_threshold_days,omitempty"`
+	InsecureSkipVerify       bool     `json:"insecure_skip_verify,omitempty"`
+	CronExpression           string   `json:"cron_expression,omitempty"`
+	ExpectUnreachable        bool     `json:"expect_unreachable,omitempty"`
+	PingCount                FlexibleInt `json:"ping_count,omitempty"`
+	MaxPacketLoss            FlexibleInt `json:"max_packet_loss,omitempty"`
+	BypassCache              bool     `json:"bypass_cache,omitempty"`
+	SourceIP                 string   `json:"source_ip,omitempty"`
+	ConfirmationThreshold    FlexibleInt `json:"confirmation_threshold,omitempty"`
+	RenotifyIntervalMinutes  FlexibleInt `json:"renotify_interval_minutes,omitempty"`
+	MaxResponseTimeMs        FlexibleInt `json:"max_response_time_ms,omitempty"`
+	RetentionDays            FlexibleInt `json:"retention_days,omitempty"`
 }
 
 type UpdateCheckRequest struct {
@@ -240,22 +672,71 @@ type UpdateCheckRequest struct {
 	Enabled             *bool         `json:"enabled,omitempty"`
 	GroupID             FlexibleInt64 `json:"group_id,omitempty"`
 	TagIDs              *[]int64      `json:"tag_ids,omitempty"`
-	ExpectedStatusCodes *[]int        `json:"expected_status_codes,omitempty"`
+	ExpectedStatusCodes *StatusCodes  `json:"expected_status_codes,omitempty"`
 	Method              *string       `json:"method,omitempty"`
 	JSONPath            *string       `json:"json_path,omitempty"`
 	ExpectedJSONValue   *string       `json:"expected_json_value,omitempty"`
 	PostgresConnString  *string       `json:"postgres_conn_string,omitempty"`
 	PostgresQuery       *string       `json:"postgres_query,omitempty"`
 	ExpectedQueryValue  *string       `json:"expected_query_value,omitempty"`
+	AssertionOperator   *string       `json:"assertion_operator,omitempty"`
+	MySQLConnString     *string       `json:"mysql_conn_string,omitempty"`
+	MongoConnString     *string       `json:"mongo_conn_string,omitempty"`
+	RedisConnString     *string       `json:"redis_conn_string,omitempty"`
+	RedisKey            *string       `json:"redis_key,omitempty"`
+	ExpectedRedisValue  *string       `json:"expected_redis_value,omitempty"`
 	Host                *string       `json:"host,omitempty"`
 	DNSHostname         *string       `json:"dns_hostname,omitempty"`
 	DNSRecordType       *string       `json:"dns_record_type,omitempty"`
 	ExpectedDNSValue    *string       `json:"expected_dns_value,omitempty"`
+	DNSResolver         *string       `json:"dns_resolver,omitempty"`
 	TailscaleDeviceID   *string       `json:"tailscale_device_id,omitempty"`
 	TailscaleServiceHost     *string  `json:"tailscale_service_host,omitempty"`
 	TailscaleServicePort     FlexibleInt `json:"tailscale_service_port,omitempty"`
 	TailscaleServiceProtocol *string  `json:"tailscale_service_protocol,omitempty"`
 	TailscaleServicePath     *string  `json:"tailscale_service_path,omitempty"`
+	Public                   *bool    `json:"public,omitempty"`
+	Port                     FlexibleInt `json:"port,omitempty"`
+	ExpectedBanner           *string  `json:"expected_banner,omitempty"`
+	GRPCService              *string  `json:"grpc_service,omitempty"`
+	GRPCTLS                  *bool    `json:"grpc_tls,omitempty"`
+	SMTPStartTLS             *bool    `json:"smtp_starttls,omitempty"`
+	Headers                  *map[string]string `json:"headers,omitempty"`
+	ResultWebhookURL         *string  `json:"result_webhook_url,omitempty"`
+	RequestBody              *string  `json:"request_body,omitempty"`
+	ContentType              *string  `json:"content_type,omitempty"`
+	AuthType                 *string  `json:"auth_type,omitempty"`
+	AuthUsername             *string  `json:"auth_username,omitempty"`
+	AuthPassword             *string  `json:"auth_password,omitempty"`
+	AuthToken                *string  `json:"auth_token,omitempty"`
+	FollowRedirects          *bool    `json:"follow_redirects,omitempty"`
+	ResponseKeyword          *string  `json:"response_keyword,omitempty"`
+	ResponseKeywordMode      *string  `json:"response_keyword_mode,omitempty"`
+	ForbiddenBodyKeyword     *string  `json:"forbidden_body_keyword,omitempty"`
+	MinResponseBytes         FlexibleInt `json:"min_response_bytes,omitempty"`
+	MaxResponseBytes         FlexibleInt `json:"max_response_bytes,omitempty"`
+	ExpectedHTTPVersion      *string  `json:"expected_http_version,omitempty"`
+	ProxyURL                 *string  `json:"proxy_url,omitempty"`
+	TriggerSecret            *string  `json:"trigger_secret,omitempty"`
+	BackoffOnFailure         *bool    `json:"backoff_on_failure,omitempty"`
+	ExpectedRowCount         *int     `json:"expected_row_count,omitempty"` // -1 removes it
+	QueryColumn              *string  `json:"query_column,omitempty"`
+	ExpectedHeaderName       *string  `json:"expected_header_name,omitempty"`
+	ExpectedHeaderValue      *string  `json:"expected_header_value,omitempty"`
+	ExpectedHeaderMode       *string  `json:"expected_header_mode,omitempty"`
+	NTPMaxOffsetMs           FlexibleInt `json:"ntp_max_offset_ms,omitempty"`
+	CertExpiryThresholdDays  FlexibleInt `json:"cert_expiry_threshold_days,omitempty"`
+	InsecureSkipVerify       *bool    `json:"insecure_skip_verify,omitempty"`
+	CronExpression           *string  `json:"cron_expression,omitempty"`
+	ExpectUnreachable        *bool    `json:"expect_unreachable,omitempty"`
+	PingCount                FlexibleInt `json:"ping_count,omitempty"`
+	MaxPacketLoss            FlexibleInt `json:"max_packet_loss,omitempty"`
+	BypassCache              *bool    `json:"bypass_cache,omitempty"`
+	SourceIP                 *string  `json:"source_ip,omitempty"`
+	ConfirmationThreshold    FlexibleInt `json:"confirmation_threshold,omitempty"`
+	RenotifyIntervalMinutes  FlexibleInt `json:"renotify_interval_minutes,omitempty"`
+	MaxResponseTimeMs        FlexibleInt `json:"max_response_time_ms,omitempty"`
+	RetentionDays            FlexibleInt `json:"retention_days,omitempty"`
 }
 
 type CreateGroupRequest struct {
@@ -278,6 +759,109 @@ type UpdateTagRequest struct {
 	Color *string `json:"color,omitempty"`
 }
 
+// NotifierConfig is a notification channel. Config holds the options of its type,
+// such as webhook_url for discord; there can be any number of channels of a type.
+type NotifierConfig struct {
+	ID        int64             `json:"id"`
+	Type      string            `json:"type"`
+	Name      string            `json:"name"`
+	Config    map[string]string `json:"config"`
+	Enabled   bool              `json:"enabled"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+type CreateNotifierRequest struct {
+	Type    string            `json:"type"`
+	Name    string            `json:"name"`
+	Config  map[string]string `json:"config"`
+	Enabled *bool             `json:"enabled,omitempty"`
+}
+
+// UpdateNotifierRequest changes a notifier; a config replaces the whole config
+type UpdateNotifierRequest struct {
+	Name    *string           `json:"name,omitempty"`
+	Config  map[string]string `json:"config,omitempty"`
+	Enabled *bool             `json:"enabled,omitempty"`
+}
+
+// Audit log actions and the kinds of configuration they apply to
+const (
+	AuditCreate = "create"
+	AuditUpdate = "update"
+	AuditDelete = "delete"
+
+	AuditTargetCheck    = "check"
+	AuditTargetGroup    = "group"
+	AuditTargetTag      = "tag"
+	AuditTargetSetting  = "setting"
+	AuditTargetNotifier = "notifier"
+)
+
+// AuditEntry records a configuration change made through the API. UserID is nil for
+// changes made before any user exists. Details describe the change, e.g. the name of
+// a check or the settings that changed, with secret values redacted.
+type AuditEntry struct {
+	ID         int64             `json:"id"`
+	UserID     *int64            `json:"user_id"`
+	Username   string            `json:"username,omitempty"`
+	Action     string            `json:"action"`
+	TargetType string            `json:"target_type"`
+	TargetID   *int64            `json:"target_id,omitempty"`
+	Details    map[string]string `json:"details,omitempty"`
+	CreatedAt  time.Time         `json:"created_at"`
+}
+
+// AuditFilter selects a page of the audit log, newest first
+type AuditFilter struct {
+	Since  *time.Time
+	Until  *time.Time
+	Limit  int
+	Offset int
+}
+
+type AuditLogResponse struct {
+	Entries []AuditEntry `json:"entries"`
+	Total   int          `json:"total"`
+	Limit   int          `json:"limit"`
+	Offset  int          `json:"offset"`
+}
+
+// TagChecksRequest adds or removes a tag on many checks at once
+type TagChecksRequest struct {
+	Assign   []int64 `json:"assign,omitempty"`
+	Unassign []int64 `json:"unassign,omitempty"`
+}
+
+type TagChecksResponse struct {
+	Tag        Tag   `json:"tag"`
+	Assigned   int64 `json:"assigned"`
+	Unassigned int64 `json:"unassigned"`
+}
+
+// Bulk check actions
+const (
+	BulkActionEnable  = "enable"
+	BulkActionDisable = "disable"
+	BulkActionDelete  = "delete"
+)
+
+// BulkCheckRequest applies one action to many checks at once
+type BulkCheckRequest struct {
+	IDs    []int64 `json:"ids"`
+	Action string  `json:"action"`
+}
+
+type BulkCheckFailure struct {
+	ID    int64  `json:"id"`
+	Error string `json:"error"`
+}
+
+type BulkCheckResponse struct {
+	Action    string             `json:"action"`
+	Succeeded []int64            `json:"succeeded"`
+	Failed    []BulkCheckFailure `json:"failed"`
+}
+
 type Settings struct {
 	DiscordWebhookURL string `json:"discord_webhook_url"`
 	GotifyServerURL   string `json:"gotify_server_url"`
@@ -286,6 +870,68 @@ type Settings struct {
 	TailscaleTailnet  string `json:"tailscale_tailnet"`
 	BrowserlessURL    string `json:"browserless_url"`
 	BrowserlessToken  string `json:"browserless_token"`
+	SlackWebhookURL   string `json:"slack_webhook_url"`
+
+	// SMTP email notifications; SMTPTo is a comma separated list of recipients
+	SMTPHost     string `json:"smtp_host"`
+	SMTPPort     int    `json:"smtp_port,omitempty"`
+	SMTPUsername string `json:"smtp_username"`
+	SMTPPassword string `json:"smtp_password"`
+	SMTPFrom     string `json:"smtp_from"`
+	SMTPTo       string `json:"smtp_to"`
+
+	// Generic webhook; the template is JSON with {{check_name}}-style placeholders
+	GenericWebhookURL      string `json:"generic_webhook_url"`
+	GenericWebhookMethod   string `json:"generic_webhook_method"`
+	GenericWebhookTemplate string `json:"generic_webhook_template"`
+
+	// Opsgenie alerts; region is "us" (default) or "eu", priority P1-P5
+	OpsgenieAPIKey   string `json:"opsgenie_api_key"`
+	OpsgenieRegion   string `json:"opsgenie_region"`
+	OpsgeniePriority string `json:"opsgenie_priority"`
+
+	// PagerDuty Events API v2; severity of DOWN events is critical (default), error,
+	// warning or info
+	PagerDutyRoutingKey string `json:"pagerduty_routing_key"`
+	PagerDutySeverity   string `json:"pagerduty_severity"`
+
+	// Pushover messages; priority of DOWN notifications is normal, high (default) or
+	// emergency, and sound is optional
+	PushoverUserKey  string `json:"pushover_user_key"`
+	PushoverAPIToken string `json:"pushover_api_token"`
+	PushoverPriority string `json:"pushover_priority"`
+	PushoverSound    string `json:"pushover_sound"`
+
+	// Apprise API notify endpoint, and the service URLs to notify unless the endpoint
+	// has a stored configuration
+	AppriseURL  string `json:"apprise_url"`
+	AppriseURLs string `json:"apprise_urls"`
+
+	// HistoryRetentionDays deletes history older than this (0 keeps it forever);
+	// nil leaves the stored value unchanged on update
+	HistoryRetentionDays *int `json:"history_retention_days,omitempty"`
+
+	// Snapshot refresh interval, viewport and full-page capture; nil leaves the stored
+	// value unchanged on update
+	SnapshotIntervalHours *int  `json:"snapshot_interval_hours,omitempty"`
+	SnapshotWidth         *int  `json:"snapshot_width,omitempty"`
+	SnapshotHeight        *int  `json:"snapshot_height,omitempty"`
+	SnapshotFullPage      *bool `json:"snapshot_full_page,omitempty"`
+
+	// Notifier toggles; nil leaves the stored value unchanged on update
+	DiscordEnabled   *bool `json:"discord_enabled,omitempty"`
+	GotifyEnabled    *bool `json:"gotify_enabled,omitempty"`
+	SlackEnabled     *bool `json:"slack_enabled,omitempty"`
+	EmailEnabled     *bool `json:"email_enabled,omitempty"`
+	WebhookEnabled   *bool `json:"generic_webhook_enabled,omitempty"`
+	OpsgenieEnabled  *bool `json:"opsgenie_enabled,omitempty"`
+	PagerDutyEnabled *bool `json:"pagerduty_enabled,omitempty"`
+	PushoverEnabled  *bool `json:"pushover_enabled,omitempty"`
+	AppriseEnabled   *bool `json:"apprise_enabled,omitempty"`
+
+	// NotifyMisconfigured sends a one-off notification when a check is found to be
+	// misconfigured; nil leaves the stored value unchanged on update
+	NotifyMisconfigured *bool `json:"notify_misconfigured,omitempty"`
 }
 
 type CheckSnapshot struct {
@@ -295,9 +941,16 @@ type CheckSnapshot struct {
 	LastError  string     `json:"last_error,omitempty"`
 }
 
+// User roles. Admins can change anything; viewers can only read checks and their stats.
+const (
+	RoleAdmin  = "admin"
+	RoleViewer = "viewer"
+)
+
 type User struct {
 	ID           int64     `json:"id"`
 	Username     string    `json:"username"`
+	Role         string    `json:"role"`
 	PasswordHash string    `json:"-"`
 	CreatedAt    time.Time `json:"created_at"`
 }
@@ -310,6 +963,12 @@ type LoginRequest struct {
 type CreateUserRequest struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
+	Role     string `json:"role,omitempty"` // ignored by initial setup, which always creates an admin
+}
+
+type UpdateUserRequest struct {
+	Role     *string `json:"role,omitempty"`
+	Password *string `json:"password,omitempty"`
 }
 
 type APIKey struct {
@@ -331,6 +990,7 @@ type Session struct {
 	Token     string    `json:"token"`
 	UserID    int64     `json:"user_id"`
 	Username  string    `json:"username"`
+	Role      string    `json:"role"`
 	ExpiresAt time.Time `json:"expires_at"`
 	CreatedAt time.Time `json:"created_at"`
 }
@@ -355,6 +1015,54 @@ type Probe struct {
 	Version    string    `json:"version,omitempty"`
 	Status     string    `json:"status"`
 	LastSeenAt *time.Time `json:"last_seen_at,omitempty"`
+	// LastHeartbeatAt is when a connected probe last sent anything; Stale is set once
+	// it has missed a heartbeat
+	LastHeartbeatAt *time.Time `json:"last_heartbeat_at,omitempty"`
+	Stale           bool       `json:"stale"`
+	ProbeLocation
+}
+
+// ProbeLocation is where a probe is, looked up from its IP address in the GeoIP
+// database when the probe is created
+type ProbeLocation struct {
+	CountryCode string   `json:"country_code,omitempty"`
+	Country     string   `json:"country,omitempty"`
+	City        string   `json:"city,omitempty"`
+	Latitude    *float64 `json:"latitude,omitempty"`
+	Longitude   *float64 `json:"longitude,omitempty"`
+}
+
+// StatusEvent is a transition of a check between up and down, derived from history
+type StatusEvent struct {
+	CheckID         int64      `json:"check_id"`
+	CheckName       string     `json:"check_name"`
+	Region          string     `json:"region"`
+	IsUp            bool       `json:"is_up"`
+	StatusCode      int        `json:"status_code,omitempty"`
+	ErrorMessage    string     `json:"error_message,omitempty"`
+	StartedAt       time.Time  `json:"started_at"`
+	EndedAt         *time.Time `json:"ended_at,omitempty"`
+	DurationSeconds *int64     `json:"duration_seconds,omitempty"`
+}
+
+// Incident is a period a check was confirmed down, opened and resolved by the engine as
+// the check changes state. DurationSeconds runs up to now while it is unresolved.
+type Incident struct {
+	ID              int64      `json:"id"`
+	CheckID         int64      `json:"check_id"`
+	CheckName       string     `json:"check_name"`
+	Cause           string     `json:"cause,omitempty"`
+	StartedAt       time.Time  `json:"started_at"`
+	ResolvedAt      *time.Time `json:"resolved_at,omitempty"`
+	DurationSeconds int64      `json:"duration_seconds"`
+}
+
+type EventFilter struct {
+	CheckID    *int64
+	GroupID    *int64
+	TagID      *int64
+	PublicOnly bool
+	Limit      int
 }
 
 type CheckHistory struct {
@@ -369,3 +1077,21 @@ type CheckHistory struct {
 	ProbeID        *int64    `json:"probe_id,omitempty"`
 	Region         string    `json:"region,omitempty"`
 }
+
+// Region statuses reported by an on-demand check of all probe regions
+const (
+	RegionStatusUp         = "up"
+	RegionStatusDown       = "down"
+	RegionStatusNoResponse = "no_response"
+	RegionStatusSendFailed = "send_failed"
+)
+
+// RegionCheckResult is one probe region's answer to an on-demand check
+type RegionCheckResult struct {
+	Region         string `json:"region"`
+	Status         string `json:"status"`
+	Success        bool   `json:"success"`
+	StatusCode     int    `json:"status_code,omitempty"`
+	ResponseTimeMs int    `json:"response_time_ms,omitempty"`
+	ErrorMessage   string `json:"error_message,omitempty"`
+}
@@ -0,0 +1,121 @@
+// Package mongocheck pings a MongoDB server and runs the optional single-value query of
+// a MongoDB check, for checks run by the server and by probes.
+package mongocheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/connstring"
+)
+
+// Query operations
+const (
+	OpCount   = "count"
+	OpFindOne = "findOne"
+)
+
+// Query is a parsed check query such as users.count({"active": true})
+type Query struct {
+	Collection string
+	Op         string
+	Filter     bson.D
+}
+
+var queryPattern = regexp.MustCompile(`^\s*(\S+)\.(count|findOne)\(\s*(.*?)\s*\)\s*$`)
+
+// ParseQuery parses "<collection>.count(<filter>)" or "<collection>.findOne(<filter>)".
+// The filter is extended JSON and may be left out to match every document.
+func ParseQuery(query string) (Query, error) {
+	m := queryPattern.FindStringSubmatch(query)
+	if m == nil {
+		return Query{}, fmt.Errorf("query must look like <collection>.count({...}) or <collection>.findOne({...})")
+	}
+
+	q := Query{Collection: m[1], Op: m[2], Filter: bson.D{}}
+	if m[3] != "" {
+		if err := bson.UnmarshalExtJSON([]byte(m[3]), false, &q.Filter); err != nil {
+			return Query{}, fmt.Errorf("invalid filter: %v", err)
+		}
+	}
+	return q, nil
+}
+
+// ValidateConnString checks a mongodb:// or mongodb+srv:// connection string. A query
+// needs the database to be named in it.
+func ValidateConnString(uri string, withQuery bool) error {
+	if uri == "" {
+		return errors.New("no connection string specified")
+	}
+	cs, err := connstring.ParseAndValidate(uri)
+	if err != nil {
+		return err
+	}
+	if withQuery && cs.Database == "" {
+		return errors.New("connection string must name the database to query")
+	}
+	return nil
+}
+
+// Check connects to uri and runs ping against the admin database. With a query it then
+// runs it against the database named in uri and returns the result: the number of
+// matching documents for count, or the first match as relaxed extended JSON for findOne.
+// ctx bounds the whole check.
+func Check(ctx context.Context, uri, query string) (string, error) {
+	if err := ValidateConnString(uri, query != ""); err != nil {
+		return "", fmt.Errorf("connection error: %v", err)
+	}
+	var q Query
+	if query != "" {
+		var err error
+		if q, err = ParseQuery(query); err != nil {
+			return "", fmt.Errorf("query failed: %v", err)
+		}
+	}
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		return "", fmt.Errorf("connection error: %v", err)
+	}
+	defer client.Disconnect(context.Background())
+
+	if err := client.Database("admin").RunCommand(ctx, bson.D{{Key: "ping", Value: 1}}).Err(); err != nil {
+		return "", fmt.Errorf("ping failed: %v", err)
+	}
+	if query == "" {
+		return "", nil
+	}
+
+	cs, _ := connstring.Parse(uri)
+	result, err := run(ctx, client.Database(cs.Database).Collection(q.Collection), q)
+	if err != nil {
+		return "", fmt.Errorf("query failed: %v", err)
+	}
+	return result, nil
+}
+
+func run(ctx context.Context, coll *mongo.Collection, q Query) (string, error) {
+	if q.Op == OpCount {
+		n, err := coll.CountDocuments(ctx, q.Filter)
+		if err != nil {
+			return "", err
+		}
+		return strconv.FormatInt(n, 10), nil
+	}
+
+	raw, err := coll.FindOne(ctx, q.Filter).Raw()
+	if err != nil {
+		return "", err
+	}
+	doc, err := bson.MarshalExtJSON(raw, false, false)
+	if err != nil {
+		return "", err
+	}
+	return string(doc), nil
+}
@@ -0,0 +1,119 @@
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// AppriseNotifier sends status changes to an Apprise API server, which fans them out
+// to the services it is configured for. The endpoint is either the stateless
+// /notify, which takes the service URLs with each message, or /notify/<key> for a
+// configuration stored on the server.
+type AppriseNotifier struct {
+	url    string
+	urls   string
+	client *http.Client
+}
+
+type AppriseMessage struct {
+	URLs  string `json:"urls,omitempty"`
+	Title string `json:"title"`
+	Body  string `json:"body"`
+	Type  string `json:"type"`
+}
+
+// NewAppriseNotifier creates a notifier for the Apprise notify endpoint url. urls are
+// the Apprise service URLs, separated by commas or whitespace, and may be empty when
+// the endpoint has a stored configuration.
+func NewAppriseNotifier(url, urls string) *AppriseNotifier {
+	services := strings.FieldsFunc(urls, func(r rune) bool { return r == ',' || unicode.IsSpace(r) })
+	return &AppriseNotifier{
+		url:  url,
+		urls: strings.Join(services, ","),
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+func (a *AppriseNotifier) GetURL() string {
+	return a.url
+}
+
+func (a *AppriseNotifier) TestWebhook() error {
+	if a.url == "" {
+		return fmt.Errorf("no Apprise URL configured")
+	}
+
+	return a.send(AppriseMessage{
+		Title: "GoCheck Test Notification",
+		Body:  "If you see this message, your Apprise integration is configured correctly!",
+		Type:  "info",
+	})
+}
+
+func (a *AppriseNotifier) SendStatusChange(checkName, url string, isUp bool, statusCode int, responseTimeMs int, errorMsg string) error {
+	if a.url == "" {
+		return nil
+	}
+
+	status, messageType := "DOWN", "failure"
+	if isUp {
+		status, messageType = "UP", "info"
+	}
+
+	var body strings.Builder
+	body.WriteString(fmt.Sprintf("Status changed to %s\n", status))
+	if url != "" {
+		body.WriteString(fmt.Sprintf("URL: %s\n", url))
+	}
+	if statusCode > 0 {
+		body.WriteString(fmt.Sprintf("Status Code: %d\n", statusCode))
+	}
+	if responseTimeMs > 0 {
+		body.WriteString(fmt.Sprintf("Response Time: %d ms\n", responseTimeMs))
+	}
+	if errorMsg != "" {
+		body.WriteString(fmt.Sprintf("Error: %s\n", errorMsg))
+	}
+
+	return a.send(AppriseMessage{
+		Title: fmt.Sprintf("Uptime Check: %s is %s", checkName, status),
+		Body:  body.String(),
+		Type:  messageType,
+	})
+}
+
+func (a *AppriseNotifier) send(msg AppriseMessage) error {
+	msg.URLs = a.urls
+
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", a.url, bytes.NewBuffer(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send message to Apprise: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("apprise returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -89,13 +90,26 @@ func (d *DiscordNotifier) TestWebhook() error {
 }
 
 func (d *DiscordNotifier) SendStatusChange(checkName, url string, isUp bool, statusCode int, responseTimeMs int, errorMsg string) error {
+	return d.SendStatusChangeDetails(StatusChange{
+		CheckName:      checkName,
+		URL:            url,
+		IsUp:           isUp,
+		StatusCode:     statusCode,
+		ResponseTimeMs: responseTimeMs,
+		ErrorMsg:       errorMsg,
+	})
+}
+
+// SendStatusChangeDetails also shows the check type and, for DOWN, a snippet of
+// the response body
+func (d *DiscordNotifier) SendStatusChangeDetails(change StatusChange) error {
 	if d.webhookURL == "" {
 		return nil
 	}
 
 	var color int
 	var status string
-	if isUp {
+	if change.IsUp {
 		color = 3066993
 		status = "UP"
 	} else {
@@ -104,36 +118,53 @@ func (d *DiscordNotifier) SendStatusChange(checkName, url string, isUp bool, sta
 	}
 
 	embed := DiscordEmbed{
-		Title:       fmt.Sprintf("Uptime Check: %s", checkName),
+		Title:       fmt.Sprintf("Uptime Check: %s", change.CheckName),
 		Description: fmt.Sprintf("Status changed to **%s**", status),
 		Color:       color,
 		Timestamp:   time.Now().Format(time.RFC3339),
 		Fields: []EmbedField{
-			{Name: "URL", Value: url, Inline: false},
+			{Name: "URL", Value: change.URL, Inline: false},
 			{Name: "Status", Value: status, Inline: true},
 		},
 	}
 
-	if statusCode > 0 {
+	if change.CheckType != "" {
+		embed.Fields = append(embed.Fields, EmbedField{
+			Name:   "Type",
+			Value:  change.CheckType,
+			Inline: true,
+		})
+	}
+
+	if change.StatusCode > 0 {
 		embed.Fields = append(embed.Fields, EmbedField{
 			Name:   "Status Code",
-			Value:  fmt.Sprintf("%d", statusCode),
+			Value:  fmt.Sprintf("%d", change.StatusCode),
 			Inline: true,
 		})
 	}
 
-	if responseTimeMs > 0 {
+	if change.ResponseTimeMs > 0 {
 		embed.Fields = append(embed.Fields, EmbedField{
 			Name:   "Response Time",
-			Value:  fmt.Sprintf("%d ms", responseTimeMs),
+			Value:  fmt.Sprintf("%d ms", change.ResponseTimeMs),
 			Inline: true,
 		})
 	}
 
-	if errorMsg != "" {
+	if change.ErrorMsg != "" {
 		embed.Fields = append(embed.Fields, EmbedField{
 			Name:   "Error",
-			Value:  errorMsg,
+			Value:  change.ErrorMsg,
+			Inline: false,
+		})
+	}
+
+	if snippet := responseSnippet(change); snippet != "" {
+		// Keep the code block closed whatever the body contains
+		embed.Fields = append(embed.Fields, EmbedField{
+			Name:   "Response",
+			Value:  "```\n" + strings.ReplaceAll(snippet, "```", "'''") + "\n```",
 			Inline: false,
 		})
 	}
@@ -0,0 +1,52 @@
+package notifier
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDiscordIncludesResponseSnippet(t *testing.T) {
+	var webhooks []DiscordWebhook
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var webhook DiscordWebhook
+		if err := json.NewDecoder(r.Body).Decode(&webhook); err != nil {
+			t.Error(err)
+		}
+		webhooks = append(webhooks, webhook)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	d := NewDiscordNotifier(srv.URL)
+	body := `{"status": "degraded", "detail": "` + strings.Repeat("x", 1000) + `"}`
+	change := StatusChange{CheckID: 1, CheckName: "api", CheckType: "json_http", URL: "https://example.com", ErrorMsg: "status is degraded", ResponseBody: body}
+	if err := SendStatusChange(d, change); err != nil {
+		t.Fatal(err)
+	}
+	change.IsUp = true
+	if err := SendStatusChange(d, change); err != nil {
+		t.Fatal(err)
+	}
+
+	fields := func(webhook DiscordWebhook) map[string]string {
+		m := map[string]string{}
+		for _, f := range webhook.Embeds[0].Fields {
+			m[f.Name] = f.Value
+		}
+		return m
+	}
+	down, up := fields(webhooks[0]), fields(webhooks[1])
+	if down["Type"] != "json_http" {
+		t.Errorf("type = %q", down["Type"])
+	}
+	response := down["Response"]
+	if !strings.Contains(response, `"status": "degraded"`) || len(response) > responseSnippetMaxLen+20 {
+		t.Errorf("response = %q (%d bytes), want a truncated snippet", response, len(response))
+	}
+	if _, ok := up["Response"]; ok {
+		t.Error("UP notification includes the response body")
+	}
+}
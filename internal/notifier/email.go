@@ -0,0 +1,160 @@
+package notifier
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const DefaultSMTPPort = 587
+
+type EmailConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	To       []string
+}
+
+type EmailNotifier struct {
+	config  EmailConfig
+	timeout time.Duration
+}
+
+// ParseRecipients splits a comma separated address list, dropping empty entries
+func ParseRecipients(list string) []string {
+	var recipients []string
+	for _, addr := range strings.Split(list, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			recipients = append(recipients, addr)
+		}
+	}
+	return recipients
+}
+
+func NewEmailNotifier(config EmailConfig) *EmailNotifier {
+	if config.Port <= 0 {
+		config.Port = DefaultSMTPPort
+	}
+	return &EmailNotifier{
+		config:  config,
+		timeout: 10 * time.Second,
+	}
+}
+
+func (e *EmailNotifier) GetHost() string {
+	return e.config.Host
+}
+
+func (e *EmailNotifier) TestWebhook() error {
+	if e.config.Host == "" || e.config.From == "" || len(e.config.To) == 0 {
+		return fmt.Errorf("smtp host, from and to addresses are required")
+	}
+
+	return e.send("GoCheck Test Notification",
+		"If you see this message, your SMTP settings are configured correctly!")
+}
+
+func (e *EmailNotifier) SendStatusChange(checkName, url string, isUp bool, statusCode int, responseTimeMs int, errorMsg string) error {
+	if e.config.Host == "" || len(e.config.To) == 0 {
+		return nil
+	}
+
+	status := "DOWN"
+	if isUp {
+		status = "UP"
+	}
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "Check: %s\n", checkName)
+	fmt.Fprintf(&body, "URL: %s\n", url)
+	fmt.Fprintf(&body, "Status: %s\n", status)
+	if statusCode > 0 {
+		fmt.Fprintf(&body, "Status Code: %d\n", statusCode)
+	}
+	if responseTimeMs > 0 {
+		fmt.Fprintf(&body, "Response Time: %d ms\n", responseTimeMs)
+	}
+	if errorMsg != "" {
+		fmt.Fprintf(&body, "Error: %s\n", errorMsg)
+	}
+	fmt.Fprintf(&body, "Time: %s\n", time.Now().UTC().Format(time.RFC1123))
+
+	return e.send(fmt.Sprintf("[GoCheck] %s is %s", checkName, status), body.String())
+}
+
+// headerSanitizer keeps check names from injecting extra mail headers
+var headerSanitizer = strings.NewReplacer("\r", " ", "\n", " ")
+
+func (e *EmailNotifier) buildMessage(subject, body string) []byte {
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", e.config.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(e.config.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", headerSanitizer.Replace(subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	return msg.Bytes()
+}
+
+// send delivers a message, upgrading the connection with STARTTLS when the server
+// offers it. Every step wraps its error so test requests can show where it failed.
+func (e *EmailNotifier) send(subject, body string) error {
+	addr := net.JoinHostPort(e.config.Host, strconv.Itoa(e.config.Port))
+
+	conn, err := net.DialTimeout("tcp", addr, e.timeout)
+	if err != nil {
+		return fmt.Errorf("failed to connect to smtp server %s: %w", addr, err)
+	}
+	conn.SetDeadline(time.Now().Add(e.timeout))
+
+	client, err := smtp.NewClient(conn, e.config.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to start smtp session: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: e.config.Host}); err != nil {
+			return fmt.Errorf("starttls failed: %w", err)
+		}
+	}
+
+	if e.config.Username != "" {
+		auth := smtp.PlainAuth("", e.config.Username, e.config.Password, e.config.Host)
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("smtp authentication failed: %w", err)
+		}
+	}
+
+	if err := client.Mail(e.config.From); err != nil {
+		return fmt.Errorf("smtp server rejected sender %s: %w", e.config.From, err)
+	}
+	for _, to := range e.config.To {
+		if err := client.Rcpt(to); err != nil {
+			return fmt.Errorf("smtp server rejected recipient %s: %w", to, err)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("failed to start message data: %w", err)
+	}
+	if _, err := w.Write(e.buildMessage(subject, body)); err != nil {
+		w.Close()
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("smtp server rejected message: %w", err)
+	}
+
+	return client.Quit()
+}
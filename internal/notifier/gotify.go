@@ -51,13 +51,26 @@ func (g *GotifyNotifier) TestWebhook() error {
 }
 
 func (g *GotifyNotifier) SendStatusChange(checkName, url string, isUp bool, statusCode int, responseTimeMs int, errorMsg string) error {
+	return g.SendStatusChangeDetails(StatusChange{
+		CheckName:      checkName,
+		URL:            url,
+		IsUp:           isUp,
+		StatusCode:     statusCode,
+		ResponseTimeMs: responseTimeMs,
+		ErrorMsg:       errorMsg,
+	})
+}
+
+// SendStatusChangeDetails also shows the check type and, for DOWN, a snippet of
+// the response body
+func (g *GotifyNotifier) SendStatusChangeDetails(change StatusChange) error {
 	if g.serverURL == "" || g.token == "" {
 		return nil
 	}
 
 	var status string
 	var priority int
-	if isUp {
+	if change.IsUp {
 		status = "UP"
 		priority = 4
 	} else {
@@ -67,22 +80,30 @@ func (g *GotifyNotifier) SendStatusChange(checkName, url string, isUp bool, stat
 
 	var messageBuilder strings.Builder
 	messageBuilder.WriteString(fmt.Sprintf("Status changed to **%s**\n\n", status))
-	messageBuilder.WriteString(fmt.Sprintf("**URL:** %s\n", url))
+	messageBuilder.WriteString(fmt.Sprintf("**URL:** %s\n", change.URL))
+
+	if change.CheckType != "" {
+		messageBuilder.WriteString(fmt.Sprintf("**Type:** %s\n", change.CheckType))
+	}
+
+	if change.StatusCode > 0 {
+		messageBuilder.WriteString(fmt.Sprintf("**Status Code:** %d\n", change.StatusCode))
+	}
 
-	if statusCode > 0 {
-		messageBuilder.WriteString(fmt.Sprintf("**Status Code:** %d\n", statusCode))
+	if change.ResponseTimeMs > 0 {
+		messageBuilder.WriteString(fmt.Sprintf("**Response Time:** %d ms\n", change.ResponseTimeMs))
 	}
 
-	if responseTimeMs > 0 {
-		messageBuilder.WriteString(fmt.Sprintf("**Response Time:** %d ms\n", responseTimeMs))
+	if change.ErrorMsg != "" {
+		messageBuilder.WriteString(fmt.Sprintf("**Error:** %s\n", change.ErrorMsg))
 	}
 
-	if errorMsg != "" {
-		messageBuilder.WriteString(fmt.Sprintf("**Error:** %s\n", errorMsg))
+	if snippet := responseSnippet(change); snippet != "" {
+		messageBuilder.WriteString(fmt.Sprintf("**Response:**\n```\n%s\n```\n", strings.ReplaceAll(snippet, "```", "'''")))
 	}
 
 	message := GotifyMessage{
-		Title:    fmt.Sprintf("Uptime Check: %s", checkName),
+		Title:    fmt.Sprintf("Uptime Check: %s", change.CheckName),
 		Message:  messageBuilder.String(),
 		Priority: priority,
 	}
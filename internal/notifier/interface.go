@@ -1,7 +1,72 @@
 package notifier
 
+import (
+	"strings"
+	"unicode/utf8"
+)
+
 type Notifier interface {
 	TestWebhook() error
 	SendStatusChange(checkName, url string, isUp bool, statusCode int, responseTimeMs int, errorMsg string) error
 }
 
+// CheckNotifier is implemented by notifiers that key their alerts on the check ID,
+// so an alert survives the check being renamed
+type CheckNotifier interface {
+	Notifier
+	SendCheckStatusChange(checkID int64, checkName, url string, isUp bool, statusCode int, responseTimeMs int, errorMsg string) error
+}
+
+// StatusChange is a status change of a check along with the result behind it
+type StatusChange struct {
+	CheckID        int64
+	CheckName      string
+	CheckType      string
+	URL            string
+	IsUp           bool
+	StatusCode     int
+	ResponseTimeMs int
+	ErrorMsg       string
+	// ResponseBody is what the check stored of the response, such as the body of a
+	// JSON check or the result of a query
+	ResponseBody string
+}
+
+// DetailedNotifier is implemented by notifiers that show the check type and the
+// response body in their messages
+type DetailedNotifier interface {
+	Notifier
+	SendStatusChangeDetails(change StatusChange) error
+}
+
+// SendStatusChange notifies n of a status change, through SendStatusChangeDetails or
+// SendCheckStatusChange when n supports them
+func SendStatusChange(n Notifier, change StatusChange) error {
+	if dn, ok := n.(DetailedNotifier); ok {
+		return dn.SendStatusChangeDetails(change)
+	}
+	if cn, ok := n.(CheckNotifier); ok {
+		return cn.SendCheckStatusChange(change.CheckID, change.CheckName, change.URL, change.IsUp, change.StatusCode, change.ResponseTimeMs, change.ErrorMsg)
+	}
+	return n.SendStatusChange(change.CheckName, change.URL, change.IsUp, change.StatusCode, change.ResponseTimeMs, change.ErrorMsg)
+}
+
+// responseSnippetMaxLen is the most of a response body a notification includes
+const responseSnippetMaxLen = 500
+
+// responseSnippet trims a response body for a notification. Bodies are only shown for
+// DOWN notifications, where they help to triage the failure.
+func responseSnippet(change StatusChange) string {
+	if change.IsUp {
+		return ""
+	}
+	body := strings.TrimSpace(change.ResponseBody)
+	if len(body) <= responseSnippetMaxLen {
+		return body
+	}
+	body = body[:responseSnippetMaxLen]
+	for len(body) > 0 && !utf8.ValidString(body) {
+		body = body[:len(body)-1]
+	}
+	return body + "…"
+}
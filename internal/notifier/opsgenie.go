@@ -0,0 +1,182 @@
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	opsgenieUSBaseURL = "https://api.opsgenie.com"
+	opsgenieEUBaseURL = "https://api.eu.opsgenie.com"
+
+	// DefaultOpsgeniePriority is used when no priority is configured
+	DefaultOpsgeniePriority = "P3"
+
+	// opsgenieAliasMaxLen is the longest alias the Alert API accepts
+	opsgenieAliasMaxLen = 512
+)
+
+// OpsgenieNotifier opens an alert when a check goes down and closes it on recovery.
+// Alerts use a per-check alias, so repeated DOWN notifications for the same check are
+// deduplicated by Opsgenie and the recovery closes the right alert.
+type OpsgenieNotifier struct {
+	apiKey   string
+	baseURL  string
+	priority string
+	client   *http.Client
+}
+
+type OpsgenieAlert struct {
+	Message     string            `json:"message"`
+	Alias       string            `json:"alias"`
+	Description string            `json:"description,omitempty"`
+	Priority    string            `json:"priority"`
+	Source      string            `json:"source"`
+	Tags        []string          `json:"tags,omitempty"`
+	Details     map[string]string `json:"details,omitempty"`
+}
+
+type OpsgenieClose struct {
+	Source string `json:"source"`
+	Note   string `json:"note,omitempty"`
+}
+
+// ValidOpsgeniePriority reports whether p is one of P1-P5
+func ValidOpsgeniePriority(p string) bool {
+	switch p {
+	case "P1", "P2", "P3", "P4", "P5":
+		return true
+	}
+	return false
+}
+
+// NewOpsgenieNotifier creates a notifier for the "us" (default) or "eu" API region
+func NewOpsgenieNotifier(apiKey, region, priority string) *OpsgenieNotifier {
+	baseURL := opsgenieUSBaseURL
+	if strings.EqualFold(region, "eu") {
+		baseURL = opsgenieEUBaseURL
+	}
+	priority = strings.ToUpper(priority)
+	if !ValidOpsgeniePriority(priority) {
+		priority = DefaultOpsgeniePriority
+	}
+	return &OpsgenieNotifier{
+		apiKey:   apiKey,
+		baseURL:  baseURL,
+		priority: priority,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+func (o *OpsgenieNotifier) GetBaseURL() string {
+	return o.baseURL
+}
+
+// opsgenieAlias derives the deduplication alias of a check
+func opsgenieAlias(checkName string) string {
+	alias := "gocheck-" + checkName
+	if len(alias) > opsgenieAliasMaxLen {
+		alias = alias[:opsgenieAliasMaxLen]
+	}
+	return alias
+}
+
+// TestWebhook opens a low priority test alert and closes it again
+func (o *OpsgenieNotifier) TestWebhook() error {
+	if o.apiKey == "" {
+		return fmt.Errorf("no Opsgenie API key configured")
+	}
+
+	alias := opsgenieAlias("test-notification")
+	if err := o.createAlert(OpsgenieAlert{
+		Message:     "GoCheck Test Notification",
+		Alias:       alias,
+		Description: "If you see this alert, your Opsgenie integration is configured correctly! It is closed automatically.",
+		Priority:    "P5",
+		Source:      "GoCheck",
+	}); err != nil {
+		return err
+	}
+
+	return o.closeAlert(alias, "Test alert closed automatically")
+}
+
+func (o *OpsgenieNotifier) SendStatusChange(checkName, url string, isUp bool, statusCode int, responseTimeMs int, errorMsg string) error {
+	if o.apiKey == "" {
+		return nil
+	}
+
+	alias := opsgenieAlias(checkName)
+	if isUp {
+		return o.closeAlert(alias, fmt.Sprintf("%s recovered", checkName))
+	}
+
+	details := map[string]string{"url": url}
+	if statusCode > 0 {
+		details["status_code"] = fmt.Sprintf("%d", statusCode)
+	}
+	if responseTimeMs > 0 {
+		details["response_time_ms"] = fmt.Sprintf("%d", responseTimeMs)
+	}
+
+	description := fmt.Sprintf("%s is DOWN (%s)", checkName, url)
+	if errorMsg != "" {
+		description += "\n\nError: " + errorMsg
+		details["error"] = errorMsg
+	}
+
+	return o.createAlert(OpsgenieAlert{
+		Message:     fmt.Sprintf("%s is DOWN", checkName),
+		Alias:       alias,
+		Description: description,
+		Priority:    o.priority,
+		Source:      "GoCheck",
+		Tags:        []string{"gocheck"},
+		Details:     details,
+	})
+}
+
+func (o *OpsgenieNotifier) createAlert(alert OpsgenieAlert) error {
+	return o.post("/v2/alerts", alert)
+}
+
+func (o *OpsgenieNotifier) closeAlert(alias, note string) error {
+	path := "/v2/alerts/" + url.PathEscape(alias) + "/close?identifierType=alias"
+	return o.post(path, OpsgenieClose{Source: "GoCheck", Note: note})
+}
+
+func (o *OpsgenieNotifier) post(path string, body interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", o.baseURL+path, bytes.NewBuffer(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "GenieKey "+o.apiKey)
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request to Opsgenie: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("Opsgenie returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	return nil
+}
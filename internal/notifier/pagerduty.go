@@ -0,0 +1,185 @@
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	pagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+	// DefaultPagerDutySeverity is used when no severity is configured
+	DefaultPagerDutySeverity = "critical"
+
+	// pagerDutySummaryMaxLen is the longest summary the Events API accepts
+	pagerDutySummaryMaxLen = 1024
+)
+
+// PagerDutyNotifier triggers an incident through the Events API v2 when a check goes
+// down and resolves it on recovery. Events carry a dedup key derived from the check
+// ID, so repeated DOWN events for a check land on one incident and the recovery
+// resolves that incident.
+type PagerDutyNotifier struct {
+	routingKey string
+	severity   string
+	eventsURL  string
+	client     *http.Client
+}
+
+type PagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *PagerDutyPayload `json:"payload,omitempty"`
+	Client      string            `json:"client,omitempty"`
+}
+
+type PagerDutyPayload struct {
+	Summary       string            `json:"summary"`
+	Source        string            `json:"source"`
+	Severity      string            `json:"severity"`
+	Component     string            `json:"component,omitempty"`
+	CustomDetails map[string]string `json:"custom_details,omitempty"`
+}
+
+// ValidPagerDutySeverity reports whether s is one of the Events API severities
+func ValidPagerDutySeverity(s string) bool {
+	switch s {
+	case "critical", "error", "warning", "info":
+		return true
+	}
+	return false
+}
+
+// NewPagerDutyNotifier creates a notifier for an Events API v2 integration. severity
+// is the severity of DOWN events.
+func NewPagerDutyNotifier(routingKey, severity string) *PagerDutyNotifier {
+	severity = strings.ToLower(severity)
+	if !ValidPagerDutySeverity(severity) {
+		severity = DefaultPagerDutySeverity
+	}
+	return &PagerDutyNotifier{
+		routingKey: routingKey,
+		severity:   severity,
+		eventsURL:  pagerDutyEventsURL,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+func (p *PagerDutyNotifier) GetBaseURL() string {
+	return p.eventsURL
+}
+
+// pagerDutyDedupKey derives the deduplication key of a check
+func pagerDutyDedupKey(checkID int64) string {
+	return fmt.Sprintf("gocheck-check-%d", checkID)
+}
+
+// TestWebhook triggers an info test incident and resolves it again
+func (p *PagerDutyNotifier) TestWebhook() error {
+	if p.routingKey == "" {
+		return fmt.Errorf("no PagerDuty routing key configured")
+	}
+
+	dedupKey := "gocheck-test-notification"
+	if err := p.send(PagerDutyEvent{
+		EventAction: "trigger",
+		DedupKey:    dedupKey,
+		Payload: &PagerDutyPayload{
+			Summary:  "GoCheck Test Notification",
+			Source:   "GoCheck",
+			Severity: "info",
+		},
+	}); err != nil {
+		return err
+	}
+
+	return p.send(PagerDutyEvent{EventAction: "resolve", DedupKey: dedupKey})
+}
+
+// SendStatusChange can't derive a dedup key without the check ID; status changes go
+// through SendCheckStatusChange instead
+func (p *PagerDutyNotifier) SendStatusChange(checkName, url string, isUp bool, statusCode int, responseTimeMs int, errorMsg string) error {
+	return fmt.Errorf("PagerDuty notifications need the check ID")
+}
+
+func (p *PagerDutyNotifier) SendCheckStatusChange(checkID int64, checkName, url string, isUp bool, statusCode int, responseTimeMs int, errorMsg string) error {
+	if p.routingKey == "" {
+		return nil
+	}
+
+	dedupKey := pagerDutyDedupKey(checkID)
+	if isUp {
+		return p.send(PagerDutyEvent{EventAction: "resolve", DedupKey: dedupKey})
+	}
+
+	details := map[string]string{"check_id": fmt.Sprintf("%d", checkID), "target": url}
+	if statusCode > 0 {
+		details["status_code"] = fmt.Sprintf("%d", statusCode)
+	}
+	if responseTimeMs > 0 {
+		details["response_time_ms"] = fmt.Sprintf("%d", responseTimeMs)
+	}
+
+	summary := fmt.Sprintf("%s is DOWN (%s)", checkName, url)
+	if errorMsg != "" {
+		summary += ": " + errorMsg
+		details["error"] = errorMsg
+	}
+	if len(summary) > pagerDutySummaryMaxLen {
+		summary = summary[:pagerDutySummaryMaxLen]
+	}
+
+	source := url
+	if source == "" {
+		source = "GoCheck"
+	}
+
+	return p.send(PagerDutyEvent{
+		EventAction: "trigger",
+		DedupKey:    dedupKey,
+		Payload: &PagerDutyPayload{
+			Summary:       summary,
+			Source:        source,
+			Severity:      p.severity,
+			Component:     checkName,
+			CustomDetails: details,
+		},
+	})
+}
+
+func (p *PagerDutyNotifier) send(event PagerDutyEvent) error {
+	event.RoutingKey = p.routingKey
+	event.Client = "GoCheck"
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", p.eventsURL, bytes.NewBuffer(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send event to PagerDuty: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("PagerDuty returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	return nil
+}
@@ -0,0 +1,45 @@
+package notifier
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPagerDutyResolvesTriggeredIncident(t *testing.T) {
+	var events []PagerDutyEvent
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event PagerDutyEvent
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			t.Error(err)
+		}
+		events = append(events, event)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	p := NewPagerDutyNotifier("key", "warning")
+	p.eventsURL = srv.URL
+
+	if err := SendStatusChange(p, StatusChange{CheckID: 42, CheckName: "api", URL: "https://example.com", StatusCode: 503, ResponseTimeMs: 120, ErrorMsg: "bad status"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := SendStatusChange(p, StatusChange{CheckID: 42, CheckName: "api", URL: "https://example.com", IsUp: true, StatusCode: 200, ResponseTimeMs: 80}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("got %d events", len(events))
+	}
+	trigger, resolve := events[0], events[1]
+	if trigger.EventAction != "trigger" || resolve.EventAction != "resolve" {
+		t.Errorf("actions = %s, %s", trigger.EventAction, resolve.EventAction)
+	}
+	if trigger.DedupKey != "gocheck-check-42" || resolve.DedupKey != trigger.DedupKey {
+		t.Errorf("dedup keys = %s, %s", trigger.DedupKey, resolve.DedupKey)
+	}
+	if trigger.RoutingKey != "key" || trigger.Payload.Severity != "warning" || trigger.Payload.Source != "https://example.com" {
+		t.Errorf("trigger = %+v, payload = %+v", trigger, trigger.Payload)
+	}
+}
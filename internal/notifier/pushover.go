@@ -0,0 +1,198 @@
+package notifier
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	pushoverAPIURL = "https://api.pushover.net/1"
+
+	// Priorities of DOWN notifications; UP notifications are always sent at normal
+	// priority
+	PushoverPriorityNormal    = "normal"
+	PushoverPriorityHigh      = "high"
+	PushoverPriorityEmergency = "emergency"
+
+	// DefaultPushoverPriority is used when no priority is configured
+	DefaultPushoverPriority = PushoverPriorityHigh
+
+	// An emergency notification is repeated every pushoverRetry until it is
+	// acknowledged, for at most pushoverExpire
+	pushoverRetry  = time.Minute
+	pushoverExpire = time.Hour
+
+	// pushoverMessageMaxLen is the longest message the API accepts
+	pushoverMessageMaxLen = 1024
+)
+
+var pushoverPriorities = map[string]int{
+	PushoverPriorityNormal:    0,
+	PushoverPriorityHigh:      1,
+	PushoverPriorityEmergency: 2,
+}
+
+// PushoverNotifier sends status changes through the Pushover messages API. DOWN
+// notifications use the configured priority; emergency ones repeat until they are
+// acknowledged and are cancelled when the check recovers.
+type PushoverNotifier struct {
+	userKey  string
+	apiToken string
+	priority string
+	sound    string
+	apiURL   string
+	client   *http.Client
+}
+
+// ValidPushoverPriority reports whether p is one of the DOWN priorities
+func ValidPushoverPriority(p string) bool {
+	_, ok := pushoverPriorities[p]
+	return ok
+}
+
+// NewPushoverNotifier creates a notifier for a Pushover user or group key. priority is
+// the priority of DOWN notifications and sound, if set, the sound they play.
+func NewPushoverNotifier(userKey, apiToken, priority, sound string) *PushoverNotifier {
+	priority = strings.ToLower(priority)
+	if !ValidPushoverPriority(priority) {
+		priority = DefaultPushoverPriority
+	}
+	return &PushoverNotifier{
+		userKey:  userKey,
+		apiToken: apiToken,
+		priority: priority,
+		sound:    sound,
+		apiURL:   pushoverAPIURL,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+func (p *PushoverNotifier) GetBaseURL() string {
+	return p.apiURL
+}
+
+// pushoverTag tags the emergency notifications of a check, so they can be cancelled
+// on recovery
+func pushoverTag(checkID int64) string {
+	return fmt.Sprintf("gocheck-check-%d", checkID)
+}
+
+func (p *PushoverNotifier) TestWebhook() error {
+	if p.userKey == "" || p.apiToken == "" {
+		return fmt.Errorf("pushover user key and API token are required")
+	}
+
+	return p.send(url.Values{
+		"title":   {"GoCheck Test Notification"},
+		"message": {"If you see this message, your Pushover integration is configured correctly!"},
+	})
+}
+
+func (p *PushoverNotifier) SendStatusChange(checkName, url string, isUp bool, statusCode int, responseTimeMs int, errorMsg string) error {
+	return p.SendCheckStatusChange(0, checkName, url, isUp, statusCode, responseTimeMs, errorMsg)
+}
+
+// SendCheckStatusChange tags emergency notifications with the check ID and cancels
+// their retries once the check is back up
+func (p *PushoverNotifier) SendCheckStatusChange(checkID int64, checkName, target string, isUp bool, statusCode int, responseTimeMs int, errorMsg string) error {
+	if p.userKey == "" || p.apiToken == "" {
+		return nil
+	}
+
+	status := "DOWN"
+	if isUp {
+		status = "UP"
+	}
+
+	var message strings.Builder
+	message.WriteString(fmt.Sprintf("Status changed to <b>%s</b>\n", status))
+	if target != "" {
+		message.WriteString(fmt.Sprintf("<b>Target:</b> %s\n", html.EscapeString(target)))
+	}
+	if statusCode > 0 {
+		message.WriteString(fmt.Sprintf("<b>Status Code:</b> %d\n", statusCode))
+	}
+	if responseTimeMs > 0 {
+		message.WriteString(fmt.Sprintf("<b>Response Time:</b> %d ms\n", responseTimeMs))
+	}
+	if errorMsg != "" {
+		message.WriteString(fmt.Sprintf("<b>Error:</b> %s\n", html.EscapeString(errorMsg)))
+	}
+	text := message.String()
+	if len(text) > pushoverMessageMaxLen {
+		text = text[:pushoverMessageMaxLen]
+	}
+
+	form := url.Values{
+		"title":   {fmt.Sprintf("Uptime Check: %s", checkName)},
+		"message": {text},
+		"html":    {"1"},
+	}
+	if strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://") {
+		form.Set("url", target)
+	}
+
+	if isUp {
+		if p.priority == PushoverPriorityEmergency && checkID > 0 {
+			if err := p.cancelEmergency(pushoverTag(checkID)); err != nil {
+				return err
+			}
+		}
+		return p.send(form)
+	}
+
+	priority := pushoverPriorities[p.priority]
+	form.Set("priority", strconv.Itoa(priority))
+	if priority == pushoverPriorities[PushoverPriorityEmergency] {
+		form.Set("retry", strconv.Itoa(int(pushoverRetry.Seconds())))
+		form.Set("expire", strconv.Itoa(int(pushoverExpire.Seconds())))
+		if checkID > 0 {
+			form.Set("tags", pushoverTag(checkID))
+		}
+	}
+	if p.sound != "" {
+		form.Set("sound", p.sound)
+	}
+	return p.send(form)
+}
+
+func (p *PushoverNotifier) send(form url.Values) error {
+	form.Set("token", p.apiToken)
+	form.Set("user", p.userKey)
+	return p.post(p.apiURL+"/messages.json", form)
+}
+
+// cancelEmergency stops the retries of the emergency notifications tagged with tag
+func (p *PushoverNotifier) cancelEmergency(tag string) error {
+	return p.post(p.apiURL+"/receipts/cancel_by_tag/"+url.PathEscape(tag)+".json", url.Values{"token": {p.apiToken}})
+}
+
+func (p *PushoverNotifier) post(endpoint string, form url.Values) error {
+	resp, err := p.client.PostForm(endpoint, form)
+	if err != nil {
+		return fmt.Errorf("failed to send message to Pushover: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		var result struct {
+			Errors []string `json:"errors"`
+		}
+		if json.Unmarshal(respBody, &result) == nil && len(result.Errors) > 0 {
+			return fmt.Errorf("pushover returned status %d: %s", resp.StatusCode, strings.Join(result.Errors, "; "))
+		}
+		return fmt.Errorf("pushover returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package notifier
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestPushoverEmergencyIsCancelledOnRecovery(t *testing.T) {
+	var paths []string
+	var forms []url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Error(err)
+		}
+		paths = append(paths, r.URL.Path)
+		forms = append(forms, r.PostForm)
+		w.Write([]byte(`{"status":1}`))
+	}))
+	defer srv.Close()
+
+	p := NewPushoverNotifier("user", "token", "Emergency", "siren")
+	p.apiURL = srv.URL
+
+	if err := SendStatusChange(p, StatusChange{CheckID: 42, CheckName: "api", URL: "https://example.com", StatusCode: 503, ResponseTimeMs: 120, ErrorMsg: "bad status"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := SendStatusChange(p, StatusChange{CheckID: 42, CheckName: "api", URL: "https://example.com", IsUp: true, StatusCode: 200, ResponseTimeMs: 80}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"/messages.json", "/receipts/cancel_by_tag/gocheck-check-42.json", "/messages.json"}
+	if strings.Join(paths, " ") != strings.Join(want, " ") {
+		t.Fatalf("paths = %v, want %v", paths, want)
+	}
+	down, up := forms[0], forms[2]
+	if down.Get("priority") != "2" || down.Get("retry") == "" || down.Get("expire") == "" || down.Get("tags") != "gocheck-check-42" {
+		t.Errorf("down = %v", down)
+	}
+	if down.Get("user") != "user" || down.Get("token") != "token" || down.Get("sound") != "siren" || !strings.Contains(down.Get("message"), "bad status") {
+		t.Errorf("down = %v", down)
+	}
+	if up.Get("priority") != "" || up.Get("sound") != "" {
+		t.Errorf("up = %v, want normal priority and the default sound", up)
+	}
+}
+
+func TestPushoverReportsAPIErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"status":0,"errors":["user identifier is invalid"]}`))
+	}))
+	defer srv.Close()
+
+	p := NewPushoverNotifier("user", "token", "", "")
+	p.apiURL = srv.URL
+
+	if err := p.TestWebhook(); err == nil || !strings.Contains(err.Error(), "user identifier is invalid") {
+		t.Errorf("error = %v", err)
+	}
+}
@@ -0,0 +1,139 @@
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+type SlackNotifier struct {
+	webhookURL string
+	client     *http.Client
+}
+
+type SlackField struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short,omitempty"`
+}
+
+type SlackAttachment struct {
+	Fallback string       `json:"fallback"`
+	Color    string       `json:"color"`
+	Title    string       `json:"title"`
+	Text     string       `json:"text,omitempty"`
+	Fields   []SlackField `json:"fields,omitempty"`
+	Ts       int64        `json:"ts,omitempty"`
+}
+
+type SlackMessage struct {
+	Text        string            `json:"text,omitempty"`
+	Attachments []SlackAttachment `json:"attachments,omitempty"`
+}
+
+func NewSlackNotifier(webhookURL string) *SlackNotifier {
+	return &SlackNotifier{
+		webhookURL: webhookURL,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+func (s *SlackNotifier) GetWebhookURL() string {
+	return s.webhookURL
+}
+
+func (s *SlackNotifier) TestWebhook() error {
+	if s.webhookURL == "" {
+		return fmt.Errorf("no webhook URL configured")
+	}
+
+	return s.send(SlackMessage{
+		Attachments: []SlackAttachment{{
+			Fallback: "GoCheck Test Notification",
+			Color:    "#58b9ff",
+			Title:    "GoCheck Test Notification",
+			Text:     "If you see this message, your Slack webhook is configured correctly!",
+			Ts:       time.Now().Unix(),
+		}},
+	})
+}
+
+func (s *SlackNotifier) SendStatusChange(checkName, url string, isUp bool, statusCode int, responseTimeMs int, errorMsg string) error {
+	if s.webhookURL == "" {
+		return nil
+	}
+
+	color := "danger"
+	status := "DOWN"
+	if isUp {
+		color = "good"
+		status = "UP"
+	}
+
+	attachment := SlackAttachment{
+		Fallback: fmt.Sprintf("%s is %s", checkName, status),
+		Color:    color,
+		Title:    fmt.Sprintf("Uptime Check: %s", checkName),
+		Text:     fmt.Sprintf("Status changed to *%s*", status),
+		Ts:       time.Now().Unix(),
+		Fields: []SlackField{
+			{Title: "URL", Value: url},
+			{Title: "Status", Value: status, Short: true},
+		},
+	}
+
+	if statusCode > 0 {
+		attachment.Fields = append(attachment.Fields, SlackField{
+			Title: "Status Code",
+			Value: fmt.Sprintf("%d", statusCode),
+			Short: true,
+		})
+	}
+
+	if responseTimeMs > 0 {
+		attachment.Fields = append(attachment.Fields, SlackField{
+			Title: "Response Time",
+			Value: fmt.Sprintf("%d ms", responseTimeMs),
+			Short: true,
+		})
+	}
+
+	if errorMsg != "" {
+		attachment.Fields = append(attachment.Fields, SlackField{
+			Title: "Error",
+			Value: errorMsg,
+		})
+	}
+
+	return s.send(SlackMessage{Attachments: []SlackAttachment{attachment}})
+}
+
+func (s *SlackNotifier) send(message SlackMessage) error {
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", s.webhookURL, bytes.NewBuffer(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,156 @@
+package notifier
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultWebhookTemplate is used when no payload template is configured
+const DefaultWebhookTemplate = `{"check_name": "{{check_name}}", "url": "{{url}}", "status": "{{status}}", "status_code": {{status_code}}, "latency_ms": {{latency_ms}}, "error": "{{error}}", "timestamp": "{{timestamp}}"}`
+
+// WebhookNotifier sends status changes to an arbitrary HTTP endpoint. The payload is
+// a JSON template with {{placeholder}} variables; string values are JSON-escaped so
+// they can be placed inside quotes, numbers are inserted as-is.
+type WebhookNotifier struct {
+	url      string
+	method   string
+	template string
+	client   *http.Client
+}
+
+func NewWebhookNotifier(url, method, template string) *WebhookNotifier {
+	method = strings.ToUpper(strings.TrimSpace(method))
+	if method == "" {
+		method = http.MethodPost
+	}
+	if strings.TrimSpace(template) == "" {
+		template = DefaultWebhookTemplate
+	}
+	return &WebhookNotifier{
+		url:      url,
+		method:   method,
+		template: template,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+func (n *WebhookNotifier) GetURL() string {
+	return n.url
+}
+
+func (n *WebhookNotifier) TestWebhook() error {
+	if n.url == "" {
+		return fmt.Errorf("no webhook URL configured")
+	}
+
+	return n.send(webhookVars{
+		checkName:  "GoCheck Test Notification",
+		url:        "https://example.com",
+		status:     "UP",
+		isUp:       true,
+		statusCode: 200,
+		latencyMs:  42,
+	})
+}
+
+func (n *WebhookNotifier) SendStatusChange(checkName, url string, isUp bool, statusCode int, responseTimeMs int, errorMsg string) error {
+	if n.url == "" {
+		return nil
+	}
+
+	status := "DOWN"
+	if isUp {
+		status = "UP"
+	}
+
+	return n.send(webhookVars{
+		checkName:  checkName,
+		url:        url,
+		status:     status,
+		isUp:       isUp,
+		statusCode: statusCode,
+		latencyMs:  responseTimeMs,
+		errorMsg:   errorMsg,
+	})
+}
+
+type webhookVars struct {
+	checkName  string
+	url        string
+	status     string
+	isUp       bool
+	statusCode int
+	latencyMs  int
+	errorMsg   string
+}
+
+// jsonEscape returns s encoded as the inside of a JSON string literal
+func jsonEscape(s string) string {
+	encoded, _ := json.Marshal(s)
+	return string(encoded[1 : len(encoded)-1])
+}
+
+// renderWebhookTemplate substitutes the placeholders and checks the result is valid JSON
+func renderWebhookTemplate(template string, vars webhookVars) ([]byte, error) {
+	payload := strings.NewReplacer(
+		"{{check_name}}", jsonEscape(vars.checkName),
+		"{{url}}", jsonEscape(vars.url),
+		"{{status}}", vars.status,
+		"{{is_up}}", strconv.FormatBool(vars.isUp),
+		"{{status_code}}", strconv.Itoa(vars.statusCode),
+		"{{latency_ms}}", strconv.Itoa(vars.latencyMs),
+		"{{error}}", jsonEscape(vars.errorMsg),
+		"{{timestamp}}", time.Now().UTC().Format(time.RFC3339),
+	).Replace(template)
+
+	if !json.Valid([]byte(payload)) {
+		return nil, fmt.Errorf("webhook template does not produce valid JSON")
+	}
+	return []byte(payload), nil
+}
+
+// ValidateWebhookTemplate renders the template with sample values
+func ValidateWebhookTemplate(template string) error {
+	if strings.TrimSpace(template) == "" {
+		return nil
+	}
+	_, err := renderWebhookTemplate(template, webhookVars{
+		checkName: "example",
+		url:       "https://example.com",
+		status:    "DOWN",
+		errorMsg:  `timeout "example"`,
+	})
+	return err
+}
+
+func (n *WebhookNotifier) send(vars webhookVars) error {
+	payload, err := renderWebhookTemplate(n.template, vars)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(n.method, n.url, strings.NewReader(string(payload)))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
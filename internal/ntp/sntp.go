@@ -0,0 +1,89 @@
+// Package ntp implements a minimal SNTP (RFC 4330) client used by NTP checks.
+package ntp
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"time"
+)
+
+const DefaultPort = 123
+
+// ntpEpochOffset is the number of seconds between 1900-01-01 and 1970-01-01
+const ntpEpochOffset = 2208988800
+
+// Response holds the measurements of a single SNTP exchange
+type Response struct {
+	Stratum int
+	Offset  time.Duration // server clock minus local clock
+	Delay   time.Duration // round-trip network delay
+}
+
+// Query sends a client request to addr (host:port) and measures clock offset and delay
+func Query(addr string, timeout time.Duration) (*Response, error) {
+	conn, err := net.DialTimeout("udp", addr, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect: %w", err)
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, err
+	}
+
+	req := make([]byte, 48)
+	req[0] = 0x1B // LI = 0, VN = 3, Mode = 3 (client)
+
+	t1 := time.Now()
+	binary.BigEndian.PutUint64(req[40:], toNTPTime(t1))
+	if _, err := conn.Write(req); err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	resp := make([]byte, 48)
+	n, err := conn.Read(resp)
+	t4 := time.Now()
+	if err != nil {
+		return nil, fmt.Errorf("no response: %w", err)
+	}
+	if n < 48 {
+		return nil, fmt.Errorf("short response (%d bytes)", n)
+	}
+
+	if mode := resp[0] & 0x07; mode != 4 {
+		return nil, fmt.Errorf("unexpected mode %d in response", mode)
+	}
+	if binary.BigEndian.Uint64(resp[24:]) != binary.BigEndian.Uint64(req[40:]) {
+		return nil, fmt.Errorf("response does not match request")
+	}
+
+	stratum := int(resp[1])
+	if stratum == 0 {
+		return nil, fmt.Errorf("kiss-of-death response: %s", string(resp[12:16]))
+	}
+	if resp[0]>>6 == 3 {
+		return nil, fmt.Errorf("server clock is not synchronized")
+	}
+
+	t2 := fromNTPTime(binary.BigEndian.Uint64(resp[32:]))
+	t3 := fromNTPTime(binary.BigEndian.Uint64(resp[40:]))
+
+	return &Response{
+		Stratum: stratum,
+		Offset:  (t2.Sub(t1) + t3.Sub(t4)) / 2,
+		Delay:   t4.Sub(t1) - t3.Sub(t2),
+	}, nil
+}
+
+func toNTPTime(t time.Time) uint64 {
+	sec := uint64(t.Unix() + ntpEpochOffset)
+	frac := uint64(t.Nanosecond()) << 32 / 1e9
+	return sec<<32 | frac
+}
+
+func fromNTPTime(v uint64) time.Time {
+	sec := int64(v>>32) - ntpEpochOffset
+	nsec := int64((v & 0xFFFFFFFF) * 1e9 >> 32)
+	return time.Unix(sec, nsec)
+}
@@ -0,0 +1,128 @@
+package ntp
+
+import (
+	"encoding/binary"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// startServer answers every request with reply(request) over UDP on a local port. A nil
+// reply sends nothing.
+func startServer(t *testing.T, reply func(req []byte) []byte) string {
+	t.Helper()
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { pc.Close() })
+
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := pc.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if resp := reply(buf[:n]); resp != nil {
+				pc.WriteTo(resp, addr)
+			}
+		}
+	}()
+	return pc.LocalAddr().String()
+}
+
+// serverReply builds a server response to req from a clock skew ahead of local time
+func serverReply(req []byte, skew time.Duration) []byte {
+	resp := make([]byte, 48)
+	resp[0] = 0x1C // LI = 0, VN = 3, Mode = 4 (server)
+	resp[1] = 2
+	copy(resp[24:32], req[40:48])
+	now := time.Now().Add(skew)
+	binary.BigEndian.PutUint64(resp[32:], toNTPTime(now))
+	binary.BigEndian.PutUint64(resp[40:], toNTPTime(now))
+	return resp
+}
+
+func TestQuery(t *testing.T) {
+	addr := startServer(t, func(req []byte) []byte {
+		if len(req) != 48 || req[0] != 0x1B {
+			return nil
+		}
+		return serverReply(req, 2*time.Second)
+	})
+
+	resp, err := Query(addr, 2*time.Second)
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if resp.Stratum != 2 {
+		t.Errorf("Stratum = %d, want 2", resp.Stratum)
+	}
+	if resp.Offset < 1900*time.Millisecond || resp.Offset > 2100*time.Millisecond {
+		t.Errorf("Offset = %v, want about 2s", resp.Offset)
+	}
+	if resp.Delay < -time.Millisecond || resp.Delay > 100*time.Millisecond {
+		t.Errorf("Delay = %v, want a small round trip", resp.Delay)
+	}
+}
+
+func TestQueryRejectsInvalidResponses(t *testing.T) {
+	tests := []struct {
+		name    string
+		reply   func(req []byte) []byte
+		wantErr string
+	}{
+		{"short", func(req []byte) []byte { return serverReply(req, 0)[:40] }, "short response"},
+		{"client mode", func(req []byte) []byte {
+			resp := serverReply(req, 0)
+			resp[0] = 0x1B
+			return resp
+		}, "unexpected mode 3"},
+		{"other request", func(req []byte) []byte {
+			resp := serverReply(req, 0)
+			resp[31]++
+			return resp
+		}, "does not match"},
+		{"kiss of death", func(req []byte) []byte {
+			resp := serverReply(req, 0)
+			resp[1] = 0
+			copy(resp[12:16], "RATE")
+			return resp
+		}, "kiss-of-death response: RATE"},
+		{"unsynchronized", func(req []byte) []byte {
+			resp := serverReply(req, 0)
+			resp[0] |= 0xC0
+			return resp
+		}, "not synchronized"},
+		{"silent", func(req []byte) []byte { return nil }, "no response"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr := startServer(t, tt.reply)
+			_, err := Query(addr, 300*time.Millisecond)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Query error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNTPTime(t *testing.T) {
+	if sec := toNTPTime(time.Unix(0, 0)) >> 32; sec != ntpEpochOffset {
+		t.Errorf("Unix epoch = %d NTP seconds, want %d", sec, ntpEpochOffset)
+	}
+	if frac := uint32(toNTPTime(time.Unix(0, 500_000_000))); frac != 1<<31 {
+		t.Errorf("half a second = %#x, want %#x", frac, uint32(1<<31))
+	}
+
+	// The 32-bit fraction resolves about 233ps, so round trips lose under a nanosecond
+	want := time.Date(2024, 2, 29, 12, 30, 45, 123456789, time.UTC)
+	got := fromNTPTime(toNTPTime(want))
+	if d := want.Sub(got); d < 0 || d > time.Nanosecond {
+		t.Errorf("round trip of %v = %v", want, got)
+	}
+}
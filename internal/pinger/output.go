@@ -0,0 +1,65 @@
+package pinger
+
+import (
+	"fmt"
+	"regexp"
+	"runtime"
+	"strconv"
+	"time"
+)
+
+var (
+	// 4 packets transmitted, 3 received / 4 packets transmitted, 3 packets received
+	unixCountsRe = regexp.MustCompile(`(\d+) packets transmitted, (\d+) (?:packets )?received`)
+	// Packets: Sent = 4, Received = 3, Lost = 1 (25% loss)
+	windowsCountsRe = regexp.MustCompile(`Sent = (\d+), Received = (\d+)`)
+	// rtt min/avg/max/mdev = 0.041/0.052/0.063/0.011 ms
+	unixAvgRe = regexp.MustCompile(`= [\d.]+/([\d.]+)/`)
+	// Minimum = 1ms, Maximum = 3ms, Average = 2ms
+	windowsAvgRe = regexp.MustCompile(`Average = (\d+)ms`)
+)
+
+// ExecArgs returns the system ping binary's arguments for sending count echo requests,
+// Interval apart where the platform allows it, each waiting up to timeoutSeconds for
+// its reply. The host is left for the caller to append.
+func ExecArgs(count, timeoutSeconds int) []string {
+	if count < 1 {
+		count = 1
+	}
+	switch runtime.GOOS {
+	case "windows":
+		return []string{"-n", strconv.Itoa(count), "-w", strconv.Itoa(timeoutSeconds * 1000)}
+	case "linux":
+		if count > 1 {
+			return []string{"-c", strconv.Itoa(count), "-i", fmt.Sprintf("%g", Interval.Seconds()), "-W", strconv.Itoa(timeoutSeconds)}
+		}
+	}
+	return []string{"-c", strconv.Itoa(count), "-W", strconv.Itoa(timeoutSeconds)}
+}
+
+// ParseOutput reads the summary the system ping binary prints on Linux, macOS and
+// Windows, reporting false when there is none
+func ParseOutput(output []byte) (Stats, bool) {
+	var stats Stats
+
+	counts := unixCountsRe.FindSubmatch(output)
+	if counts == nil {
+		counts = windowsCountsRe.FindSubmatch(output)
+	}
+	if counts == nil {
+		return stats, false
+	}
+	stats.Sent, _ = strconv.Atoi(string(counts[1]))
+	stats.Received, _ = strconv.Atoi(string(counts[2]))
+
+	avg := unixAvgRe.FindSubmatch(output)
+	if avg == nil {
+		avg = windowsAvgRe.FindSubmatch(output)
+	}
+	if avg != nil {
+		if ms, err := strconv.ParseFloat(string(avg[1]), 64); err == nil {
+			stats.AvgRTT = time.Duration(ms * float64(time.Millisecond))
+		}
+	}
+	return stats, true
+}
@@ -0,0 +1,230 @@
+// Package pinger sends ICMP echo requests without the system ping binary, measuring the
+// round trip of the echo reply itself.
+package pinger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"net"
+	"os"
+	"time"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+// ErrUnavailable is returned when neither an unprivileged ICMP socket nor a raw socket
+// can be opened, typically for lack of permissions; callers can fall back to exec'ing ping
+var ErrUnavailable = errors.New("ICMP sockets unavailable")
+
+// ErrNoReply is returned when no echo reply arrived before the deadline
+var ErrNoReply = errors.New("no response before timeout")
+
+// protocol numbers for icmp.ParseMessage
+const (
+	protocolICMP     = 1
+	protocolIPv6ICMP = 58
+)
+
+// Interval is the gap between the echo requests of a Run, the shortest ping allows
+// unprivileged users
+const Interval = 200 * time.Millisecond
+
+// Stats summarises a run of echo requests
+type Stats struct {
+	Sent     int
+	Received int
+	// AvgRTT is the mean round trip of the replies that arrived
+	AvgRTT time.Duration
+}
+
+// Loss is the percentage of echo requests that got no reply
+func (s Stats) Loss() float64 {
+	if s.Sent == 0 {
+		return 0
+	}
+	return float64(s.Sent-s.Received) * 100 / float64(s.Sent)
+}
+
+func (s Stats) String() string {
+	return fmt.Sprintf("%d/%d received, %g%% packet loss", s.Received, s.Sent, math.Round(s.Loss()*10)/10)
+}
+
+// Ping resolves host and sends it a single echo request from source (nil lets the OS
+// choose), returning the round-trip time of the reply. The context bounds the whole
+// exchange, including name resolution. IPv4 addresses are preferred, or the family of
+// source when one is given.
+func Ping(ctx context.Context, host string, source net.IP) (time.Duration, error) {
+	stats, err := Run(ctx, host, source, 1)
+	return stats.AvgRTT, err
+}
+
+// Run is Ping with count echo requests sent Interval apart. It waits for the replies
+// until all have arrived or the context is done, so requests sent just before the
+// deadline count as lost. ErrNoReply is returned, with the stats, when none arrived.
+func Run(ctx context.Context, host string, source net.IP, count int) (Stats, error) {
+	var stats Stats
+	if count < 1 {
+		count = 1
+	}
+
+	dst, err := resolve(ctx, host, source)
+	if err != nil {
+		return stats, err
+	}
+
+	conn, datagram, err := listen(dst, source)
+	if err != nil {
+		return stats, err
+	}
+	defer conn.Close()
+
+	deadline, hasDeadline := ctx.Deadline()
+	// Unblock the read if the context is cancelled before its deadline
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	v4 := dst.To4() != nil
+	var echoType, replyType icmp.Type = ipv4.ICMPTypeEcho, ipv4.ICMPTypeEchoReply
+	proto := protocolICMP
+	if !v4 {
+		echoType, replyType, proto = ipv6.ICMPTypeEchoRequest, ipv6.ICMPTypeEchoReply, protocolIPv6ICMP
+	}
+
+	var peer net.Addr = &net.IPAddr{IP: dst}
+	if datagram {
+		peer = &net.UDPAddr{IP: dst}
+	}
+
+	// Datagram sockets get their ID rewritten by the kernel, so only the sequence
+	// number and payload identify our replies there
+	id := os.Getpid() & 0xffff
+	firstSeq := rand.Intn(0xffff)
+	payload := []byte(fmt.Sprintf("gocheck-%d", rand.Int63()))
+	pending := make(map[int]time.Time, count) // send time by sequence number
+	var total time.Duration
+
+	rb := make([]byte, 1500)
+	next := time.Now()
+	for stats.Received < count {
+		if stats.Sent < count && !time.Now().Before(next) {
+			seq := (firstSeq + stats.Sent) & 0xffff
+			msg := icmp.Message{
+				Type: echoType,
+				Body: &icmp.Echo{ID: id, Seq: seq, Data: payload},
+			}
+			wb, err := msg.Marshal(nil)
+			if err != nil {
+				return stats, err
+			}
+			if _, err := conn.WriteTo(wb, peer); err != nil {
+				if ctx.Err() != nil {
+					break
+				}
+				return stats, fmt.Errorf("failed to send echo request: %w", err)
+			}
+			pending[seq] = time.Now()
+			stats.Sent++
+			next = next.Add(Interval)
+		}
+
+		// Wake up for the next request, or wait out the context once all are sent
+		var readDeadline time.Time
+		if hasDeadline {
+			readDeadline = deadline
+		}
+		if stats.Sent < count && (readDeadline.IsZero() || next.Before(readDeadline)) {
+			readDeadline = next
+		}
+		conn.SetReadDeadline(readDeadline)
+
+		n, _, err := conn.ReadFrom(rb)
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				if stats.Sent < count && (!hasDeadline || time.Now().Before(deadline)) {
+					continue
+				}
+				break
+			}
+			return stats, fmt.Errorf("failed to read echo reply: %w", err)
+		}
+		received := time.Now()
+
+		reply, err := icmp.ParseMessage(proto, rb[:n])
+		if err != nil || reply.Type != replyType {
+			continue
+		}
+		echo, ok := reply.Body.(*icmp.Echo)
+		if !ok || string(echo.Data) != string(payload) {
+			continue
+		}
+		if !datagram && echo.ID != id {
+			continue
+		}
+		sentAt, ok := pending[echo.Seq]
+		if !ok {
+			continue
+		}
+		delete(pending, echo.Seq)
+		stats.Received++
+		total += received.Sub(sentAt)
+	}
+
+	if stats.Received == 0 {
+		return stats, ErrNoReply
+	}
+	stats.AvgRTT = total / time.Duration(stats.Received)
+	return stats, nil
+}
+
+// resolve picks the address to ping, preferring IPv4 or the family of source
+func resolve(ctx context.Context, host string, source net.IP) (net.IP, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		return ip, nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	wantV4 := source == nil || source.To4() != nil
+	for _, addr := range addrs {
+		if (addr.IP.To4() != nil) == wantV4 {
+			return addr.IP, nil
+		}
+	}
+	if source == nil && len(addrs) > 0 {
+		return addrs[0].IP, nil
+	}
+	return nil, fmt.Errorf("%s has no address of the same family as the source address", host)
+}
+
+// listen opens an unprivileged ICMP datagram socket, or a raw socket when those are not
+// allowed, and reports whether the datagram one was used
+func listen(dst, source net.IP) (*icmp.PacketConn, bool, error) {
+	datagramNet, rawNet, addr := "udp4", "ip4:icmp", "0.0.0.0"
+	if dst.To4() == nil {
+		datagramNet, rawNet, addr = "udp6", "ip6:ipv6-icmp", "::"
+	}
+	if source != nil {
+		addr = source.String()
+	}
+
+	conn, err := icmp.ListenPacket(datagramNet, addr)
+	if err == nil {
+		return conn, true, nil
+	}
+	conn, rawErr := icmp.ListenPacket(rawNet, addr)
+	if rawErr == nil {
+		return conn, false, nil
+	}
+	return nil, false, fmt.Errorf("%w: %v", ErrUnavailable, rawErr)
+}
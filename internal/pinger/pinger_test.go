@@ -0,0 +1,100 @@
+package pinger
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPingLoopback(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	rtt, err := Ping(ctx, "127.0.0.1", nil)
+	if errors.Is(err, ErrUnavailable) {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rtt <= 0 || rtt > time.Second {
+		t.Errorf("rtt = %v, want a loopback round trip", rtt)
+	}
+}
+
+func TestRunLoopback(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	stats, err := Run(ctx, "127.0.0.1", nil, 3)
+	if errors.Is(err, ErrUnavailable) {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Sent != 3 || stats.Received != 3 || stats.Loss() != 0 {
+		t.Errorf("stats = %+v, want 3 sent and received", stats)
+	}
+	if got, want := stats.String(), "3/3 received, 0% packet loss"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestParseOutput(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   Stats
+		wantOK bool
+	}{
+		{
+			name: "linux",
+			output: `64 bytes from 127.0.0.1: icmp_seq=1 ttl=64 time=0.041 ms
+
+--- 127.0.0.1 ping statistics ---
+4 packets transmitted, 3 received, 25% packet loss, time 3004ms
+rtt min/avg/max/mdev = 0.041/0.052/0.063/0.011 ms`,
+			want:   Stats{Sent: 4, Received: 3, AvgRTT: 52 * time.Microsecond},
+			wantOK: true,
+		},
+		{
+			name: "macos",
+			output: `--- 127.0.0.1 ping statistics ---
+2 packets transmitted, 2 packets received, 0.0% packet loss
+round-trip min/avg/max/stddev = 1.000/1.500/2.000/0.500 ms`,
+			want:   Stats{Sent: 2, Received: 2, AvgRTT: 1500 * time.Microsecond},
+			wantOK: true,
+		},
+		{
+			name: "windows",
+			output: `Ping statistics for 127.0.0.1:
+    Packets: Sent = 4, Received = 4, Lost = 0 (0% loss),
+Approximate round trip times in milli-seconds:
+    Minimum = 1ms, Maximum = 3ms, Average = 2ms`,
+			want:   Stats{Sent: 4, Received: 4, AvgRTT: 2 * time.Millisecond},
+			wantOK: true,
+		},
+		{
+			name: "all lost",
+			output: `--- 10.255.255.1 ping statistics ---
+2 packets transmitted, 0 received, 100% packet loss, time 1020ms`,
+			want:   Stats{Sent: 2},
+			wantOK: true,
+		},
+		{
+			name:   "no summary",
+			output: "ping: unknown host example.invalid",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseOutput([]byte(tt.output))
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("ParseOutput() = %+v, %v, want %+v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
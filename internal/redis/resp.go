@@ -0,0 +1,207 @@
+// Package redis implements a minimal RESP2 client used by Redis checks. It only speaks
+// the handful of commands a check needs (AUTH, SELECT, PING, GET).
+package redis
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const DefaultPort = 6379
+
+// Options is a parsed Redis connection string
+type Options struct {
+	Addr     string
+	Username string
+	Password string
+	DB       int
+	TLS      bool
+}
+
+// ParseURL parses redis://[[user]:password@]host[:port][/db], rediss:// for TLS, or a
+// bare host[:port]
+func ParseURL(s string) (Options, error) {
+	var opts Options
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return opts, fmt.Errorf("no connection string specified")
+	}
+	if !strings.Contains(s, "://") {
+		s = "redis://" + s
+	}
+
+	u, err := url.Parse(s)
+	if err != nil {
+		return opts, fmt.Errorf("invalid connection string: %w", err)
+	}
+	switch u.Scheme {
+	case "redis":
+	case "rediss":
+		opts.TLS = true
+	default:
+		return opts, fmt.Errorf("unsupported scheme %q, use redis:// or rediss://", u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return opts, fmt.Errorf("connection string has no host")
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = strconv.Itoa(DefaultPort)
+	}
+	opts.Addr = net.JoinHostPort(u.Hostname(), port)
+
+	if u.User != nil {
+		// redis://:password@host carries only a password, redis://secret@host is
+		// treated the same way since Redis has no user without a password
+		if password, ok := u.User.Password(); ok {
+			opts.Username = u.User.Username()
+			opts.Password = password
+		} else {
+			opts.Password = u.User.Username()
+		}
+	}
+
+	if db := strings.Trim(u.Path, "/"); db != "" {
+		if opts.DB, err = strconv.Atoi(db); err != nil || opts.DB < 0 {
+			return opts, fmt.Errorf("invalid database number %q", db)
+		}
+	}
+	return opts, nil
+}
+
+// Reply is a decoded RESP reply. Nil is set for a null bulk string, e.g. GET of a
+// missing key.
+type Reply struct {
+	Kind byte
+	Str  string
+	Nil  bool
+}
+
+// Conn is a single Redis connection with a deadline covering its whole lifetime
+type Conn struct {
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// Dial connects to opts.Addr, authenticates and selects the database. timeout bounds
+// the dial and every command sent on the connection afterwards.
+func Dial(opts Options, timeout time.Duration) (*Conn, error) {
+	dialer := &net.Dialer{Timeout: timeout}
+	var conn net.Conn
+	var err error
+	if opts.TLS {
+		host, _, _ := net.SplitHostPort(opts.Addr)
+		conn, err = tls.DialWithDialer(dialer, "tcp", opts.Addr, &tls.Config{ServerName: host})
+	} else {
+		conn, err = dialer.Dial("tcp", opts.Addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("connection error: %w", err)
+	}
+	conn.SetDeadline(time.Now().Add(timeout))
+	c := &Conn{conn: conn, r: bufio.NewReader(conn)}
+
+	if opts.Password != "" {
+		args := []string{"AUTH", opts.Password}
+		if opts.Username != "" {
+			args = []string{"AUTH", opts.Username, opts.Password}
+		}
+		if _, err := c.Do(args...); err != nil {
+			c.Close()
+			return nil, fmt.Errorf("auth failed: %w", err)
+		}
+	}
+	if opts.DB != 0 {
+		if _, err := c.Do("SELECT", strconv.Itoa(opts.DB)); err != nil {
+			c.Close()
+			return nil, fmt.Errorf("select failed: %w", err)
+		}
+	}
+	return c, nil
+}
+
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
+
+// Do sends a command as a RESP array and reads the reply. Error replies are returned
+// as errors.
+func (c *Conn) Do(args ...string) (Reply, error) {
+	var cmd strings.Builder
+	fmt.Fprintf(&cmd, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&cmd, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := io.WriteString(c.conn, cmd.String()); err != nil {
+		return Reply{}, err
+	}
+	return c.readReply()
+}
+
+func (c *Conn) readReply() (Reply, error) {
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return Reply{}, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return Reply{}, fmt.Errorf("empty reply")
+	}
+
+	reply := Reply{Kind: line[0], Str: line[1:]}
+	switch reply.Kind {
+	case '+', ':':
+		return reply, nil
+	case '-':
+		return reply, fmt.Errorf("%s", reply.Str)
+	case '$':
+		n, err := strconv.Atoi(reply.Str)
+		if err != nil {
+			return reply, fmt.Errorf("invalid bulk length %q", reply.Str)
+		}
+		if n < 0 {
+			reply.Nil = true
+			reply.Str = ""
+			return reply, nil
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(c.r, buf); err != nil {
+			return reply, err
+		}
+		reply.Str = string(buf[:n])
+		return reply, nil
+	default:
+		return reply, fmt.Errorf("unexpected reply %q", line)
+	}
+}
+
+// Ping sends PING and returns its round-trip time
+func (c *Conn) Ping() (time.Duration, error) {
+	start := time.Now()
+	reply, err := c.Do("PING")
+	rtt := time.Since(start)
+	if err != nil {
+		return rtt, fmt.Errorf("ping failed: %w", err)
+	}
+	if reply.Kind != '+' || reply.Str != "PONG" {
+		return rtt, fmt.Errorf("expected PONG, got '%s'", reply.Str)
+	}
+	return rtt, nil
+}
+
+// Get returns the value of key; ok is false when the key does not exist
+func (c *Conn) Get(key string) (value string, ok bool, err error) {
+	reply, err := c.Do("GET", key)
+	if err != nil {
+		return "", false, fmt.Errorf("get failed: %w", err)
+	}
+	return reply.Str, !reply.Nil, nil
+}
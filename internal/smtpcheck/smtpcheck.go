@@ -0,0 +1,81 @@
+// Package smtpcheck holds a short SMTP session that tells whether a mail server is
+// accepting connections, for SMTP checks run by the server and by probes.
+package smtpcheck
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+const DefaultPort = 25
+
+// Result describes a successful session
+type Result struct {
+	Greeting string // text of the 220 greeting, without the code
+	StartTLS bool   // whether EHLO advertised STARTTLS
+}
+
+// Check connects to addr, reads the 220 greeting, sends EHLO and quits. With tlsConfig
+// set the server must also offer STARTTLS and complete a handshake with it. The whole
+// session must finish within timeout.
+func Check(addr string, timeout time.Duration, tlsConfig *tls.Config) (Result, error) {
+	var result Result
+
+	conn, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		return result, fmt.Errorf("connection failed: %v", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	text := textproto.NewConn(conn)
+	_, greeting, err := text.ReadResponse(220)
+	if err != nil {
+		return result, fmt.Errorf("unexpected greeting: %v", err)
+	}
+	result.Greeting = greeting
+
+	extensions, err := command(text, 250, "EHLO localhost")
+	if err != nil {
+		return result, fmt.Errorf("EHLO failed: %v", err)
+	}
+	// The first line of the EHLO reply is the server's name, the rest its extensions
+	for _, line := range strings.Split(extensions, "\n")[1:] {
+		if fields := strings.Fields(line); len(fields) > 0 && strings.EqualFold(fields[0], "STARTTLS") {
+			result.StartTLS = true
+		}
+	}
+
+	if tlsConfig != nil {
+		if !result.StartTLS {
+			return result, fmt.Errorf("server does not offer STARTTLS")
+		}
+		if _, err := command(text, 220, "STARTTLS"); err != nil {
+			return result, fmt.Errorf("STARTTLS failed: %v", err)
+		}
+		tlsConn := tls.Client(conn, tlsConfig)
+		if err := tlsConn.Handshake(); err != nil {
+			return result, fmt.Errorf("STARTTLS handshake failed: %v", err)
+		}
+		text = textproto.NewConn(tlsConn)
+	}
+
+	// The server has already proven itself; a sloppy QUIT doesn't fail the check
+	command(text, 221, "QUIT")
+	return result, nil
+}
+
+func command(text *textproto.Conn, expectCode int, format string, args ...interface{}) (string, error) {
+	id, err := text.Cmd(format, args...)
+	if err != nil {
+		return "", err
+	}
+	text.StartResponse(id)
+	defer text.EndResponse(id)
+	_, msg, err := text.ReadResponse(expectCode)
+	return msg, err
+}
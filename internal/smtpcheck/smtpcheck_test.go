@@ -0,0 +1,82 @@
+package smtpcheck
+
+import (
+	"bufio"
+	"crypto/tls"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// serveSMTP answers one connection with greeting, then the EHLO reply ehlo and 221
+// to anything else
+func serveSMTP(t *testing.T, greeting, ehlo string) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { lis.Close() })
+
+	go func() {
+		conn, err := lis.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.Write([]byte(greeting))
+		r := bufio.NewReader(conn)
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			if strings.HasPrefix(line, "EHLO") {
+				conn.Write([]byte(ehlo))
+			} else {
+				conn.Write([]byte("221 bye\r\n"))
+			}
+		}
+	}()
+	return lis.Addr().String()
+}
+
+func TestCheck(t *testing.T) {
+	addr := serveSMTP(t, "220 mx.example.com ESMTP Postfix\r\n", "250-mx.example.com\r\n250-SIZE 10240000\r\n250 STARTTLS\r\n")
+
+	result, err := Check(addr, 2*time.Second, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Greeting != "mx.example.com ESMTP Postfix" {
+		t.Errorf("greeting = %q", result.Greeting)
+	}
+	if !result.StartTLS {
+		t.Error("STARTTLS not detected")
+	}
+}
+
+func TestCheckFailures(t *testing.T) {
+	tests := []struct {
+		name     string
+		greeting string
+		ehlo     string
+		want     string
+	}{
+		{"rejecting greeting", "554 no service\r\n", "", "unexpected greeting"},
+		{"ehlo refused", "220 mx ESMTP\r\n", "502 not implemented\r\n", "EHLO failed"},
+		{"no starttls", "220 mx ESMTP\r\n", "250-mx\r\n250 SIZE 1000\r\n", "does not offer STARTTLS"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr := serveSMTP(t, tt.greeting, tt.ehlo)
+			// Requiring STARTTLS only matters once EHLO succeeded
+			_, err := Check(addr, 2*time.Second, &tls.Config{})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
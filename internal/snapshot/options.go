@@ -0,0 +1,88 @@
+package snapshot
+
+import (
+	"fmt"
+	"strconv"
+
+	"gocheck/internal/db"
+)
+
+// Settings keys for the capture options
+const (
+	IntervalSetting = "snapshot_interval_hours"
+	WidthSetting    = "snapshot_width"
+	HeightSetting   = "snapshot_height"
+	FullPageSetting = "snapshot_full_page"
+)
+
+// Bounds of the capture options. The interval floor keeps a refresh of every check
+// from hammering browserless.
+const (
+	MinIntervalHours = 1
+	MaxIntervalHours = 24 * 7
+	MinWidth         = 320
+	MaxWidth         = 3840
+	MinHeight        = 240
+	MaxHeight        = 2160
+)
+
+// Options controls how often snapshots are refreshed and how they are captured
+type Options struct {
+	IntervalHours int
+	Width         int
+	Height        int
+	// FullPage captures the whole scrollable page instead of just the viewport
+	FullPage bool
+}
+
+// DefaultOptions apply to settings that are unset or out of range
+var DefaultOptions = Options{IntervalHours: 6, Width: 1280, Height: 800}
+
+// ValidateIntervalHours, ValidateWidth and ValidateHeight reject values outside the
+// bounds, for the settings API
+func ValidateIntervalHours(hours int) error {
+	return validateRange(IntervalSetting, hours, MinIntervalHours, MaxIntervalHours)
+}
+
+func ValidateWidth(width int) error {
+	return validateRange(WidthSetting, width, MinWidth, MaxWidth)
+}
+
+func ValidateHeight(height int) error {
+	return validateRange(HeightSetting, height, MinHeight, MaxHeight)
+}
+
+func validateRange(name string, value, min, max int) error {
+	if value < min || value > max {
+		return fmt.Errorf("%s must be between %d and %d", name, min, max)
+	}
+	return nil
+}
+
+// LoadOptions reads the capture options from the settings, falling back to the
+// default for anything unset or invalid
+func LoadOptions(database *db.Database) Options {
+	opts := DefaultOptions
+	if v, ok := intSetting(database, IntervalSetting); ok && ValidateIntervalHours(v) == nil {
+		opts.IntervalHours = v
+	}
+	if v, ok := intSetting(database, WidthSetting); ok && ValidateWidth(v) == nil {
+		opts.Width = v
+	}
+	if v, ok := intSetting(database, HeightSetting); ok && ValidateHeight(v) == nil {
+		opts.Height = v
+	}
+	if value, _ := database.GetSetting(FullPageSetting); value == "true" {
+		opts.FullPage = true
+	}
+	return opts
+}
+
+func intSetting(database *db.Database, key string) (int, bool) {
+	value, err := database.GetSetting(key)
+	if err != nil || value == "" {
+		return 0, false
+	}
+	n, err := strconv.Atoi(value)
+	return n, err == nil
+}
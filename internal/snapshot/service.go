@@ -19,7 +19,19 @@ import (
 	"gocheck/internal/models"
 )
 
-const refreshInterval = 6 * time.Hour
+// manualRefreshMinAge skips checks snapshotted this recently on a manual refresh
+const manualRefreshMinAge = 10 * time.Minute
+
+// RefreshStatus reports the progress of the current or last snapshot refresh
+type RefreshStatus struct {
+	Running    bool       `json:"running"`
+	Total      int        `json:"total"`
+	Completed  int        `json:"completed"`
+	Failed     int        `json:"failed"`
+	Skipped    int        `json:"skipped"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
 
 type Service struct {
 	db            *db.Database
@@ -30,6 +42,10 @@ type Service struct {
 	cancel        context.CancelFunc
 	wg            sync.WaitGroup
 	sem chan struct{}
+	reload        chan struct{} // wakes run to pick up a changed interval
+
+	statusMu sync.Mutex
+	status   RefreshStatus
 }
 
 func NewService(database *db.Database, engine *checker.Engine, dataDir string) *Service {
@@ -48,6 +64,7 @@ func NewService(database *db.Database, engine *checker.Engine, dataDir string) *
 		ctx:           ctx,
 		cancel:        cancel,
 		sem:           make(chan struct{}, 1),
+		reload:        make(chan struct{}, 1),
 	}
 }
 
@@ -61,8 +78,54 @@ func (s *Service) Stop() {
 	s.wg.Wait()
 }
 
-func (s *Service) TriggerRefresh() {
-	go s.refreshAll()
+// SetConcurrency sets how many captures may run at once. Call before Start.
+func (s *Service) SetConcurrency(n int) {
+	if n < 1 {
+		n = 1
+	}
+	s.sem = make(chan struct{}, n)
+}
+
+// ReloadOptions makes the refresh loop pick up a changed snapshot interval now rather
+// than after the current one elapses
+func (s *Service) ReloadOptions() {
+	select {
+	case s.reload <- struct{}{}:
+	default:
+	}
+}
+
+// TriggerRefresh starts a background refresh of snapshots older than a few minutes.
+// It returns false without starting anything if a refresh is already running.
+func (s *Service) TriggerRefresh() bool {
+	if !s.beginRefresh() {
+		return false
+	}
+	go s.refreshAll(manualRefreshMinAge)
+	return true
+}
+
+func (s *Service) RefreshStatus() RefreshStatus {
+	s.statusMu.Lock()
+	defer s.statusMu.Unlock()
+	return s.status
+}
+
+func (s *Service) beginRefresh() bool {
+	s.statusMu.Lock()
+	defer s.statusMu.Unlock()
+	if s.status.Running {
+		return false
+	}
+	now := time.Now().UTC()
+	s.status = RefreshStatus{Running: true, StartedAt: &now}
+	return true
+}
+
+func (s *Service) updateStatus(fn func(status *RefreshStatus)) {
+	s.statusMu.Lock()
+	fn(&s.status)
+	s.statusMu.Unlock()
 }
 
 func (s *Service) CaptureCheck(checkID int64) error {
@@ -71,18 +134,13 @@ func (s *Service) CaptureCheck(checkID int64) error {
 		return fmt.Errorf("check %d not found in database", checkID)
 	}
 
-	if s.isTailscale(*check) {
-		log.Printf("snapshot: skipping check %d (Tailscale/Private network logic ignored)", checkID)
-		return nil
-	}
-
 	targetURL, err := s.resolveTargetURL(*check)
 	if err != nil {
 		s.storeFailure(checkID, "", err.Error())
 		return err
 	}
 
-	data, err := s.performCapture(targetURL)
+	data, err := s.performCapture(targetURL, s.isTailscale(*check))
 	if err != nil {
 		s.storeFailure(checkID, "", err.Error())
 		return err
@@ -110,28 +168,52 @@ func (s *Service) CaptureCheck(checkID int64) error {
 }
 
 func (s *Service) TestSnapshot(targetURL string) ([]byte, error) {
-	return s.performCapture(targetURL)
+	return s.performCapture(targetURL, tailnetURL(targetURL))
 }
 
 
 func (s *Service) run() {
 	defer s.wg.Done()
-	ticker := time.NewTicker(refreshInterval)
+	interval := s.refreshInterval()
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
-	s.refreshAll()
+	if s.beginRefresh() {
+		s.refreshAll(interval)
+	}
 
 	for {
 		select {
 		case <-ticker.C:
-			s.refreshAll()
+			if s.beginRefresh() {
+				s.refreshAll(interval)
+			}
+		case <-s.reload:
 		case <-s.ctx.Done():
 			return
 		}
+
+		if next := s.refreshInterval(); next != interval {
+			log.Printf("snapshot: refresh interval changed to %v", next)
+			interval = next
+			ticker.Reset(interval)
+		}
 	}
 }
 
-func (s *Service) refreshAll() {
+func (s *Service) refreshInterval() time.Duration {
+	return time.Duration(LoadOptions(s.db).IntervalHours) * time.Hour
+}
+
+// refreshAll captures every eligible check whose snapshot is older than maxAge, running
+// up to the configured concurrency at once. Callers must have claimed beginRefresh.
+func (s *Service) refreshAll(maxAge time.Duration) {
+	defer s.updateStatus(func(status *RefreshStatus) {
+		now := time.Now().UTC()
+		status.Running = false
+		status.FinishedAt = &now
+	})
+
 	checks, err := s.db.GetAllChecks()
 	if err != nil {
 		log.Printf("snapshot: failed to list checks for refresh: %v", err)
@@ -139,34 +221,53 @@ func (s *Service) refreshAll() {
 	}
 
 	now := time.Now().UTC()
+	var pending []int64
 	for _, check := range checks {
-		if s.isTailscale(check) {
-			continue
-		}
-
 		snapshot, err := s.db.GetCheckSnapshot(check.ID)
 		if err != nil {
 			log.Printf("snapshot: failed to get snapshot for check %d: %v", check.ID, err)
 			continue
 		}
 
-		needsRefresh := false
-		if snapshot == nil || snapshot.TakenAt == nil {
-			needsRefresh = true
-		} else {
-			age := now.Sub(*snapshot.TakenAt)
-			if age >= refreshInterval {
-				needsRefresh = true
-			}
+		if snapshot != nil && snapshot.TakenAt != nil && now.Sub(*snapshot.TakenAt) < maxAge {
+			s.updateStatus(func(status *RefreshStatus) { status.Skipped++ })
+			continue
 		}
+		pending = append(pending, check.ID)
+	}
+
+	s.updateStatus(func(status *RefreshStatus) { status.Total = len(pending) })
+
+	jobs := make(chan int64)
+	var wg sync.WaitGroup
+	for i := 0; i < cap(s.sem); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for checkID := range jobs {
+				err := s.CaptureCheck(checkID)
+				s.updateStatus(func(status *RefreshStatus) {
+					status.Completed++
+					if err != nil {
+						status.Failed++
+					}
+				})
+			}
+		}()
+	}
 
-		if needsRefresh {
-			_ = s.CaptureCheck(check.ID)
+	for _, checkID := range pending {
+		select {
+		case jobs <- checkID:
+		case <-s.ctx.Done():
 		}
 	}
+	close(jobs)
+	wg.Wait()
 }
 
-func (s *Service) performCapture(targetURL string) (data []byte, err error) {
+// performCapture screenshots targetURL, loading it through the tailnet when tailnet is set
+func (s *Service) performCapture(targetURL string, tailnet bool) (data []byte, err error) {
 	select {
 	case s.sem <- struct{}{}:
 		defer func() { <-s.sem }()
@@ -189,11 +290,11 @@ func (s *Service) performCapture(targetURL string) (data []byte, err error) {
 	ctx, cancel := context.WithTimeout(s.ctx, 30*time.Second)
 	defer cancel()
 
-	data, err = s.executeCapture(ctx, controlURL, targetURL)
+	data, err = s.executeCapture(ctx, controlURL, targetURL, LoadOptions(s.db), tailnet)
 	return data, err
 }
 
-func (s *Service) executeCapture(ctx context.Context, controlURL, targetURL string) ([]byte, error) {
+func (s *Service) executeCapture(ctx context.Context, controlURL, targetURL string, opts Options, tailnet bool) ([]byte, error) {
 	// Create browser with context for automatic cancellation
 	browser := rod.New().ControlURL(controlURL).Context(ctx)
 	
@@ -210,9 +311,21 @@ func (s *Service) executeCapture(ctx context.Context, controlURL, targetURL stri
 	}
 	defer page.Close()
 
+	if tailnet {
+		parsed, err := url.Parse(targetURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid URL %q: %w", targetURL, err)
+		}
+		stop, err := routeThroughTailnet(ctx, page, parsed.Hostname())
+		if err != nil {
+			return nil, fmt.Errorf("failed to route page through Tailscale: %w", err)
+		}
+		defer stop()
+	}
+
 	if err := page.SetViewport(&proto.EmulationSetDeviceMetricsOverride{
-		Width:  1280,
-		Height: 800,
+		Width:  opts.Width,
+		Height: opts.Height,
 	}); err != nil {
 		return nil, fmt.Errorf("failed to set viewport: %w", err)
 	}
@@ -240,7 +353,7 @@ func (s *Service) executeCapture(ctx context.Context, controlURL, targetURL stri
 	time.Sleep(3 * time.Second)
 
 	quality := 90
-	screenshot, err := page.Screenshot(false, &proto.PageCaptureScreenshot{
+	screenshot, err := page.Screenshot(opts.FullPage, &proto.PageCaptureScreenshot{
 		Format:  proto.PageCaptureScreenshotFormatPng,
 		Quality: &quality,
 	})
@@ -295,14 +408,22 @@ func (s *Service) resolveTargetURL(check models.Check) (string, error) {
 	var targetURL string
 
 	if check.URL != "" {
-		targetURL = check.URL
+		expanded, err := checker.ExpandTemplate(check.URL, time.Now().UTC())
+		if err != nil {
+			return "", err
+		}
+		targetURL = expanded
 	} else if check.Type == models.CheckTypeTailscaleService && check.TailscaleServiceHost != "" {
 		port := check.TailscaleServicePort
 		if port == 0 {
 			port = 80
 		}
+		protocol := check.TailscaleServiceProtocol
+		if protocol == "" {
+			protocol = "http"
+		}
 		path := "/" + strings.TrimPrefix(check.TailscaleServicePath, "/")
-		targetURL = fmt.Sprintf("%s://%s:%d%s", check.TailscaleServiceProtocol, check.TailscaleServiceHost, port, path)
+		targetURL = fmt.Sprintf("%s://%s:%d%s", protocol, check.TailscaleServiceHost, port, path)
 	}
 
 	if targetURL == "" {
@@ -324,19 +445,18 @@ func (s *Service) resolveTargetURL(check models.Check) (string, error) {
 	return targetURL, nil
 }
 
+// isTailscale reports whether the check's page is only reachable over the tailnet
 func (s *Service) isTailscale(check models.Check) bool {
 	if check.Type == models.CheckTypeTailscaleService {
 		return true
 	}
-	
-	lowURL := strings.ToLower(check.URL)
-	lowHost := strings.ToLower(check.TailscaleServiceHost)
-	
-	return strings.Contains(lowURL, ".ts.net") || 
-		   strings.Contains(lowHost, ".ts.net") ||
-		   strings.HasPrefix(lowHost, "100.")
-}
 
+	targetURL, err := checker.ExpandTemplate(check.URL, time.Now().UTC())
+	if err != nil {
+		targetURL = check.URL
+	}
+	return tailnetURL(targetURL) || isTailnetHost(check.TailscaleServiceHost)
+}
 
 func (s *Service) loadCredentials() (string, string, error) {
 	u, _ := s.db.GetSetting("browserless_url")
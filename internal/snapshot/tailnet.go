@@ -0,0 +1,73 @@
+package snapshot
+
+import (
+	"context"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+
+	"gocheck/internal/checker"
+)
+
+// tailscaleCGNAT is the range Tailscale assigns node addresses from
+var tailscaleCGNAT = netip.MustParsePrefix("100.64.0.0/10")
+
+// isTailnetHost reports whether host is a MagicDNS name or a Tailscale address
+func isTailnetHost(host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if strings.HasSuffix(host, ".ts.net") {
+		return true
+	}
+	addr, err := netip.ParseAddr(host)
+	return err == nil && tailscaleCGNAT.Contains(addr.Unmap())
+}
+
+// tailnetClient fetches on behalf of the browser, so redirects are handed back to it
+// rather than followed
+var tailnetClient = &http.Client{
+	Transport: &http.Transport{
+		DialContext:         checker.TailscaleDial,
+		TLSHandshakeTimeout: 10 * time.Second,
+	},
+	CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+// routeThroughTailnet makes the page load tailnet resources through tsnet instead of
+// from browserless, which can't reach them. Requests to targetHost, which may be a short
+// MagicDNS name, and to other tailnet hosts are fetched here; everything else is left to
+// the browser. The returned function stops the routing.
+func routeThroughTailnet(ctx context.Context, page *rod.Page, targetHost string) (func(), error) {
+	router := page.HijackRequests()
+	err := router.Add("*", "", func(h *rod.Hijack) {
+		host := h.Request.URL().Hostname()
+		if !strings.EqualFold(host, targetHost) && !isTailnetHost(host) {
+			h.ContinueRequest(&proto.FetchContinueRequest{})
+			return
+		}
+
+		h.Request.SetContext(ctx)
+		// The body is handed to the browser as is, so it must not stay compressed
+		h.Request.Req().Header.Del("Accept-Encoding")
+		if err := h.LoadResponse(tailnetClient, true); err != nil {
+			h.Response.Fail(proto.NetworkErrorReasonConnectionFailed)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	go router.Run()
+	return func() { _ = router.Stop() }, nil
+}
+
+// tailnetURL reports whether targetURL points into the tailnet
+func tailnetURL(targetURL string) bool {
+	parsed, err := url.Parse(targetURL)
+	return err == nil && isTailnetHost(parsed.Hostname())
+}
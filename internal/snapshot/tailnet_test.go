@@ -0,0 +1,26 @@
+package snapshot
+
+import "testing"
+
+func TestIsTailnetHost(t *testing.T) {
+	tests := []struct {
+		host string
+		want bool
+	}{
+		{"grafana.tail1234.ts.net", true},
+		{"GRAFANA.TAIL1234.TS.NET.", true},
+		{"100.64.0.1", true},
+		{"100.127.255.254", true},
+		{"100.128.0.1", false},
+		{"100.1.2.3", false},
+		{"example.com", false},
+		{"ts.net.example.com", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := isTailnetHost(tt.host); got != tt.want {
+			t.Errorf("isTailnetHost(%q) = %v, want %v", tt.host, got, tt.want)
+		}
+	}
+}
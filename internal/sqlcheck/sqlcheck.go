@@ -0,0 +1,135 @@
+// Package sqlcheck runs the query of a PostgreSQL or MySQL check and asserts on its
+// rows, for checks run by the server and by probes.
+package sqlcheck
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"gocheck/internal/compare"
+)
+
+const (
+	// maxRows bounds the rows kept for assertions; the rest are only counted
+	maxRows = 1000
+
+	// previewRows is how many rows Preview shows
+	previewRows = 10
+
+	// null is how NULL values are compared and shown
+	null = "NULL"
+)
+
+// Assertion is what a check expects of its query's result
+type Assertion struct {
+	// RowCount, when set, is the exact number of rows the query must return
+	RowCount *int
+	// Column is compared with Expected in every row; the first column when empty
+	Column string
+	// Operator and Expected are those of compare.Match; an empty Expected skips the
+	// comparison
+	Operator string
+	Expected string
+}
+
+// Result holds the rows of a query as strings
+type Result struct {
+	Columns  []string
+	Rows     [][]string // the first maxRows rows
+	RowCount int        // every row returned
+}
+
+// Query runs query and reads its rows
+func Query(ctx context.Context, db *sql.DB, query string) (*Result, error) {
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	result := &Result{Columns: columns}
+	values := make([]sql.NullString, len(columns))
+	dest := make([]any, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	for rows.Next() {
+		result.RowCount++
+		if len(result.Rows) == maxRows {
+			continue
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		row := make([]string, len(values))
+		for i, v := range values {
+			row[i] = null
+			if v.Valid {
+				row[i] = v.String
+			}
+		}
+		result.Rows = append(result.Rows, row)
+	}
+	return result, rows.Err()
+}
+
+// Check returns why the result fails the assertion, or nil when it passes. Without a
+// row count to expect, a query must return at least one row.
+func (r *Result) Check(a Assertion) error {
+	if a.RowCount != nil && r.RowCount != *a.RowCount {
+		return fmt.Errorf("expected %d rows, got %d", *a.RowCount, r.RowCount)
+	}
+	if a.RowCount == nil && r.RowCount == 0 {
+		return fmt.Errorf("query returned no rows")
+	}
+
+	column := 0
+	if a.Column != "" {
+		column = slices.Index(r.Columns, a.Column)
+		if column < 0 {
+			return fmt.Errorf("query has no column %q (columns: %s)", a.Column, strings.Join(r.Columns, ", "))
+		}
+	}
+	if a.Expected == "" {
+		return nil
+	}
+	for i, row := range r.Rows {
+		if compare.Match(row[column], a.Operator, a.Expected) {
+			continue
+		}
+		mismatch := compare.Mismatch(row[column], a.Operator, a.Expected)
+		if len(r.Rows) > 1 {
+			mismatch = fmt.Sprintf("row %d: %s", i+1, mismatch)
+		}
+		return errors.New(mismatch)
+	}
+	return nil
+}
+
+// Preview formats the result for a check's response body. A single value is shown as
+// is; anything else as tab-separated columns, the first rows and the row count.
+func (r *Result) Preview() string {
+	if len(r.Columns) == 1 && r.RowCount == 1 {
+		return r.Rows[0][0]
+	}
+
+	var b strings.Builder
+	b.WriteString(strings.Join(r.Columns, "\t"))
+	for _, row := range r.Rows[:min(len(r.Rows), previewRows)] {
+		b.WriteString("\n")
+		b.WriteString(strings.Join(row, "\t"))
+	}
+	if r.RowCount > previewRows {
+		b.WriteString("\n...")
+	}
+	fmt.Fprintf(&b, "\n(%d rows)", r.RowCount)
+	return b.String()
+}
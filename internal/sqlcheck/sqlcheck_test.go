@@ -0,0 +1,59 @@
+package sqlcheck
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	zero, two := 0, 2
+	single := &Result{Columns: []string{"count"}, Rows: [][]string{{"42"}}, RowCount: 1}
+	jobs := &Result{
+		Columns:  []string{"id", "status"},
+		Rows:     [][]string{{"1", "ok"}, {"2", "failed"}},
+		RowCount: 2,
+	}
+	empty := &Result{Columns: []string{"id"}, RowCount: 0}
+
+	for _, tc := range []struct {
+		name   string
+		result *Result
+		a      Assertion
+		want   string // substring of the failure, empty for a pass
+	}{
+		{"single value", single, Assertion{Expected: "42"}, ""},
+		{"single value operator", single, Assertion{Operator: "gt", Expected: "50"}, "expected value > '50', got '42'"},
+		{"no rows", empty, Assertion{}, "no rows"},
+		{"zero rows expected", empty, Assertion{RowCount: &zero}, ""},
+		{"row count", jobs, Assertion{RowCount: &two}, ""},
+		{"wrong row count", jobs, Assertion{RowCount: &zero}, "expected 0 rows, got 2"},
+		{"named column", jobs, Assertion{Column: "status", Expected: "ok"}, "row 2: expected 'ok', got 'failed'"},
+		{"unknown column", jobs, Assertion{Column: "state", Expected: "ok"}, `no column "state"`},
+		{"first column", jobs, Assertion{Operator: "lte", Expected: "2"}, ""},
+	} {
+		err := tc.result.Check(tc.a)
+		switch {
+		case tc.want == "" && err != nil:
+			t.Errorf("%s: %v", tc.name, err)
+		case tc.want != "" && (err == nil || !strings.Contains(err.Error(), tc.want)):
+			t.Errorf("%s: error %v, want %q", tc.name, err, tc.want)
+		}
+	}
+}
+
+func TestPreview(t *testing.T) {
+	single := &Result{Columns: []string{"count"}, Rows: [][]string{{"42"}}, RowCount: 1}
+	if got := single.Preview(); got != "42" {
+		t.Errorf("single value preview = %q", got)
+	}
+
+	jobs := &Result{Columns: []string{"id", "status"}, RowCount: 12}
+	for i := 0; i < 12; i++ {
+		jobs.Rows = append(jobs.Rows, []string{"1", null})
+	}
+	got := jobs.Preview()
+	lines := strings.Split(got, "\n")
+	if lines[0] != "id\tstatus" || lines[1] != "1\tNULL" || len(lines) != 1+previewRows+2 || lines[len(lines)-1] != "(12 rows)" {
+		t.Errorf("preview = %q", got)
+	}
+}
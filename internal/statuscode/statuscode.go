@@ -0,0 +1,113 @@
+// Package statuscode parses and matches the expected HTTP status codes of a check,
+// given as exact codes, ranges such as "200-204" or classes such as "2xx".
+package statuscode
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Bounds of a valid HTTP status code
+const (
+	Min = 100
+	Max = 599
+)
+
+// Parse expands specs into a sorted list of distinct codes. Each spec is a code, a
+// range "200-299", a class "2xx", or several of those separated by commas.
+func Parse(specs []string) ([]int, error) {
+	seen := make(map[int]bool)
+	for _, spec := range specs {
+		for _, part := range strings.Split(spec, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			start, end, err := parseRange(part)
+			if err != nil {
+				return nil, err
+			}
+			for code := start; code <= end; code++ {
+				seen[code] = true
+			}
+		}
+	}
+
+	codes := make([]int, 0, len(seen))
+	for code := range seen {
+		codes = append(codes, code)
+	}
+	slices.Sort(codes)
+	return codes, nil
+}
+
+func parseRange(spec string) (start, end int, err error) {
+	if class, ok := strings.CutSuffix(strings.ToLower(spec), "xx"); ok {
+		digit, err := strconv.Atoi(class)
+		if err != nil || digit < 1 || digit > 5 {
+			return 0, 0, fmt.Errorf("invalid status code class %q, expected 1xx to 5xx", spec)
+		}
+		return digit * 100, digit*100 + 99, nil
+	}
+
+	from, to, isRange := strings.Cut(spec, "-")
+	start, err = parseCode(from)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !isRange {
+		return start, start, nil
+	}
+	end, err = parseCode(to)
+	if err != nil {
+		return 0, 0, err
+	}
+	if start > end {
+		return 0, 0, fmt.Errorf("invalid status code range %q, the start is after the end", spec)
+	}
+	return start, end, nil
+}
+
+func parseCode(s string) (int, error) {
+	code, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || code < Min || code > Max {
+		return 0, fmt.Errorf("invalid status code %q, expected %d to %d", strings.TrimSpace(s), Min, Max)
+	}
+	return code, nil
+}
+
+// Match reports whether code is one of expected. With nothing expected, any 2xx or
+// 3xx code matches.
+func Match(code int, expected []int) bool {
+	if len(expected) == 0 {
+		return code >= 200 && code < 400
+	}
+	return slices.Contains(expected, code)
+}
+
+// Format writes codes compactly, collapsing runs into ranges: "200-204, 301"
+func Format(codes []int) string {
+	if len(codes) == 0 {
+		return "2xx or 3xx"
+	}
+	sorted := slices.Clone(codes)
+	slices.Sort(sorted)
+	sorted = slices.Compact(sorted)
+
+	var parts []string
+	for i := 0; i < len(sorted); {
+		j := i
+		for j+1 < len(sorted) && sorted[j+1] == sorted[j]+1 {
+			j++
+		}
+		if j > i {
+			parts = append(parts, fmt.Sprintf("%d-%d", sorted[i], sorted[j]))
+		} else {
+			parts = append(parts, strconv.Itoa(sorted[i]))
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,62 @@
+package statuscode
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		specs []string
+		want  []int
+	}{
+		{[]string{"200"}, []int{200}},
+		{[]string{"200-204"}, []int{200, 201, 202, 203, 204}},
+		{[]string{"301, 200-201", "200"}, []int{200, 201, 301}},
+		{[]string{"1XX"}, nil},
+		{[]string{""}, []int{}},
+	}
+
+	for _, tt := range tests {
+		got, err := Parse(tt.specs)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.specs, err)
+		}
+		if tt.want == nil {
+			if len(got) != 100 || got[0] != 100 || got[99] != 199 {
+				t.Errorf("Parse(%q) = %v, want 100 to 199", tt.specs, got)
+			}
+			continue
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("Parse(%q) = %v, want %v", tt.specs, got, tt.want)
+		}
+	}
+
+	for _, spec := range []string{"abc", "99", "600", "204-200", "6xx", "2-xx", "200-"} {
+		if _, err := Parse([]string{spec}); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", spec)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	if !Match(302, nil) || Match(404, nil) {
+		t.Error("with nothing expected, only 2xx and 3xx should match")
+	}
+	if Match(302, []int{200}) {
+		t.Error("302 matched [200]")
+	}
+	if !Match(404, []int{200, 404}) {
+		t.Error("404 did not match [200 404]")
+	}
+}
+
+func TestFormat(t *testing.T) {
+	if got, want := Format([]int{301, 204, 200, 201, 202, 203, 200}), "200-204, 301"; got != want {
+		t.Errorf("Format = %q, want %q", got, want)
+	}
+	if got, want := Format(nil), "2xx or 3xx"; got != want {
+		t.Errorf("Format(nil) = %q, want %q", got, want)
+	}
+}
@@ -1,20 +1,26 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
 	"net"
 	"net/http"
 	"os"
+	"strconv"
+	"time"
 
 	"gocheck/internal/api"
 	"gocheck/internal/auth"
 	"gocheck/internal/checker"
 	"gocheck/internal/db"
+	"gocheck/internal/geoip"
+	"gocheck/internal/metrics"
 	grpc_server "gocheck/internal/grpc"
-	"gocheck/internal/notifier"
+	"gocheck/internal/grpctls"
 	"gocheck/internal/snapshot"
+	"gocheck/internal/tracing"
 	"gocheck/proto/pb"
 
 	"github.com/gorilla/mux"
@@ -29,6 +35,35 @@ type Config struct {
 	Database struct {
 		URL string `yaml:"url"`
 	} `yaml:"database"`
+	Checks struct {
+		MaxTimeoutSeconds int    `yaml:"max_timeout_seconds"`
+		SourceIP          string `yaml:"source_ip"`
+		MaxConcurrentChecks int  `yaml:"max_concurrent_checks"`
+	} `yaml:"checks"`
+	Snapshots struct {
+		Concurrency int `yaml:"concurrency"`
+	} `yaml:"snapshots"`
+	API struct {
+		MaxHistoryRows              int `yaml:"max_history_rows"`
+		GroupedChecksTimeoutSeconds int `yaml:"grouped_checks_timeout_seconds"`
+	} `yaml:"api"`
+	Probes struct {
+		DispatchConcurrency int    `yaml:"dispatch_concurrency"`
+		GeoIPDatabase       string `yaml:"geoip_database"`
+	} `yaml:"probes"`
+	GRPC struct {
+		TLSCertFile  string `yaml:"tls_cert_file"`
+		TLSKeyFile   string `yaml:"tls_key_file"`
+		ClientCAFile string `yaml:"client_ca_file"` // requires probes to present a certificate it signed
+		Insecure     bool   `yaml:"insecure"`       // serve without TLS, for local development
+	} `yaml:"grpc"`
+	Metrics struct {
+		Enabled bool `yaml:"enabled"`
+	} `yaml:"metrics"`
+	Rollups struct {
+		Enabled       bool `yaml:"enabled"`
+		RawWindowDays int  `yaml:"raw_window_days"`
+	} `yaml:"rollups"`
 }
 
 func loadConfig() (*Config, error) {
@@ -58,10 +93,123 @@ func loadConfig() (*Config, error) {
 	if dbURL := os.Getenv("DATABASE_URL"); dbURL != "" {
 		config.Database.URL = dbURL
 	}
+	if maxTimeout := os.Getenv("MAX_CHECK_TIMEOUT_SECONDS"); maxTimeout != "" {
+		if v, err := strconv.Atoi(maxTimeout); err == nil {
+			config.Checks.MaxTimeoutSeconds = v
+		}
+	}
+	if sourceIP := os.Getenv("CHECK_SOURCE_IP"); sourceIP != "" {
+		config.Checks.SourceIP = sourceIP
+	}
+	if maxConcurrent := os.Getenv("MAX_CONCURRENT_CHECKS"); maxConcurrent != "" {
+		if v, err := strconv.Atoi(maxConcurrent); err == nil {
+			config.Checks.MaxConcurrentChecks = v
+		}
+	}
+	if concurrency := os.Getenv("SNAPSHOT_CONCURRENCY"); concurrency != "" {
+		if v, err := strconv.Atoi(concurrency); err == nil {
+			config.Snapshots.Concurrency = v
+		}
+	}
+	if concurrency := os.Getenv("PROBE_DISPATCH_CONCURRENCY"); concurrency != "" {
+		if v, err := strconv.Atoi(concurrency); err == nil {
+			config.Probes.DispatchConcurrency = v
+		}
+	}
+	if geoipDB := os.Getenv("GEOIP_DATABASE"); geoipDB != "" {
+		config.Probes.GeoIPDatabase = geoipDB
+	}
+	if certFile := os.Getenv("GRPC_TLS_CERT_FILE"); certFile != "" {
+		config.GRPC.TLSCertFile = certFile
+	}
+	if keyFile := os.Getenv("GRPC_TLS_KEY_FILE"); keyFile != "" {
+		config.GRPC.TLSKeyFile = keyFile
+	}
+	if caFile := os.Getenv("GRPC_CLIENT_CA_FILE"); caFile != "" {
+		config.GRPC.ClientCAFile = caFile
+	}
+	if insecure := os.Getenv("GRPC_INSECURE"); insecure != "" {
+		if v, err := strconv.ParseBool(insecure); err == nil {
+			config.GRPC.Insecure = v
+		}
+	}
+	if enabled := os.Getenv("METRICS_ENABLED"); enabled != "" {
+		if v, err := strconv.ParseBool(enabled); err == nil {
+			config.Metrics.Enabled = v
+		}
+	}
+	if maxRows := os.Getenv("MAX_HISTORY_ROWS"); maxRows != "" {
+		if v, err := strconv.Atoi(maxRows); err == nil {
+			config.API.MaxHistoryRows = v
+		}
+	}
+	if timeout := os.Getenv("GROUPED_CHECKS_TIMEOUT_SECONDS"); timeout != "" {
+		if v, err := strconv.Atoi(timeout); err == nil {
+			config.API.GroupedChecksTimeoutSeconds = v
+		}
+	}
+	if enabled := os.Getenv("HISTORY_ROLLUPS_ENABLED"); enabled != "" {
+		if v, err := strconv.ParseBool(enabled); err == nil {
+			config.Rollups.Enabled = v
+		}
+	}
+	if window := os.Getenv("HISTORY_RAW_WINDOW_DAYS"); window != "" {
+		if v, err := strconv.Atoi(window); err == nil {
+			config.Rollups.RawWindowDays = v
+		}
+	}
+	// The rollup job recomputes the latest daily bucket, so raw rows must outlive it
+	if config.Rollups.RawWindowDays > 0 && config.Rollups.RawWindowDays < minRawWindowDays {
+		config.Rollups.RawWindowDays = minRawWindowDays
+	}
 
 	return &config, nil
 }
 
+// minRawWindowDays is the shortest raw history window allowed while rollups are enabled
+const minRawWindowDays = 2
+
+// rollupHistory summarises raw history into the hourly and daily rollup tables every
+// hour. With rawWindowDays set, raw rows older than the window are deleted once they
+// have been rolled up.
+func rollupHistory(database *db.Database, rawWindowDays int) {
+	ticker := time.NewTicker(1 * time.Hour)
+	defer ticker.Stop()
+
+	for {
+		failed := false
+		for _, resolution := range []int{db.RollupHourly, db.RollupDaily} {
+			if err := database.RollupHistory(resolution); err != nil {
+				log.Printf("History rollup (%d minutes) failed: %v", resolution, err)
+				failed = true
+			}
+		}
+		if !failed && rawWindowDays > 0 {
+			if _, err := database.DeleteHistoryBefore(time.Now().AddDate(0, 0, -rawWindowDays)); err != nil {
+				log.Printf("Deleting raw history outside the rollup window failed: %v", err)
+			}
+		}
+		<-ticker.C
+	}
+}
+
+// pruneHistory applies the history retention at startup and then once a day. The
+// retention is read from the settings on every run, so changes need no restart.
+func pruneHistory(database *db.Database) {
+	ticker := time.NewTicker(24 * time.Hour)
+	defer ticker.Stop()
+
+	for {
+		deleted, err := database.PruneHistory(database.HistoryRetentionDays())
+		if err != nil {
+			log.Printf("History pruning failed: %v", err)
+		} else if deleted > 0 {
+			log.Printf("History pruning deleted %d rows", deleted)
+		}
+		<-ticker.C
+	}
+}
+
 func main() {
 	defer func() {
 		if r := recover(); r != nil {
@@ -76,6 +224,15 @@ func main() {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
+	shutdownTracing, err := tracing.Init(context.Background())
+	if err != nil {
+		log.Fatalf("Failed to initialize tracing: %v", err)
+	}
+	defer shutdownTracing(context.Background())
+	if tracing.Configured() {
+		log.Printf("Exporting traces over OTLP")
+	}
+
 	// Ensure data directory exists
 	dataDir := "./data"
 	if err := os.MkdirAll(dataDir, 0755); err != nil {
@@ -93,25 +250,20 @@ func main() {
 	log.Printf("Using TimescaleDB database")
 	defer database.Close()
 
-	// Load notification settings from database or environment variables
-	webhookURL := os.Getenv("DISCORD_WEBHOOK_URL")
-	if dbWebhook, err := database.GetSetting("discord_webhook_url"); err == nil && dbWebhook != "" {
-		webhookURL = dbWebhook
+	// Load notifiers from the database, moving notifier settings into notifier rows first
+	if err := api.MigrateNotifierSettings(database); err != nil {
+		log.Fatalf("Failed to migrate notifier settings: %v", err)
 	}
+	notifiers, enabledNotifiers := api.LoadNotifiers(database)
 
-	gotifyServerURL, _ := database.GetSetting("gotify_server_url")
-	gotifyToken, _ := database.GetSetting("gotify_token")
-
-	var notifiers []notifier.Notifier
-	if webhookURL != "" {
-		notifiers = append(notifiers, notifier.NewDiscordNotifier(webhookURL))
-	}
-	if gotifyServerURL != "" && gotifyToken != "" {
-		notifiers = append(notifiers, notifier.NewGotifyNotifier(gotifyServerURL, gotifyToken))
+	engine := checker.NewEngine(database, enabledNotifiers)
+	engine.SetLimits(checker.DefaultLimits.WithMaxTimeout(config.Checks.MaxTimeoutSeconds))
+	engine.SetMaxConcurrentChecks(config.Checks.MaxConcurrentChecks)
+	if err := engine.SetSourceIP(config.Checks.SourceIP); err != nil {
+		log.Fatalf("Invalid check source address %q: %v", config.Checks.SourceIP, err)
 	}
-
-	engine := checker.NewEngine(database, notifiers)
 	sentinelServer := grpc_server.NewSentinelServerWithEngine(database, engine)
+	sentinelServer.SetDispatchConcurrency(config.Probes.DispatchConcurrency)
 	engine.SetSentinelServer(sentinelServer)
 
 	if err := engine.Start(); err != nil {
@@ -119,12 +271,29 @@ func main() {
 	}
 	defer engine.Stop()
 
+	go pruneHistory(database)
+	if config.Rollups.Enabled {
+		go rollupHistory(database, config.Rollups.RawWindowDays)
+	}
+
 	snapshotService := snapshot.NewService(database, engine, dataDir)
+	snapshotService.SetConcurrency(config.Snapshots.Concurrency)
 	snapshotService.Start()
 	defer snapshotService.Stop()
 
 	handlers := api.NewHandlers(database, engine, notifiers, snapshotService, dataDir, sentinelServer)
+	handlers.SetMaxHistoryRows(config.API.MaxHistoryRows)
+	handlers.SetGroupedChecksTimeout(time.Duration(config.API.GroupedChecksTimeoutSeconds) * time.Second)
+	handlers.SetHistoryRollups(config.Rollups.Enabled)
+	if config.Probes.GeoIPDatabase != "" {
+		reader, err := geoip.Open(config.Probes.GeoIPDatabase)
+		if err != nil {
+			log.Fatalf("Failed to open GeoIP database %s: %v", config.Probes.GeoIPDatabase, err)
+		}
+		handlers.SetGeoIP(reader)
+	}
 	authManager := auth.NewAuthManager(database)
+	handlers.SetSessionLookup(authManager)
 
 	rpID := os.Getenv("WEBAUTHN_RP_ID")
 	if rpID == "" {
@@ -142,6 +311,20 @@ func main() {
 
 	auth.SetGlobalManagers(authManager, webAuthnManager)
 
+	// Probes send their token over this connection, so plaintext needs an explicit opt-in
+	var grpcOptions []grpc.ServerOption
+	if config.GRPC.TLSCertFile != "" || config.GRPC.TLSKeyFile != "" || config.GRPC.ClientCAFile != "" {
+		creds, err := grpctls.ServerCredentials(config.GRPC.TLSCertFile, config.GRPC.TLSKeyFile, config.GRPC.ClientCAFile)
+		if err != nil {
+			log.Fatalf("Invalid gRPC TLS configuration: %v", err)
+		}
+		grpcOptions = append(grpcOptions, grpc.Creds(creds))
+	} else if config.GRPC.Insecure {
+		log.Printf("WARNING: gRPC server is running without TLS; probe tokens are sent in plaintext")
+	} else {
+		log.Fatalf("gRPC TLS is not configured. Set grpc.tls_cert_file and grpc.tls_key_file (GRPC_TLS_CERT_FILE, GRPC_TLS_KEY_FILE), or grpc.insecure (GRPC_INSECURE=true) for local development")
+	}
+
 	go func() {
 		grpcPort := os.Getenv("GRPC_PORT")
 		if grpcPort == "" {
@@ -151,7 +334,7 @@ func main() {
 		if err != nil {
 			log.Fatalf("Failed to listen on gRPC port %s: %v", grpcPort, err)
 		}
-		s := grpc.NewServer()
+		s := grpc.NewServer(grpcOptions...)
 		pb.RegisterSentinelServer(s, sentinelServer)
 		log.Printf("gRPC server starting on :%s", grpcPort)
 		if err := s.Serve(lis); err != nil {
@@ -181,40 +364,94 @@ func main() {
 	router.HandleFunc("/api/auth/apikeys", authManager.CreateAPIKey).Methods("POST")
 	router.HandleFunc("/api/auth/apikeys", authManager.DeleteAPIKey).Methods("DELETE")
 
-	// Protected routes
-	router.HandleFunc("/api/checks", authManager.OptionalAuth(handlers.GetChecks)).Methods("GET")
-	router.HandleFunc("/api/checks", authManager.OptionalAuth(handlers.CreateCheck)).Methods("POST")
-	router.HandleFunc("/api/checks/{id}", authManager.OptionalAuth(handlers.UpdateCheck)).Methods("PUT")
-	router.HandleFunc("/api/checks/{id}", authManager.OptionalAuth(handlers.DeleteCheck)).Methods("DELETE")
-	router.HandleFunc("/api/checks/{id}/history", authManager.OptionalAuth(handlers.GetCheckHistory)).Methods("GET")
-	router.HandleFunc("/api/checks/{id}/stats", authManager.OptionalAuth(handlers.GetCheckStats)).Methods("GET")
-	router.HandleFunc("/api/checks/{id}/snapshot", authManager.OptionalAuth(handlers.GetCheckSnapshot)).Methods("GET")
+	// User management (admins only)
+	router.HandleFunc("/api/users", authManager.RequireAdmin(authManager.GetUsers)).Methods("GET")
+	router.HandleFunc("/api/users", authManager.RequireAdmin(authManager.CreateUser)).Methods("POST")
+	router.HandleFunc("/api/users/{id}", authManager.RequireAdmin(authManager.UpdateUser)).Methods("PUT")
+	router.HandleFunc("/api/users/{id}", authManager.RequireAdmin(authManager.DeleteUser)).Methods("DELETE")
+
+	// Protected routes: viewers may read, but changes and settings (which hold
+	// notifier secrets) are for admins. Polled reads are wrapped in api.ETag; streams,
+	// downloads and mutations are not
+	router.HandleFunc("/api/checks", authManager.OptionalAuth(api.ETag(handlers.GetChecks))).Methods("GET")
+	router.HandleFunc("/api/checks", authManager.OptionalAdmin(handlers.CreateCheck)).Methods("POST")
+	router.HandleFunc("/api/checks/import", authManager.OptionalAdmin(handlers.ImportChecks)).Methods("POST")
+	router.HandleFunc("/api/checks/apply", authManager.OptionalAdmin(handlers.ApplyChecks)).Methods("POST")
+	router.HandleFunc("/api/checks/validate", authManager.OptionalAdmin(handlers.ValidateCheck)).Methods("POST")
+	router.HandleFunc("/api/checks/bulk", authManager.OptionalAdmin(handlers.BulkUpdateChecks)).Methods("POST")
+	router.HandleFunc("/api/checks/{id}", authManager.OptionalAdmin(handlers.UpdateCheck)).Methods("PUT")
+	router.HandleFunc("/api/checks/{id}", authManager.OptionalAdmin(handlers.DeleteCheck)).Methods("DELETE")
+	router.HandleFunc("/api/checks/{id}/clone", authManager.OptionalAdmin(handlers.CloneCheck)).Methods("POST")
+	router.HandleFunc("/api/checks/{id}/history", authManager.OptionalAuth(api.ETag(handlers.GetCheckHistory))).Methods("GET")
+	router.HandleFunc("/api/checks/{id}/history.csv", authManager.OptionalAuth(handlers.GetCheckHistoryCSV)).Methods("GET")
+	router.HandleFunc("/api/checks/{id}/stats", authManager.OptionalAuth(api.ETag(handlers.GetCheckStats))).Methods("GET")
+	router.HandleFunc("/api/checks/{id}/sla", authManager.OptionalAuth(api.ETag(handlers.GetCheckSLA))).Methods("GET")
+	router.HandleFunc("/api/checks/{id}/regions", authManager.OptionalAuth(api.ETag(handlers.GetCheckRegions))).Methods("GET")
+	router.HandleFunc("/api/checks/{id}/regions", authManager.OptionalAdmin(handlers.SetCheckRegions)).Methods("PUT")
+	router.HandleFunc("/api/checks/{id}/snapshot", authManager.OptionalAuth(api.ETag(handlers.GetCheckSnapshot))).Methods("GET")
 	router.HandleFunc("/api/checks/{id}/snapshot/image", authManager.OptionalAuth(handlers.GetCheckSnapshotImage)).Methods("GET")
-	router.HandleFunc("/api/checks/{id}/snapshot/trigger", authManager.OptionalAuth(handlers.TriggerCheckSnapshot)).Methods("POST")
-	router.HandleFunc("/api/checks/{id}/trigger", authManager.OptionalAuth(handlers.TriggerCheck)).Methods("POST")
-	router.HandleFunc("/api/checks/{id}/trigger/{region}", authManager.OptionalAuth(handlers.TriggerCheckForRegion)).Methods("POST")
-	router.HandleFunc("/api/checks/grouped", authManager.OptionalAuth(handlers.GetGroupedChecks)).Methods("GET")
+	router.HandleFunc("/api/checks/{id}/snapshot/trigger", authManager.OptionalAdmin(handlers.TriggerCheckSnapshot)).Methods("POST")
+	router.HandleFunc("/api/checks/{id}/trigger", authManager.OptionalAdmin(handlers.TriggerCheck)).Methods("POST")
+	router.HandleFunc("/api/checks/{id}/run", authManager.OptionalAdmin(handlers.TriggerCheck)).Methods("POST")
+	// Signed with the check's trigger secret instead of a session, for CI and deploy pipelines
+	router.HandleFunc("/api/checks/{id}/trigger/webhook", handlers.TriggerCheckWebhook).Methods("POST")
+	router.HandleFunc("/api/checks/{id}/trigger/all", authManager.OptionalAdmin(handlers.TriggerCheckAllRegions)).Methods("POST")
+	router.HandleFunc("/api/checks/{id}/trigger/{region}", authManager.OptionalAdmin(handlers.TriggerCheckForRegion)).Methods("POST")
+	router.HandleFunc("/api/snapshots/refresh", authManager.OptionalAdmin(handlers.RefreshSnapshots)).Methods("POST")
+	router.HandleFunc("/api/snapshots/refresh", authManager.OptionalAuth(api.ETag(handlers.GetSnapshotRefreshStatus))).Methods("GET")
+	router.HandleFunc("/api/checks/grouped", authManager.OptionalAuth(api.ETag(handlers.GetGroupedChecks))).Methods("GET")
 	router.HandleFunc("/api/stream/updates", authManager.OptionalAuth(handlers.StreamCheckUpdates)).Methods("GET")
-	router.HandleFunc("/api/stats", authManager.OptionalAuth(handlers.GetStats)).Methods("GET")
-	router.HandleFunc("/api/settings", authManager.OptionalAuth(handlers.GetSettings)).Methods("GET")
-	router.HandleFunc("/api/settings", authManager.OptionalAuth(handlers.UpdateSettings)).Methods("PUT")
-	router.HandleFunc("/api/settings/test-webhook", authManager.OptionalAuth(handlers.TestWebhook)).Methods("POST")
-	router.HandleFunc("/api/settings/test-gotify", authManager.OptionalAuth(handlers.TestGotify)).Methods("POST")
-	router.HandleFunc("/api/settings/test-tailscale", authManager.OptionalAuth(handlers.TestTailscale)).Methods("POST")
-	router.HandleFunc("/api/settings/test-browserless", authManager.OptionalAuth(handlers.TestBrowserless)).Methods("POST")
-	router.HandleFunc("/api/tailscale/devices", authManager.OptionalAuth(handlers.GetTailscaleDevices)).Methods("GET")
-	router.HandleFunc("/api/groups", authManager.OptionalAuth(handlers.GetGroups)).Methods("GET")
-	router.HandleFunc("/api/groups", authManager.OptionalAuth(handlers.CreateGroup)).Methods("POST")
-	router.HandleFunc("/api/groups/{id}", authManager.OptionalAuth(handlers.UpdateGroup)).Methods("PUT")
-	router.HandleFunc("/api/groups/{id}", authManager.OptionalAuth(handlers.DeleteGroup)).Methods("DELETE")
-	router.HandleFunc("/api/tags", authManager.OptionalAuth(handlers.GetTags)).Methods("GET")
-	router.HandleFunc("/api/tags", authManager.OptionalAuth(handlers.CreateTag)).Methods("POST")
-	router.HandleFunc("/api/tags/{id}", authManager.OptionalAuth(handlers.UpdateTag)).Methods("PUT")
-	router.HandleFunc("/api/tags/{id}", authManager.OptionalAuth(handlers.DeleteTag)).Methods("DELETE")
-	router.HandleFunc("/api/probes", authManager.OptionalAuth(handlers.GetProbes)).Methods("GET")
-	router.HandleFunc("/api/probes", authManager.OptionalAuth(handlers.CreateProbe)).Methods("POST")
-	router.HandleFunc("/api/probes/{id}", authManager.OptionalAuth(handlers.DeleteProbe)).Methods("DELETE")
-	router.HandleFunc("/api/probes/{id}/regenerate-token", authManager.OptionalAuth(handlers.RegenerateProbeToken)).Methods("POST")
+	router.HandleFunc("/api/stream/ws", authManager.OptionalAuth(handlers.StreamCheckUpdatesWS)).Methods("GET")
+	router.HandleFunc("/api/stats", authManager.OptionalAuth(api.ETag(handlers.GetStats))).Methods("GET")
+	router.HandleFunc("/api/dashboard", authManager.OptionalAuth(api.ETag(handlers.GetDashboard))).Methods("GET")
+	router.HandleFunc("/api/events", authManager.OptionalAuth(api.ETag(handlers.GetEvents))).Methods("GET")
+	router.HandleFunc("/api/incidents", authManager.OptionalAuth(api.ETag(handlers.GetIncidents))).Methods("GET")
+	router.HandleFunc("/api/monitoring", authManager.OptionalAuth(api.ETag(handlers.GetMonitoringStatus))).Methods("GET")
+	router.HandleFunc("/api/monitoring", authManager.OptionalAdmin(handlers.UpdateMonitoringStatus)).Methods("PUT")
+	// Incident feeds only include checks marked public, so they are served without auth
+	router.HandleFunc("/api/feed.atom", api.ETag(handlers.GetAtomFeed)).Methods("GET")
+	router.HandleFunc("/api/feed.rss", api.ETag(handlers.GetRSSFeed)).Methods("GET")
+	// Orchestrator probes can't log in either
+	router.HandleFunc("/healthz", handlers.Healthz).Methods("GET", "HEAD")
+	router.HandleFunc("/readyz", handlers.Readyz).Methods("GET", "HEAD")
+	// Prometheus scrapes can't log in, so metrics are unauthenticated and opt-in
+	if config.Metrics.Enabled {
+		router.Handle("/metrics", metrics.Default.Handler()).Methods("GET")
+	}
+	router.HandleFunc("/api/settings", authManager.OptionalAdmin(handlers.GetSettings)).Methods("GET")
+	router.HandleFunc("/api/settings", authManager.OptionalAdmin(handlers.UpdateSettings)).Methods("PUT")
+	router.HandleFunc("/api/notifiers", authManager.OptionalAdmin(handlers.GetNotifiers)).Methods("GET")
+	router.HandleFunc("/api/notifiers", authManager.OptionalAdmin(handlers.CreateNotifier)).Methods("POST")
+	router.HandleFunc("/api/notifiers/{id}", authManager.OptionalAdmin(handlers.UpdateNotifier)).Methods("PUT")
+	router.HandleFunc("/api/notifiers/{id}", authManager.OptionalAdmin(handlers.DeleteNotifier)).Methods("DELETE")
+	router.HandleFunc("/api/notifiers/{id}/test", authManager.OptionalAdmin(handlers.TestNotifier)).Methods("POST")
+	router.HandleFunc("/api/audit", authManager.OptionalAdmin(handlers.GetAuditLog)).Methods("GET")
+	router.HandleFunc("/api/export", authManager.OptionalAdmin(handlers.ExportConfig)).Methods("GET")
+	router.HandleFunc("/api/settings/test-webhook", authManager.OptionalAdmin(handlers.TestWebhook)).Methods("POST")
+	router.HandleFunc("/api/settings/test-gotify", authManager.OptionalAdmin(handlers.TestGotify)).Methods("POST")
+	router.HandleFunc("/api/settings/test-slack", authManager.OptionalAdmin(handlers.TestSlack)).Methods("POST")
+	router.HandleFunc("/api/settings/test-email", authManager.OptionalAdmin(handlers.TestEmail)).Methods("POST")
+	router.HandleFunc("/api/settings/test-generic-webhook", authManager.OptionalAdmin(handlers.TestGenericWebhook)).Methods("POST")
+	router.HandleFunc("/api/settings/test-opsgenie", authManager.OptionalAdmin(handlers.TestOpsgenie)).Methods("POST")
+	router.HandleFunc("/api/settings/test-pagerduty", authManager.OptionalAdmin(handlers.TestPagerDuty)).Methods("POST")
+	router.HandleFunc("/api/settings/test-pushover", authManager.OptionalAdmin(handlers.TestPushover)).Methods("POST")
+	router.HandleFunc("/api/settings/test-apprise", authManager.OptionalAdmin(handlers.TestApprise)).Methods("POST")
+	router.HandleFunc("/api/settings/test-tailscale", authManager.OptionalAdmin(handlers.TestTailscale)).Methods("POST")
+	router.HandleFunc("/api/settings/test-browserless", authManager.OptionalAdmin(handlers.TestBrowserless)).Methods("POST")
+	router.HandleFunc("/api/tailscale/devices", authManager.OptionalAdmin(handlers.GetTailscaleDevices)).Methods("GET")
+	router.HandleFunc("/api/groups", authManager.OptionalAuth(api.ETag(handlers.GetGroups))).Methods("GET")
+	router.HandleFunc("/api/groups", authManager.OptionalAdmin(handlers.CreateGroup)).Methods("POST")
+	router.HandleFunc("/api/groups/{id}", authManager.OptionalAdmin(handlers.UpdateGroup)).Methods("PUT")
+	router.HandleFunc("/api/groups/{id}", authManager.OptionalAdmin(handlers.DeleteGroup)).Methods("DELETE")
+	router.HandleFunc("/api/tags", authManager.OptionalAuth(api.ETag(handlers.GetTags))).Methods("GET")
+	router.HandleFunc("/api/tags", authManager.OptionalAdmin(handlers.CreateTag)).Methods("POST")
+	router.HandleFunc("/api/tags/{id}", authManager.OptionalAdmin(handlers.UpdateTag)).Methods("PUT")
+	router.HandleFunc("/api/tags/{id}", authManager.OptionalAdmin(handlers.DeleteTag)).Methods("DELETE")
+	router.HandleFunc("/api/tags/{id}/checks", authManager.OptionalAdmin(handlers.UpdateTagChecks)).Methods("POST")
+	router.HandleFunc("/api/probes", authManager.OptionalAuth(api.ETag(handlers.GetProbes))).Methods("GET")
+	router.HandleFunc("/api/probes", authManager.OptionalAdmin(handlers.CreateProbe)).Methods("POST")
+	router.HandleFunc("/api/probes/{id}", authManager.OptionalAdmin(handlers.DeleteProbe)).Methods("DELETE")
+	router.HandleFunc("/api/probes/{id}/regenerate-token", authManager.OptionalAdmin(handlers.RegenerateProbeToken)).Methods("POST")
 
 	// Serve static files from web/dist (built frontend)
 	// In development, run the Vite dev server separately
@@ -238,5 +475,5 @@ func main() {
 
 	addr := ":" + config.Server.Port
 	log.Printf("Server starting on http://localhost%s", addr)
-	log.Fatal(http.ListenAndServe(addr, router))
+	log.Fatal(http.ListenAndServe(addr, api.Gzip(router)))
 }
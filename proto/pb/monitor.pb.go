@@ -179,6 +179,7 @@ type CheckResult struct {
 	LatencyMs     int32                  `protobuf:"varint,4,opt,name=latency_ms,json=latencyMs,proto3" json:"latency_ms,omitempty"`
 	Success       bool                   `protobuf:"varint,5,opt,name=success,proto3" json:"success,omitempty"`
 	ErrorMessage  string                 `protobuf:"bytes,6,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
+	RequestId     string                 `protobuf:"bytes,7,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -255,6 +256,13 @@ func (x *CheckResult) GetErrorMessage() string {
 	return ""
 }
 
+func (x *CheckResult) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
 type Heartbeat struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Timestamp     int64                  `protobuf:"varint,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
@@ -300,24 +308,67 @@ func (x *Heartbeat) GetTimestamp() int64 {
 }
 
 type ServerCommand struct {
-	state              protoimpl.MessageState `protogen:"open.v1"`
-	CommandType        string                 `protobuf:"bytes,1,opt,name=command_type,json=commandType,proto3" json:"command_type,omitempty"`
-	CheckId            int64                  `protobuf:"varint,2,opt,name=check_id,json=checkId,proto3" json:"check_id,omitempty"`
-	CheckType          string                 `protobuf:"bytes,3,opt,name=check_type,json=checkType,proto3" json:"check_type,omitempty"`
-	Url                string                 `protobuf:"bytes,4,opt,name=url,proto3" json:"url,omitempty"`
-	Host               string                 `protobuf:"bytes,5,opt,name=host,proto3" json:"host,omitempty"`
-	PostgresConnString string                 `protobuf:"bytes,6,opt,name=postgres_conn_string,json=postgresConnString,proto3" json:"postgres_conn_string,omitempty"`
-	PostgresQuery      string                 `protobuf:"bytes,7,opt,name=postgres_query,json=postgresQuery,proto3" json:"postgres_query,omitempty"`
-	ExpectedQueryValue string                 `protobuf:"bytes,8,opt,name=expected_query_value,json=expectedQueryValue,proto3" json:"expected_query_value,omitempty"`
-	DnsHostname        string                 `protobuf:"bytes,9,opt,name=dns_hostname,json=dnsHostname,proto3" json:"dns_hostname,omitempty"`
-	DnsRecordType      string                 `protobuf:"bytes,10,opt,name=dns_record_type,json=dnsRecordType,proto3" json:"dns_record_type,omitempty"`
-	ExpectedDnsValue   string                 `protobuf:"bytes,11,opt,name=expected_dns_value,json=expectedDnsValue,proto3" json:"expected_dns_value,omitempty"`
-	Method             string                 `protobuf:"bytes,12,opt,name=method,proto3" json:"method,omitempty"`
-	TimeoutSeconds     int32                  `protobuf:"varint,13,opt,name=timeout_seconds,json=timeoutSeconds,proto3" json:"timeout_seconds,omitempty"`
-	JsonPath           string                 `protobuf:"bytes,14,opt,name=json_path,json=jsonPath,proto3" json:"json_path,omitempty"`
-	ExpectedJsonValue  string                 `protobuf:"bytes,15,opt,name=expected_json_value,json=expectedJsonValue,proto3" json:"expected_json_value,omitempty"`
-	unknownFields      protoimpl.UnknownFields
-	sizeCache          protoimpl.SizeCache
+	state                   protoimpl.MessageState `protogen:"open.v1"`
+	CommandType             string                 `protobuf:"bytes,1,opt,name=command_type,json=commandType,proto3" json:"command_type,omitempty"`
+	CheckId                 int64                  `protobuf:"varint,2,opt,name=check_id,json=checkId,proto3" json:"check_id,omitempty"`
+	CheckType               string                 `protobuf:"bytes,3,opt,name=check_type,json=checkType,proto3" json:"check_type,omitempty"`
+	Url                     string                 `protobuf:"bytes,4,opt,name=url,proto3" json:"url,omitempty"`
+	Host                    string                 `protobuf:"bytes,5,opt,name=host,proto3" json:"host,omitempty"`
+	PostgresConnString      string                 `protobuf:"bytes,6,opt,name=postgres_conn_string,json=postgresConnString,proto3" json:"postgres_conn_string,omitempty"`
+	PostgresQuery           string                 `protobuf:"bytes,7,opt,name=postgres_query,json=postgresQuery,proto3" json:"postgres_query,omitempty"`
+	ExpectedQueryValue      string                 `protobuf:"bytes,8,opt,name=expected_query_value,json=expectedQueryValue,proto3" json:"expected_query_value,omitempty"`
+	DnsHostname             string                 `protobuf:"bytes,9,opt,name=dns_hostname,json=dnsHostname,proto3" json:"dns_hostname,omitempty"`
+	DnsRecordType           string                 `protobuf:"bytes,10,opt,name=dns_record_type,json=dnsRecordType,proto3" json:"dns_record_type,omitempty"`
+	ExpectedDnsValue        string                 `protobuf:"bytes,11,opt,name=expected_dns_value,json=expectedDnsValue,proto3" json:"expected_dns_value,omitempty"`
+	Method                  string                 `protobuf:"bytes,12,opt,name=method,proto3" json:"method,omitempty"`
+	TimeoutSeconds          int32                  `protobuf:"varint,13,opt,name=timeout_seconds,json=timeoutSeconds,proto3" json:"timeout_seconds,omitempty"`
+	JsonPath                string                 `protobuf:"bytes,14,opt,name=json_path,json=jsonPath,proto3" json:"json_path,omitempty"`
+	ExpectedJsonValue       string                 `protobuf:"bytes,15,opt,name=expected_json_value,json=expectedJsonValue,proto3" json:"expected_json_value,omitempty"`
+	Port                    int32                  `protobuf:"varint,16,opt,name=port,proto3" json:"port,omitempty"`
+	ExpectedBanner          string                 `protobuf:"bytes,17,opt,name=expected_banner,json=expectedBanner,proto3" json:"expected_banner,omitempty"`
+	Headers                 map[string]string      `protobuf:"bytes,18,rep,name=headers,proto3" json:"headers,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	RequestBody             string                 `protobuf:"bytes,19,opt,name=request_body,json=requestBody,proto3" json:"request_body,omitempty"`
+	ContentType             string                 `protobuf:"bytes,20,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	ResponseKeyword         string                 `protobuf:"bytes,21,opt,name=response_keyword,json=responseKeyword,proto3" json:"response_keyword,omitempty"`
+	ResponseKeywordMode     string                 `protobuf:"bytes,22,opt,name=response_keyword_mode,json=responseKeywordMode,proto3" json:"response_keyword_mode,omitempty"`
+	NtpMaxOffsetMs          int32                  `protobuf:"varint,23,opt,name=ntp_max_offset_ms,json=ntpMaxOffsetMs,proto3" json:"ntp_max_offset_ms,omitempty"`
+	CertExpiryThresholdDays int32                  `protobuf:"varint,24,opt,name=cert_expiry_threshold_days,json=certExpiryThresholdDays,proto3" json:"cert_expiry_threshold_days,omitempty"`
+	InsecureSkipVerify      bool                   `protobuf:"varint,25,opt,name=insecure_skip_verify,json=insecureSkipVerify,proto3" json:"insecure_skip_verify,omitempty"`
+	ForbiddenBodyKeyword    string                 `protobuf:"bytes,26,opt,name=forbidden_body_keyword,json=forbiddenBodyKeyword,proto3" json:"forbidden_body_keyword,omitempty"`
+	ExpectUnreachable       bool                   `protobuf:"varint,27,opt,name=expect_unreachable,json=expectUnreachable,proto3" json:"expect_unreachable,omitempty"`
+	RequestId               string                 `protobuf:"bytes,28,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	MaxResponseTimeMs       int32                  `protobuf:"varint,29,opt,name=max_response_time_ms,json=maxResponseTimeMs,proto3" json:"max_response_time_ms,omitempty"`
+	BypassCache             bool                   `protobuf:"varint,30,opt,name=bypass_cache,json=bypassCache,proto3" json:"bypass_cache,omitempty"`
+	RedisConnString         string                 `protobuf:"bytes,31,opt,name=redis_conn_string,json=redisConnString,proto3" json:"redis_conn_string,omitempty"`
+	RedisKey                string                 `protobuf:"bytes,32,opt,name=redis_key,json=redisKey,proto3" json:"redis_key,omitempty"`
+	ExpectedRedisValue      string                 `protobuf:"bytes,33,opt,name=expected_redis_value,json=expectedRedisValue,proto3" json:"expected_redis_value,omitempty"`
+	MysqlConnString         string                 `protobuf:"bytes,34,opt,name=mysql_conn_string,json=mysqlConnString,proto3" json:"mysql_conn_string,omitempty"`
+	DnsResolver             string                 `protobuf:"bytes,35,opt,name=dns_resolver,json=dnsResolver,proto3" json:"dns_resolver,omitempty"`
+	PingCount               int32                  `protobuf:"varint,36,opt,name=ping_count,json=pingCount,proto3" json:"ping_count,omitempty"`
+	MaxPacketLoss           int32                  `protobuf:"varint,37,opt,name=max_packet_loss,json=maxPacketLoss,proto3" json:"max_packet_loss,omitempty"`
+	AuthType                string                 `protobuf:"bytes,38,opt,name=auth_type,json=authType,proto3" json:"auth_type,omitempty"`
+	AuthUsername            string                 `protobuf:"bytes,39,opt,name=auth_username,json=authUsername,proto3" json:"auth_username,omitempty"`
+	AuthPassword            string                 `protobuf:"bytes,40,opt,name=auth_password,json=authPassword,proto3" json:"auth_password,omitempty"`
+	AuthToken               string                 `protobuf:"bytes,41,opt,name=auth_token,json=authToken,proto3" json:"auth_token,omitempty"`
+	NoFollowRedirects       bool                   `protobuf:"varint,42,opt,name=no_follow_redirects,json=noFollowRedirects,proto3" json:"no_follow_redirects,omitempty"`
+	AssertionOperator       string                 `protobuf:"bytes,43,opt,name=assertion_operator,json=assertionOperator,proto3" json:"assertion_operator,omitempty"`
+	GrpcService             string                 `protobuf:"bytes,44,opt,name=grpc_service,json=grpcService,proto3" json:"grpc_service,omitempty"`
+	GrpcTls                 bool                   `protobuf:"varint,45,opt,name=grpc_tls,json=grpcTls,proto3" json:"grpc_tls,omitempty"`
+	SmtpStarttls            bool                   `protobuf:"varint,46,opt,name=smtp_starttls,json=smtpStarttls,proto3" json:"smtp_starttls,omitempty"`
+	ExpectedHeaderName      string                 `protobuf:"bytes,47,opt,name=expected_header_name,json=expectedHeaderName,proto3" json:"expected_header_name,omitempty"`
+	ExpectedHeaderValue     string                 `protobuf:"bytes,48,opt,name=expected_header_value,json=expectedHeaderValue,proto3" json:"expected_header_value,omitempty"`
+	ExpectedHeaderMode      string                 `protobuf:"bytes,49,opt,name=expected_header_mode,json=expectedHeaderMode,proto3" json:"expected_header_mode,omitempty"`
+	MongoConnString         string                 `protobuf:"bytes,50,opt,name=mongo_conn_string,json=mongoConnString,proto3" json:"mongo_conn_string,omitempty"`
+	MinResponseBytes        int32                  `protobuf:"varint,51,opt,name=min_response_bytes,json=minResponseBytes,proto3" json:"min_response_bytes,omitempty"`
+	MaxResponseBytes        int32                  `protobuf:"varint,52,opt,name=max_response_bytes,json=maxResponseBytes,proto3" json:"max_response_bytes,omitempty"`
+	ExpectedHttpVersion     string                 `protobuf:"bytes,53,opt,name=expected_http_version,json=expectedHttpVersion,proto3" json:"expected_http_version,omitempty"`
+	ProxyUrl                string                 `protobuf:"bytes,54,opt,name=proxy_url,json=proxyUrl,proto3" json:"proxy_url,omitempty"`
+	QueryColumn             string                 `protobuf:"bytes,55,opt,name=query_column,json=queryColumn,proto3" json:"query_column,omitempty"`
+	ExpectedRowCount        int32                  `protobuf:"varint,56,opt,name=expected_row_count,json=expectedRowCount,proto3" json:"expected_row_count,omitempty"`
+	AssertRowCount          bool                   `protobuf:"varint,57,opt,name=assert_row_count,json=assertRowCount,proto3" json:"assert_row_count,omitempty"`                       // expected_row_count is only asserted when set
+	ExpectedStatusCodes     []int32                `protobuf:"varint,58,rep,packed,name=expected_status_codes,json=expectedStatusCodes,proto3" json:"expected_status_codes,omitempty"` // http checks; empty accepts 2xx and 3xx
+	unknownFields           protoimpl.UnknownFields
+	sizeCache               protoimpl.SizeCache
 }
 
 func (x *ServerCommand) Reset() {
@@ -455,6 +506,307 @@ func (x *ServerCommand) GetExpectedJsonValue() string {
 	return ""
 }
 
+func (x *ServerCommand) GetPort() int32 {
+	if x != nil {
+		return x.Port
+	}
+	return 0
+}
+
+func (x *ServerCommand) GetExpectedBanner() string {
+	if x != nil {
+		return x.ExpectedBanner
+	}
+	return ""
+}
+
+func (x *ServerCommand) GetHeaders() map[string]string {
+	if x != nil {
+		return x.Headers
+	}
+	return nil
+}
+
+func (x *ServerCommand) GetRequestBody() string {
+	if x != nil {
+		return x.RequestBody
+	}
+	return ""
+}
+
+func (x *ServerCommand) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *ServerCommand) GetResponseKeyword() string {
+	if x != nil {
+		return x.ResponseKeyword
+	}
+	return ""
+}
+
+func (x *ServerCommand) GetResponseKeywordMode() string {
+	if x != nil {
+		return x.ResponseKeywordMode
+	}
+	return ""
+}
+
+func (x *ServerCommand) GetNtpMaxOffsetMs() int32 {
+	if x != nil {
+		return x.NtpMaxOffsetMs
+	}
+	return 0
+}
+
+func (x *ServerCommand) GetCertExpiryThresholdDays() int32 {
+	if x != nil {
+		return x.CertExpiryThresholdDays
+	}
+	return 0
+}
+
+func (x *ServerCommand) GetInsecureSkipVerify() bool {
+	if x != nil {
+		return x.InsecureSkipVerify
+	}
+	return false
+}
+
+func (x *ServerCommand) GetForbiddenBodyKeyword() string {
+	if x != nil {
+		return x.ForbiddenBodyKeyword
+	}
+	return ""
+}
+
+func (x *ServerCommand) GetExpectUnreachable() bool {
+	if x != nil {
+		return x.ExpectUnreachable
+	}
+	return false
+}
+
+func (x *ServerCommand) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *ServerCommand) GetMaxResponseTimeMs() int32 {
+	if x != nil {
+		return x.MaxResponseTimeMs
+	}
+	return 0
+}
+
+func (x *ServerCommand) GetBypassCache() bool {
+	if x != nil {
+		return x.BypassCache
+	}
+	return false
+}
+
+func (x *ServerCommand) GetRedisConnString() string {
+	if x != nil {
+		return x.RedisConnString
+	}
+	return ""
+}
+
+func (x *ServerCommand) GetRedisKey() string {
+	if x != nil {
+		return x.RedisKey
+	}
+	return ""
+}
+
+func (x *ServerCommand) GetExpectedRedisValue() string {
+	if x != nil {
+		return x.ExpectedRedisValue
+	}
+	return ""
+}
+
+func (x *ServerCommand) GetMysqlConnString() string {
+	if x != nil {
+		return x.MysqlConnString
+	}
+	return ""
+}
+
+func (x *ServerCommand) GetDnsResolver() string {
+	if x != nil {
+		return x.DnsResolver
+	}
+	return ""
+}
+
+func (x *ServerCommand) GetPingCount() int32 {
+	if x != nil {
+		return x.PingCount
+	}
+	return 0
+}
+
+func (x *ServerCommand) GetMaxPacketLoss() int32 {
+	if x != nil {
+		return x.MaxPacketLoss
+	}
+	return 0
+}
+
+func (x *ServerCommand) GetAuthType() string {
+	if x != nil {
+		return x.AuthType
+	}
+	return ""
+}
+
+func (x *ServerCommand) GetAuthUsername() string {
+	if x != nil {
+		return x.AuthUsername
+	}
+	return ""
+}
+
+func (x *ServerCommand) GetAuthPassword() string {
+	if x != nil {
+		return x.AuthPassword
+	}
+	return ""
+}
+
+func (x *ServerCommand) GetAuthToken() string {
+	if x != nil {
+		return x.AuthToken
+	}
+	return ""
+}
+
+func (x *ServerCommand) GetNoFollowRedirects() bool {
+	if x != nil {
+		return x.NoFollowRedirects
+	}
+	return false
+}
+
+func (x *ServerCommand) GetAssertionOperator() string {
+	if x != nil {
+		return x.AssertionOperator
+	}
+	return ""
+}
+
+func (x *ServerCommand) GetGrpcService() string {
+	if x != nil {
+		return x.GrpcService
+	}
+	return ""
+}
+
+func (x *ServerCommand) GetGrpcTls() bool {
+	if x != nil {
+		return x.GrpcTls
+	}
+	return false
+}
+
+func (x *ServerCommand) GetSmtpStarttls() bool {
+	if x != nil {
+		return x.SmtpStarttls
+	}
+	return false
+}
+
+func (x *ServerCommand) GetExpectedHeaderName() string {
+	if x != nil {
+		return x.ExpectedHeaderName
+	}
+	return ""
+}
+
+func (x *ServerCommand) GetExpectedHeaderValue() string {
+	if x != nil {
+		return x.ExpectedHeaderValue
+	}
+	return ""
+}
+
+func (x *ServerCommand) GetExpectedHeaderMode() string {
+	if x != nil {
+		return x.ExpectedHeaderMode
+	}
+	return ""
+}
+
+func (x *ServerCommand) GetMongoConnString() string {
+	if x != nil {
+		return x.MongoConnString
+	}
+	return ""
+}
+
+func (x *ServerCommand) GetMinResponseBytes() int32 {
+	if x != nil {
+		return x.MinResponseBytes
+	}
+	return 0
+}
+
+func (x *ServerCommand) GetMaxResponseBytes() int32 {
+	if x != nil {
+		return x.MaxResponseBytes
+	}
+	return 0
+}
+
+func (x *ServerCommand) GetExpectedHttpVersion() string {
+	if x != nil {
+		return x.ExpectedHttpVersion
+	}
+	return ""
+}
+
+func (x *ServerCommand) GetProxyUrl() string {
+	if x != nil {
+		return x.ProxyUrl
+	}
+	return ""
+}
+
+func (x *ServerCommand) GetQueryColumn() string {
+	if x != nil {
+		return x.QueryColumn
+	}
+	return ""
+}
+
+func (x *ServerCommand) GetExpectedRowCount() int32 {
+	if x != nil {
+		return x.ExpectedRowCount
+	}
+	return 0
+}
+
+func (x *ServerCommand) GetAssertRowCount() bool {
+	if x != nil {
+		return x.AssertRowCount
+	}
+	return false
+}
+
+func (x *ServerCommand) GetExpectedStatusCodes() []int32 {
+	if x != nil {
+		return x.ExpectedStatusCodes
+	}
+	return nil
+}
+
 var File_monitor_proto protoreflect.FileDescriptor
 
 const file_monitor_proto_rawDesc = "" +
@@ -468,7 +820,7 @@ const file_monitor_proto_rawDesc = "" +
 	"\bRegister\x12\x1f\n" +
 	"\vregion_code\x18\x01 \x01(\tR\n" +
 	"regionCode\x12\x14\n" +
-	"\x05token\x18\x02 \x01(\tR\x05token\"\xbf\x01\n" +
+	"\x05token\x18\x02 \x01(\tR\x05token\"\xde\x01\n" +
 	"\vCheckResult\x12\x19\n" +
 	"\bcheck_id\x18\x01 \x01(\x03R\acheckId\x12\x16\n" +
 	"\x06region\x18\x02 \x01(\tR\x06region\x12\x1f\n" +
@@ -477,9 +829,11 @@ const file_monitor_proto_rawDesc = "" +
 	"\n" +
 	"latency_ms\x18\x04 \x01(\x05R\tlatencyMs\x12\x18\n" +
 	"\asuccess\x18\x05 \x01(\bR\asuccess\x12#\n" +
-	"\rerror_message\x18\x06 \x01(\tR\ferrorMessage\")\n" +
+	"\rerror_message\x18\x06 \x01(\tR\ferrorMessage\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\a \x01(\tR\trequestId\")\n" +
 	"\tHeartbeat\x12\x1c\n" +
-	"\ttimestamp\x18\x01 \x01(\x03R\ttimestamp\"\xa4\x04\n" +
+	"\ttimestamp\x18\x01 \x01(\x03R\ttimestamp\"\xec\x12\n" +
 	"\rServerCommand\x12!\n" +
 	"\fcommand_type\x18\x01 \x01(\tR\vcommandType\x12\x19\n" +
 	"\bcheck_id\x18\x02 \x01(\x03R\acheckId\x12\x1d\n" +
@@ -497,7 +851,56 @@ const file_monitor_proto_rawDesc = "" +
 	"\x06method\x18\f \x01(\tR\x06method\x12'\n" +
 	"\x0ftimeout_seconds\x18\r \x01(\x05R\x0etimeoutSeconds\x12\x1b\n" +
 	"\tjson_path\x18\x0e \x01(\tR\bjsonPath\x12.\n" +
-	"\x13expected_json_value\x18\x0f \x01(\tR\x11expectedJsonValue2T\n" +
+	"\x13expected_json_value\x18\x0f \x01(\tR\x11expectedJsonValue\x12\x12\n" +
+	"\x04port\x18\x10 \x01(\x05R\x04port\x12'\n" +
+	"\x0fexpected_banner\x18\x11 \x01(\tR\x0eexpectedBanner\x12=\n" +
+	"\aheaders\x18\x12 \x03(\v2#.monitor.ServerCommand.HeadersEntryR\aheaders\x12!\n" +
+	"\frequest_body\x18\x13 \x01(\tR\vrequestBody\x12!\n" +
+	"\fcontent_type\x18\x14 \x01(\tR\vcontentType\x12)\n" +
+	"\x10response_keyword\x18\x15 \x01(\tR\x0fresponseKeyword\x122\n" +
+	"\x15response_keyword_mode\x18\x16 \x01(\tR\x13responseKeywordMode\x12)\n" +
+	"\x11ntp_max_offset_ms\x18\x17 \x01(\x05R\x0entpMaxOffsetMs\x12;\n" +
+	"\x1acert_expiry_threshold_days\x18\x18 \x01(\x05R\x17certExpiryThresholdDays\x120\n" +
+	"\x14insecure_skip_verify\x18\x19 \x01(\bR\x12insecureSkipVerify\x124\n" +
+	"\x16forbidden_body_keyword\x18\x1a \x01(\tR\x14forbiddenBodyKeyword\x12-\n" +
+	"\x12expect_unreachable\x18\x1b \x01(\bR\x11expectUnreachable\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x1c \x01(\tR\trequestId\x12/\n" +
+	"\x14max_response_time_ms\x18\x1d \x01(\x05R\x11maxResponseTimeMs\x12!\n" +
+	"\fbypass_cache\x18\x1e \x01(\bR\vbypassCache\x12*\n" +
+	"\x11redis_conn_string\x18\x1f \x01(\tR\x0fredisConnString\x12\x1b\n" +
+	"\tredis_key\x18  \x01(\tR\bredisKey\x120\n" +
+	"\x14expected_redis_value\x18! \x01(\tR\x12expectedRedisValue\x12*\n" +
+	"\x11mysql_conn_string\x18\" \x01(\tR\x0fmysqlConnString\x12!\n" +
+	"\fdns_resolver\x18# \x01(\tR\vdnsResolver\x12\x1d\n" +
+	"\n" +
+	"ping_count\x18$ \x01(\x05R\tpingCount\x12&\n" +
+	"\x0fmax_packet_loss\x18% \x01(\x05R\rmaxPacketLoss\x12\x1b\n" +
+	"\tauth_type\x18& \x01(\tR\bauthType\x12#\n" +
+	"\rauth_username\x18' \x01(\tR\fauthUsername\x12#\n" +
+	"\rauth_password\x18( \x01(\tR\fauthPassword\x12\x1d\n" +
+	"\n" +
+	"auth_token\x18) \x01(\tR\tauthToken\x12.\n" +
+	"\x13no_follow_redirects\x18* \x01(\bR\x11noFollowRedirects\x12-\n" +
+	"\x12assertion_operator\x18+ \x01(\tR\x11assertionOperator\x12!\n" +
+	"\fgrpc_service\x18, \x01(\tR\vgrpcService\x12\x19\n" +
+	"\bgrpc_tls\x18- \x01(\bR\agrpcTls\x12#\n" +
+	"\rsmtp_starttls\x18. \x01(\bR\fsmtpStarttls\x120\n" +
+	"\x14expected_header_name\x18/ \x01(\tR\x12expectedHeaderName\x122\n" +
+	"\x15expected_header_value\x180 \x01(\tR\x13expectedHeaderValue\x120\n" +
+	"\x14expected_header_mode\x181 \x01(\tR\x12expectedHeaderMode\x12*\n" +
+	"\x11mongo_conn_string\x182 \x01(\tR\x0fmongoConnString\x12,\n" +
+	"\x12min_response_bytes\x183 \x01(\x05R\x10minResponseBytes\x12,\n" +
+	"\x12max_response_bytes\x184 \x01(\x05R\x10maxResponseBytes\x122\n" +
+	"\x15expected_http_version\x185 \x01(\tR\x13expectedHttpVersion\x12\x1b\n" +
+	"\tproxy_url\x186 \x01(\tR\bproxyUrl\x12!\n" +
+	"\fquery_column\x187 \x01(\tR\vqueryColumn\x12,\n" +
+	"\x12expected_row_count\x188 \x01(\x05R\x10expectedRowCount\x12(\n" +
+	"\x10assert_row_count\x189 \x01(\bR\x0eassertRowCount\x122\n" +
+	"\x15expected_status_codes\x18: \x03(\x05R\x13expectedStatusCodes\x1a:\n" +
+	"\fHeadersEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x012T\n" +
 	"\bSentinel\x12H\n" +
 	"\x13EstablishConnection\x12\x15.monitor.ProbeMessage\x1a\x16.monitor.ServerCommand(\x010\x01B\x12Z\x10gocheck/proto/pbb\x06proto3"
 
@@ -513,25 +916,27 @@ func file_monitor_proto_rawDescGZIP() []byte {
 	return file_monitor_proto_rawDescData
 }
 
-var file_monitor_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_monitor_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
 var file_monitor_proto_goTypes = []any{
 	(*ProbeMessage)(nil),  // 0: monitor.ProbeMessage
 	(*Register)(nil),      // 1: monitor.Register
 	(*CheckResult)(nil),   // 2: monitor.CheckResult
 	(*Heartbeat)(nil),     // 3: monitor.Heartbeat
 	(*ServerCommand)(nil), // 4: monitor.ServerCommand
+	nil,                   // 5: monitor.ServerCommand.HeadersEntry
 }
 var file_monitor_proto_depIdxs = []int32{
 	1, // 0: monitor.ProbeMessage.register:type_name -> monitor.Register
 	2, // 1: monitor.ProbeMessage.result:type_name -> monitor.CheckResult
 	3, // 2: monitor.ProbeMessage.heartbeat:type_name -> monitor.Heartbeat
-	0, // 3: monitor.Sentinel.EstablishConnection:input_type -> monitor.ProbeMessage
-	4, // 4: monitor.Sentinel.EstablishConnection:output_type -> monitor.ServerCommand
-	4, // [4:5] is the sub-list for method output_type
-	3, // [3:4] is the sub-list for method input_type
-	3, // [3:3] is the sub-list for extension type_name
-	3, // [3:3] is the sub-list for extension extendee
-	0, // [0:3] is the sub-list for field type_name
+	5, // 3: monitor.ServerCommand.headers:type_name -> monitor.ServerCommand.HeadersEntry
+	0, // 4: monitor.Sentinel.EstablishConnection:input_type -> monitor.ProbeMessage
+	4, // 5: monitor.Sentinel.EstablishConnection:output_type -> monitor.ServerCommand
+	5, // [5:6] is the sub-list for method output_type
+	4, // [4:5] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
 }
 
 func init() { file_monitor_proto_init() }
@@ -550,7 +955,7 @@ func file_monitor_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_monitor_proto_rawDesc), len(file_monitor_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   5,
+			NumMessages:   6,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
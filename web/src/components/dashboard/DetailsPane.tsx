@@ -105,7 +105,12 @@ export function DetailsPane({
   const avgLatency = stats ? `${stats.avg_latency}ms` : calculateAvgLatency(history);
   const downCount = stats ? stats.down_count : history.filter((h) => h && !h.success).length;
   const percentileLatency = stats 
-    ? { p90: `${stats.p90_latency}ms`, p99: `${stats.p99_latency}ms` }
+    ? {
+        p50: `${stats.p50_latency}ms`,
+        p90: `${stats.p90_latency}ms`,
+        p95: `${stats.p95_latency}ms`,
+        p99: `${stats.p99_latency}ms`,
+      }
     : calculatePercentiles(history);
   
   const regions = stats?.regions || [];
@@ -356,7 +361,9 @@ export function DetailsPane({
                 <StatBox label="avg latency" value={avgLatency} />
                 <StatBox label="checks" value={stats ? String(stats.total_checks) : String(history.length)} />
                 <StatBox label="down" value={String(downCount)} />
+                <StatBox label="p50 latency" value={percentileLatency.p50} />
                 <StatBox label="p90 latency" value={percentileLatency.p90} />
+                <StatBox label="p95 latency" value={percentileLatency.p95} />
                 <StatBox label="p99 latency" value={percentileLatency.p99} />
               </div>
             )}
@@ -679,18 +686,20 @@ function calculateAvgLatency(history: CheckStatus[]): string {
   return `${avgLatency}ms`;
 }
 
-function calculatePercentiles(history: CheckStatus[]): { p90: string; p99: string } {
-  if (history.length === 0) return { p90: '0ms', p99: '0ms' };
+function calculatePercentiles(history: CheckStatus[]): { p50: string; p90: string; p95: string; p99: string } {
+  const empty = { p50: '0ms', p90: '0ms', p95: '0ms', p99: '0ms' };
+  if (history.length === 0) return empty;
   
   const validHistory = history.filter((h) => h);
-  if (validHistory.length === 0) return { p90: '0ms', p99: '0ms' };
+  if (validHistory.length === 0) return empty;
   
   const latencies = validHistory.map((h) => h.response_time_ms || 0).sort((a, b) => a - b);
-  const p90Index = Math.ceil(latencies.length * 0.9) - 1;
-  const p99Index = Math.ceil(latencies.length * 0.99) - 1;
+  const at = (p: number) => `${latencies[Math.ceil(latencies.length * p) - 1] || 0}ms`;
   
   return {
-    p90: `${latencies[p90Index] || 0}ms`,
-    p99: `${latencies[p99Index] || 0}ms`,
+    p50: at(0.5),
+    p90: at(0.9),
+    p95: at(0.95),
+    p99: at(0.99),
   };
 }
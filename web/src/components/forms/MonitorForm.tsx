@@ -23,7 +23,7 @@ const defaultFormData: CheckFormData = {
   retry_delay_seconds: 5,
   enabled: true,
   method: 'GET',
-  expected_status_codes: [200],
+  expected_status_codes: [],
   json_path: '',
   expected_json_value: '',
   host: '',
@@ -72,7 +72,7 @@ export function MonitorForm({ onSave, onCancel, editingCheck }: MonitorFormProps
         retry_delay_seconds: editingCheck.retry_delay_seconds ?? 5,
         enabled: editingCheck.enabled,
         method: editingCheck.method || 'GET',
-        expected_status_codes: editingCheck.expected_status_codes || [200],
+        expected_status_codes: editingCheck.expected_status_codes || [],
         json_path: editingCheck.json_path || '',
         expected_json_value: editingCheck.expected_json_value || '',
         host: editingCheck.host || '',
@@ -346,13 +346,13 @@ function HTTPFields({ form, isJsonHttp }: { form: any; isJsonHttp: boolean }) {
               </label>
               <input
                 type="text"
-                value={(field.state.value || [200]).join(', ')}
+                value={(field.state.value || []).join(', ')}
                 onChange={(e) => field.handleChange(parseStatusCodes(e.target.value))}
                 className="w-full bg-terminal-bg border border-terminal-border text-terminal-text px-4 py-2 rounded focus:border-terminal-green outline-none transition font-mono"
                 placeholder="200, 201, 204"
               />
               <div className="text-[10px] text-terminal-muted mt-1">
-                Comma-separated list
+                Comma-separated list; leave empty to accept any 2xx or 3xx
               </div>
             </div>
           )}
@@ -25,7 +25,7 @@ const defaultFormData: CheckFormData = {
   retry_delay_seconds: 5,
   enabled: true,
   method: 'GET',
-  expected_status_codes: [200],
+  expected_status_codes: [],
   json_path: '',
   expected_json_value: '',
   host: '',
@@ -83,7 +83,7 @@ export function MonitorFormModal({
           retry_delay_seconds: editingCheck.retry_delay_seconds ?? 5,
           enabled: editingCheck.enabled,
           method: editingCheck.method || 'GET',
-          expected_status_codes: editingCheck.expected_status_codes || [200],
+          expected_status_codes: editingCheck.expected_status_codes || [],
           json_path: editingCheck.json_path || '',
           expected_json_value: editingCheck.expected_json_value || '',
           host: editingCheck.host || '',
@@ -410,7 +410,7 @@ function HTTPFields({ form, isJsonHttp }: { form: any; isJsonHttp: boolean }) {
               </label>
               <input
                 type="text"
-                value={(field.state.value || [200]).join(', ')}
+                value={(field.state.value || []).join(', ')}
                 onChange={(e) =>
                   field.handleChange(parseStatusCodes(e.target.value))
                 }
@@ -418,7 +418,7 @@ function HTTPFields({ form, isJsonHttp }: { form: any; isJsonHttp: boolean }) {
                 placeholder="200, 201, 204"
               />
               <div className="text-[10px] text-terminal-muted mt-1">
-                Comma-separated list
+                Comma-separated list; leave empty to accept any 2xx or 3xx
               </div>
             </div>
           )}
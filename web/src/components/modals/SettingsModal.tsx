@@ -718,12 +718,14 @@ function ProbesTab() {
                       <div
                         className={cn(
                           'text-[10px] px-2 py-0.5 rounded',
-                          probe.status === 'ONLINE'
-                            ? 'bg-terminal-green/20 text-terminal-green'
-                            : 'bg-terminal-red/20 text-terminal-red'
+                          probe.status !== 'ONLINE'
+                            ? 'bg-terminal-red/20 text-terminal-red'
+                            : probe.stale
+                              ? 'bg-terminal-yellow/20 text-terminal-yellow'
+                              : 'bg-terminal-green/20 text-terminal-green'
                         )}
                       >
-                        {probe.status}
+                        {probe.status === 'ONLINE' && probe.stale ? 'STALE' : probe.status}
                       </div>
                     </div>
                     {probe.ip_address && (
@@ -736,6 +738,11 @@ function ProbesTab() {
                         Last seen: {new Date(probe.last_seen_at).toLocaleString()}
                       </div>
                     )}
+                    {probe.last_heartbeat_at && (
+                      <div className="text-[10px] text-terminal-muted mt-1">
+                        Last heartbeat: {new Date(probe.last_heartbeat_at).toLocaleString()}
+                      </div>
+                    )}
                   </div>
                   <div className="flex gap-2">
                     <Button
@@ -23,6 +23,9 @@ async function login(credentials: LoginCredentials): Promise<{ user: User }> {
     headers: { 'Content-Type': 'application/json' },
     body: JSON.stringify(credentials),
   });
+  if (response.status === 429) {
+    throw new Error('Too many failed login attempts, try again later');
+  }
   if (!response.ok) {
     throw new Error('Invalid username or password');
   }
@@ -54,7 +54,7 @@ export function parseStatusCodes(input: string): number[] {
     .split(',')
     .map((s) => parseInt(s.trim()))
     .filter((n) => !isNaN(n));
-  return codes.length > 0 ? codes : [200];
+  return codes;
 }
 
 // Local storage helpers
@@ -261,6 +261,53 @@ function SnapshotsTab({ formData, updateField, onSave, isSaving }: SnapshotsTabP
             className="bg-terminal-surface border-terminal-border text-terminal-text"
           />
         </div>
+
+        <div>
+          <label className="text-xs text-terminal-muted mb-1 block">Refresh Interval (hours)</label>
+          <Input
+            type="number"
+            min={1}
+            max={168}
+            value={formData.snapshot_interval_hours ?? ''}
+            onChange={(e) => updateField('snapshot_interval_hours', parseInt(e.target.value) || undefined)}
+            className="bg-terminal-surface border-terminal-border text-terminal-text"
+          />
+          <div className="text-[10px] text-terminal-muted mt-1">Between 1 and 168 hours</div>
+        </div>
+
+        <div className="grid grid-cols-2 gap-4">
+          <div>
+            <label className="text-xs text-terminal-muted mb-1 block">Viewport Width</label>
+            <Input
+              type="number"
+              min={320}
+              max={3840}
+              value={formData.snapshot_width ?? ''}
+              onChange={(e) => updateField('snapshot_width', parseInt(e.target.value) || undefined)}
+              className="bg-terminal-surface border-terminal-border text-terminal-text"
+            />
+          </div>
+          <div>
+            <label className="text-xs text-terminal-muted mb-1 block">Viewport Height</label>
+            <Input
+              type="number"
+              min={240}
+              max={2160}
+              value={formData.snapshot_height ?? ''}
+              onChange={(e) => updateField('snapshot_height', parseInt(e.target.value) || undefined)}
+              className="bg-terminal-surface border-terminal-border text-terminal-text"
+            />
+          </div>
+        </div>
+
+        <label className="flex items-center gap-2 text-xs text-terminal-muted">
+          <input
+            type="checkbox"
+            checked={formData.snapshot_full_page ?? false}
+            onChange={(e) => updateField('snapshot_full_page', e.target.checked)}
+          />
+          Capture the full scrollable page
+        </label>
       </div>
 
       <Button
@@ -861,12 +908,14 @@ function ProbesTab() {
                       <div
                         className={cn(
                           'text-[10px] px-2 py-0.5 rounded',
-                          probe.status === 'ONLINE'
-                            ? 'bg-terminal-green/20 text-terminal-green'
-                            : 'bg-terminal-red/20 text-terminal-red'
+                          probe.status !== 'ONLINE'
+                            ? 'bg-terminal-red/20 text-terminal-red'
+                            : probe.stale
+                              ? 'bg-terminal-yellow/20 text-terminal-yellow'
+                              : 'bg-terminal-green/20 text-terminal-green'
                         )}
                       >
-                        {probe.status}
+                        {probe.status === 'ONLINE' && probe.stale ? 'STALE' : probe.status}
                       </div>
                     </div>
                     {probe.ip_address && (
@@ -879,6 +928,11 @@ function ProbesTab() {
                         Last seen: {new Date(probe.last_seen_at).toLocaleString()}
                       </div>
                     )}
+                    {probe.last_heartbeat_at && (
+                      <div className="text-[10px] text-terminal-muted mt-1">
+                        Last heartbeat: {new Date(probe.last_heartbeat_at).toLocaleString()}
+                      </div>
+                    )}
                   </div>
                   <div className="flex gap-2">
                     <Button
@@ -3,6 +3,7 @@
 export interface User {
   id: number;
   username: string;
+  role: 'admin' | 'viewer';
 }
 
 export interface AuthState {
@@ -123,7 +124,9 @@ export interface CheckStats {
   success_count: number;
   success_rate: number;
   avg_latency: number;
+  p50_latency: number;
   p90_latency: number;
+  p95_latency: number;
   p99_latency: number;
   down_count: number;
   regions: RegionStats[];
@@ -138,6 +141,10 @@ export interface Settings {
   tailscale_tailnet: string;
   browserless_url: string;
   browserless_token: string;
+  snapshot_interval_hours?: number;
+  snapshot_width?: number;
+  snapshot_height?: number;
+  snapshot_full_page?: boolean;
 }
 
 export interface TailscaleDevice {
@@ -221,7 +228,7 @@ export const DEFAULT_CHECK_FORM_DATA: CheckFormData = {
   retry_delay_seconds: 5,
   enabled: true,
   method: 'GET',
-  expected_status_codes: [200],
+  expected_status_codes: [],
   json_path: '',
   expected_json_value: '',
   host: '',
@@ -257,6 +264,8 @@ export interface Probe {
   version?: string;
   status: 'ONLINE' | 'OFFLINE';
   last_seen_at?: string;
+  last_heartbeat_at?: string;
+  stale: boolean;
 }
 
 export interface CreateProbeRequest {